@@ -1,12 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"todo-cli-refactor/consts"
 	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/delivery/presenter"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/chunk"
+	"todo-cli-refactor/pkg/color"
+	"todo-cli-refactor/pkg/duedate"
+	attachment2 "todo-cli-refactor/services/attachment"
+	category2 "todo-cli-refactor/services/category"
+	task2 "todo-cli-refactor/services/task"
+	template2 "todo-cli-refactor/services/template"
+	view2 "todo-cli-refactor/services/view"
+	workflow2 "todo-cli-refactor/services/workflow"
 )
 
 func main() {
@@ -23,6 +44,28 @@ func main() {
 		message = os.Args[2]
 	}
 
+	var commandArgs []string
+	if len(os.Args) > 3 {
+		commandArgs = os.Args[3:]
+	}
+
+	req, localPath := buildRequest(message, commandArgs)
+
+	// NO_COLOR or a dumb terminal keep the output plain
+	presenter.ColorMode = color.Detect()
+
+	// listings are fetched a page per connection until the last page
+	switch req.Command {
+	case "list-task":
+		listTasks(serverAddress, req)
+
+		return
+	case "list-users":
+		listUsers(serverAddress, req)
+
+		return
+	}
+
 	connection, err := net.Dial("tcp", serverAddress)
 	if err != nil {
 		log.Fatalln("cant dial the server ...", err)
@@ -32,15 +75,6 @@ func main() {
 
 	fmt.Println("local address", connection.LocalAddr())
 
-	req := deliveryParam.Request{Command: message}
-	if req.Command == "create-task" {
-		req.CreateTaskRequest = deliveryParam.CreateTaskRequest{
-			Title:      "test",
-			DueDate:    "test",
-			CategoryID: 1,
-		}
-	}
-
 	serializedData, mErr := json.Marshal(&req)
 	if mErr != nil {
 		log.Fatalln("cant marshal request ", mErr)
@@ -53,11 +87,1005 @@ func main() {
 
 	fmt.Println("number of written bytes: ", numberOfWrittenBytes)
 
-	var data = make([]byte, 1024)
-	_, rErr := connection.Read(data)
+	switch req.Command {
+	case "upload-attachment":
+		// a failed upload is answered before all content was read, the
+		// response still tells why
+		if uErr := uploadFile(connection, localPath); uErr != nil {
+			fmt.Println("cant upload file: ", uErr)
+		}
+	case "download-attachment":
+		if dErr := downloadFile(connection, localPath); dErr != nil {
+			log.Fatalln("cant download attachment: ", dErr)
+		}
+
+		return
+	}
+
+	// the server closes the connection once the response is written
+	data, rErr := io.ReadAll(connection)
 	if rErr != nil {
 		log.Fatalln("cant read data from connection: ", rErr)
 	}
 
-	fmt.Println("server response: ", string(data))
+	printResponse(req.Command, data)
+}
+
+// roundTrip sends a request on a connection of its own and returns the
+// whole response.
+func roundTrip(serverAddress string, req deliveryParam.Request) []byte {
+	connection, err := net.Dial("tcp", serverAddress)
+	if err != nil {
+		log.Fatalln("cant dial the server ...", err)
+	}
+
+	defer connection.Close()
+
+	serializedData, mErr := json.Marshal(&req)
+	if mErr != nil {
+		log.Fatalln("cant marshal request ", mErr)
+	}
+
+	if _, wErr := connection.Write(serializedData); wErr != nil {
+		log.Fatalln("cant write to connection ", wErr)
+	}
+
+	data, rErr := io.ReadAll(connection)
+	if rErr != nil {
+		log.Fatalln("cant read data from connection: ", rErr)
+	}
+
+	return data
+}
+
+// listTasks fetches every page of a task listing and prints them as one.
+// Pages follow the first page's cursor, so tasks changed meanwhile neither
+// repeat nor go missing.
+func listTasks(serverAddress string, req deliveryParam.Request) {
+	list := deliveryParam.ListTaskResponse{Progress: map[int]int{}, OpenBlockers: map[int][]int{}}
+
+	for {
+		data := roundTrip(serverAddress, req)
+
+		page := deliveryParam.ListTaskResponse{}
+		if uErr := json.Unmarshal(data, &page); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		list.Tasks = append(list.Tasks, page.Tasks...)
+		for id, percentage := range page.Progress {
+			list.Progress[id] = percentage
+		}
+		for id, open := range page.OpenBlockers {
+			list.OpenBlockers[id] = open
+		}
+		list.Group, list.Categories, list.Calendar = page.Group, page.Categories, page.Calendar
+
+		if page.NextCursor == "" {
+			break
+		}
+		req.ListTaskRequest.Cursor = page.NextCursor
+	}
+
+	for _, line := range presenter.TaskGroups(list, time.Now()) {
+		fmt.Println(line)
+	}
+}
+
+// listUsers fetches and prints every page of users.
+func listUsers(serverAddress string, req deliveryParam.Request) {
+	for {
+		data := roundTrip(serverAddress, req)
+
+		page := deliveryParam.ListUsersResponse{}
+		if uErr := json.Unmarshal(data, &page); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, u := range page.Users {
+			fmt.Printf("#%d %s <%s>\n", u.ID, u.Name, u.Email)
+		}
+
+		if page.NextCursor == "" {
+			return
+		}
+		req.ListUsersRequest.Cursor = page.NextCursor
+	}
+}
+
+// buildRequest parses the flags of a command into the request sent to the
+// server. Credentials default to the TODO_EMAIL and TODO_PASSWORD variables.
+// Commands transferring a file also return the path of the local file.
+func buildRequest(command string, args []string) (deliveryParam.Request, string) {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	email := flags.String("email", os.Getenv("TODO_EMAIL"), "email to log in with")
+	password := flags.String("password", os.Getenv("TODO_PASSWORD"), "password to log in with")
+
+	req := deliveryParam.Request{Command: command}
+	var localPath string
+
+	switch command {
+	case "create-task":
+		title := flags.String("title", "test", "title of the task")
+		description := flags.String("description", "", "markdown notes on the task")
+		descriptionFile := flags.String("description-file", "", "file to read the markdown notes from, - for stdin")
+		due := flags.String("due", "", "due date, e.g. 2026-11-01, 2026-11-01T14:30, 1405/08/10, tomorrow 09:00, next fri, +3d")
+		categoryID := flags.Int("category", 0, "category id of the task, 0 for none")
+		priority := flags.String("priority", "", "priority of the task: none, low, medium, high or urgent")
+		tags := flags.String("tags", "", "comma separated tags of the task, e.g. @waiting,#release-3")
+		repeat := flags.String("repeat", "", "recurrence rule, e.g. daily, weekly or FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=6")
+		parentID := flags.Int("parent", 0, "id of the task this one is a subtask of")
+		milestoneID := flags.Int("milestone", 0, "id of the project milestone the task is planned for")
+		checklist := flags.String("checklist", "", "comma separated checklist items")
+		fields := nameValues{}
+		flags.Var(fields, "field", "custom field value as name=value, may be repeated")
+		flags.Parse(args)
+
+		// relative dates are resolved here so they follow the client's clock and time zone
+		dueDate, pErr := duedate.Parse(*due, time.Now())
+		if pErr != nil {
+			log.Fatalln("invalid due date ", pErr)
+		}
+
+		if *descriptionFile != "" {
+			*description = readDescription(*descriptionFile)
+		}
+
+		req.CreateTaskRequest = deliveryParam.CreateTaskRequest{
+			Title:       *title,
+			Description: *description,
+			DueDate:     dueDate.String(),
+			CategoryID:  *categoryID,
+			Priority:    *priority,
+			Tags:        splitList(*tags),
+			Recurrence:  *repeat,
+			ParentID:    *parentID,
+			MilestoneID: *milestoneID,
+			Checklist:   splitList(*checklist),
+			Fields:      fields,
+		}
+	case "list-task":
+		sortOption := flags.String("sort", "", "order of the tasks: smart (default), priority, due, created or title")
+		tags := flags.String("tag", "", "comma separated tags a task must all carry")
+		excludeTags := flags.String("exclude-tag", "", "comma separated tags a task must not carry")
+		ready := flags.Bool("ready", false, "only list open tasks that aren't blocked")
+		query := flags.String("query", "", `filter expression, e.g. 'status:open due<+1w tag:work -tag:someday title~"deploy"'`)
+		viewName := flags.String("view", "", "saved or built-in view to list, see list-views")
+		group := flags.String("group", "", "group the tasks by category, priority or due")
+		pageSize := flags.Int("page-size", 50, "number of tasks fetched per request")
+		flags.Parse(args)
+
+		// the filter may also follow the flags, as in "list-task status:open tag:work"
+		if flags.NArg() > 0 {
+			*query = strings.TrimSpace(*query + " " + strings.Join(flags.Args(), " "))
+		}
+
+		req.ListTaskRequest = deliveryParam.ListTaskRequest{
+			Sort:        *sortOption,
+			Tags:        splitList(*tags),
+			ExcludeTags: splitList(*excludeTags),
+			Ready:       *ready,
+			Query:       *query,
+			View:        *viewName,
+			Group:       *group,
+			Limit:       *pageSize,
+		}
+	case "list-users":
+		pageSize := flags.Int("page-size", 50, "number of users fetched per request")
+		flags.Parse(args)
+
+		req.ListUsersRequest = deliveryParam.ListUsersRequest{Limit: *pageSize}
+	case "save-view":
+		name := flags.String("name", "", "name of the view")
+		query := flags.String("query", "", "filter expression of the view")
+		sortOption := flags.String("sort", "", "order of the tasks: smart (default), priority, due, created or title")
+		group := flags.String("group", "", "group the tasks by category, priority or due")
+		flags.Parse(args)
+
+		req.ViewRequest = deliveryParam.ViewRequest{
+			Name:  *name,
+			Query: *query,
+			Sort:  *sortOption,
+			Group: *group,
+		}
+	case "delete-view":
+		name := flags.String("name", "", "name of the view")
+		flags.Parse(args)
+
+		req.ViewRequest = deliveryParam.ViewRequest{Name: *name}
+	case "save-template":
+		name := flags.String("name", "", "name of the template")
+		file := flags.String("file", "", "JSON file listing the tasks of the template, - for stdin")
+		flags.Parse(args)
+
+		req.TemplateRequest = deliveryParam.TemplateRequest{Name: *name, Tasks: readTemplateTasks(*file)}
+	case "delete-template":
+		name := flags.String("name", "", "name of the template")
+		flags.Parse(args)
+
+		req.TemplateRequest = deliveryParam.TemplateRequest{Name: *name}
+	case "apply-template":
+		name := flags.String("name", "", "name of the template")
+		start := flags.String("start", "", "day the due offsets count from, e.g. 2026-11-01 or next mon, today by default")
+		values := nameValues{}
+		flags.Var(values, "set", "value of a placeholder as name=value, may be repeated")
+		flags.Parse(args)
+
+		// the start day is resolved here so offsets follow the client's clock and time zone
+		startDate, pErr := duedate.Parse(*start, time.Now())
+		if pErr != nil {
+			log.Fatalln("invalid start ", pErr)
+		}
+
+		req.TemplateRequest = deliveryParam.TemplateRequest{Name: *name, Values: values, Start: startTime(startDate)}
+	case "create-project", "update-project":
+		projectID := flags.Int("id", 0, "id of the project to update")
+		title := flags.String("title", "", "title of the project")
+		description := flags.String("description", "", "what the project is about")
+		flags.Parse(args)
+
+		projectRequest := deliveryParam.ProjectRequest{ProjectID: *projectID}
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "title":
+				projectRequest.Title = title
+			case "description":
+				projectRequest.Description = description
+			}
+		})
+
+		req.ProjectRequest = projectRequest
+	case "delete-project", "project-progress":
+		projectID := flags.Int("id", 0, "id of the project")
+		flags.Parse(args)
+
+		req.ProjectRequest = deliveryParam.ProjectRequest{ProjectID: *projectID}
+	case "create-milestone", "update-milestone":
+		projectID := flags.Int("project", 0, "id of the project the milestone belongs to")
+		milestoneID := flags.Int("id", 0, "id of the milestone to update")
+		title := flags.String("title", "", "title of the milestone")
+		target := flags.String("target", "", "target date, e.g. 2026-12-01, 1405/09/10 or +4w, empty to remove it")
+		flags.Parse(args)
+
+		projectRequest := deliveryParam.ProjectRequest{ProjectID: *projectID, MilestoneID: *milestoneID}
+
+		// only the flags given on the command line are sent
+		var dErr error
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "title":
+				projectRequest.Title = title
+			case "target":
+				var targetDate models.DueDate
+				targetDate, dErr = duedate.Parse(*target, time.Now())
+				formatted := targetDate.String()
+				projectRequest.TargetDate = &formatted
+			}
+		})
+		if dErr != nil {
+			log.Fatalln("invalid target date ", dErr)
+		}
+
+		req.ProjectRequest = projectRequest
+	case "delete-milestone":
+		milestoneID := flags.Int("id", 0, "id of the milestone")
+		flags.Parse(args)
+
+		req.ProjectRequest = deliveryParam.ProjectRequest{MilestoneID: *milestoneID}
+	case "add-tag", "remove-tag":
+		taskID := flags.Int("id", 0, "id of the task")
+		tags := flags.String("tags", "", "comma separated tags")
+		flags.Parse(args)
+
+		req.TagTaskRequest = deliveryParam.TagTaskRequest{
+			TaskID: *taskID,
+			Tags:   splitList(*tags),
+		}
+	case "add-item", "check-item", "uncheck-item", "remove-item":
+		taskID := flags.Int("id", 0, "id of the task")
+		text := flags.String("text", "", "text of the checklist item to add")
+		index := flags.Int("index", 0, "position of the checklist item, starting at 0")
+		flags.Parse(args)
+
+		req.ChecklistRequest = deliveryParam.ChecklistRequest{
+			TaskID: *taskID,
+			Text:   *text,
+			Index:  *index,
+		}
+	case "add-dependency", "remove-dependency":
+		taskID := flags.Int("id", 0, "id of the blocked task")
+		blockerID := flags.Int("blocker", 0, "id of the task it waits on")
+		flags.Parse(args)
+
+		req.DependencyRequest = deliveryParam.DependencyRequest{
+			TaskID:    *taskID,
+			BlockerID: *blockerID,
+		}
+	case "critical-path":
+		taskID := flags.Int("id", 0, "id of the task the path ends at, 0 for the longest path overall")
+		flags.Parse(args)
+
+		req.CriticalPathRequest = deliveryParam.CriticalPathRequest{TaskID: *taskID}
+	case "rename-tag":
+		from := flags.String("from", "", "tag to rename")
+		to := flags.String("to", "", "new name of the tag")
+		flags.Parse(args)
+
+		req.RenameTagRequest = deliveryParam.RenameTagRequest{
+			From: *from,
+			To:   *to,
+		}
+	case "update-task":
+		taskID := flags.Int("id", 0, "id of the task to update")
+		title := flags.String("title", "", "new title of the task")
+		description := flags.String("description", "", "new markdown notes on the task, empty to remove them")
+		descriptionFile := flags.String("description-file", "", "file to read the new markdown notes from, - for stdin")
+		due := flags.String("due", "", "new due date, empty to remove it")
+		categoryID := flags.Int("category", 0, "new category id of the task")
+		priority := flags.String("priority", "", "new priority of the task")
+		parentID := flags.Int("parent", 0, "id of the new parent task, 0 to move it to the top level")
+		milestoneID := flags.Int("milestone", 0, "id of the new milestone of the task, 0 to take it out of its project")
+		fields := nameValues{}
+		flags.Var(fields, "field", "custom field value as name=value, name= to clear it, may be repeated")
+		flags.Parse(args)
+
+		updateRequest := deliveryParam.UpdateTaskRequest{TaskID: *taskID}
+
+		// only the flags given on the command line are sent as changes
+		var dErr error
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "title":
+				updateRequest.Title = title
+			case "description":
+				updateRequest.Description = description
+			case "description-file":
+				notes := readDescription(*descriptionFile)
+				updateRequest.Description = &notes
+			case "due":
+				var dueDate models.DueDate
+				dueDate, dErr = duedate.Parse(*due, time.Now())
+				formatted := dueDate.String()
+				updateRequest.DueDate = &formatted
+			case "category":
+				updateRequest.CategoryID = categoryID
+			case "priority":
+				updateRequest.Priority = priority
+			case "parent":
+				updateRequest.ParentID = parentID
+			case "milestone":
+				updateRequest.MilestoneID = milestoneID
+			case "field":
+				updateRequest.Fields = fields
+			}
+		})
+		if dErr != nil {
+			log.Fatalln("invalid due date ", dErr)
+		}
+
+		req.UpdateTaskRequest = updateRequest
+	case "show-task":
+		taskID := flags.Int("id", 0, "id of the task to show")
+		flags.Parse(args)
+
+		req.ShowTaskRequest = deliveryParam.ShowTaskRequest{TaskID: *taskID}
+	case "complete-task":
+		taskID := flags.Int("id", 0, "id of the task to complete")
+		force := flags.Bool("force", false, "complete the task along with its open subtasks and checklist items")
+		flags.Parse(args)
+
+		req.CompleteTaskRequest = deliveryParam.CompleteTaskRequest{
+			TaskID: *taskID,
+			Force:  *force,
+		}
+	case "move-task":
+		taskID := flags.Int("id", 0, "id of the task to move")
+		status := flags.String("status", "", "workflow status to move the task to")
+		force := flags.Bool("force", false, "complete the task along with its open subtasks and checklist items when the status is a done one")
+		flags.Parse(args)
+
+		req.MoveTaskRequest = deliveryParam.MoveTaskRequest{
+			TaskID: *taskID,
+			Status: *status,
+			Force:  *force,
+		}
+	case "assign-task":
+		taskID := flags.Int("id", 0, "id of the task to assign")
+		email := flags.String("user", "", "email of the user to assign the task to, empty to unassign it")
+		flags.Parse(args)
+
+		req.AssignTaskRequest = deliveryParam.AssignTaskRequest{TaskID: *taskID, Email: *email}
+	case "transfer-task":
+		taskID := flags.Int("id", 0, "id of the task to transfer along with its subtasks")
+		email := flags.String("user", "", "email of the user to make the owner of the task")
+		flags.Parse(args)
+
+		req.AssignTaskRequest = deliveryParam.AssignTaskRequest{TaskID: *taskID, Email: *email}
+	case "board":
+		query := flags.String("query", "", "filter query choosing the tasks on the board, e.g. category:Work")
+		flags.Parse(args)
+
+		req.BoardRequest = deliveryParam.BoardRequest{Query: *query}
+	case "save-workflow":
+		file := flags.String("file", "", "JSON file with the statuses of the workflow and its transitions, - for stdin")
+		flags.Parse(args)
+
+		req.WorkflowRequest = readWorkflow(*file)
+	case "upload-attachment":
+		taskID := flags.Int("id", 0, "id of the task to attach the file to")
+		file := flags.String("file", "", "path of the file to attach")
+		flags.Parse(args)
+
+		if _, sErr := os.Stat(*file); sErr != nil {
+			log.Fatalln("cant read file ", sErr)
+		}
+
+		localPath = *file
+		req.AttachmentRequest = deliveryParam.AttachmentRequest{
+			TaskID: *taskID,
+			Name:   filepath.Base(*file),
+		}
+	case "list-attachments":
+		taskID := flags.Int("id", 0, "id of the task")
+		flags.Parse(args)
+
+		req.AttachmentRequest = deliveryParam.AttachmentRequest{TaskID: *taskID}
+	case "download-attachment", "remove-attachment":
+		attachmentID := flags.Int("id", 0, "id of the attachment")
+		out := flags.String("out", "", "path to save the attachment to, its own name by default")
+		flags.Parse(args)
+
+		localPath = *out
+		req.AttachmentRequest = deliveryParam.AttachmentRequest{AttachmentID: *attachmentID}
+	case "create-category":
+		title := flags.String("title", "", "title of the category")
+		categoryColor := flags.String("color", "", "color of the category, a hex code like #ff8800 or one of "+strings.Join(color.Names(), ", "))
+		parentID := flags.Int("parent", 0, "id of the category to nest the category in")
+		fields := fieldDefs{}
+		flags.Var(&fields, "field", "custom field of its tasks as name:type, e.g. ticket:url or severity:enum:low|high, may be repeated")
+		flags.Parse(args)
+
+		req.CategoryRequest = deliveryParam.CategoryRequest{
+			Title:    *title,
+			Color:    *categoryColor,
+			ParentID: *parentID,
+			Fields:   fields,
+		}
+	case "define-fields":
+		categoryID := flags.Int("id", 0, "id of the category")
+		fields := fieldDefs{}
+		flags.Var(&fields, "field", "custom field of its tasks as name:type, e.g. ticket:url or severity:enum:low|high, may be repeated")
+		flags.Parse(args)
+
+		req.CategoryRequest = deliveryParam.CategoryRequest{
+			CategoryID: *categoryID,
+			Fields:     fields,
+		}
+	case "move-category":
+		categoryID := flags.Int("id", 0, "id of the category")
+		parentID := flags.Int("parent", 0, "id of the category to nest the category in, 0 for the top level")
+		flags.Parse(args)
+
+		req.CategoryRequest = deliveryParam.CategoryRequest{
+			CategoryID: *categoryID,
+			ParentID:   *parentID,
+		}
+	case "share-category", "revoke-category":
+		categoryID := flags.Int("id", 0, "id of the category")
+		email := flags.String("user", "", "email of the user to share the category with or revoke it from")
+		role := flags.String("role", "viewer", "role to share the category with: viewer, editor or admin")
+		flags.Parse(args)
+
+		req.ShareCategoryRequest = deliveryParam.ShareCategoryRequest{
+			CategoryID: *categoryID,
+			Email:      *email,
+			Role:       *role,
+		}
+	case "task-history":
+		taskID := flags.Int("id", 0, "id of the task")
+		flags.Parse(args)
+
+		// the id may also be given on its own, as in "task-history 3"
+		if flags.NArg() > 0 {
+			id, aErr := strconv.Atoi(flags.Arg(0))
+			if aErr != nil {
+				log.Fatalln("invalid task id ", flags.Arg(0))
+			}
+			*taskID = id
+		}
+
+		req.TaskHistoryRequest = deliveryParam.TaskHistoryRequest{TaskID: *taskID}
+	case "add-comment", "list-comments":
+		taskID := flags.Int("id", 0, "id of the task")
+		text := flags.String("text", "", "text of the comment, mentioning users as @name")
+		flags.Parse(args)
+
+		req.CommentRequest = deliveryParam.CommentRequest{TaskID: *taskID, Text: *text}
+	case "edit-comment", "delete-comment":
+		commentID := flags.Int("comment", 0, "id of the comment")
+		text := flags.String("text", "", "new text of the comment, mentioning users as @name")
+		flags.Parse(args)
+
+		req.CommentRequest = deliveryParam.CommentRequest{CommentID: *commentID, Text: *text}
+	case "search":
+		limit := flags.Int("limit", 10, "how many tasks to show at most")
+		flags.Parse(args)
+
+		// the query is what's left after the flags, as in "search -limit 5 buy milk"
+		req.SearchRequest = deliveryParam.SearchRequest{
+			Query: strings.Join(flags.Args(), " "),
+			Limit: *limit,
+		}
+	case "delete-task", "restore-task":
+		taskID := flags.Int("id", 0, "id of the task")
+		flags.Parse(args)
+
+		req.TrashRequest = deliveryParam.TrashRequest{TaskID: *taskID}
+	case "delete-category", "restore-category":
+		categoryID := flags.Int("id", 0, "id of the category")
+		flags.Parse(args)
+
+		req.TrashRequest = deliveryParam.TrashRequest{CategoryID: *categoryID}
+	case "update-profile":
+		calendar := flags.String("calendar", consts.GregorianCalendar, "calendar to render dates in, gregorian or jalali")
+		flags.Parse(args)
+
+		req.UpdateProfileRequest = deliveryParam.UpdateProfileRequest{
+			Calendar: *calendar,
+		}
+	default:
+		flags.Parse(args)
+	}
+
+	req.Credentials = deliveryParam.Credentials{Email: *email, Password: *password}
+
+	return req, localPath
+}
+
+// uploadFile streams the content of the file at path as chunks.
+func uploadFile(connection net.Conn, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := chunk.NewWriter(connection)
+	if _, err := io.Copy(w, file); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// downloadFile reads the attachment's metadata line and saves the chunked
+// content following it to path, checking it against the attachment's hash.
+// A server error is sent as plain text in place of the metadata.
+func downloadFile(connection net.Conn, path string) error {
+	reader := bufio.NewReader(connection)
+
+	header, err := reader.ReadBytes('\n')
+	if err != nil {
+		fmt.Println("server response: ", string(header))
+
+		return nil
+	}
+
+	attachment := models.Attachment{}
+	if uErr := json.Unmarshal(header, &attachment); uErr != nil {
+		return uErr
+	}
+
+	if path == "" {
+		path = filepath.Base(attachment.Name)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, h), chunk.NewReader(reader))
+	if err != nil {
+		return err
+	}
+
+	if hash := hex.EncodeToString(h.Sum(nil)); hash != attachment.Hash {
+		return fmt.Errorf("content of %s is corrupted, its hash is %s instead of %s", path, hash, attachment.Hash)
+	}
+
+	fmt.Printf("saved %s (%d bytes) to %s\n", attachment.Name, size, path)
+
+	return nil
+}
+
+// readDescription reads task notes from a file, or from stdin for "-".
+func readDescription(path string) string {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		log.Fatalln("cant read description ", err)
+	}
+
+	return string(data)
+}
+
+// readTemplateTasks reads the tasks of a template from a JSON file, or from
+// stdin for "-".
+func readTemplateTasks(path string) []models.TemplateTask {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		log.Fatalln("cant read template ", err)
+	}
+
+	var tasks []models.TemplateTask
+	if uErr := json.Unmarshal(data, &tasks); uErr != nil {
+		log.Fatalln("cant parse template ", uErr)
+	}
+
+	return tasks
+}
+
+// readWorkflow reads a workflow from a JSON file, or from stdin for "-".
+func readWorkflow(path string) deliveryParam.WorkflowRequest {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		log.Fatalln("cant read workflow ", err)
+	}
+
+	var workflow deliveryParam.WorkflowRequest
+	if uErr := json.Unmarshal(data, &workflow); uErr != nil {
+		log.Fatalln("cant parse workflow ", uErr)
+	}
+
+	return workflow
+}
+
+// startTime turns the start of an applied template into the moment offsets
+// count from. A day without a time starts at local midnight.
+func startTime(d models.DueDate) time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+
+	if d.AllDay {
+		y, m, day := d.Date()
+		return time.Date(y, m, day, 0, 0, 0, 0, time.Local)
+	}
+
+	return d.Time
+}
+
+// nameValues collects repeated name=value flags, like -set and -field.
+type nameValues map[string]string
+
+func (p nameValues) String() string {
+	pairs := make([]string, 0, len(p))
+	for name, value := range p {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (p nameValues) Set(pair string) error {
+	i := strings.Index(pair, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected name=value, got %q", pair)
+	}
+
+	p[pair[:i]] = pair[i+1:]
+
+	return nil
+}
+
+// fieldDefs collects repeated -field name:type flags, enum options follow
+// the type separated by |, as in severity:enum:low|medium|high.
+type fieldDefs []models.FieldDef
+
+func (f *fieldDefs) String() string {
+	defs := make([]string, 0, len(*f))
+	for _, def := range *f {
+		defs = append(defs, def.Name+":"+string(def.Type))
+	}
+
+	return strings.Join(defs, ",")
+}
+
+func (f *fieldDefs) Set(spec string) error {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) < 2 {
+		return fmt.Errorf("expected name:type, got %q", spec)
+	}
+
+	def := models.FieldDef{Name: parts[0], Type: models.FieldType(parts[1])}
+	if len(parts) == 3 {
+		def.Options = strings.Split(parts[2], "|")
+	}
+	*f = append(*f, def)
+
+	return nil
+}
+
+// idList renders record IDs as "#1, #2".
+func idList(ids []int) string {
+	refs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, fmt.Sprintf("#%d", id))
+	}
+
+	return strings.Join(refs, ", ")
+}
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func printResponse(command string, data []byte) {
+	switch command {
+	case "show-task":
+		response := deliveryParam.ShowTaskResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.TaskDetail(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "task-history":
+		response := deliveryParam.TaskHistoryResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.History(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "list-comments":
+		response := deliveryParam.CommentsResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Comments(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "mentions":
+		response := deliveryParam.MentionsResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Mentions(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "search":
+		response := deliveryParam.SearchResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.SearchResults(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "list-categories":
+		response := category2.ListResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Categories(response.Categories, response.Roles) {
+			fmt.Println(line)
+		}
+	case "trash":
+		response := deliveryParam.TrashResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Trash(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "list-attachments":
+		response := attachment2.ListResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, a := range response.Attachments {
+			fmt.Printf("#%d %s (%d bytes, %s)\n", a.ID, a.Name, a.Size, a.Hash[:12])
+		}
+		fmt.Printf("%d of %d bytes used\n", response.Usage, response.Quota)
+	case "critical-path":
+		response := task2.CriticalPathResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		steps := make([]string, 0, len(response.Tasks))
+		for _, t := range response.Tasks {
+			steps = append(steps, fmt.Sprintf("#%d %s", t.ID, t.Title))
+		}
+		fmt.Println(strings.Join(steps, " -> "))
+	case "list-views":
+		response := view2.ListResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Views(response.Views) {
+			fmt.Println(line)
+		}
+	case "undo", "redo":
+		response := deliveryParam.JournalResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		var records []string
+		if len(response.TaskIDs) > 0 {
+			records = append(records, "tasks "+idList(response.TaskIDs))
+		}
+		if len(response.CategoryIDs) > 0 {
+			records = append(records, "categories "+idList(response.CategoryIDs))
+		}
+		verb := "undid"
+		if command == "redo" {
+			verb = "redid"
+		}
+		fmt.Printf("%s %s: %s\n", verb, response.Command, strings.Join(records, ", "))
+	case "list-projects":
+		response := deliveryParam.ProjectsResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Projects(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "project-progress":
+		response := deliveryParam.ProjectProgressResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.ProjectProgress(response, time.Now()) {
+			fmt.Println(line)
+		}
+	case "list-templates":
+		response := deliveryParam.TemplatesResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Templates(response) {
+			fmt.Println(line)
+		}
+	case "apply-template":
+		response := template2.ApplyResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		ids := make([]int, 0, len(response.Tasks))
+		for _, t := range response.Tasks {
+			ids = append(ids, t.ID)
+		}
+		fmt.Printf("created %d tasks: %s\n", len(ids), idList(ids))
+	case "list-tags":
+		response := task2.ListTagsResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, tag := range response.Tags {
+			fmt.Printf("%s (%d)\n", tag.Tag, tag.Count)
+		}
+	case "board":
+		response := deliveryParam.BoardResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Board(response) {
+			fmt.Println(line)
+		}
+	case "show-workflow":
+		response := workflow2.GetResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Workflow(response.Workflow) {
+			fmt.Println(line)
+		}
+	case "save-workflow", "reset-workflow":
+		response := workflow2.SaveResponse{}
+		if uErr := json.Unmarshal(data, &response); uErr != nil {
+			fmt.Println("server response: ", string(data))
+
+			return
+		}
+
+		for _, line := range presenter.Workflow(response.Workflow) {
+			fmt.Println(line)
+		}
+		if len(response.Migrated) > 0 {
+			ids := make([]int, 0, len(response.Migrated))
+			for _, t := range response.Migrated {
+				ids = append(ids, t.ID)
+			}
+			fmt.Printf("moved %d tasks: %s\n", len(ids), idList(ids))
+		}
+	default:
+		fmt.Println("server response: ", string(data))
+	}
 }
@@ -4,3 +4,8 @@ const (
 	TextSerializationMode = "text"
 	JsonSerializationMode = "json"
 )
+
+const (
+	GregorianCalendar = "gregorian"
+	JalaliCalendar    = "jalali"
+)
@@ -1,12 +1,266 @@
 package deliveryParam
 
+import (
+	"time"
+	"todo-cli-refactor/models"
+)
+
 type Request struct {
-	Command           string
-	CreateTaskRequest CreateTaskRequest
+	Command              string
+	Credentials          Credentials
+	CreateTaskRequest    CreateTaskRequest
+	UpdateProfileRequest UpdateProfileRequest
+	CompleteTaskRequest  CompleteTaskRequest
+	ListTaskRequest      ListTaskRequest
+	UpdateTaskRequest    UpdateTaskRequest
+	TagTaskRequest       TagTaskRequest
+	RenameTagRequest     RenameTagRequest
+	ChecklistRequest     ChecklistRequest
+	DependencyRequest    DependencyRequest
+	CriticalPathRequest  CriticalPathRequest
+	ShowTaskRequest      ShowTaskRequest
+	AttachmentRequest    AttachmentRequest
+	CategoryRequest      CategoryRequest
+	TrashRequest         TrashRequest
+	TaskHistoryRequest   TaskHistoryRequest
+	SearchRequest        SearchRequest
+	ViewRequest          ViewRequest
+	ListUsersRequest     ListUsersRequest
+	TemplateRequest      TemplateRequest
+	ProjectRequest       ProjectRequest
+	WorkflowRequest      WorkflowRequest
+	MoveTaskRequest      MoveTaskRequest
+	BoardRequest         BoardRequest
+	AssignTaskRequest    AssignTaskRequest
+	ShareCategoryRequest ShareCategoryRequest
+	CommentRequest       CommentRequest
+}
+
+// Credentials authenticate the user a request is made on behalf of.
+type Credentials struct {
+	Email    string
+	Password string
 }
 
 type CreateTaskRequest struct {
-	Title      string
+	Title string
+	// Description holds free-form markdown notes, it may span several lines.
+	Description string
+	// DueDate accepts ISO-8601 dates and timestamps, Jalali dates as well as
+	// relative inputs such as "tomorrow", "next fri" or "+3d".
 	DueDate    string
 	CategoryID int
+	// Priority is one of none, low, medium, high or urgent.
+	Priority string
+	Tags     []string
+	// Recurrence is an RRULE such as FREQ=WEEKLY;BYDAY=MO,TH or a bare
+	// frequency like "daily". Empty for tasks that don't repeat.
+	Recurrence string
+	ParentID   int
+	// MilestoneID plans the task for a project milestone.
+	MilestoneID int
+	Checklist   []string
+	// Fields holds values of the custom fields of the task's category.
+	Fields map[string]string
+}
+
+type UpdateProfileRequest struct {
+	Calendar string
+}
+
+type CompleteTaskRequest struct {
+	TaskID int
+	// Force completes the task even when it has open subtasks or checklist items.
+	Force bool
+}
+
+type ListTaskRequest struct {
+	// Sort is one of smart, priority, due, created or title.
+	Sort        string
+	Tags        []string
+	ExcludeTags []string
+	// Ready only lists open tasks that aren't waiting on an open blocker.
+	Ready bool
+	// Query is a filter expression, e.g.
+	// status:open due<2026-11-01 category:work tag:urgent -tag:someday title~"deploy"
+	Query string
+	// View names a saved or built-in view to list. Query further narrows it
+	// down and Sort and Group, when set, override the view's.
+	View string
+	// Group is one of category, priority or due.
+	Group string
+	// Limit caps the number of tasks returned, zero returns them all. Cursor
+	// is the NextCursor of the previous page.
+	Limit  int
+	Cursor string
+}
+
+// UpdateTaskRequest changes the fields of a task that are set.
+type UpdateTaskRequest struct {
+	TaskID      int
+	Title       *string
+	Description *string
+	DueDate     *string
+	CategoryID  *int
+	Priority    *string
+	ParentID    *int
+	// MilestoneID moves the task to a milestone, or out of its project
+	// when it points to zero.
+	MilestoneID *int
+	// Fields sets values of custom fields, an empty value clears one.
+	Fields map[string]string
+}
+
+// TagTaskRequest adds tags to or removes tags from a task.
+type TagTaskRequest struct {
+	TaskID int
+	Tags   []string
+}
+
+type RenameTagRequest struct {
+	From string
+	To   string
+}
+
+// ChecklistRequest adds, checks, unchecks or removes a checklist item. Text
+// is used when adding, Index to address an existing item.
+type ChecklistRequest struct {
+	TaskID int
+	Text   string
+	Index  int
+}
+
+type ShowTaskRequest struct {
+	TaskID int
+}
+
+// DependencyRequest makes a task wait on, or stop waiting on, a blocker task.
+type DependencyRequest struct {
+	TaskID    int
+	BlockerID int
+}
+
+// CriticalPathRequest asks for the longest chain of open blockers ending at
+// TaskID, or at any task when TaskID is zero.
+type CriticalPathRequest struct {
+	TaskID int
+}
+
+// AttachmentRequest addresses the attachments of a task. TaskID and Name are
+// used when uploading or listing, AttachmentID to address an existing
+// attachment. The content of an upload follows the request on the connection
+// as a chunked stream.
+type AttachmentRequest struct {
+	TaskID       int
+	Name         string
+	AttachmentID int
+}
+
+// CategoryRequest holds the fields of a new category, CategoryID and
+// ParentID when moving one or CategoryID and Fields when defining its custom
+// fields. A zero ParentID stands for the top level.
+type CategoryRequest struct {
+	CategoryID int
+	Title      string
+	Color      string
+	ParentID   int
+	Fields     []models.FieldDef
+}
+
+// TrashRequest moves a task or a category to the trash or back, whichever
+// ID is set.
+type TrashRequest struct {
+	TaskID     int
+	CategoryID int
+}
+
+type TaskHistoryRequest struct {
+	TaskID int
+}
+
+type SearchRequest struct {
+	// Query holds words, "quoted phrases" and prefixes ending in *, all of
+	// which a task has to match.
+	Query string
+	Limit int
+}
+
+type ViewRequest struct {
+	Name  string
+	Query string
+	Sort  string
+	Group string
+}
+
+// TemplateRequest names a template of the user. Tasks are the tasks of a
+// template being saved, Values and Start are used when applying one.
+type TemplateRequest struct {
+	Name  string
+	Tasks []models.TemplateTask
+	// Values fill in the template's {{placeholders}}, keyed by their name.
+	Values map[string]string
+	// Start is the day due offsets count from, now when zero.
+	Start time.Time
+}
+
+// ProjectRequest addresses a project or one of its milestones. When
+// updating, only the fields that are set change.
+type ProjectRequest struct {
+	ProjectID   int
+	MilestoneID int
+	Title       *string
+	Description *string
+	// TargetDate is the target of a milestone, it accepts the same inputs as
+	// a task's due date.
+	TargetDate *string
+}
+
+// ListUsersRequest pages through users, see ListTaskRequest.
+type ListUsersRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// WorkflowRequest holds the statuses of a workflow being saved, in board
+// order, and the statuses each of them can move to.
+type WorkflowRequest struct {
+	Statuses    []models.WorkflowStatus
+	Transitions map[string][]string
+}
+
+// MoveTaskRequest puts a task in another status of the user's workflow.
+type MoveTaskRequest struct {
+	TaskID int
+	Status string
+	// Force completes a task with open parts when it moves to a done status.
+	Force bool
+}
+
+// BoardRequest shows the board of the tasks matching Query, of all tasks
+// when it's empty.
+type BoardRequest struct {
+	Query string
+}
+
+// AssignTaskRequest names the user a task is assigned or transferred to by
+// email. An empty email unassigns the task.
+type AssignTaskRequest struct {
+	TaskID int
+	Email  string
+}
+
+// ShareCategoryRequest names the user a category is shared with or revoked
+// from by email. Role is one of viewer, editor or admin.
+type ShareCategoryRequest struct {
+	CategoryID int
+	Email      string
+	Role       string
+}
+
+// CommentRequest addresses the comments of a task, or a single comment when
+// editing or deleting one. Text may mention users as @name.
+type CommentRequest struct {
+	TaskID    int
+	CommentID int
+	Text      string
 }
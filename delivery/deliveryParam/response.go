@@ -0,0 +1,157 @@
+package deliveryParam
+
+import (
+	"time"
+	"todo-cli-refactor/models"
+)
+
+type ListTaskResponse struct {
+	Tasks []models.Task
+	// Progress is the percentage complete of tasks with subtasks or
+	// checklist items, keyed by task ID.
+	Progress map[int]int
+	// OpenBlockers lists the IDs of the open tasks each waiting task is
+	// blocked by, keyed by task ID.
+	OpenBlockers map[int][]int
+	// Group is how the tasks are grouped, see models.View.
+	Group string
+	// Categories name the groups of a listing grouped by category and color
+	// its tasks.
+	Categories []models.Category `json:",omitempty"`
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+	// NextCursor fetches the next page, it's empty on the last one.
+	NextCursor string `json:",omitempty"`
+}
+
+type ShowTaskResponse struct {
+	Task     models.Task
+	Subtasks []models.Task
+	// OpenBlockers lists the IDs of the open tasks the task waits on.
+	OpenBlockers []int
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+type TrashResponse struct {
+	Tasks      []models.Task
+	Categories []models.Category
+	// Retention is how long items stay in the trash before they're purged.
+	Retention time.Duration
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+type TaskHistoryResponse struct {
+	// Changes are the changes of the task, oldest first.
+	Changes []models.Change
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+type SearchResponse struct {
+	// Tasks are the matching tasks, best match first.
+	Tasks []models.Task
+	// Total is the number of matching tasks, including ones past the limit.
+	Total int
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+// JournalResponse describes an operation that was undone or redone.
+type JournalResponse struct {
+	Command     string
+	TaskIDs     []int `json:",omitempty"`
+	CategoryIDs []int `json:",omitempty"`
+}
+
+// ListUsersResponse lists users without their passwords.
+type ListUsersResponse struct {
+	Users []UserSummary
+	// NextCursor fetches the next page, it's empty on the last one.
+	NextCursor string `json:",omitempty"`
+}
+
+// UserSummary describes a user without their password.
+type UserSummary struct {
+	ID    int
+	Name  string
+	Email string
+	// Calendar is the calendar the user wants dates rendered in, it's only
+	// set for the authenticated user's own profile.
+	Calendar string `json:",omitempty"`
+}
+
+type TemplatesResponse struct {
+	Templates []TemplateSummary
+}
+
+type TemplateSummary struct {
+	Name string
+	// Titles are the titles of the tasks the template creates.
+	Titles       []string
+	Placeholders []string
+}
+
+type ProjectsResponse struct {
+	Projects   []models.Project
+	Milestones []models.Milestone
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+type ProjectProgressResponse struct {
+	Project models.Project
+	// Milestones are in the order of their target dates.
+	Milestones []MilestoneProgress
+	Total      Progress
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+type MilestoneProgress struct {
+	Milestone models.Milestone
+	Progress  Progress
+	// Late is set when the target date passed, or is projected to pass,
+	// with tasks still open.
+	Late bool
+}
+
+// Progress counts the tasks of a project or a milestone. Projected is when
+// the open tasks are expected to be done, zero when it can't be told.
+type Progress struct {
+	Open      int
+	Done      int
+	Overdue   int
+	Projected time.Time
+}
+
+// BoardResponse holds a column per workflow status, in workflow order.
+type BoardResponse struct {
+	Columns []BoardColumn
+}
+
+type BoardColumn struct {
+	Status models.WorkflowStatus
+	Tasks  []models.Task
+}
+
+type CommentsResponse struct {
+	// Comments are the comments of a task, oldest first.
+	Comments []models.Comment
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+type MentionsResponse struct {
+	// Mentions are the comments mentioning the user, newest first.
+	Mentions []Mention
+	// Calendar is the calendar the authenticated user wants dates rendered in.
+	Calendar string
+}
+
+// Mention is a comment mentioning the user along with the task it's on.
+type Mention struct {
+	Comment models.Comment
+	Task    models.Task
+}
@@ -0,0 +1,90 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+	"unicode/utf8"
+)
+
+// boardColumnWidth is the number of characters a board column takes, cards
+// longer than that are cut short.
+const boardColumnWidth = 24
+
+// Board renders the columns of a board side by side, each headed by its
+// status and how many tasks it holds out of its WIP limit.
+func Board(board deliveryParam.BoardResponse) []string {
+	if len(board.Columns) == 0 {
+		return []string{"no statuses"}
+	}
+
+	headers := make([]string, 0, len(board.Columns))
+	rules := make([]string, 0, len(board.Columns))
+	rows := 0
+	for _, c := range board.Columns {
+		header := fmt.Sprintf("%s (%d)", c.Status.Name, len(c.Tasks))
+		if c.Status.WIPLimit > 0 {
+			header = fmt.Sprintf("%s (%d/%d)", c.Status.Name, len(c.Tasks), c.Status.WIPLimit)
+		}
+		headers = append(headers, cell(header))
+		rules = append(rules, strings.Repeat("-", boardColumnWidth))
+
+		if len(c.Tasks) > rows {
+			rows = len(c.Tasks)
+		}
+	}
+
+	lines := []string{boardLine(headers), boardLine(rules)}
+	for i := 0; i < rows; i++ {
+		cells := make([]string, 0, len(board.Columns))
+		for _, c := range board.Columns {
+			card := ""
+			if i < len(c.Tasks) {
+				card = fmt.Sprintf("#%d %s", c.Tasks[i].ID, c.Tasks[i].Title)
+			}
+			cells = append(cells, cell(card))
+		}
+		lines = append(lines, boardLine(cells))
+	}
+
+	return lines
+}
+
+// cell pads text to the column width, or cuts it short with an ellipsis.
+func cell(text string) string {
+	n := utf8.RuneCountInString(text)
+	if n > boardColumnWidth {
+		return string([]rune(text)[:boardColumnWidth-1]) + "…"
+	}
+
+	return text + strings.Repeat(" ", boardColumnWidth-n)
+}
+
+func boardLine(cells []string) string {
+	return strings.TrimRight(strings.Join(cells, " | "), " ")
+}
+
+// Workflow renders a status per line in board order, with its WIP limit and
+// the statuses it can move to.
+func Workflow(workflow models.Workflow) []string {
+	lines := make([]string, 0, len(workflow.Statuses))
+	for _, s := range workflow.Statuses {
+		parts := []string{s.Name}
+		if s.Done {
+			parts = append(parts, "done")
+		}
+		if s.WIPLimit > 0 {
+			parts = append(parts, fmt.Sprintf("WIP limit %d", s.WIPLimit))
+		}
+		if next, ok := workflow.Transitions[s.Name]; ok && len(next) == 0 {
+			parts = append(parts, "-> none")
+		} else if ok {
+			parts = append(parts, "-> "+strings.Join(next, ", "))
+		}
+
+		lines = append(lines, strings.Join(parts, "  "))
+	}
+
+	return lines
+}
@@ -0,0 +1,55 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestBoard(t *testing.T) {
+	got := Board(deliveryParam.BoardResponse{Columns: []deliveryParam.BoardColumn{
+		{Status: models.WorkflowStatus{Name: "todo"}, Tasks: []models.Task{
+			{ID: 1, Title: "Write the release notes for everyone"},
+			{ID: 2, Title: "Café"},
+		}},
+		{Status: models.WorkflowStatus{Name: "doing", WIPLimit: 2}, Tasks: []models.Task{{ID: 3, Title: "Fix login"}}},
+		{Status: models.WorkflowStatus{Name: "done", Done: true}},
+	}})
+
+	expected := []string{
+		"todo (2)                 | doing (1/2)              | done (0)",
+		"------------------------ | ------------------------ | ------------------------",
+		"#1 Write the release no… | #3 Fix login             |",
+		"#2 Café                  |                          |",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("board does not match:\ngot  %q\nwant %q", got, expected)
+	}
+
+	if got := Board(deliveryParam.BoardResponse{}); !reflect.DeepEqual(got, []string{"no statuses"}) {
+		t.Errorf("unexpected empty board %q", got)
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	got := Workflow(models.Workflow{
+		Statuses: []models.WorkflowStatus{
+			{Name: "todo"},
+			{Name: "doing", WIPLimit: 3},
+			{Name: "done", Done: true},
+		},
+		Transitions: map[string][]string{"todo": {"doing"}, "done": {}},
+	})
+
+	expected := []string{
+		"todo  -> doing",
+		"doing  WIP limit 3",
+		"done  done  -> none",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("workflow does not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
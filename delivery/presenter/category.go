@@ -0,0 +1,142 @@
+package presenter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/color"
+)
+
+// ColorMode is how category colors are shown, the client sets it from the
+// terminal it runs in.
+var ColorMode = color.NoColor
+
+// Categories renders categories as an indented tree, subcategories below
+// their parent. Categories whose parent isn't listed are shown at the top
+// level. Roles holds the role the user has on categories others share with
+// them, keyed by category ID.
+func Categories(categories []models.Category, roles map[int]models.Role) []string {
+	if len(categories) == 0 {
+		return []string{"no categories"}
+	}
+
+	listed := map[int]bool{}
+	for _, c := range categories {
+		listed[c.ID] = true
+	}
+
+	children := map[int][]models.Category{}
+	var roots []models.Category
+	for _, c := range categories {
+		if c.ParentID != 0 && listed[c.ParentID] && c.ParentID != c.ID {
+			children[c.ParentID] = append(children[c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	var lines []string
+	visited := map[int]bool{}
+
+	var walk func(c models.Category, depth int)
+	walk = func(c models.Category, depth int) {
+		if visited[c.ID] {
+			return
+		}
+		visited[c.ID] = true
+
+		line := strings.Repeat(indent, depth) + color.Paint(fmt.Sprintf("#%d %s", c.ID, c.Title), c.Color, ColorMode)
+		if c.Color != "" {
+			line += " (" + c.Color + ")"
+		}
+		if len(c.Fields) > 0 {
+			defs := make([]string, 0, len(c.Fields))
+			for _, f := range c.Fields {
+				def := f.Name + ":" + string(f.Type)
+				if len(f.Options) > 0 {
+					def += ":" + strings.Join(f.Options, "|")
+				}
+				defs = append(defs, def)
+			}
+			line += " [" + strings.Join(defs, ", ") + "]"
+		}
+		if role, ok := roles[c.ID]; ok {
+			line += fmt.Sprintf("  shared with you as %s", role)
+		} else if len(c.Shares) > 0 {
+			shares := make([]string, 0, len(c.Shares))
+			for _, share := range c.Shares {
+				shares = append(shares, fmt.Sprintf("%s as %s", actor(share.UserID), share.Role))
+			}
+			line += "  shared with " + strings.Join(shares, ", ")
+		}
+		lines = append(lines, line)
+
+		for _, child := range children[c.ID] {
+			walk(child, depth+1)
+		}
+	}
+
+	for _, c := range roots {
+		walk(c, 0)
+	}
+
+	return lines
+}
+
+// categoryPath names a category along with the categories it's nested in,
+// as in "home / groceries".
+func categoryPath(categories []models.Category, categoryID int) string {
+	byID := map[int]models.Category{}
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	var titles []string
+	seen := map[int]bool{}
+	for id := categoryID; id != 0 && !seen[id]; id = byID[id].ParentID {
+		c, ok := byID[id]
+		if !ok {
+			break
+		}
+
+		seen[id] = true
+		titles = append([]string{c.Title}, titles...)
+	}
+
+	return strings.Join(titles, " / ")
+}
+
+// fieldValues lists the custom field values of a task as "name: value", in
+// the order its category defines the fields in when the category is given.
+// Values of fields the category no longer defines come last, by name.
+func fieldValues(t models.Task, categories []models.Category) []string {
+	if len(t.Fields) == 0 {
+		return nil
+	}
+
+	var values []string
+	listed := map[string]bool{}
+	for _, c := range categories {
+		if c.ID != t.CategoryID {
+			continue
+		}
+
+		for _, f := range c.Fields {
+			if value, ok := t.Fields[f.Name]; ok {
+				values = append(values, f.Name+": "+value)
+				listed[f.Name] = true
+			}
+		}
+	}
+
+	var rest []string
+	for name, value := range t.Fields {
+		if !listed[name] {
+			rest = append(rest, name+": "+value)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(values, rest...)
+}
@@ -0,0 +1,83 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/color"
+)
+
+func TestCategories(t *testing.T) {
+	categories := []models.Category{
+		{ID: 1, Title: "Home"},
+		{ID: 3, Title: "Groceries", ParentID: 1, Color: "green"},
+		{ID: 2, Title: "Work", Shares: []models.Share{{UserID: 4, Role: models.EditorRole}, {UserID: 5, Role: models.ViewerRole}}},
+		{ID: 4, Title: "Bakery", ParentID: 3},
+		{ID: 5, Title: "Orphan", ParentID: 9},
+		{ID: 6, Title: "Party", UserID: 7, Shares: []models.Share{{UserID: 3, Role: models.AdminRole}}},
+	}
+
+	expected := []string{
+		"#1 Home",
+		"    #3 Groceries (green)",
+		"        #4 Bakery",
+		"#2 Work  shared with user #4 as editor, user #5 as viewer",
+		"#5 Orphan",
+		"#6 Party  shared with you as admin",
+	}
+
+	if got := Categories(categories, map[int]models.Role{6: models.AdminRole}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+
+	if got := categoryPath(categories, 4); got != "Home / Groceries / Bakery" {
+		t.Errorf("got path %q", got)
+	}
+}
+
+func TestCategoryColors(t *testing.T) {
+	defer func(mode color.Mode) { ColorMode = mode }(ColorMode)
+	ColorMode = color.TrueColor
+
+	list := deliveryParam.ListTaskResponse{
+		Tasks:      []models.Task{{ID: 1, Title: "Deploy", CategoryID: 1}, {ID: 2, Title: "Rest"}},
+		Categories: []models.Category{{ID: 1, Title: "Work", Color: "#ff8800"}},
+		Calendar:   consts.GregorianCalendar,
+	}
+
+	expected := []string{
+		"\x1b[38;2;255;136;0m[ ] #1 Deploy\x1b[0m",
+		"[ ] #2 Rest",
+	}
+
+	if got := TaskTree(list, time.Now()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestFieldValues(t *testing.T) {
+	categories := []models.Category{{ID: 1, Title: "Bugs", Fields: []models.FieldDef{
+		{Name: "ticket", Type: models.URLField},
+		{Name: "severity", Type: models.EnumField, Options: []string{"low", "high"}},
+	}}}
+
+	list := deliveryParam.ListTaskResponse{
+		Tasks: []models.Task{{ID: 1, Title: "Crash", CategoryID: 1,
+			Fields: map[string]string{"severity": "high", "ticket": "https://example.com/1", "old": "x"}}},
+		Categories: categories,
+		Calendar:   consts.GregorianCalendar,
+	}
+
+	expected := []string{"[ ] #1 Crash [ticket: https://example.com/1, severity: high, old: x]"}
+	if got := TaskTree(list, time.Now()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+
+	expected = []string{"#1 Bugs [ticket:url, severity:enum:low|high]"}
+	if got := Categories(categories, nil); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
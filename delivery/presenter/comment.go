@@ -0,0 +1,58 @@
+package presenter
+
+import (
+	"fmt"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+// Comments renders the comments of a task as a thread, each under a heading
+// with its ID, time and author.
+func Comments(comments deliveryParam.CommentsResponse, now time.Time) []string {
+	if len(comments.Comments) == 0 {
+		return []string{"no comments"}
+	}
+
+	var lines []string
+
+	for _, comment := range comments.Comments {
+		lines = append(lines, fmt.Sprintf("#%d %s", comment.ID, commentHeading(comment, comments.Calendar, now)))
+		for _, line := range splitLines(comment.Text) {
+			lines = append(lines, indent+line)
+		}
+	}
+
+	return lines
+}
+
+// Mentions renders the mentions inbox of a user, naming the task each
+// comment is on.
+func Mentions(mentions deliveryParam.MentionsResponse, now time.Time) []string {
+	if len(mentions.Mentions) == 0 {
+		return []string{"no mentions"}
+	}
+
+	var lines []string
+
+	for _, mention := range mentions.Mentions {
+		lines = append(lines, fmt.Sprintf("task #%d %s, comment #%d %s", mention.Task.ID, mention.Task.Title,
+			mention.Comment.ID, commentHeading(mention.Comment, mentions.Calendar, now)))
+		for _, line := range splitLines(mention.Comment.Text) {
+			lines = append(lines, indent+line)
+		}
+	}
+
+	return lines
+}
+
+func commentHeading(comment models.Comment, calendar string, now time.Time) string {
+	createdAt := comment.CreatedAt.In(now.Location())
+	heading := fmt.Sprintf("%s%s by %s", FormatDate(createdAt, calendar), createdAt.Format(" 15:04"), actor(comment.AuthorID))
+
+	if comment.EditedAt != nil {
+		heading += " (edited)"
+	}
+
+	return heading
+}
@@ -0,0 +1,53 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestComments(t *testing.T) {
+	now := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	editedAt := createdAt.Add(time.Hour)
+
+	comments := []models.Comment{
+		{ID: 1, TaskID: 7, AuthorID: 3, Text: "@bob can you check this?\nthe numbers look off", Mentions: []int{4}, CreatedAt: createdAt},
+		{ID: 2, TaskID: 7, AuthorID: 4, Text: "fixed", CreatedAt: createdAt.Add(time.Minute), EditedAt: &editedAt},
+	}
+
+	got := Comments(deliveryParam.CommentsResponse{Comments: comments, Calendar: consts.GregorianCalendar}, now)
+	expected := []string{
+		"#1 2026-10-19 09:30 by user #3",
+		"    @bob can you check this?",
+		"    the numbers look off",
+		"#2 2026-10-19 09:31 by user #4 (edited)",
+		"    fixed",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("comments do not match:\ngot  %q\nwant %q", got, expected)
+	}
+
+	got = Mentions(deliveryParam.MentionsResponse{
+		Mentions: []deliveryParam.Mention{{Comment: comments[0], Task: models.Task{ID: 7, Title: "Quarterly report"}}},
+		Calendar: consts.GregorianCalendar,
+	}, now)
+	expected = []string{
+		"task #7 Quarterly report, comment #1 2026-10-19 09:30 by user #3",
+		"    @bob can you check this?",
+		"    the numbers look off",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("mentions do not match:\ngot  %q\nwant %q", got, expected)
+	}
+
+	if got := Comments(deliveryParam.CommentsResponse{}, now); !reflect.DeepEqual(got, []string{"no comments"}) {
+		t.Errorf("got %q for no comments", got)
+	}
+	if got := Mentions(deliveryParam.MentionsResponse{}, now); !reflect.DeepEqual(got, []string{"no mentions"}) {
+		t.Errorf("got %q for no mentions", got)
+	}
+}
@@ -0,0 +1,47 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+)
+
+// TaskDetail renders a single task with who it's assigned to, its custom
+// field values, checklist, direct subtasks and its description rendered from
+// markdown.
+func TaskDetail(show deliveryParam.ShowTaskResponse, now time.Time) []string {
+	line := Task(show.Task, show.Calendar, now)
+	if len(show.OpenBlockers) > 0 {
+		line += " (blocked by " + taskRefs(show.OpenBlockers) + ")"
+	}
+
+	lines := []string{line}
+
+	if show.Task.AssigneeID != 0 {
+		lines = append(lines, fmt.Sprintf("%sowned by %s, assigned to %s", indent, actor(show.Task.UserID), actor(show.Task.AssigneeID)))
+	}
+
+	for _, value := range fieldValues(show.Task, nil) {
+		lines = append(lines, indent+value)
+	}
+
+	for _, item := range show.Task.Checklist {
+		check := " "
+		if item.Done {
+			check = "x"
+		}
+		lines = append(lines, fmt.Sprintf("%s- [%s] %s", indent, check, item.Text))
+	}
+
+	for _, subtask := range show.Subtasks {
+		lines = append(lines, indent+Task(subtask, show.Calendar, now))
+	}
+
+	if strings.TrimSpace(show.Task.Description) != "" {
+		lines = append(lines, "")
+		lines = append(lines, Markdown(show.Task.Description)...)
+	}
+
+	return lines
+}
@@ -0,0 +1,134 @@
+package presenter
+
+import (
+	"math"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/color"
+)
+
+type taskGroup struct {
+	title string
+	tasks []models.Task
+}
+
+// TaskGroups renders a task listing split into the groups it asks for, each
+// group as a tree below its title. Empty groups are left out; without a
+// grouping the listing is a single tree.
+func TaskGroups(list deliveryParam.ListTaskResponse, now time.Time) []string {
+	var groups []*taskGroup
+	switch list.Group {
+	case models.CategoryGroup:
+		groups = groupByCategory(list)
+	case models.PriorityGroup:
+		groups = groupByPriority(list)
+	case models.DueGroup:
+		groups = groupByDue(list, now)
+	default:
+		return TaskTree(list, now)
+	}
+
+	var lines []string
+	for _, group := range groups {
+		if len(group.tasks) == 0 {
+			continue
+		}
+
+		lines = append(lines, group.title+":")
+
+		sub := list
+		sub.Tasks = group.tasks
+		for _, line := range TaskTree(sub, now) {
+			lines = append(lines, indent+line)
+		}
+	}
+
+	return lines
+}
+
+func groupByCategory(list deliveryParam.ListTaskResponse) []*taskGroup {
+	var groups []*taskGroup
+	byID := map[int]*taskGroup{}
+	for _, c := range list.Categories {
+		byID[c.ID] = &taskGroup{title: color.Paint(categoryPath(list.Categories, c.ID), c.Color, ColorMode)}
+		groups = append(groups, byID[c.ID])
+	}
+
+	none := &taskGroup{title: "no category"}
+	for _, t := range list.Tasks {
+		if group, ok := byID[t.CategoryID]; ok {
+			group.tasks = append(group.tasks, t)
+		} else {
+			none.tasks = append(none.tasks, t)
+		}
+	}
+
+	return append(groups, none)
+}
+
+func groupByPriority(list deliveryParam.ListTaskResponse) []*taskGroup {
+	var groups []*taskGroup
+	for p := models.UrgentPriority; p > models.NoPriority; p-- {
+		groups = append(groups, &taskGroup{title: p.String() + " priority"})
+	}
+	groups = append(groups, &taskGroup{title: "no priority"})
+
+	for _, t := range list.Tasks {
+		index := len(groups) - 1
+		if t.Priority > models.NoPriority && t.Priority <= models.UrgentPriority {
+			index = int(models.UrgentPriority - t.Priority)
+		}
+		groups[index].tasks = append(groups[index].tasks, t)
+	}
+
+	return groups
+}
+
+func groupByDue(list deliveryParam.ListTaskResponse, now time.Time) []*taskGroup {
+	overdue := &taskGroup{title: "overdue"}
+	earlier := &taskGroup{title: "earlier"}
+	today := &taskGroup{title: "today"}
+	tomorrow := &taskGroup{title: "tomorrow"}
+	week := &taskGroup{title: "next 7 days"}
+	later := &taskGroup{title: "later"}
+	none := &taskGroup{title: "no due date"}
+
+	y, m, d := now.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	for _, t := range list.Tasks {
+		var group *taskGroup
+
+		if t.DueDate.IsZero() {
+			group = none
+		} else if OverdueLabel(t, list.Calendar, now) != "" {
+			group = overdue
+		} else {
+			due := t.DueDate.Time
+			if !t.DueDate.AllDay {
+				due = due.In(now.Location())
+			}
+			dy, dm, dd := due.Date()
+			// rounded since days around a DST change aren't 24 hours long
+			days := int(math.Round(time.Date(dy, dm, dd, 0, 0, 0, 0, now.Location()).Sub(start).Hours() / 24))
+
+			switch {
+			case days < 0:
+				group = earlier
+			case days == 0:
+				group = today
+			case days == 1:
+				group = tomorrow
+			case days <= 7:
+				group = week
+			default:
+				group = later
+			}
+		}
+
+		group.tasks = append(group.tasks, t)
+	}
+
+	return []*taskGroup{overdue, earlier, today, tomorrow, week, later, none}
+}
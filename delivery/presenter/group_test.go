@@ -0,0 +1,78 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestTaskGroups(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	tasks := []models.Task{
+		{ID: 1, Title: "Pay rent", CategoryID: 2, DueDate: models.NewDueDate(2026, 10, 18), Priority: models.HighPriority},
+		{ID: 2, Title: "Deploy", CategoryID: 1, DueDate: models.NewDueDate(2026, 10, 19)},
+		{ID: 3, Title: "Write notes", CategoryID: 1, ParentID: 2, DueDate: models.NewDueDate(2026, 10, 23), Priority: models.HighPriority},
+		{ID: 4, Title: "Read a book"},
+	}
+
+	tests := []struct {
+		group    string
+		expected []string
+	}{
+		{
+			group: models.CategoryGroup,
+			expected: []string{
+				"Work:",
+				"    [ ] #2 Deploy (due 2026-10-19)",
+				"        [ ] #3 Write notes !high (due 2026-10-23)",
+				"Home:",
+				"    [ ] #1 Pay rent !high (overdue since 2026-10-18)",
+				"no category:",
+				"    [ ] #4 Read a book",
+			},
+		},
+		{
+			group: models.PriorityGroup,
+			expected: []string{
+				"high priority:",
+				"    [ ] #1 Pay rent !high (overdue since 2026-10-18)",
+				"    [ ] #3 Write notes !high (due 2026-10-23)",
+				"no priority:",
+				"    [ ] #2 Deploy (due 2026-10-19)",
+				"    [ ] #4 Read a book",
+			},
+		},
+		{
+			group: models.DueGroup,
+			expected: []string{
+				"overdue:",
+				"    [ ] #1 Pay rent !high (overdue since 2026-10-18)",
+				"today:",
+				"    [ ] #2 Deploy (due 2026-10-19)",
+				"next 7 days:",
+				"    [ ] #3 Write notes !high (due 2026-10-23)",
+				"no due date:",
+				"    [ ] #4 Read a book",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.group, func(t *testing.T) {
+			got := TaskGroups(deliveryParam.ListTaskResponse{
+				Tasks:      tasks,
+				Group:      tc.group,
+				Categories: []models.Category{{ID: 1, Title: "Work"}, {ID: 2, Title: "Home"}},
+				Calendar:   consts.GregorianCalendar,
+			}, now)
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("groups do not match:\ngot  %q\nwant %q", got, tc.expected)
+			}
+		})
+	}
+}
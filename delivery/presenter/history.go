@@ -0,0 +1,103 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+// History renders the changes of a task as a timeline. Changes made together
+// share a heading with their time and actor, and every changed field is
+// shown as a diff of its old and new lines.
+func History(history deliveryParam.TaskHistoryResponse, now time.Time) []string {
+	if len(history.Changes) == 0 {
+		return []string{"no changes recorded"}
+	}
+
+	var lines []string
+
+	for i, change := range history.Changes {
+		if i == 0 || !change.ChangedAt.Equal(history.Changes[i-1].ChangedAt) || change.ActorID != history.Changes[i-1].ActorID {
+			changedAt := change.ChangedAt.In(now.Location())
+			lines = append(lines, fmt.Sprintf("%s%s by %s",
+				FormatDate(changedAt, history.Calendar), changedAt.Format(" 15:04"), actor(change.ActorID)))
+		}
+
+		switch {
+		case change.Field == models.CreatedField || change.Field == models.PurgedField:
+			lines = append(lines, indent+change.Field)
+		case change.Field == "deletedAt" && change.NewValue != "":
+			lines = append(lines, indent+"moved to the trash")
+		case change.Field == "deletedAt":
+			lines = append(lines, indent+"restored from the trash")
+		default:
+			lines = append(lines, indent+change.Field)
+			for _, line := range diffLines(splitLines(change.OldValue), splitLines(change.NewValue)) {
+				lines = append(lines, indent+indent+line)
+			}
+		}
+	}
+
+	return lines
+}
+
+// actor names who made a change, user ID zero being the server itself.
+func actor(userID int) string {
+	if userID == 0 {
+		return "the system"
+	}
+
+	return fmt.Sprintf("user #%d", userID)
+}
+
+func splitLines(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, "\n")
+}
+
+// diffLines marks the lines only in before with "- ", the ones only in after
+// with "+ " and the ones kept with two spaces, following their longest common
+// subsequence.
+func diffLines(before, after []string) []string {
+	// common[i][j] is the length of the longest common subsequence of before[i:]
+	// and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			lines = append(lines, "  "+before[i])
+			i++
+			j++
+		case j == len(after) || (i < len(before) && common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, "- "+before[i])
+			i++
+		default:
+			lines = append(lines, "+ "+after[j])
+			j++
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,51 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestHistory(t *testing.T) {
+	now := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	changedAt := createdAt.Add(time.Hour)
+
+	got := History(deliveryParam.TaskHistoryResponse{
+		Changes: []models.Change{
+			{TaskID: 1, ActorID: 3, ChangedAt: createdAt, Field: models.CreatedField},
+			{TaskID: 1, ActorID: 3, ChangedAt: createdAt, Field: "title", NewValue: "Buy milk"},
+			{TaskID: 1, ActorID: 4, ChangedAt: changedAt, Field: "title", OldValue: "Buy milk", NewValue: "Buy oat milk"},
+			{TaskID: 1, ActorID: 4, ChangedAt: changedAt, Field: "description",
+				OldValue: "# Notes\nfirst\nsecond", NewValue: "# Notes\nfirst\nthird\nfourth"},
+			{TaskID: 1, ActorID: 4, ChangedAt: changedAt.Add(time.Minute), Field: "deletedAt", NewValue: "2026-10-19T10:31:00Z"},
+		},
+		Calendar: consts.GregorianCalendar,
+	}, now)
+
+	expected := []string{
+		"2026-10-19 09:30 by user #3",
+		"    created",
+		"    title",
+		"        + Buy milk",
+		"2026-10-19 10:30 by user #4",
+		"    title",
+		"        - Buy milk",
+		"        + Buy oat milk",
+		"    description",
+		"          # Notes",
+		"          first",
+		"        - second",
+		"        + third",
+		"        + fourth",
+		"2026-10-19 10:31 by user #4",
+		"    moved to the trash",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("history does not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
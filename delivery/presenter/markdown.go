@@ -0,0 +1,166 @@
+package presenter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern      = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedPattern     = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	quotePattern       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	ruleLinePattern    = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	autolinkPattern    = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	markdownEscapables = "\\`*_{}[]()#+-.!<>"
+)
+
+// Markdown renders markdown as plain terminal text. Headings are underlined,
+// list bullets become "•", code blocks are indented and links show their
+// target in parentheses. Anything else is passed through unchanged.
+func Markdown(text string) []string {
+	var lines []string
+
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+
+			continue
+		}
+
+		if inCode {
+			lines = append(lines, indent+line)
+
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			heading := inline(m[2])
+			lines = append(lines, heading)
+
+			switch len(m[1]) {
+			case 1:
+				lines = append(lines, strings.Repeat("=", utf8.RuneCountInString(heading)))
+			case 2:
+				lines = append(lines, strings.Repeat("-", utf8.RuneCountInString(heading)))
+			}
+
+			continue
+		}
+
+		switch {
+		case ruleLinePattern.MatchString(line):
+			lines = append(lines, strings.Repeat("─", 40))
+		case bulletPattern.MatchString(line):
+			m := bulletPattern.FindStringSubmatch(line)
+			lines = append(lines, m[1]+"• "+inline(m[2]))
+		case orderedPattern.MatchString(line):
+			m := orderedPattern.FindStringSubmatch(line)
+			lines = append(lines, m[1]+m[2]+" "+inline(m[3]))
+		case quotePattern.MatchString(line):
+			m := quotePattern.FindStringSubmatch(line)
+			lines = append(lines, "│ "+inline(m[1]))
+		case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			// indented code is shown as it is
+			lines = append(lines, line)
+		default:
+			lines = append(lines, inline(line))
+		}
+	}
+
+	// trailing blank lines add nothing on a terminal
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// inline strips inline markdown: emphasis markers and code backticks are
+// dropped, and links are written as "text (url)".
+func inline(s string) string {
+	s = autolinkPattern.ReplaceAllString(s, "$1")
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && strings.IndexByte(markdownEscapables, s[i+1]) >= 0:
+			b.WriteByte(s[i+1])
+			i += 2
+		case s[i] == '`':
+			end := strings.IndexByte(s[i+1:], '`')
+			if end < 0 {
+				b.WriteByte(s[i])
+				i++
+
+				continue
+			}
+
+			// code spans are shown verbatim
+			b.WriteString(s[i+1 : i+1+end])
+			i += end + 2
+		case s[i] == '[':
+			text, url, n := link(s[i:])
+			if n == 0 {
+				b.WriteByte(s[i])
+				i++
+
+				continue
+			}
+
+			if text = inline(text); text == url || text == "" {
+				b.WriteString(url)
+			} else {
+				b.WriteString(text + " (" + url + ")")
+			}
+			i += n
+		case strings.HasPrefix(s[i:], "**") || strings.HasPrefix(s[i:], "__"):
+			marker := s[i : i+2]
+			end := strings.Index(s[i+2:], marker)
+			if end <= 0 {
+				b.WriteString(marker)
+				i += 2
+
+				continue
+			}
+
+			b.WriteString(inline(s[i+2 : i+2+end]))
+			i += end + 4
+		case s[i] == '*' || (s[i] == '_' && (i == 0 || s[i-1] == ' ')):
+			// a lone underscore inside a word, as in snake_case, isn't emphasis
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end <= 0 || s[i+1] == ' ' {
+				b.WriteByte(s[i])
+				i++
+
+				continue
+			}
+
+			b.WriteString(inline(s[i+1 : i+1+end]))
+			i += end + 2
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// link parses a "[text](url)" link at the start of s, returning the number
+// of bytes it spans or zero when s doesn't start with one.
+func link(s string) (string, string, int) {
+	closing := strings.Index(s, "](")
+	if closing < 0 || strings.ContainsAny(s[1:closing], "[]") {
+		return "", "", 0
+	}
+
+	end := strings.IndexByte(s[closing+2:], ')')
+	if end < 0 {
+		return "", "", 0
+	}
+
+	return s[1:closing], s[closing+2 : closing+2+end], closing + 2 + end + 1
+}
@@ -0,0 +1,78 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestMarkdown(t *testing.T) {
+	text := "# Release *3*\r\n" +
+		"Ship the **new** `todo` client, see [the plan](https://example.com/plan).\n" +
+		"\n" +
+		"## Steps\n" +
+		"- update snake_case names\n" +
+		"  * check <https://example.com/ci>\n" +
+		"1. tag the release\n" +
+		"> don't forget \\*docs\\*\n" +
+		"```go\n" +
+		"fmt.Println(\"**done**\")\n" +
+		"```\n" +
+		"### Later\n" +
+		"---\n" +
+		"\n"
+
+	expected := []string{
+		"Release 3",
+		"=========",
+		"Ship the new todo client, see the plan (https://example.com/plan).",
+		"",
+		"Steps",
+		"-----",
+		"• update snake_case names",
+		"  • check https://example.com/ci",
+		"1. tag the release",
+		"│ don't forget *docs*",
+		"    fmt.Println(\"**done**\")",
+		"Later",
+		"────────────────────────────────────────",
+	}
+
+	if got := Markdown(text); !reflect.DeepEqual(got, expected) {
+		t.Errorf("markdown does not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
+
+func TestTaskDetail(t *testing.T) {
+	now := time.Date(2026, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	got := TaskDetail(deliveryParam.ShowTaskResponse{
+		Task: models.Task{
+			ID:          1,
+			Title:       "Release 3",
+			Description: "Notes on **the** release",
+			UserID:      3,
+			AssigneeID:  4,
+			Checklist:   []models.ChecklistItem{{Text: "changelog", Done: true}},
+		},
+		Subtasks:     []models.Task{{ID: 2, Title: "Announce", ParentID: 1}},
+		OpenBlockers: []int{4},
+		Calendar:     consts.GregorianCalendar,
+	}, now)
+
+	expected := []string{
+		"[ ] #1 Release 3 (blocked by #4)",
+		"    owned by user #3, assigned to user #4",
+		"    - [x] changelog",
+		"    [ ] #2 Announce",
+		"",
+		"Notes on the release",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("detail does not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
@@ -0,0 +1,80 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+// Projects renders each project followed by its milestones and their target
+// dates.
+func Projects(list deliveryParam.ProjectsResponse, now time.Time) []string {
+	if len(list.Projects) == 0 {
+		return []string{"no projects"}
+	}
+
+	var lines []string
+	for _, p := range list.Projects {
+		lines = append(lines, fmt.Sprintf("#%d %s", p.ID, p.Title))
+
+		for _, m := range list.Milestones {
+			if m.ProjectID == p.ID {
+				lines = append(lines, indent+milestone(m, list.Calendar, now))
+			}
+		}
+	}
+
+	return lines
+}
+
+// ProjectProgress renders the task counts of a project and of each of its
+// milestones, with the share of tasks done and when the rest is projected to
+// be done.
+func ProjectProgress(progress deliveryParam.ProjectProgressResponse, now time.Time) []string {
+	lines := []string{fmt.Sprintf("#%d %s  %s", progress.Project.ID, progress.Project.Title,
+		progressSummary(progress.Total, progress.Calendar))}
+
+	for _, m := range progress.Milestones {
+		line := indent + milestone(m.Milestone, progress.Calendar, now) + "  " + progressSummary(m.Progress, progress.Calendar)
+		if m.Late {
+			line += "  LATE"
+		}
+		lines = append(lines, line)
+	}
+
+	if len(progress.Milestones) == 0 {
+		lines = append(lines, indent+"no milestones")
+	}
+
+	return lines
+}
+
+func milestone(m models.Milestone, calendar string, now time.Time) string {
+	line := fmt.Sprintf("#%d %s", m.ID, m.Title)
+	if !m.TargetDate.IsZero() {
+		line += " (target " + FormatDueDate(m.TargetDate, calendar, now.Location()) + ")"
+	}
+
+	return line
+}
+
+// progressSummary renders counts as "1 open, 2 done, 1 overdue  67%".
+func progressSummary(p deliveryParam.Progress, calendar string) string {
+	if p.Open+p.Done == 0 {
+		return "no tasks"
+	}
+
+	counts := []string{fmt.Sprintf("%d open", p.Open), fmt.Sprintf("%d done", p.Done)}
+	if p.Overdue > 0 {
+		counts = append(counts, fmt.Sprintf("%d overdue", p.Overdue))
+	}
+
+	parts := []string{strings.Join(counts, ", "), fmt.Sprintf("%d%%", p.Done*100/(p.Open+p.Done))}
+	if !p.Projected.IsZero() {
+		parts = append(parts, "projected "+FormatDate(p.Projected, calendar))
+	}
+
+	return strings.Join(parts, "  ")
+}
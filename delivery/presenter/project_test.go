@@ -0,0 +1,57 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestProjectProgress(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	got := ProjectProgress(deliveryParam.ProjectProgressResponse{
+		Project: models.Project{ID: 1, Title: "Website"},
+		Milestones: []deliveryParam.MilestoneProgress{
+			{
+				Milestone: models.Milestone{ID: 2, Title: "Beta", TargetDate: models.NewDueDate(2026, 10, 22)},
+				Progress:  deliveryParam.Progress{Open: 1, Done: 2, Overdue: 1, Projected: now.AddDate(0, 0, 5)},
+				Late:      true,
+			},
+			{Milestone: models.Milestone{ID: 3, Title: "Launch"}},
+		},
+		Total: deliveryParam.Progress{Open: 1, Done: 2, Overdue: 1, Projected: now.AddDate(0, 0, 5)},
+	}, now)
+
+	expected := []string{
+		"#1 Website  1 open, 2 done, 1 overdue  66%  projected 2026-10-24",
+		"    #2 Beta (target 2026-10-22)  1 open, 2 done, 1 overdue  66%  projected 2026-10-24  LATE",
+		"    #3 Launch  no tasks",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("progress does not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
+
+func TestProjects(t *testing.T) {
+	got := Projects(deliveryParam.ProjectsResponse{
+		Projects: []models.Project{{ID: 1, Title: "Website"}, {ID: 2, Title: "Garden"}},
+		Milestones: []models.Milestone{
+			{ID: 1, ProjectID: 1, Title: "Beta", TargetDate: models.NewDueDate(2026, 10, 22)},
+			{ID: 2, ProjectID: 2, Title: "Seeds"},
+		},
+	}, time.Now())
+
+	expected := []string{
+		"#1 Website",
+		"    #1 Beta (target 2026-10-22)",
+		"#2 Garden",
+		"    #2 Seeds",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("projects do not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
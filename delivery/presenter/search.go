@@ -0,0 +1,26 @@
+package presenter
+
+import (
+	"fmt"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+)
+
+// SearchResults renders the tasks a search found, best match first, and how
+// many more there are past the limit.
+func SearchResults(results deliveryParam.SearchResponse, now time.Time) []string {
+	if len(results.Tasks) == 0 {
+		return []string{"no tasks match"}
+	}
+
+	lines := make([]string, 0, len(results.Tasks)+1)
+	for _, t := range results.Tasks {
+		lines = append(lines, Task(t, results.Calendar, now))
+	}
+
+	if more := results.Total - len(results.Tasks); more > 0 {
+		lines = append(lines, fmt.Sprintf("... and %d more", more))
+	}
+
+	return lines
+}
@@ -0,0 +1,34 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestSearchResults(t *testing.T) {
+	now := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+
+	got := SearchResults(deliveryParam.SearchResponse{
+		Tasks:    []models.Task{{ID: 3, Title: "Buy milk"}, {ID: 1, Title: "Call mom", Tags: []string{"@milk"}}},
+		Total:    5,
+		Calendar: consts.GregorianCalendar,
+	}, now)
+
+	expected := []string{
+		"[ ] #3 Buy milk",
+		"[ ] #1 Call mom @milk",
+		"... and 3 more",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("results do not match:\ngot  %q\nwant %q", got, expected)
+	}
+
+	if got := SearchResults(deliveryParam.SearchResponse{}, now); !reflect.DeepEqual(got, []string{"no tasks match"}) {
+		t.Errorf("empty results do not match: got %q", got)
+	}
+}
@@ -0,0 +1,112 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/jalali"
+)
+
+// FormatDate renders the calendar date of t in the given calendar.
+func FormatDate(t time.Time, calendar string) string {
+	if calendar == consts.JalaliCalendar {
+		return jalali.Format(t)
+	}
+
+	return t.Format("2006-01-02")
+}
+
+// FormatDueDate renders a due date in the given calendar. Due dates with a time
+// of day are shown in loc, the viewer's time zone.
+func FormatDueDate(d models.DueDate, calendar string, loc *time.Location) string {
+	if d.IsZero() {
+		return d.Legacy
+	}
+
+	if d.AllDay {
+		return FormatDate(d.Time, calendar)
+	}
+
+	local := d.Time.In(loc)
+
+	return FormatDate(local, calendar) + local.Format(" 15:04")
+}
+
+// OverdueLabel describes how a task stands against its due date at now, or is
+// empty when the task isn't overdue.
+func OverdueLabel(t models.Task, calendar string, now time.Time) string {
+	if t.IsDone || !t.DueDate.Before(now) {
+		return ""
+	}
+
+	return "overdue since " + FormatDueDate(t.DueDate, calendar, now.Location())
+}
+
+// Task renders a task as a single listing line.
+func Task(t models.Task, calendar string, now time.Time) string {
+	check := " "
+	if t.IsDone {
+		check = "x"
+	}
+
+	line := fmt.Sprintf("[%s] #%d %s", check, t.ID, t.Title)
+
+	if t.Priority != models.NoPriority {
+		line += " !" + t.Priority.String()
+	}
+
+	if label := OverdueLabel(t, calendar, now); label != "" {
+		line += " (" + label + ")"
+	} else if !t.DueDate.IsZero() || t.DueDate.Legacy != "" {
+		line += " (due " + FormatDueDate(t.DueDate, calendar, now.Location()) + ")"
+	}
+
+	if len(t.Tags) > 0 {
+		line += " " + strings.Join(t.Tags, " ")
+	}
+
+	if t.Recurrence != nil {
+		line += " [" + Recurrence(*t.Recurrence, calendar) + "]"
+	}
+
+	return line
+}
+
+var frequencyUnits = map[string]string{
+	models.DailyFrequency:   "day",
+	models.WeeklyFrequency:  "week",
+	models.MonthlyFrequency: "month",
+	models.YearlyFrequency:  "year",
+}
+
+// Recurrence describes a recurrence rule in words, e.g. "every 2 weeks on Mon, Thu".
+func Recurrence(r models.Recurrence, calendar string) string {
+	unit := frequencyUnits[r.Frequency]
+
+	text := "every " + unit
+	if r.Interval > 1 {
+		text = fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+
+	if len(r.ByWeekday) > 0 {
+		days := make([]string, 0, len(r.ByWeekday))
+		for _, wd := range r.ByWeekday {
+			days = append(days, wd.String()[:3])
+		}
+		text += " on " + strings.Join(days, ", ")
+	}
+
+	if !r.Until.IsZero() {
+		text += " until " + FormatDate(r.Until.Time, calendar)
+	}
+
+	if r.Count > 1 {
+		text += fmt.Sprintf(", %d more", r.Count-1)
+	} else if r.Count == 1 {
+		text += ", last one"
+	}
+
+	return text
+}
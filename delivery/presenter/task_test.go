@@ -0,0 +1,67 @@
+package presenter
+
+import (
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestTask(t *testing.T) {
+	tehran := time.FixedZone("IRST", 3*60*60+30*60)
+	now := time.Date(2026, 11, 2, 10, 0, 0, 0, tehran)
+
+	tests := []struct {
+		name     string
+		task     models.Task
+		calendar string
+		expected string
+	}{
+		{
+			name:     "no due date",
+			task:     models.Task{ID: 1, Title: "Read a book", Tags: []string{"@home", "#someday"}},
+			calendar: consts.GregorianCalendar,
+			expected: "[ ] #1 Read a book @home #someday",
+		},
+		{
+			name:     "gregorian due date",
+			task:     models.Task{ID: 2, Title: "Buy groceries", DueDate: models.NewDueDate(2026, 11, 3), Priority: models.HighPriority},
+			calendar: consts.GregorianCalendar,
+			expected: "[ ] #2 Buy groceries !high (due 2026-11-03)",
+		},
+		{
+			name:     "jalali due date with time",
+			task:     models.Task{ID: 3, Title: "Call mom", DueDate: models.NewDueDateTime(time.Date(2026, 11, 3, 6, 0, 0, 0, time.UTC))},
+			calendar: consts.JalaliCalendar,
+			expected: "[ ] #3 Call mom (due 1405/08/12 09:30)",
+		},
+		{
+			name:     "jalali overdue",
+			task:     models.Task{ID: 4, Title: "Pay rent", DueDate: models.NewDueDate(2026, 11, 1)},
+			calendar: consts.JalaliCalendar,
+			expected: "[ ] #4 Pay rent (overdue since 1405/08/10)",
+		},
+		{
+			name:     "done tasks are never overdue",
+			task:     models.Task{ID: 5, Title: "Pay rent", DueDate: models.NewDueDate(2026, 11, 1), IsDone: true},
+			calendar: consts.GregorianCalendar,
+			expected: "[x] #5 Pay rent (due 2026-11-01)",
+		},
+		{
+			name: "recurring",
+			task: models.Task{ID: 6, Title: "Water the plants", DueDate: models.NewDueDate(2026, 11, 5), Recurrence: &models.Recurrence{
+				Frequency: models.WeeklyFrequency, Interval: 2, ByWeekday: []time.Weekday{time.Monday, time.Thursday}, Count: 3,
+			}},
+			calendar: consts.GregorianCalendar,
+			expected: "[ ] #6 Water the plants (due 2026-11-05) [every 2 weeks on Mon, Thu, 2 more]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Task(tc.task, tc.calendar, now); got != tc.expected {
+				t.Errorf("got %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
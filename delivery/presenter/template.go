@@ -0,0 +1,44 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+	"todo-cli-refactor/delivery/deliveryParam"
+)
+
+// Templates renders each template as a line with its name, the number of
+// tasks it creates and the placeholders it needs, followed by the titles of
+// its tasks.
+func Templates(list deliveryParam.TemplatesResponse) []string {
+	if len(list.Templates) == 0 {
+		return []string{"no templates"}
+	}
+
+	var lines []string
+	for _, t := range list.Templates {
+		parts := []string{t.Name, plural(len(t.Titles), "task")}
+
+		if len(t.Placeholders) > 0 {
+			names := make([]string, 0, len(t.Placeholders))
+			for _, name := range t.Placeholders {
+				names = append(names, "{{"+name+"}}")
+			}
+			parts = append(parts, "needs "+strings.Join(names, ", "))
+		}
+
+		lines = append(lines, strings.Join(parts, "  "))
+		for _, title := range t.Titles {
+			lines = append(lines, indent+title)
+		}
+	}
+
+	return lines
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,30 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/delivery/deliveryParam"
+)
+
+func TestTemplates(t *testing.T) {
+	got := Templates(deliveryParam.TemplatesResponse{Templates: []deliveryParam.TemplateSummary{
+		{Name: "release", Titles: []string{"Release {{version}}", "Announce"}, Placeholders: []string{"version"}},
+		{Name: "onboarding", Titles: []string{"Laptop"}},
+	}})
+
+	expected := []string{
+		"release  2 tasks  needs {{version}}",
+		"    Release {{version}}",
+		"    Announce",
+		"onboarding  1 task",
+		"    Laptop",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("templates do not match:\ngot  %q\nwant %q", got, expected)
+	}
+
+	if got := Templates(deliveryParam.TemplatesResponse{}); !reflect.DeepEqual(got, []string{"no templates"}) {
+		t.Errorf("unexpected empty listing %q", got)
+	}
+}
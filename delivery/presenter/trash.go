@@ -0,0 +1,39 @@
+package presenter
+
+import (
+	"fmt"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+)
+
+// Trash renders the contents of the trash with the date each item was
+// deleted on and the date it will be purged after.
+func Trash(trash deliveryParam.TrashResponse, now time.Time) []string {
+	if len(trash.Tasks) == 0 && len(trash.Categories) == 0 {
+		return []string{"the trash is empty"}
+	}
+
+	deleted := func(deletedAt *time.Time) string {
+		return fmt.Sprintf("(deleted %s, purged after %s)",
+			FormatDate(deletedAt.In(now.Location()), trash.Calendar),
+			FormatDate(deletedAt.Add(trash.Retention).In(now.Location()), trash.Calendar))
+	}
+
+	var lines []string
+
+	if len(trash.Categories) > 0 {
+		lines = append(lines, "categories:")
+		for _, c := range trash.Categories {
+			lines = append(lines, fmt.Sprintf("%s#%d %s %s", indent, c.ID, c.Title, deleted(c.DeletedAt)))
+		}
+	}
+
+	if len(trash.Tasks) > 0 {
+		lines = append(lines, "tasks:")
+		for _, t := range trash.Tasks {
+			lines = append(lines, indent+Task(t, trash.Calendar, now)+" "+deleted(t.DeletedAt))
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,37 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestTrash(t *testing.T) {
+	now := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	got := Trash(deliveryParam.TrashResponse{
+		Tasks:      []models.Task{{ID: 3, Title: "Buy groceries", CategoryID: 2, DeletedAt: &deletedAt}},
+		Categories: []models.Category{{ID: 2, Title: "Home", DeletedAt: &deletedAt}},
+		Retention:  30 * 24 * time.Hour,
+		Calendar:   consts.GregorianCalendar,
+	}, now)
+
+	expected := []string{
+		"categories:",
+		"    #2 Home (deleted 2026-10-19, purged after 2026-11-18)",
+		"tasks:",
+		"    [ ] #3 Buy groceries (deleted 2026-10-19, purged after 2026-11-18)",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("trash does not match:\ngot  %q\nwant %q", got, expected)
+	}
+
+	if got := Trash(deliveryParam.TrashResponse{}, now); !reflect.DeepEqual(got, []string{"the trash is empty"}) {
+		t.Errorf("empty trash does not match: got %q", got)
+	}
+}
@@ -0,0 +1,91 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/color"
+)
+
+const indent = "    "
+
+// TaskTree renders a task listing as an indented tree, subtasks below their
+// parent and checklist items below their task. Tasks keep the order they're
+// given in among their siblings; tasks whose parent isn't listed are shown at
+// the top level. Progress and open blockers are shown next to their task.
+func TaskTree(list deliveryParam.ListTaskResponse, now time.Time) []string {
+	tasks := list.Tasks
+
+	listed := map[int]bool{}
+	for _, t := range tasks {
+		listed[t.ID] = true
+	}
+
+	children := map[int][]models.Task{}
+	var roots []models.Task
+	for _, t := range tasks {
+		if t.ParentID != 0 && listed[t.ParentID] && t.ParentID != t.ID {
+			children[t.ParentID] = append(children[t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	colors := map[int]string{}
+	for _, c := range list.Categories {
+		colors[c.ID] = c.Color
+	}
+
+	var lines []string
+	visited := map[int]bool{}
+
+	var walk func(t models.Task, depth int)
+	walk = func(t models.Task, depth int) {
+		if visited[t.ID] {
+			return
+		}
+		visited[t.ID] = true
+
+		line := strings.Repeat(indent, depth) + color.Paint(Task(t, list.Calendar, now), colors[t.CategoryID], ColorMode)
+		if percentage, ok := list.Progress[t.ID]; ok {
+			line += fmt.Sprintf(" %d%%", percentage)
+		}
+		if blockers := list.OpenBlockers[t.ID]; len(blockers) > 0 {
+			line += " (blocked by " + taskRefs(blockers) + ")"
+		}
+		if values := fieldValues(t, list.Categories); len(values) > 0 {
+			line += " [" + strings.Join(values, ", ") + "]"
+		}
+		lines = append(lines, line)
+
+		for _, item := range t.Checklist {
+			check := " "
+			if item.Done {
+				check = "x"
+			}
+			lines = append(lines, fmt.Sprintf("%s- [%s] %s", strings.Repeat(indent, depth+1), check, item.Text))
+		}
+
+		for _, child := range children[t.ID] {
+			walk(child, depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	return lines
+}
+
+// taskRefs renders task IDs as "#3, #5".
+func taskRefs(ids []int) string {
+	refs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, fmt.Sprintf("#%d", id))
+	}
+
+	return strings.Join(refs, ", ")
+}
@@ -0,0 +1,44 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+)
+
+func TestTaskTree(t *testing.T) {
+	now := time.Date(2026, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	tasks := []models.Task{
+		{ID: 1, Title: "Release 3"},
+		{ID: 4, Title: "Proofread", ParentID: 2},
+		{ID: 2, Title: "Write changelog", ParentID: 1, Checklist: []models.ChecklistItem{{Text: "features", Done: true}, {Text: "fixes"}}},
+		{ID: 3, Title: "Announce", ParentID: 1, IsDone: true},
+		{ID: 5, Title: "Orphan", ParentID: 9},
+		{ID: 6, Title: "Publish", BlockedBy: []int{2, 4}},
+	}
+
+	expected := []string{
+		"[ ] #1 Release 3 42%",
+		"    [ ] #2 Write changelog 33%",
+		"        - [x] features",
+		"        - [ ] fixes",
+		"        [ ] #4 Proofread",
+		"    [x] #3 Announce",
+		"[ ] #5 Orphan",
+		"[ ] #6 Publish (blocked by #2, #4)",
+	}
+
+	got := TaskTree(deliveryParam.ListTaskResponse{
+		Tasks:        tasks,
+		Progress:     map[int]int{1: 42, 2: 33},
+		OpenBlockers: map[int][]int{6: {2, 4}},
+		Calendar:     consts.GregorianCalendar,
+	}, now)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("tree does not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
@@ -0,0 +1,32 @@
+package presenter
+
+import (
+	"strings"
+	"todo-cli-refactor/models"
+)
+
+// Views renders one line per view: its name, filter, sort and grouping.
+// Built-in views, which have no ID, are marked as such.
+func Views(views []models.View) []string {
+	lines := make([]string, 0, len(views))
+	for _, v := range views {
+		parts := []string{v.Name}
+
+		if v.Query != "" {
+			parts = append(parts, v.Query)
+		}
+		if v.Sort != "" {
+			parts = append(parts, "sorted by "+v.Sort)
+		}
+		if v.Group != "" {
+			parts = append(parts, "grouped by "+v.Group)
+		}
+		if v.ID == 0 {
+			parts = append(parts, "(built-in)")
+		}
+
+		lines = append(lines, strings.Join(parts, "  "))
+	}
+
+	return lines
+}
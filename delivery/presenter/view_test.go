@@ -0,0 +1,23 @@
+package presenter
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestViews(t *testing.T) {
+	got := Views([]models.View{
+		{Name: "overdue", Query: "status:overdue", Sort: "due"},
+		{ID: 1, Name: "work", Query: "category:work", Group: models.PriorityGroup},
+	})
+
+	expected := []string{
+		"overdue  status:overdue  sorted by due  (built-in)",
+		"work  category:work  grouped by priority",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("views do not match:\ngot  %q\nwant %q", got, expected)
+	}
+}
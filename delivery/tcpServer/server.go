@@ -2,21 +2,61 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"time"
 	"todo-cli-refactor/consts"
 	"todo-cli-refactor/delivery/deliveryParam"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/chunk"
+	"todo-cli-refactor/pkg/duedate"
+	"todo-cli-refactor/pkg/search"
+	"todo-cli-refactor/repositories/fileRepository/attachment"
+	"todo-cli-refactor/repositories/fileRepository/blob"
+	"todo-cli-refactor/repositories/fileRepository/category"
+	"todo-cli-refactor/repositories/fileRepository/comment"
+	"todo-cli-refactor/repositories/fileRepository/history"
+	"todo-cli-refactor/repositories/fileRepository/journal"
+	"todo-cli-refactor/repositories/fileRepository/milestone"
+	"todo-cli-refactor/repositories/fileRepository/project"
 	"todo-cli-refactor/repositories/fileRepository/task"
+	"todo-cli-refactor/repositories/fileRepository/template"
+	"todo-cli-refactor/repositories/fileRepository/user"
+	"todo-cli-refactor/repositories/fileRepository/view"
+	"todo-cli-refactor/repositories/fileRepository/workflow"
+	attachment2 "todo-cli-refactor/services/attachment"
+	category2 "todo-cli-refactor/services/category"
+	comment2 "todo-cli-refactor/services/comment"
+	history2 "todo-cli-refactor/services/history"
+	journal2 "todo-cli-refactor/services/journal"
+	project2 "todo-cli-refactor/services/project"
+	search2 "todo-cli-refactor/services/search"
 	task2 "todo-cli-refactor/services/task"
+	template2 "todo-cli-refactor/services/template"
+	"todo-cli-refactor/services/trash"
+	user2 "todo-cli-refactor/services/user"
+	view2 "todo-cli-refactor/services/view"
+	workflow2 "todo-cli-refactor/services/workflow"
 )
 
+// attachmentQuota is the number of bytes of attachments each user may store.
+const attachmentQuota = 50 << 20
+
+// purgeInterval is how often the trash is checked for items to purge.
+const purgeInterval = time.Hour
+
 func main() {
 	const (
 		network = "tcp"
 		address = ":9986"
 	)
 
+	retention := flag.Duration("trash-retention", 30*24*time.Hour, "how long deleted tasks and categories are kept in the trash")
+	flag.Parse()
+
 	listener, err := net.Listen(network, address)
 	if err != nil {
 		log.Fatalln("cant listen on given address", err)
@@ -26,8 +66,53 @@ func main() {
 
 	fmt.Println("server listening on: ", listener.Addr())
 
-	f := task.New("./task.txt", consts.JsonSerializationMode)
-	taskService := task2.NewService(f)
+	// tasks are written through the indexer to keep the search index current
+	index := search.NewIndex()
+	f := search2.NewIndexer(task.New("./task.txt", consts.JsonSerializationMode), index)
+	if rErr := f.Rebuild(); rErr != nil {
+		log.Fatalln("cant build the search index,", rErr)
+	}
+
+	c := category.New("./category.txt", consts.JsonSerializationMode)
+
+	// a user's tasks include the ones of categories shared with them
+	scoped := category2.NewScope(f, c)
+	searchService := search2.NewService(scoped, index)
+
+	h := history.New("./history.txt", consts.JsonSerializationMode)
+	historyService := history2.NewService(h, scoped)
+
+	u := user.New("./user.txt", consts.TextSerializationMode)
+	userService := user2.NewService(u)
+
+	// comments resolve their @mentions against the registered users
+	comments := comment.New("./comment.txt", consts.JsonSerializationMode)
+	commentService := comment2.NewService(comments, scoped, u)
+
+	j := journal.New("./journal.txt", consts.JsonSerializationMode)
+
+	viewService := view2.NewService(view.New("./view.txt", consts.JsonSerializationMode))
+
+	templates := template.New("./template.txt", consts.JsonSerializationMode)
+
+	projects := project.New("./project.txt", consts.JsonSerializationMode)
+	milestones := milestone.New("./milestone.txt", consts.JsonSerializationMode)
+
+	workflows := workflow.New("./workflow.txt", consts.JsonSerializationMode)
+
+	a := attachment.New("./attachment.txt", consts.JsonSerializationMode)
+	attachmentService := attachment2.NewService(a, blob.New("./attachments"), scoped, attachmentQuota)
+
+	// purges are recorded as made by the system, user ID zero
+	purger := trash.NewService(history2.NewTracker(scoped, h, 0), c, a, comments, *retention)
+
+	if collected, cErr := attachmentService.CollectGarbage(); cErr != nil {
+		log.Println("cant collect unreferenced attachments,", cErr)
+	} else if collected.Removed > 0 {
+		fmt.Println("removed unreferenced attachments: ", collected.Removed)
+	}
+
+	var lastPurge time.Time
 
 	for {
 		connection, aErr := listener.Accept()
@@ -36,59 +121,1047 @@ func main() {
 			continue
 		}
 
-		var rawRequest = make([]byte, 1024)
-		numberOfBytes, rErr := connection.Read(rawRequest)
-		if rErr != nil {
-			log.Println("cant read data from connection", rErr)
+		// the request is decoded as a stream since an upload's content follows it
+		decoder := json.NewDecoder(connection)
+		req := &deliveryParam.Request{}
+		if dErr := decoder.Decode(req); dErr != nil {
+			log.Println("bad request...", dErr)
+			connection.Close()
 
 			continue
 		}
 
-		fmt.Printf("client address: %s, numberOfByttes: %d, data: %s\n",
-			connection.RemoteAddr(), numberOfBytes, string(rawRequest))
+		fmt.Printf("client address: %s, command: %s\n", connection.RemoteAddr(), req.Command)
+
+		stream := io.MultiReader(decoder.Buffered(), connection)
+
+		if time.Since(lastPurge) >= purgeInterval {
+			purgeTrash(purger, attachmentService)
+			lastPurge = time.Now()
+		}
+
+		authenticated, lErr := userService.Login(user2.LoginRequest{
+			Email:    req.Credentials.Email,
+			Password: req.Credentials.Password,
+		})
+		if lErr != nil {
+			writeResponse(connection, nil, lErr)
+			connection.Close()
 
-		req := &deliveryParam.Request{}
-		if uErr := json.Unmarshal(rawRequest[:numberOfBytes], req); uErr != nil {
-			log.Println("bad request...", uErr)
 			continue
 		}
 
+		// task changes are recorded as made by the authenticated user
+		tracked := history2.NewTracker(scoped, h, authenticated.User.ID)
+		journalService := journal2.NewService(j, tracked, c)
+
+		// services write through a recorder so the request can be undone
+		recorder := journal2.NewRecorder(tracked, c)
+		taskService := task2.NewService(recorder, milestones)
+		trashService := trash.NewService(recorder, recorder, a, comments, *retention)
+		categoryService := category2.NewService(recorder)
+		templateService := template2.NewService(templates, taskService, recorder)
+		projectService := project2.NewService(projects, milestones, recorder)
+		workflowService := workflow2.NewService(workflows, recorder)
+
 		switch req.Command {
 		case "create-task":
+			dueDate, pErr := duedate.Parse(req.CreateTaskRequest.DueDate, time.Now())
+			if pErr != nil {
+				writeResponse(connection, nil, pErr)
+
+				break
+			}
+
+			priority, pErr := models.ParsePriority(req.CreateTaskRequest.Priority)
+			if pErr != nil {
+				writeResponse(connection, nil, pErr)
+
+				break
+			}
+
+			var recurrence *models.Recurrence
+			if req.CreateTaskRequest.Recurrence != "" {
+				r, rErr := models.ParseRecurrence(req.CreateTaskRequest.Recurrence)
+				if rErr != nil {
+					writeResponse(connection, nil, rErr)
+
+					break
+				}
+				recurrence = &r
+			}
+
+			// custom field values are checked against the fields of the category
+			var categories []models.Category
+			if len(req.CreateTaskRequest.Fields) > 0 {
+				response, lErr := categoryService.List(category2.ListRequest{
+					AuthenticatedUserID: authenticated.User.ID,
+				})
+				if lErr != nil {
+					writeResponse(connection, nil, lErr)
+
+					break
+				}
+				categories = response.Categories
+			}
+
+			userWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if wErr != nil {
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+
 			response, cErr := taskService.Create(task2.CreateRequest{
 				Title:               req.CreateTaskRequest.Title,
-				DueDate:             req.CreateTaskRequest.DueDate,
+				Description:         req.CreateTaskRequest.Description,
+				DueDate:             dueDate,
 				CategoryID:          req.CreateTaskRequest.CategoryID,
-				AuthenticatedUserID: 0,
+				Priority:            priority,
+				Tags:                req.CreateTaskRequest.Tags,
+				Recurrence:          recurrence,
+				ParentID:            req.CreateTaskRequest.ParentID,
+				MilestoneID:         req.CreateTaskRequest.MilestoneID,
+				Checklist:           req.CreateTaskRequest.Checklist,
+				Fields:              req.CreateTaskRequest.Fields,
+				Categories:          categories,
+				Workflow:            userWorkflow.Workflow,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "complete-task":
+			userWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if wErr != nil {
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+
+			response, cErr := taskService.Complete(task2.CompleteRequest{
+				TaskID:              req.CompleteTaskRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+				Force:               req.CompleteTaskRequest.Force,
+				Workflow:            userWorkflow.Workflow,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "list-task":
+			listRequest := task2.ListRequest{
+				UserID:      authenticated.User.ID,
+				Sort:        req.ListTaskRequest.Sort,
+				Tags:        req.ListTaskRequest.Tags,
+				ExcludeTags: req.ListTaskRequest.ExcludeTags,
+				Ready:       req.ListTaskRequest.Ready,
+				Query:       req.ListTaskRequest.Query,
+				Limit:       req.ListTaskRequest.Limit,
+				Cursor:      req.ListTaskRequest.Cursor,
+			}
+			group := req.ListTaskRequest.Group
+
+			if req.ListTaskRequest.View != "" {
+				found, vErr := viewService.Get(view2.GetRequest{
+					Name:                req.ListTaskRequest.View,
+					AuthenticatedUserID: authenticated.User.ID,
+				})
+				if vErr != nil {
+					writeResponse(connection, nil, vErr)
+
+					break
+				}
+
+				listRequest.ViewQuery = found.View.Query
+				if listRequest.Sort == "" {
+					listRequest.Sort = found.View.Sort
+				}
+				if group == "" {
+					group = found.View.Group
+				}
+			}
+
+			if gErr := view2.ValidateGroup(group); gErr != nil {
+				writeResponse(connection, nil, gErr)
+
+				break
+			}
+
+			// the filter query may name categories by their title
+			categories, cErr := categoryService.List(category2.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
 			})
 			if cErr != nil {
-				_, wErr := connection.Write([]byte(cErr.Error()))
-				if wErr != nil {
-					log.Println("cant write data to connection,", rErr)
-					continue
+				writeResponse(connection, nil, cErr)
+
+				break
+			}
+			listRequest.Categories = categories.Categories
+
+			// the filter query may name workflow statuses
+			userWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if wErr != nil {
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+			listRequest.Workflow = userWorkflow.Workflow
+
+			response, lErr := taskService.List(listRequest)
+
+			list := deliveryParam.ListTaskResponse{
+				Tasks:        response.Tasks,
+				Progress:     response.Progress,
+				OpenBlockers: response.OpenBlockers,
+				Group:        group,
+				Categories:   categories.Categories,
+				Calendar:     authenticated.User.Calendar,
+				NextCursor:   response.NextCursor,
+			}
+
+			writeResponse(connection, list, lErr)
+		case "update-task":
+			updateRequest, pErr := parseUpdateTaskRequest(req.UpdateTaskRequest, time.Now())
+			if pErr != nil {
+				writeResponse(connection, nil, pErr)
+
+				break
+			}
+			updateRequest.AuthenticatedUserID = authenticated.User.ID
+
+			if len(updateRequest.Fields) > 0 || updateRequest.CategoryID != nil {
+				categories, lErr := categoryService.List(category2.ListRequest{
+					AuthenticatedUserID: authenticated.User.ID,
+				})
+				if lErr != nil {
+					writeResponse(connection, nil, lErr)
+
+					break
 				}
+				updateRequest.Categories = categories.Categories
+			}
+
+			response, uErr := taskService.Update(updateRequest)
+
+			writeResponse(connection, response, uErr)
+		case "show-task":
+			response, sErr := taskService.Show(task2.ShowRequest{
+				TaskID:              req.ShowTaskRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, deliveryParam.ShowTaskResponse{
+				Task:         response.Task,
+				Subtasks:     response.Subtasks,
+				OpenBlockers: response.OpenBlockers,
+				Calendar:     authenticated.User.Calendar,
+			}, sErr)
+		case "add-tag":
+			response, tErr := taskService.AddTags(task2.TagRequest{
+				TaskID:              req.TagTaskRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+				Tags:                req.TagTaskRequest.Tags,
+			})
+
+			writeResponse(connection, response, tErr)
+		case "remove-tag":
+			response, tErr := taskService.RemoveTags(task2.TagRequest{
+				TaskID:              req.TagTaskRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+				Tags:                req.TagTaskRequest.Tags,
+			})
+
+			writeResponse(connection, response, tErr)
+		case "rename-tag":
+			response, rErr := taskService.RenameTag(task2.RenameTagRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+				From:                req.RenameTagRequest.From,
+				To:                  req.RenameTagRequest.To,
+			})
+
+			writeResponse(connection, response, rErr)
+		case "list-tags":
+			response, lErr := taskService.ListTags(task2.ListTagsRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, lErr)
+		case "add-item":
+			response, cErr := taskService.AddChecklistItem(task2.ChecklistRequest{
+				TaskID:              req.ChecklistRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+				Text:                req.ChecklistRequest.Text,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "check-item", "uncheck-item":
+			response, cErr := taskService.CheckChecklistItem(task2.ChecklistItemRequest{
+				TaskID:              req.ChecklistRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+				Index:               req.ChecklistRequest.Index,
+				Done:                req.Command == "check-item",
+			})
+
+			writeResponse(connection, response, cErr)
+		case "remove-item":
+			response, cErr := taskService.RemoveChecklistItem(task2.ChecklistItemRequest{
+				TaskID:              req.ChecklistRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+				Index:               req.ChecklistRequest.Index,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "add-dependency":
+			response, dErr := taskService.AddDependency(task2.DependencyRequest{
+				TaskID:              req.DependencyRequest.TaskID,
+				BlockerID:           req.DependencyRequest.BlockerID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "remove-dependency":
+			response, dErr := taskService.RemoveDependency(task2.DependencyRequest{
+				TaskID:              req.DependencyRequest.TaskID,
+				BlockerID:           req.DependencyRequest.BlockerID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "critical-path":
+			response, cErr := taskService.CriticalPath(task2.CriticalPathRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+				TaskID:              req.CriticalPathRequest.TaskID,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "upload-attachment":
+			content := chunk.NewReader(stream)
+			response, uErr := attachmentService.Upload(attachment2.UploadRequest{
+				TaskID:              req.AttachmentRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+				Name:                req.AttachmentRequest.Name,
+				Content:             content,
+			})
+
+			// read what a failed upload left so the client gets to see the response
+			io.Copy(io.Discard, content)
+
+			writeResponse(connection, response, uErr)
+		case "download-attachment":
+			response, dErr := attachmentService.Download(attachment2.DownloadRequest{
+				AttachmentID:        req.AttachmentRequest.AttachmentID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if dErr != nil {
+				writeResponse(connection, nil, dErr)
+
+				break
 			}
 
-			data, mErr := json.Marshal(&response)
-			if mErr != nil {
-				_, wErr := connection.Write([]byte(mErr.Error()))
-				if wErr != nil {
-					log.Println("cant marshal response,", rErr)
+			writeAttachment(connection, response)
+		case "list-attachments":
+			response, lErr := attachmentService.List(attachment2.ListRequest{
+				TaskID:              req.AttachmentRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, lErr)
+		case "remove-attachment":
+			response, rErr := attachmentService.Remove(attachment2.RemoveRequest{
+				AttachmentID:        req.AttachmentRequest.AttachmentID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
 
-					continue
+			writeResponse(connection, response, rErr)
+		case "move-task":
+			userWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if wErr != nil {
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+
+			response, mErr := taskService.Move(task2.MoveRequest{
+				TaskID:              req.MoveTaskRequest.TaskID,
+				Status:              req.MoveTaskRequest.Status,
+				Force:               req.MoveTaskRequest.Force,
+				Workflow:            userWorkflow.Workflow,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, mErr)
+		case "assign-task":
+			assigneeID := 0
+			if req.AssignTaskRequest.Email != "" {
+				assignee, fErr := userService.Find(user2.FindRequest{Email: req.AssignTaskRequest.Email})
+				if fErr != nil {
+					writeResponse(connection, nil, fErr)
+
+					break
 				}
+				assigneeID = assignee.User.ID
+			}
+
+			response, aErr := taskService.Assign(task2.AssignRequest{
+				TaskID:              req.AssignTaskRequest.TaskID,
+				AssigneeID:          assigneeID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, aErr)
+		case "transfer-task":
+			owner, fErr := userService.Find(user2.FindRequest{Email: req.AssignTaskRequest.Email})
+			if fErr != nil {
+				writeResponse(connection, nil, fErr)
+
+				break
+			}
+
+			ownerWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: owner.User.ID,
+			})
+			if wErr != nil {
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+
+			response, tErr := taskService.Transfer(task2.TransferRequest{
+				TaskID:              req.AssignTaskRequest.TaskID,
+				OwnerID:             owner.User.ID,
+				Workflow:            ownerWorkflow.Workflow,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, tErr)
+		case "board":
+			userWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if wErr != nil {
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+
+			categories, cErr := categoryService.List(category2.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if cErr != nil {
+				writeResponse(connection, nil, cErr)
+
+				break
+			}
+
+			response, bErr := taskService.Board(task2.BoardRequest{
+				UserID:     authenticated.User.ID,
+				Query:      req.BoardRequest.Query,
+				Categories: categories.Categories,
+				Workflow:   userWorkflow.Workflow,
+			})
+
+			board := deliveryParam.BoardResponse{}
+			for _, column := range response.Columns {
+				board.Columns = append(board.Columns, deliveryParam.BoardColumn{Status: column.Status, Tasks: column.Tasks})
+			}
+
+			writeResponse(connection, board, bErr)
+		case "show-workflow":
+			response, gErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, gErr)
+		case "save-workflow":
+			response, sErr := workflowService.Save(workflow2.SaveRequest{
+				Statuses:            req.WorkflowRequest.Statuses,
+				Transitions:         req.WorkflowRequest.Transitions,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, sErr)
+		case "reset-workflow":
+			response, rErr := workflowService.Reset(workflow2.ResetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, rErr)
+		case "create-category":
+			response, cErr := categoryService.Create(category2.CreateRequest{
+				Title:               req.CategoryRequest.Title,
+				Color:               req.CategoryRequest.Color,
+				ParentID:            req.CategoryRequest.ParentID,
+				Fields:              req.CategoryRequest.Fields,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "define-fields":
+			response, dErr := categoryService.DefineFields(category2.DefineFieldsRequest{
+				CategoryID:          req.CategoryRequest.CategoryID,
+				Fields:              req.CategoryRequest.Fields,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "move-category":
+			response, mErr := categoryService.Move(category2.MoveRequest{
+				CategoryID:          req.CategoryRequest.CategoryID,
+				ParentID:            req.CategoryRequest.ParentID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, mErr)
+		case "share-category":
+			member, fErr := userService.Find(user2.FindRequest{Email: req.ShareCategoryRequest.Email})
+			if fErr != nil {
+				writeResponse(connection, nil, fErr)
+
+				break
+			}
+
+			response, sErr := categoryService.Share(category2.ShareRequest{
+				CategoryID:          req.ShareCategoryRequest.CategoryID,
+				UserID:              member.User.ID,
+				Role:                req.ShareCategoryRequest.Role,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, sErr)
+		case "revoke-category":
+			member, fErr := userService.Find(user2.FindRequest{Email: req.ShareCategoryRequest.Email})
+			if fErr != nil {
+				writeResponse(connection, nil, fErr)
+
+				break
+			}
+
+			response, rErr := categoryService.Revoke(category2.RevokeRequest{
+				CategoryID:          req.ShareCategoryRequest.CategoryID,
+				UserID:              member.User.ID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, rErr)
+		case "list-categories":
+			response, lErr := categoryService.List(category2.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, lErr)
+		case "delete-task":
+			response, dErr := trashService.DeleteTask(trash.DeleteTaskRequest{
+				TaskID:              req.TrashRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "delete-category":
+			response, dErr := trashService.DeleteCategory(trash.DeleteCategoryRequest{
+				CategoryID:          req.TrashRequest.CategoryID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "restore-task":
+			response, rErr := trashService.RestoreTask(trash.RestoreTaskRequest{
+				TaskID:              req.TrashRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, rErr)
+		case "restore-category":
+			response, rErr := trashService.RestoreCategory(trash.RestoreCategoryRequest{
+				CategoryID:          req.TrashRequest.CategoryID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, rErr)
+		case "trash":
+			response, lErr := trashService.List(trash.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, deliveryParam.TrashResponse{
+				Tasks:      response.Tasks,
+				Categories: response.Categories,
+				Retention:  response.Retention,
+				Calendar:   authenticated.User.Calendar,
+			}, lErr)
+		case "task-history":
+			response, hErr := historyService.TaskHistory(history2.TaskHistoryRequest{
+				TaskID:              req.TaskHistoryRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, deliveryParam.TaskHistoryResponse{
+				Changes:  response.Changes,
+				Calendar: authenticated.User.Calendar,
+			}, hErr)
+		case "add-comment":
+			response, aErr := commentService.Add(comment2.AddRequest{
+				TaskID:              req.CommentRequest.TaskID,
+				Text:                req.CommentRequest.Text,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, aErr)
+		case "list-comments":
+			response, lErr := commentService.List(comment2.ListRequest{
+				TaskID:              req.CommentRequest.TaskID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
 
-				continue
+			writeResponse(connection, deliveryParam.CommentsResponse{
+				Comments: response.Comments,
+				Calendar: authenticated.User.Calendar,
+			}, lErr)
+		case "edit-comment":
+			response, eErr := commentService.Edit(comment2.EditRequest{
+				CommentID:           req.CommentRequest.CommentID,
+				Text:                req.CommentRequest.Text,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, eErr)
+		case "delete-comment":
+			response, dErr := commentService.Delete(comment2.DeleteRequest{
+				CommentID:           req.CommentRequest.CommentID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "mentions":
+			response, mErr := commentService.Mentions(comment2.MentionsRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			mentions := deliveryParam.MentionsResponse{Calendar: authenticated.User.Calendar}
+			for _, mention := range response.Mentions {
+				mentions.Mentions = append(mentions.Mentions, deliveryParam.Mention{Comment: mention.Comment, Task: mention.Task})
 			}
 
-			_, wErr := connection.Write(data)
+			writeResponse(connection, mentions, mErr)
+		case "undo":
+			response, uErr := journalService.Undo(journal2.UndoRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, journalResponse(response.Entry), uErr)
+		case "redo":
+			response, rErr := journalService.Redo(journal2.RedoRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, journalResponse(response.Entry), rErr)
+		case "save-view":
+			// the query may name categories and workflow statuses
+			categories, cErr := categoryService.List(category2.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if cErr != nil {
+				writeResponse(connection, nil, cErr)
+
+				break
+			}
+
+			userWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
 			if wErr != nil {
-				log.Println("cant write data to connection", rErr)
-				continue
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+
+			response, sErr := viewService.Save(view2.SaveRequest{
+				Name:                req.ViewRequest.Name,
+				Query:               req.ViewRequest.Query,
+				Sort:                req.ViewRequest.Sort,
+				Group:               req.ViewRequest.Group,
+				Categories:          categories.Categories,
+				Workflow:            userWorkflow.Workflow,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, sErr)
+		case "list-views":
+			response, lErr := viewService.List(view2.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, lErr)
+		case "delete-view":
+			response, dErr := viewService.Delete(view2.DeleteRequest{
+				Name:                req.ViewRequest.Name,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "save-template":
+			response, sErr := templateService.Save(template2.SaveRequest{
+				Name:                req.TemplateRequest.Name,
+				Tasks:               req.TemplateRequest.Tasks,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, sErr)
+		case "list-templates":
+			response, lErr := templateService.List(template2.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			list := deliveryParam.TemplatesResponse{}
+			for _, t := range response.Templates {
+				summary := deliveryParam.TemplateSummary{Name: t.Name, Placeholders: template2.Placeholders(t)}
+				for _, templateTask := range t.Tasks {
+					summary.Titles = append(summary.Titles, templateTask.Title)
+				}
+				list.Templates = append(list.Templates, summary)
+			}
+
+			writeResponse(connection, list, lErr)
+		case "delete-template":
+			response, dErr := templateService.Delete(template2.DeleteRequest{
+				Name:                req.TemplateRequest.Name,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "apply-template":
+			userWorkflow, wErr := workflowService.Get(workflow2.GetRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+			if wErr != nil {
+				writeResponse(connection, nil, wErr)
+
+				break
+			}
+
+			response, aErr := templateService.Apply(template2.ApplyRequest{
+				Name:                req.TemplateRequest.Name,
+				Values:              req.TemplateRequest.Values,
+				Start:               req.TemplateRequest.Start,
+				Workflow:            userWorkflow.Workflow,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, aErr)
+		case "create-project":
+			var title, description string
+			if req.ProjectRequest.Title != nil {
+				title = *req.ProjectRequest.Title
+			}
+			if req.ProjectRequest.Description != nil {
+				description = *req.ProjectRequest.Description
+			}
+
+			response, cErr := projectService.Create(project2.CreateRequest{
+				Title:               title,
+				Description:         description,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "list-projects":
+			response, lErr := projectService.List(project2.ListRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, deliveryParam.ProjectsResponse{
+				Projects:   response.Projects,
+				Milestones: response.Milestones,
+				Calendar:   authenticated.User.Calendar,
+			}, lErr)
+		case "update-project":
+			response, uErr := projectService.Update(project2.UpdateRequest{
+				ProjectID:           req.ProjectRequest.ProjectID,
+				Title:               req.ProjectRequest.Title,
+				Description:         req.ProjectRequest.Description,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, uErr)
+		case "delete-project":
+			response, dErr := projectService.Delete(project2.DeleteRequest{
+				ProjectID:           req.ProjectRequest.ProjectID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "create-milestone":
+			var title string
+			if req.ProjectRequest.Title != nil {
+				title = *req.ProjectRequest.Title
+			}
+
+			var targetDate models.DueDate
+			if req.ProjectRequest.TargetDate != nil {
+				var pErr error
+				targetDate, pErr = duedate.Parse(*req.ProjectRequest.TargetDate, time.Now())
+				if pErr != nil {
+					writeResponse(connection, nil, pErr)
+
+					break
+				}
+			}
+
+			response, cErr := projectService.CreateMilestone(project2.CreateMilestoneRequest{
+				ProjectID:           req.ProjectRequest.ProjectID,
+				Title:               title,
+				TargetDate:          targetDate,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, cErr)
+		case "update-milestone":
+			updateRequest := project2.UpdateMilestoneRequest{
+				MilestoneID:         req.ProjectRequest.MilestoneID,
+				Title:               req.ProjectRequest.Title,
+				AuthenticatedUserID: authenticated.User.ID,
+			}
+
+			if req.ProjectRequest.TargetDate != nil {
+				targetDate, pErr := duedate.Parse(*req.ProjectRequest.TargetDate, time.Now())
+				if pErr != nil {
+					writeResponse(connection, nil, pErr)
+
+					break
+				}
+				updateRequest.TargetDate = &targetDate
+			}
+
+			response, uErr := projectService.UpdateMilestone(updateRequest)
+
+			writeResponse(connection, response, uErr)
+		case "delete-milestone":
+			response, dErr := projectService.DeleteMilestone(project2.DeleteMilestoneRequest{
+				MilestoneID:         req.ProjectRequest.MilestoneID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			writeResponse(connection, response, dErr)
+		case "project-progress":
+			response, pErr := projectService.Progress(project2.ProgressRequest{
+				ProjectID:           req.ProjectRequest.ProjectID,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			progress := deliveryParam.ProjectProgressResponse{
+				Project:  response.Project,
+				Total:    deliveryParam.Progress(response.Total),
+				Calendar: authenticated.User.Calendar,
+			}
+			for _, m := range response.Milestones {
+				progress.Milestones = append(progress.Milestones, deliveryParam.MilestoneProgress{
+					Milestone: m.Milestone,
+					Progress:  deliveryParam.Progress(m.Progress),
+					Late:      m.Late,
+				})
+			}
+
+			writeResponse(connection, progress, pErr)
+		case "search":
+			response, sErr := searchService.Search(search2.SearchRequest{
+				Query:               req.SearchRequest.Query,
+				Limit:               req.SearchRequest.Limit,
+				AuthenticatedUserID: authenticated.User.ID,
+			})
+
+			tasks := make([]models.Task, 0, len(response.Results))
+			for _, result := range response.Results {
+				tasks = append(tasks, result.Task)
+			}
+
+			writeResponse(connection, deliveryParam.SearchResponse{
+				Tasks:    tasks,
+				Total:    response.Total,
+				Calendar: authenticated.User.Calendar,
+			}, sErr)
+		case "list-users":
+			response, uErr := userService.ListUsers(user2.ListUsersRequest{
+				Limit:  req.ListUsersRequest.Limit,
+				Cursor: req.ListUsersRequest.Cursor,
+			})
+
+			users := deliveryParam.ListUsersResponse{NextCursor: response.NextCursor}
+			for _, listed := range response.Users {
+				users.Users = append(users.Users, deliveryParam.UserSummary{ID: listed.ID, Name: listed.Name, Email: listed.Email})
 			}
+
+			writeResponse(connection, users, uErr)
+		case "update-profile":
+			response, uErr := userService.UpdateProfile(user2.UpdateProfileRequest{
+				AuthenticatedUserID: authenticated.User.ID,
+				Calendar:            req.UpdateProfileRequest.Calendar,
+			})
+
+			// the stored user holds the password, only its summary is sent back
+			writeResponse(connection, deliveryParam.UserSummary{
+				ID:       response.User.ID,
+				Name:     response.User.Name,
+				Email:    response.User.Email,
+				Calendar: response.User.Calendar,
+			}, uErr)
+		default:
+			writeResponse(connection, nil, fmt.Errorf("unknown command %q", req.Command))
+		}
+
+		// a failed request may have changed some records before failing, so
+		// whatever it changed is recorded either way
+		if _, rErr := journalService.Record(journal2.RecordRequest{
+			Command:             req.Command,
+			Tasks:               recorder.Tasks(),
+			Categories:          recorder.Categories(),
+			AuthenticatedUserID: authenticated.User.ID,
+		}); rErr != nil {
+			log.Println("cant record the request in the journal,", rErr)
 		}
 
 		connection.Close()
 	}
 
 }
+
+func writeResponse(connection net.Conn, response interface{}, err error) {
+	if err != nil {
+		_, wErr := connection.Write([]byte(err.Error()))
+		if wErr != nil {
+			log.Println("cant write data to connection,", wErr)
+		}
+
+		return
+	}
+
+	data, mErr := json.Marshal(response)
+	if mErr != nil {
+		_, wErr := connection.Write([]byte(mErr.Error()))
+		if wErr != nil {
+			log.Println("cant marshal response,", wErr)
+		}
+
+		return
+	}
+
+	_, wErr := connection.Write(data)
+	if wErr != nil {
+		log.Println("cant write data to connection", wErr)
+	}
+}
+
+func purgeTrash(trashService trash.Service, attachmentService attachment2.Service) {
+	purged, pErr := trashService.Purge()
+	if pErr != nil {
+		log.Println("cant purge the trash,", pErr)
+
+		return
+	}
+
+	if purged.Tasks > 0 || purged.Categories > 0 {
+		fmt.Printf("purged from the trash: %d tasks, %d categories\n", purged.Tasks, purged.Categories)
+	}
+
+	if purged.Tasks == 0 {
+		return
+	}
+
+	// the attachments of purged tasks are gone, free their blobs
+	collected, cErr := attachmentService.CollectGarbage()
+	if cErr != nil {
+		log.Println("cant collect unreferenced attachments,", cErr)
+
+		return
+	}
+
+	if collected.Removed > 0 {
+		fmt.Println("removed unreferenced attachments: ", collected.Removed)
+	}
+}
+
+func journalResponse(entry models.JournalEntry) deliveryParam.JournalResponse {
+	response := deliveryParam.JournalResponse{Command: entry.Command}
+
+	for _, version := range entry.Tasks {
+		if version.Before != nil {
+			response.TaskIDs = append(response.TaskIDs, version.Before.ID)
+		} else {
+			response.TaskIDs = append(response.TaskIDs, version.After.ID)
+		}
+	}
+
+	for _, version := range entry.Categories {
+		if version.Before != nil {
+			response.CategoryIDs = append(response.CategoryIDs, version.Before.ID)
+		} else {
+			response.CategoryIDs = append(response.CategoryIDs, version.After.ID)
+		}
+	}
+
+	return response
+}
+
+// writeAttachment sends the attachment's metadata as a line of JSON followed
+// by its content as a chunked stream.
+func writeAttachment(connection net.Conn, response attachment2.DownloadResponse) {
+	defer response.Content.Close()
+
+	data, mErr := json.Marshal(response.Attachment)
+	if mErr != nil {
+		writeResponse(connection, nil, mErr)
+
+		return
+	}
+
+	if _, wErr := connection.Write(append(data, '\n')); wErr != nil {
+		log.Println("cant write data to connection", wErr)
+
+		return
+	}
+
+	w := chunk.NewWriter(connection)
+	if _, cErr := io.Copy(w, response.Content); cErr != nil {
+		log.Println("cant write attachment to connection", cErr)
+
+		return
+	}
+
+	if cErr := w.Close(); cErr != nil {
+		log.Println("cant write attachment to connection", cErr)
+	}
+}
+
+func parseUpdateTaskRequest(req deliveryParam.UpdateTaskRequest, now time.Time) (task2.UpdateRequest, error) {
+	updateRequest := task2.UpdateRequest{
+		TaskID:      req.TaskID,
+		Title:       req.Title,
+		Description: req.Description,
+		CategoryID:  req.CategoryID,
+		ParentID:    req.ParentID,
+		MilestoneID: req.MilestoneID,
+		Fields:      req.Fields,
+	}
+
+	if req.DueDate != nil {
+		dueDate, pErr := duedate.Parse(*req.DueDate, now)
+		if pErr != nil {
+			return task2.UpdateRequest{}, pErr
+		}
+		updateRequest.DueDate = &dueDate
+	}
+
+	if req.Priority != nil {
+		priority, pErr := models.ParsePriority(*req.Priority)
+		if pErr != nil {
+			return task2.UpdateRequest{}, pErr
+		}
+		updateRequest.Priority = &priority
+	}
+
+	return updateRequest, nil
+}
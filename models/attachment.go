@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Attachment describes a file attached to a task. Its content is stored once
+// per distinct SHA-256 hash, however many attachments share it.
+type Attachment struct {
+	ID     int
+	TaskID int
+	UserID int
+	Name   string
+	Size   int64
+	// Hash is the hex encoded SHA-256 hash of the content.
+	Hash      string
+	CreatedAt time.Time
+}
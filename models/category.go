@@ -1,8 +1,19 @@
 package models
 
+import "time"
+
 type Category struct {
 	ID     int
 	Title  string
 	Color  string
 	UserID int
+	// ParentID is the category this one is nested in, zero for top level
+	// categories.
+	ParentID int `json:",omitempty"`
+	// Fields are the custom fields the tasks of the category carry.
+	Fields []FieldDef `json:",omitempty"`
+	// Shares are the users the category is shared with besides its owner.
+	Shares []Share `json:",omitempty"`
+	// DeletedAt is set while the category is in the trash.
+	DeletedAt *time.Time `json:",omitempty"`
 }
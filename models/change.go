@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Fields of a Change that mark the task as a whole being created or removed
+// for good rather than one of its fields changing.
+const (
+	CreatedField = "created"
+	PurgedField  = "purged"
+)
+
+// Change records one field of a task changing, who changed it and when.
+// Values are rendered as text, empty for unset fields.
+type Change struct {
+	TaskID    int
+	ActorID   int
+	ChangedAt time.Time
+	Field     string
+	OldValue  string `json:",omitempty"`
+	NewValue  string `json:",omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// ChecklistItem is a lightweight step of a task that has no fields of its own
+// besides its text.
+type ChecklistItem struct {
+	Text string
+	Done bool
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Comment is a note a user left on a task.
+type Comment struct {
+	ID       int
+	TaskID   int
+	AuthorID int
+	Text     string
+	// Mentions are the IDs of the users the text mentions as @name.
+	Mentions  []int `json:",omitempty"`
+	CreatedAt time.Time
+	// EditedAt is set once the author changes the text.
+	EditedAt *time.Time `json:",omitempty"`
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	DueDateLayout     = "2006-01-02"
+	DueDateTimeLayout = time.RFC3339
+)
+
+// legacyDueDateLayouts are the free-form shapes due dates were stored in before
+// they became real timestamps.
+var legacyDueDateLayouts = []string{
+	"2006/01/02",
+	"2006-1-2",
+	"2006/1/2",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+}
+
+// DueDate is the deadline of a task. AllDay is set when only a calendar date
+// was given, in which case Time is midnight UTC of that date.
+type DueDate struct {
+	Time   time.Time
+	AllDay bool
+	// Legacy holds a stored due date that couldn't be understood. Such a due
+	// date sets no deadline, but is written back as it was so it isn't lost.
+	Legacy string
+}
+
+func NewDueDate(year int, month time.Month, day int) DueDate {
+	return DueDate{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), AllDay: true}
+}
+
+func NewDueDateTime(t time.Time) DueDate {
+	return DueDate{Time: t.Truncate(time.Second)}
+}
+
+func (d DueDate) IsZero() bool {
+	return d.Time.IsZero()
+}
+
+// Date returns the calendar date of the due date in its own time zone.
+func (d DueDate) Date() (int, time.Month, int) {
+	return d.Time.Date()
+}
+
+// Before reports whether the due date has passed at the given moment. All-day
+// due dates only pass once their whole day is over in now's time zone.
+func (d DueDate) Before(now time.Time) bool {
+	if d.IsZero() {
+		return false
+	}
+
+	if d.AllDay {
+		y, m, day := d.Time.Date()
+		endOfDay := time.Date(y, m, day+1, 0, 0, 0, 0, now.Location())
+		return !now.Before(endOfDay)
+	}
+
+	return d.Time.Before(now)
+}
+
+func (d DueDate) String() string {
+	switch {
+	case d.IsZero():
+		return d.Legacy
+	case d.AllDay:
+		return d.Time.Format(DueDateLayout)
+	default:
+		return d.Time.Format(DueDateTimeLayout)
+	}
+}
+
+// ParseDueDate parses the canonical storage form produced by DueDate.String.
+func ParseDueDate(s string) (DueDate, error) {
+	if s == "" {
+		return DueDate{}, nil
+	}
+
+	if t, err := time.Parse(DueDateLayout, s); err == nil {
+		return DueDate{Time: t, AllDay: true}, nil
+	}
+
+	t, err := time.Parse(DueDateTimeLayout, s)
+	if err != nil {
+		return DueDate{}, fmt.Errorf("invalid due date: %s", s)
+	}
+
+	return DueDate{Time: t}, nil
+}
+
+// MigrateDueDate converts a stored due date into a DueDate. Besides the
+// canonical form it understands the layouts older records were written in;
+// values that can't be understood at all are kept as a Legacy due date so the
+// task itself is still loaded.
+func MigrateDueDate(s string) DueDate {
+	if d, err := ParseDueDate(s); err == nil {
+		return d
+	}
+
+	for _, layout := range legacyDueDateLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+			return DueDate{Time: t, AllDay: true}
+		}
+
+		return DueDate{Time: t}
+	}
+
+	return DueDate{Legacy: s}
+}
+
+func (d DueDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *DueDate) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("due date must be a string: %w", err)
+	}
+
+	*d = MigrateDueDate(s)
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the kind of value a custom field holds.
+type FieldType string
+
+const (
+	StringField FieldType = "string"
+	NumberField FieldType = "number"
+	// DateField holds a calendar day, written as YYYY-MM-DD.
+	DateField FieldType = "date"
+	// EnumField holds one of the options of the field.
+	EnumField FieldType = "enum"
+	// URLField holds an http or https URL.
+	URLField FieldType = "url"
+)
+
+var fieldTypes = []FieldType{StringField, NumberField, DateField, EnumField, URLField}
+
+// FilterFieldNames are the task attributes a filter query tests, in the
+// order they're suggested. A custom field can't take one of their names.
+var FilterFieldNames = []string{"status", "due", "category", "under", "tag", "title", "description", "priority", "id", "parent"}
+
+// FieldDef is a custom field a category defines for its tasks.
+type FieldDef struct {
+	Name string
+	Type FieldType
+	// Options are the values an enum field allows.
+	Options []string `json:",omitempty"`
+}
+
+// IsReservedFieldName reports whether a name is taken by a task attribute.
+func IsReservedFieldName(name string) bool {
+	for _, reserved := range FilterFieldNames {
+		if reserved == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseFieldType reads a field type, matched regardless of case.
+func ParseFieldType(s string) (FieldType, error) {
+	t := FieldType(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range fieldTypes {
+		if known == t {
+			return t, nil
+		}
+	}
+
+	names := make([]string, len(fieldTypes))
+	for i, known := range fieldTypes {
+		names[i] = string(known)
+	}
+
+	return "", fmt.Errorf("unknown field type %q, use one of %s", s, strings.Join(names, ", "))
+}
+
+// Validate checks that a field can be defined: its name is made of
+// lowercase letters and underscores so filter queries can name it, and only
+// enum fields have options, at least one of them.
+func (f FieldDef) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("a field needs a name")
+	}
+
+	for _, r := range f.Name {
+		if (r < 'a' || r > 'z') && r != '_' {
+			return fmt.Errorf("invalid field name %q, use lowercase letters and underscores", f.Name)
+		}
+	}
+
+	if IsReservedFieldName(f.Name) {
+		return fmt.Errorf("field name %q is taken by a task attribute", f.Name)
+	}
+
+	if _, err := ParseFieldType(string(f.Type)); err != nil {
+		return err
+	}
+
+	if f.Type != EnumField {
+		if len(f.Options) > 0 {
+			return fmt.Errorf("field %s: only enum fields have options", f.Name)
+		}
+
+		return nil
+	}
+
+	if len(f.Options) == 0 {
+		return fmt.Errorf("field %s: an enum field needs options", f.Name)
+	}
+
+	seen := map[string]bool{}
+	for _, option := range f.Options {
+		key := strings.ToLower(option)
+		if strings.TrimSpace(option) == "" || seen[key] {
+			return fmt.Errorf("field %s: options have to be distinct and not empty", f.Name)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// Normalize validates a value of the field and returns the form it's stored
+// in: numbers in their shortest form, dates as YYYY-MM-DD and enum values
+// spelled as their option. An empty value stands for no value.
+func (f FieldDef) Normalize(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	switch f.Type {
+	case NumberField:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return "", fmt.Errorf("field %s: %q isn't a number", f.Name, value)
+		}
+
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case DateField:
+		d, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %q isn't a date, use YYYY-MM-DD", f.Name, value)
+		}
+
+		return d.Format("2006-01-02"), nil
+	case EnumField:
+		for _, option := range f.Options {
+			if strings.EqualFold(option, value) {
+				return option, nil
+			}
+		}
+
+		return "", fmt.Errorf("field %s: unknown value %q, use one of %s", f.Name, value, strings.Join(f.Options, ", "))
+	case URLField:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("field %s: %q isn't an http or https URL", f.Name, value)
+		}
+
+		return u.String(), nil
+	default:
+		return value, nil
+	}
+}
+
+// FieldDefByName returns the field of a schema with the name.
+func FieldDefByName(fields []FieldDef, name string) (FieldDef, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return FieldDef{}, false
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// JournalEntry records the versions of the tasks and categories one
+// operation changed, so the operation can be undone and redone.
+type JournalEntry struct {
+	ID         int
+	UserID     int
+	Command    string
+	CreatedAt  time.Time
+	Tasks      []TaskVersion     `json:",omitempty"`
+	Categories []CategoryVersion `json:",omitempty"`
+	// Undone is set while the operation is undone and can be redone.
+	Undone bool `json:",omitempty"`
+}
+
+// TaskVersion holds a task before and after an operation. Before is nil for
+// a task the operation created, After for one it removed.
+type TaskVersion struct {
+	Before *Task `json:",omitempty"`
+	After  *Task `json:",omitempty"`
+}
+
+type CategoryVersion struct {
+	Before *Category `json:",omitempty"`
+	After  *Category `json:",omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority ranks how important a task is. The zero value means no priority
+// was given; higher values are more important.
+type Priority int
+
+const (
+	NoPriority Priority = iota
+	LowPriority
+	MediumPriority
+	HighPriority
+	UrgentPriority
+)
+
+var priorityNames = [...]string{"none", "low", "medium", "high", "urgent"}
+
+func (p Priority) String() string {
+	if p < NoPriority || p > UrgentPriority {
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+
+	return priorityNames[p]
+}
+
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return NoPriority, nil
+	}
+
+	for p, name := range priorityNames {
+		if name == s {
+			return Priority(p), nil
+		}
+	}
+
+	return NoPriority, fmt.Errorf("unknown priority %q, use one of %s", s, strings.Join(priorityNames[:], ", "))
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(data []byte) error {
+	parsed, err := ParsePriority(string(data))
+	if err != nil {
+		return err
+	}
+
+	*p = parsed
+
+	return nil
+}
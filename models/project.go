@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Project groups work above categories. Its tasks belong to it through the
+// milestones it owns.
+type Project struct {
+	ID          int
+	UserID      int
+	Title       string
+	Description string `json:",omitempty"`
+	CreatedAt   time.Time
+}
+
+// Milestone is a step of a project that tasks are planned towards.
+type Milestone struct {
+	ID         int
+	ProjectID  int
+	UserID     int
+	Title      string
+	TargetDate DueDate
+	CreatedAt  time.Time
+}
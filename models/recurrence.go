@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DailyFrequency   = "daily"
+	WeeklyFrequency  = "weekly"
+	MonthlyFrequency = "monthly"
+	YearlyFrequency  = "yearly"
+)
+
+var weekdayCodes = [7]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence describes how a task repeats, modelled after RFC 5545 RRULEs.
+type Recurrence struct {
+	Frequency string
+	// Interval is the number of frequency units between occurrences.
+	Interval  int
+	ByWeekday []time.Weekday `json:",omitempty"`
+	// ByMonthDay anchors monthly and yearly rules to a day of the month so
+	// that short months don't make later occurrences drift.
+	ByMonthDay int `json:",omitempty"`
+	// Until is the last date an occurrence may fall on.
+	Until DueDate
+	// Count is the number of occurrences left including this one, zero for
+	// no limit.
+	Count int `json:",omitempty"`
+	// SeriesID is the ID of the task the series started with, zero on that
+	// first task itself.
+	SeriesID int `json:",omitempty"`
+}
+
+// String renders the rule in RRULE form, e.g. FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + strings.ToUpper(r.Frequency)}
+
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+
+	if len(r.ByWeekday) > 0 {
+		days := make([]string, 0, len(r.ByWeekday))
+		for _, wd := range r.ByWeekday {
+			days = append(days, weekdayCodes[wd])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.ByMonthDay != 0 {
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", r.ByMonthDay))
+	}
+
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.String())
+	}
+
+	if r.Count != 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+
+	if r.SeriesID != 0 {
+		parts = append(parts, fmt.Sprintf("X-SERIES=%d", r.SeriesID))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// ParseRecurrence reads a rule written in RRULE form. A bare frequency such as
+// "weekly" is accepted as a shorthand for FREQ=WEEKLY.
+func ParseRecurrence(s string) (Recurrence, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+
+	r := Recurrence{Interval: 1}
+	if !strings.Contains(s, "=") {
+		r.Frequency = strings.ToLower(s)
+		return r, r.Validate()
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return Recurrence{}, fmt.Errorf("invalid recurrence part: %s", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Frequency = strings.ToLower(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "BYDAY":
+			r.ByWeekday, err = parseWeekdays(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = strconv.Atoi(value)
+		case "UNTIL":
+			r.Until, err = ParseDueDate(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "X-SERIES":
+			r.SeriesID, err = strconv.Atoi(value)
+		default:
+			return Recurrence{}, fmt.Errorf("unsupported recurrence part: %s", key)
+		}
+		if err != nil {
+			return Recurrence{}, fmt.Errorf("invalid recurrence %s: %s", key, value)
+		}
+	}
+
+	return r, r.Validate()
+}
+
+func (r Recurrence) Validate() error {
+	switch r.Frequency {
+	case DailyFrequency, WeeklyFrequency, MonthlyFrequency, YearlyFrequency:
+	default:
+		return fmt.Errorf("unknown recurrence frequency %q", r.Frequency)
+	}
+
+	if r.Interval < 1 {
+		return fmt.Errorf("recurrence interval must be at least 1")
+	}
+
+	if r.ByMonthDay < 0 || r.ByMonthDay > 31 {
+		return fmt.Errorf("invalid recurrence month day: %d", r.ByMonthDay)
+	}
+
+	if r.Count < 0 {
+		return fmt.Errorf("recurrence count can't be negative")
+	}
+
+	return nil
+}
+
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	var days []time.Weekday
+
+	for _, code := range strings.Split(strings.ToUpper(s), ",") {
+		found := false
+		for wd, c := range weekdayCodes {
+			if c == code {
+				days = append(days, time.Weekday(wd))
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("invalid weekday: %s", code)
+		}
+	}
+
+	return days, nil
+}
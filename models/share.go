@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is what a user may do with a category and its tasks. Each role can do
+// everything the roles before it can.
+type Role string
+
+const (
+	// ViewerRole sees the tasks of the category.
+	ViewerRole Role = "viewer"
+	// EditorRole changes the tasks of the category and adds new ones.
+	EditorRole Role = "editor"
+	// AdminRole deletes tasks of the category and shares it with others.
+	AdminRole Role = "admin"
+	// OwnerRole is the role of the user a category or task belongs to, it
+	// can't be granted.
+	OwnerRole Role = "owner"
+)
+
+var roleRanks = map[Role]int{ViewerRole: 1, EditorRole: 2, AdminRole: 3, OwnerRole: 4}
+
+// grantedRoles are the roles a category can be shared with.
+var grantedRoles = []string{string(ViewerRole), string(EditorRole), string(AdminRole)}
+
+func ParseRole(s string) (Role, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, name := range grantedRoles {
+		if name == s {
+			return Role(s), nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown role %q, use one of %s", s, strings.Join(grantedRoles, ", "))
+}
+
+// Allows tells whether the role can do what the needed role can. The empty
+// role allows nothing.
+func (r Role) Allows(need Role) bool {
+	return roleRanks[r] > 0 && roleRanks[r] >= roleRanks[need]
+}
+
+// Share grants a user a role on a category and the categories nested in it.
+type Share struct {
+	UserID int
+	Role   Role
+}
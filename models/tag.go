@@ -0,0 +1,22 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NormalizeTag checks that a tag is a single word and returns its canonical
+// lower case form. Sigils such as @ or # are kept as part of the tag.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", fmt.Errorf("tag can't be empty")
+	}
+
+	if strings.ContainsAny(tag, ",") || strings.IndexFunc(tag, unicode.IsSpace) != -1 {
+		return "", fmt.Errorf("invalid tag %q, tags can't contain spaces or commas", tag)
+	}
+
+	return strings.ToLower(tag), nil
+}
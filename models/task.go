@@ -1,10 +1,40 @@
 package models
 
+import "time"
+
 type Task struct {
-	ID         int
-	Title      string
-	DueDate    string
-	CategoryID int
-	IsDone     bool
-	UserID     int
+	ID    int
+	Title string
+	// Description holds free-form notes on the task, written in markdown.
+	Description string `json:",omitempty"`
+	DueDate     DueDate
+	CategoryID  int
+	// IsDone tells whether Status is a done status of the user's workflow,
+	// tasks stored before workflows existed only have IsDone.
+	IsDone bool
+	// UserID is the owner of the task.
+	UserID int
+	// AssigneeID is the user the task is assigned to, who sees it among
+	// their own tasks. Zero for unassigned tasks.
+	AssigneeID int `json:",omitempty"`
+	// Status is the workflow status the task is in, see Workflow.StatusOf.
+	Status   string   `json:",omitempty"`
+	Priority Priority `json:",omitempty"`
+	Tags     []string `json:",omitempty"`
+	// ParentID is the task this one is a subtask of, zero for top level tasks.
+	ParentID int `json:",omitempty"`
+	// MilestoneID is the project milestone the task is planned for, zero
+	// for tasks outside of projects.
+	MilestoneID int             `json:",omitempty"`
+	Checklist   []ChecklistItem `json:",omitempty"`
+	// Fields holds the values of the custom fields of the task's category,
+	// keyed by field name.
+	Fields map[string]string `json:",omitempty"`
+	// BlockedBy lists the IDs of tasks that have to be done before this one.
+	BlockedBy []int `json:",omitempty"`
+	// Recurrence is set on tasks that repeat; completing one of them creates
+	// the next occurrence as a new task.
+	Recurrence *Recurrence `json:",omitempty"`
+	// DeletedAt is set while the task is in the trash.
+	DeletedAt *time.Time `json:",omitempty"`
 }
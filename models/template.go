@@ -0,0 +1,26 @@
+package models
+
+// Template is a named set of tasks a user creates together, such as the
+// steps of a release. Its texts may hold {{placeholders}} that are filled in
+// when the template is applied.
+type Template struct {
+	ID     int
+	UserID int
+	Name   string
+	Tasks  []TemplateTask
+}
+
+// TemplateTask is a task a template creates. Due is relative to the day the
+// template is applied on, e.g. "+3d", "+1w 09:00" or "fri". Parent is the
+// position, counting from 1, of an earlier task of the template this one is
+// a subtask of.
+type TemplateTask struct {
+	Title       string
+	Description string   `json:",omitempty"`
+	Due         string   `json:",omitempty"`
+	CategoryID  int      `json:",omitempty"`
+	Priority    Priority `json:",omitempty"`
+	Tags        []string `json:",omitempty"`
+	Checklist   []string `json:",omitempty"`
+	Parent      int      `json:",omitempty"`
+}
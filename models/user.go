@@ -5,4 +5,7 @@ type User struct {
 	Name     string
 	Email    string
 	Password string
+	// Calendar is the calendar listings are rendered in for this user, one of
+	// the consts calendar names. Empty means Gregorian.
+	Calendar string `json:",omitempty"`
 }
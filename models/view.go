@@ -0,0 +1,20 @@
+package models
+
+// Ways a task listing can be grouped.
+const (
+	NoGroup       = ""
+	CategoryGroup = "category"
+	PriorityGroup = "priority"
+	DueGroup      = "due"
+)
+
+// View is a named task listing a user saved: a filter query with the sort
+// and grouping to show its tasks in.
+type View struct {
+	ID     int
+	UserID int
+	Name   string
+	Query  string `json:",omitempty"`
+	Sort   string `json:",omitempty"`
+	Group  string `json:",omitempty"`
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WorkflowStatus is a stage tasks move through, a column of the board.
+type WorkflowStatus struct {
+	Name string
+	// Done marks statuses that finish a task.
+	Done bool `json:",omitempty"`
+	// WIPLimit caps the number of tasks in the status, zero leaves it
+	// unlimited.
+	WIPLimit int `json:",omitempty"`
+}
+
+// Workflow is the statuses a user's tasks move through, in the order they
+// go through them. New tasks start in the first status that isn't done.
+type Workflow struct {
+	ID       int
+	UserID   int
+	Statuses []WorkflowStatus
+	// Transitions lists the statuses a task can move to from a status,
+	// keyed by status name. A status without an entry can move to any
+	// other.
+	Transitions map[string][]string `json:",omitempty"`
+}
+
+// reservedStatusNames are the task states a status filter knows besides
+// the workflow statuses.
+var reservedStatusNames = []string{"open", "blocked", "ready", "overdue"}
+
+// DefaultWorkflow is the workflow of users who didn't set up their own.
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		Statuses: []WorkflowStatus{
+			{Name: "backlog"},
+			{Name: "todo"},
+			{Name: "doing"},
+			{Name: "review"},
+			{Name: "done", Done: true},
+		},
+	}
+}
+
+// Status looks a status of the workflow up by name.
+func (w Workflow) Status(name string) (WorkflowStatus, bool) {
+	for _, s := range w.Statuses {
+		if s.Name == name {
+			return s, true
+		}
+	}
+
+	return WorkflowStatus{}, false
+}
+
+// InitialStatus is the status new and reopened tasks start in.
+func (w Workflow) InitialStatus() string {
+	for _, s := range w.Statuses {
+		if !s.Done {
+			return s.Name
+		}
+	}
+
+	return ""
+}
+
+// DoneStatus is the status completed tasks move to.
+func (w Workflow) DoneStatus() string {
+	for _, s := range w.Statuses {
+		if s.Done {
+			return s.Name
+		}
+	}
+
+	return ""
+}
+
+// StatusOf returns the status a task is in. Tasks stored before workflows
+// existed, or left in a status the workflow no longer has, are in its first
+// done status when they're done and in its initial status otherwise.
+func (w Workflow) StatusOf(t Task) string {
+	if s, ok := w.Status(t.Status); ok && s.Done == t.IsDone {
+		return s.Name
+	}
+
+	if t.IsDone {
+		return w.DoneStatus()
+	}
+
+	return w.InitialStatus()
+}
+
+// CanMove reports whether a task in one status can move to another.
+func (w Workflow) CanMove(from, to string) bool {
+	allowed, ok := w.Transitions[from]
+	if !ok {
+		return true
+	}
+
+	for _, name := range allowed {
+		if name == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks that a workflow has distinct, named statuses, at least
+// one of them done and one not, and that its transitions are between its
+// statuses.
+func (w Workflow) Validate() error {
+	if w.InitialStatus() == "" || w.DoneStatus() == "" {
+		return fmt.Errorf("a workflow needs a done status and one that isn't")
+	}
+
+	seen := map[string]bool{}
+	for _, s := range w.Statuses {
+		if s.Name == "" || strings.ContainsAny(s.Name, " \t\r\n,:") {
+			return fmt.Errorf("invalid status name %q", s.Name)
+		}
+
+		for _, reserved := range reservedStatusNames {
+			if s.Name == reserved {
+				return fmt.Errorf("status name %q is taken, a status filter uses it", s.Name)
+			}
+		}
+
+		if s.Name == "done" && !s.Done {
+			return fmt.Errorf("a status named done has to be a done status")
+		}
+
+		if s.WIPLimit < 0 {
+			return fmt.Errorf("status %s: invalid WIP limit %d", s.Name, s.WIPLimit)
+		}
+
+		if seen[s.Name] {
+			return fmt.Errorf("status %s is defined twice", s.Name)
+		}
+		seen[s.Name] = true
+	}
+
+	for from, to := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transition from unknown status %q", from)
+		}
+
+		for _, name := range to {
+			if !seen[name] {
+				return fmt.Errorf("transition from %s to unknown status %q", from, name)
+			}
+		}
+	}
+
+	return nil
+}
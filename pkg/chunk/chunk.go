@@ -0,0 +1,112 @@
+// Package chunk frames a byte stream into length prefixed chunks so it can
+// share a connection with the messages around it. Every chunk starts with its
+// length as a 4 byte big endian integer and a chunk of length zero ends the
+// stream.
+package chunk
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// MaxSize is the largest chunk a Writer sends and a Reader accepts.
+const MaxSize = 64 * 1024
+
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write sends p as one or more chunks.
+func (c *Writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		size := len(p)
+		if size > MaxSize {
+			size = MaxSize
+		}
+
+		var header [4]byte
+		binary.BigEndian.PutUint32(header[:], uint32(size))
+		if _, err := c.w.Write(header[:]); err != nil {
+			return written, err
+		}
+
+		n, err := c.w.Write(p[:size])
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		p = p[size:]
+	}
+
+	return written, nil
+}
+
+// Close ends the stream. It doesn't close the underlying writer.
+func (c *Writer) Close() error {
+	var header [4]byte
+	_, err := c.w.Write(header[:])
+
+	return err
+}
+
+type Reader struct {
+	r         io.Reader
+	remaining int
+	done      bool
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// Read reads the content of the chunks, returning io.EOF once the chunk
+// ending the stream was read and io.ErrUnexpectedEOF if the stream ends
+// without one.
+func (c *Reader) Read(p []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+
+	if c.remaining == 0 {
+		var header [4]byte
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return 0, err
+		}
+
+		size := binary.BigEndian.Uint32(header[:])
+		if size == 0 {
+			c.done = true
+
+			return 0, io.EOF
+		}
+
+		if size > MaxSize {
+			return 0, fmt.Errorf("chunk of %d bytes is larger than %d bytes", size, MaxSize)
+		}
+
+		c.remaining = int(size)
+	}
+
+	if len(p) > c.remaining {
+		p = p[:c.remaining]
+	}
+
+	n, err := c.r.Read(p)
+	c.remaining -= n
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
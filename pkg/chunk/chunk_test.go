@@ -0,0 +1,54 @@
+package chunk
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), MaxSize/4)
+
+	var stream bytes.Buffer
+	w := NewWriter(&stream)
+	if _, err := w.Write(content[:10]); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := w.Write(content[10:]); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	stream.WriteString("trailing message")
+
+	got, err := io.ReadAll(NewReader(&stream))
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+
+	if !bytes.Equal(got, content) {
+		t.Errorf("content does not match: got %d bytes, want %d", len(got), len(content))
+	}
+
+	if rest := stream.String(); rest != "trailing message" {
+		t.Errorf("reader consumed data after the stream: %q is left", rest)
+	}
+}
+
+func TestTruncatedStream(t *testing.T) {
+	var stream bytes.Buffer
+	w := NewWriter(&stream)
+	if _, err := w.Write([]byte("unfinished")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	truncated := stream.Bytes()[:8]
+	if _, err := io.ReadAll(NewReader(bytes.NewReader(truncated))); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF for a cut chunk, got %v", err)
+	}
+
+	if _, err := io.ReadAll(NewReader(&stream)); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF for a missing end chunk, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Package color validates the colors categories are given and renders them
+// on terminals. A color is either a name from the palette or a hex code,
+// stored normalized as the lowercase name or "#rrggbb".
+package color
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type rgb struct {
+	r, g, b uint8
+}
+
+// palette holds the named colors, in the order they're suggested in.
+var palette = []struct {
+	name string
+	rgb  rgb
+}{
+	{"red", rgb{0xe5, 0x48, 0x4d}},
+	{"orange", rgb{0xf7, 0x6b, 0x15}},
+	{"yellow", rgb{0xf5, 0xd9, 0x0a}},
+	{"green", rgb{0x30, 0xa4, 0x6c}},
+	{"teal", rgb{0x12, 0xa5, 0x94}},
+	{"blue", rgb{0x00, 0x90, 0xff}},
+	{"purple", rgb{0x8e, 0x4e, 0xc6}},
+	{"pink", rgb{0xd6, 0x40, 0x9f}},
+	{"brown", rgb{0xad, 0x7f, 0x58}},
+	{"gray", rgb{0x8b, 0x8d, 0x98}},
+}
+
+// Names returns the names of the palette.
+func Names() []string {
+	names := make([]string, len(palette))
+	for i, p := range palette {
+		names[i] = p.name
+	}
+
+	return names
+}
+
+// Normalize validates a color and returns the form it's stored in. Names
+// are matched regardless of case, hex codes may leave out the # and be
+// given in their short #rgb form. An empty color stands for no color.
+func Normalize(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" {
+		return "", nil
+	}
+
+	for _, p := range palette {
+		if p.name == color {
+			return color, nil
+		}
+	}
+
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		if _, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return "#" + hex, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown color %q, use a hex code like #ff8800 or one of %s",
+		color, strings.Join(Names(), ", "))
+}
+
+func lookup(color string) (rgb, bool) {
+	normalized, err := Normalize(color)
+	if err != nil || normalized == "" {
+		return rgb{}, false
+	}
+
+	for _, p := range palette {
+		if p.name == normalized {
+			return p.rgb, true
+		}
+	}
+
+	n, _ := strconv.ParseUint(normalized[1:], 16, 32)
+
+	return rgb{uint8(n >> 16), uint8(n >> 8), uint8(n)}, true
+}
+
+// Mode is how many colors a terminal can show.
+type Mode int
+
+const (
+	// NoColor leaves text as it is.
+	NoColor Mode = iota
+	// Palette256 maps colors to the nearest of the 256 color palette.
+	Palette256
+	// TrueColor shows colors as they are.
+	TrueColor
+)
+
+// Detect picks the mode of the terminal from the environment: NO_COLOR
+// turns colors off, a COLORTERM of truecolor or 24bit turns them all on and
+// any other terminal but a dumb one gets the 256 color palette.
+func Detect() Mode {
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColor
+	}
+
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return TrueColor
+	}
+
+	if term := os.Getenv("TERM"); term == "" || term == "dumb" {
+		return NoColor
+	}
+
+	return Palette256
+}
+
+// Paint wraps text in the escape codes that show it in a color. Text is
+// returned as it is when there's no color to show.
+func Paint(text, color string, mode Mode) string {
+	if mode == NoColor {
+		return text
+	}
+
+	c, ok := lookup(color)
+	if !ok {
+		return text
+	}
+
+	if mode == TrueColor {
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", c.r, c.g, c.b, text)
+	}
+
+	return fmt.Sprintf("\x1b[38;5;%dm%s\x1b[0m", index256(c), text)
+}
+
+// index256 returns the nearest entry of the 6x6x6 color cube of the 256
+// color palette, or of its gray ramp for colors without a hue.
+func index256(c rgb) int {
+	if c.r == c.g && c.g == c.b {
+		// the ramp runs from 8 to 238 in steps of 10, black and white
+		// are in the cube
+		switch {
+		case c.r < 4:
+			return 16
+		case c.r > 246:
+			return 231
+		case c.r > 233:
+			return 255
+		default:
+			return 232 + (int(c.r)-3)/10
+		}
+	}
+
+	level := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+
+	return 16 + 36*level(c.r) + 6*level(c.g) + level(c.b)
+}
@@ -0,0 +1,73 @@
+package color
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		color    string
+		expected string
+	}{
+		{color: "", expected: ""},
+		{color: " Red ", expected: "red"},
+		{color: "#FF8800", expected: "#ff8800"},
+		{color: "ff8800", expected: "#ff8800"},
+		{color: "#f80", expected: "#ff8800"},
+	}
+
+	for _, tc := range tests {
+		got, err := Normalize(tc.color)
+		if err != nil {
+			t.Errorf("Normalize(%q) failed: %v", tc.color, err)
+		}
+		if got != tc.expected {
+			t.Errorf("Normalize(%q) = %q, want %q", tc.color, got, tc.expected)
+		}
+	}
+
+	for _, color := range []string{"redd", "#ff88", "#gg8800"} {
+		if _, err := Normalize(color); err == nil {
+			t.Errorf("Normalize(%q) should fail", color)
+		}
+	}
+}
+
+func TestPaint(t *testing.T) {
+	tests := []struct {
+		color    string
+		mode     Mode
+		expected string
+	}{
+		{color: "#ff8800", mode: TrueColor, expected: "\x1b[38;2;255;136;0mtext\x1b[0m"},
+		{color: "#ff8800", mode: Palette256, expected: "\x1b[38;5;214mtext\x1b[0m"},
+		{color: "#808080", mode: Palette256, expected: "\x1b[38;5;244mtext\x1b[0m"},
+		{color: "blue", mode: TrueColor, expected: "\x1b[38;2;0;144;255mtext\x1b[0m"},
+		{color: "blue", mode: NoColor, expected: "text"},
+		{color: "", mode: TrueColor, expected: "text"},
+		{color: "not a color", mode: TrueColor, expected: "text"},
+	}
+
+	for _, tc := range tests {
+		if got := Paint("text", tc.color, tc.mode); got != tc.expected {
+			t.Errorf("Paint(%q, %d) = %q, want %q", tc.color, tc.mode, got, tc.expected)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("COLORTERM", "truecolor")
+	t.Setenv("NO_COLOR", "")
+	if got := Detect(); got != TrueColor {
+		t.Errorf("got mode %d, want truecolor", got)
+	}
+
+	t.Setenv("COLORTERM", "")
+	if got := Detect(); got != Palette256 {
+		t.Errorf("got mode %d, want 256 colors", got)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got := Detect(); got != NoColor {
+		t.Errorf("got mode %d, want no color", got)
+	}
+}
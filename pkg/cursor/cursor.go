@@ -0,0 +1,35 @@
+// Package cursor turns the position a paged listing stopped at into an
+// opaque token clients hand back to get the next page. A position is any
+// JSON encodable value, typically the sort key of the last item returned,
+// so the next page starts right after it however the list changed since.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Encode returns the token for a position.
+func Encode(position interface{}) (string, error) {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("can't encode cursor: %v", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode reads the position of a token into position.
+func Decode(token string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("invalid cursor %q", token)
+	}
+
+	if err := json.Unmarshal(data, position); err != nil {
+		return fmt.Errorf("invalid cursor %q", token)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package cursor
+
+import "testing"
+
+func TestCursor(t *testing.T) {
+	type position struct {
+		Sort    string
+		AfterID int
+	}
+
+	token, err := Encode(position{Sort: "due", AfterID: 42})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	var got position
+	if err := Decode(token, &got); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if got != (position{Sort: "due", AfterID: 42}) {
+		t.Errorf("got %+v", got)
+	}
+
+	for _, bad := range []string{"not a cursor!", "bm90IGpzb24"} {
+		if err := Decode(bad, &got); err == nil {
+			t.Errorf("Decode(%q) should fail", bad)
+		}
+	}
+}
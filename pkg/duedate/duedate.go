@@ -0,0 +1,217 @@
+package duedate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/jalali"
+)
+
+// jalaliYearLimit tells Jalali dates apart from Gregorian ones: a year below it
+// is read as a Solar Hijri year since nobody schedules tasks before 1700 AD.
+const jalaliYearLimit = 1700
+
+// absoluteLayouts are the ISO-8601 shapes accepted from users. Layouts without
+// a zone are interpreted in the caller's location.
+var absoluteLayouts = []struct {
+	layout  string
+	hasTime bool
+	hasZone bool
+}{
+	{layout: "2006-01-02", hasTime: false, hasZone: false},
+	{layout: "2006-01-02T15:04", hasTime: true, hasZone: false},
+	{layout: "2006-01-02T15:04:05", hasTime: true, hasZone: false},
+	{layout: "2006-01-02 15:04", hasTime: true, hasZone: false},
+	{layout: "2006-01-02 15:04:05", hasTime: true, hasZone: false},
+	{layout: time.RFC3339, hasTime: true, hasZone: true},
+	{layout: "2006-01-02T15:04Z07:00", hasTime: true, hasZone: true},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Parse turns what a user typed as a due date into a models.DueDate, relative
+// to now. Accepted inputs are:
+//
+//	2026-11-01, 2026-11-01T14:30, 2026-11-01 14:30, 2026-11-01T14:30:00+03:30
+//	1405/08/10, 1405-08-10 14:30 (Jalali, Persian digits are accepted too)
+//	today, tomorrow, yesterday
+//	fri, friday, next fri (the first such weekday after today)
+//	+3d, +2w, +1m, +1y, -1d
+//
+// Relative forms may be followed by a time of day, e.g. "tomorrow 09:30".
+// An empty input means the task has no due date.
+func Parse(input string, now time.Time) (models.DueDate, error) {
+	input = jalali.NormalizeDigits(strings.ToLower(strings.TrimSpace(input)))
+	if input == "" {
+		return models.DueDate{}, nil
+	}
+
+	if d, ok, err := parseJalali(input, now.Location()); ok {
+		return d, err
+	}
+
+	if d, ok := parseAbsolute(input, now.Location()); ok {
+		return d, nil
+	}
+
+	fields := strings.Fields(input)
+
+	var clock string
+	if last := fields[len(fields)-1]; len(fields) > 1 && strings.Contains(last, ":") {
+		clock = last
+		fields = fields[:len(fields)-1]
+	}
+
+	date, err := parseRelative(fields, now)
+	if err != nil {
+		return models.DueDate{}, err
+	}
+
+	if clock == "" {
+		return date, nil
+	}
+
+	return withClock(date, clock, now.Location())
+}
+
+func parseAbsolute(input string, loc *time.Location) (models.DueDate, bool) {
+	// time layouts are case sensitive about the T separator and the Z zone
+	upper := strings.ToUpper(input)
+
+	for _, l := range absoluteLayouts {
+		var t time.Time
+		var err error
+		if l.hasZone {
+			t, err = time.Parse(l.layout, upper)
+		} else {
+			t, err = time.ParseInLocation(l.layout, upper, loc)
+		}
+		if err != nil {
+			continue
+		}
+
+		if !l.hasTime {
+			return models.NewDueDate(t.Date()), true
+		}
+
+		return models.NewDueDateTime(t), true
+	}
+
+	return models.DueDate{}, false
+}
+
+func parseJalali(input string, loc *time.Location) (models.DueDate, bool, error) {
+	date, clock := input, ""
+	if i := strings.IndexAny(input, " t"); i != -1 {
+		date, clock = input[:i], strings.TrimSpace(input[i+1:])
+	}
+
+	i := strings.IndexAny(date, "/-")
+	if i <= 0 {
+		return models.DueDate{}, false, nil
+	}
+
+	if year, aErr := strconv.Atoi(date[:i]); aErr != nil || year >= jalaliYearLimit {
+		return models.DueDate{}, false, nil
+	}
+
+	jy, jm, jd, err := jalali.Parse(date)
+	if err != nil {
+		return models.DueDate{}, true, err
+	}
+
+	gy, gm, gd := jalali.ToGregorian(jy, jm, jd)
+	if clock == "" {
+		return models.NewDueDate(gy, gm, gd), true, nil
+	}
+
+	d, err := withClock(models.NewDueDate(gy, gm, gd), clock, loc)
+
+	return d, true, err
+}
+
+func parseRelative(fields []string, now time.Time) (models.DueDate, error) {
+	y, m, d := now.Date()
+	phrase := strings.Join(fields, " ")
+
+	switch phrase {
+	case "today":
+		return models.NewDueDate(y, m, d), nil
+	case "tomorrow":
+		return models.NewDueDate(y, m, d+1), nil
+	case "yesterday":
+		return models.NewDueDate(y, m, d-1), nil
+	}
+
+	if len(fields) == 2 && fields[0] == "next" {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 1 {
+		if wd, ok := weekdays[fields[0]]; ok {
+			days := (int(wd)-int(now.Weekday())+6)%7 + 1
+			return models.NewDueDate(y, m, d+days), nil
+		}
+
+		if offset := fields[0]; len(offset) > 2 && (offset[0] == '+' || offset[0] == '-') {
+			return parseOffset(offset, y, m, d)
+		}
+	}
+
+	return models.DueDate{}, fmt.Errorf("can't understand due date %q", phrase)
+}
+
+func parseOffset(offset string, y int, m time.Month, d int) (models.DueDate, error) {
+	unit := offset[len(offset)-1]
+
+	n, err := strconv.Atoi(offset[:len(offset)-1])
+	if err != nil {
+		return models.DueDate{}, fmt.Errorf("invalid due date offset: %s", offset)
+	}
+
+	switch unit {
+	case 'd':
+		return models.NewDueDate(y, m, d+n), nil
+	case 'w':
+		return models.NewDueDate(y, m, d+7*n), nil
+	case 'm':
+		return addMonths(y, m, d, n), nil
+	case 'y':
+		return addMonths(y, m, d, 12*n), nil
+	default:
+		return models.DueDate{}, fmt.Errorf("invalid due date unit %q, use d, w, m or y", unit)
+	}
+}
+
+// addMonths moves a date by n months. Days the target month doesn't have,
+// as in a month after January 31, land on its last day instead of spilling
+// into the month after.
+func addMonths(y int, m time.Month, d, n int) models.DueDate {
+	first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+
+	return models.NewDueDate(first.Year(), first.Month(), d)
+}
+
+func withClock(date models.DueDate, clock string, loc *time.Location) (models.DueDate, error) {
+	c, err := time.Parse("15:04", clock)
+	if err != nil {
+		return models.DueDate{}, fmt.Errorf("invalid time of day: %s", clock)
+	}
+
+	y, m, d := date.Date()
+
+	return models.NewDueDateTime(time.Date(y, m, d, c.Hour(), c.Minute(), 0, 0, loc)), nil
+}
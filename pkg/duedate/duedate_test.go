@@ -0,0 +1,103 @@
+package duedate
+
+import (
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+func TestParse(t *testing.T) {
+	tehran := time.FixedZone("IRST", 3*60*60+30*60)
+	// Wednesday
+	now := time.Date(2026, 10, 21, 15, 4, 0, 0, tehran)
+
+	tests := []struct {
+		input    string
+		expected models.DueDate
+	}{
+		{input: "", expected: models.DueDate{}},
+		{input: "2026-11-01", expected: models.NewDueDate(2026, 11, 1)},
+		{input: "2026-11-01T14:30", expected: models.NewDueDateTime(time.Date(2026, 11, 1, 14, 30, 0, 0, tehran))},
+		{input: "2026-11-01 14:30", expected: models.NewDueDateTime(time.Date(2026, 11, 1, 14, 30, 0, 0, tehran))},
+		{input: "2026-11-01T14:30:00Z", expected: models.NewDueDateTime(time.Date(2026, 11, 1, 14, 30, 0, 0, time.UTC))},
+		{input: "today", expected: models.NewDueDate(2026, 10, 21)},
+		{input: "Tomorrow", expected: models.NewDueDate(2026, 10, 22)},
+		{input: "yesterday", expected: models.NewDueDate(2026, 10, 20)},
+		{input: "fri", expected: models.NewDueDate(2026, 10, 23)},
+		{input: "next fri", expected: models.NewDueDate(2026, 10, 23)},
+		{input: "wednesday", expected: models.NewDueDate(2026, 10, 28)},
+		{input: "+3d", expected: models.NewDueDate(2026, 10, 24)},
+		{input: "+2w", expected: models.NewDueDate(2026, 11, 4)},
+		{input: "+1m", expected: models.NewDueDate(2026, 11, 21)},
+		{input: "-1d", expected: models.NewDueDate(2026, 10, 20)},
+		{input: "1405/08/10", expected: models.NewDueDate(2026, 11, 1)},
+		{input: "۱۴۰۵-۰۸-۱۰ 14:30", expected: models.NewDueDateTime(time.Date(2026, 11, 1, 14, 30, 0, 0, tehran))},
+		{input: "tomorrow 09:30", expected: models.NewDueDateTime(time.Date(2026, 10, 22, 9, 30, 0, 0, tehran))},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := Parse(tc.input, now)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			if got.AllDay != tc.expected.AllDay || !got.Time.Equal(tc.expected.Time) {
+				t.Errorf("due date does not match expected data: got %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseMonthEnd(t *testing.T) {
+	tests := []struct {
+		now      time.Time
+		input    string
+		expected models.DueDate
+	}{
+		{now: time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC), input: "+1m", expected: models.NewDueDate(2026, 2, 28)},
+		{now: time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC), input: "-1m", expected: models.NewDueDate(2026, 2, 28)},
+		{now: time.Date(2026, 10, 31, 9, 0, 0, 0, time.UTC), input: "+4m", expected: models.NewDueDate(2027, 2, 28)},
+		{now: time.Date(2028, 2, 29, 9, 0, 0, 0, time.UTC), input: "+1y", expected: models.NewDueDate(2029, 2, 28)},
+		{now: time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC), input: "+2m", expected: models.NewDueDate(2026, 3, 30)},
+	}
+
+	for _, tc := range tests {
+		got, err := Parse(tc.input, tc.now)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		if !got.Time.Equal(tc.expected.Time) {
+			t.Errorf("%s from %s: got %v, want %v", tc.input, tc.now.Format("2006-01-02"), got, tc.expected)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	now := time.Date(2026, 10, 21, 15, 4, 0, 0, time.UTC)
+
+	for _, input := range []string{"test", "2026-02-30", "+3x", "next", "tomorrow 25:00", "1404/12/30"} {
+		if _, err := Parse(input, now); err == nil {
+			t.Errorf("Parse should fail for %q", input)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	now := time.Date(2026, 10, 21, 15, 4, 0, 0, time.UTC)
+
+	d, err := Parse("2026-11-01T14:30:00+03:30", now)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	stored, err := models.ParseDueDate(d.String())
+	if err != nil {
+		t.Fatalf("ParseDueDate failed: %v", err)
+	}
+
+	if stored.String() != "2026-11-01T14:30:00+03:30" || !stored.Time.Equal(d.Time) {
+		t.Errorf("stored due date does not match: got %v, want %v", stored, d)
+	}
+}
@@ -0,0 +1,201 @@
+// Package jalali converts dates between the Gregorian and the Jalali (Solar
+// Hijri) calendars. The conversion follows the astronomical break table used
+// by the Iranian calendar and is valid for Jalali years -61 to 3177.
+package jalali
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var MonthNames = [12]string{
+	"Farvardin", "Ordibehesht", "Khordad",
+	"Tir", "Mordad", "Shahrivar",
+	"Mehr", "Aban", "Azar",
+	"Dey", "Bahman", "Esfand",
+}
+
+// breaks are the Jalali years in which the 33-year leap cycle is interrupted.
+var breaks = [...]int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210,
+	1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+// FromGregorian converts a Gregorian date to its Jalali year, month and day.
+func FromGregorian(gy int, gm time.Month, gd int) (int, int, int) {
+	return dayToJalali(gregorianToDay(gy, int(gm), gd))
+}
+
+// ToGregorian converts a Jalali date to its Gregorian year, month and day.
+func ToGregorian(jy, jm, jd int) (int, time.Month, int) {
+	gy, gm, gd := dayToGregorian(jalaliToDay(jy, jm, jd))
+	return gy, time.Month(gm), gd
+}
+
+// FromTime returns the Jalali date of t in t's own time zone.
+func FromTime(t time.Time) (int, int, int) {
+	return FromGregorian(t.Date())
+}
+
+// Date returns the time at the given Jalali date and clock in loc.
+func Date(jy, jm, jd, hour, min, sec int, loc *time.Location) time.Time {
+	gy, gm, gd := ToGregorian(jy, jm, jd)
+	return time.Date(gy, gm, gd, hour, min, sec, 0, loc)
+}
+
+func IsLeap(jy int) bool {
+	leap, _, _ := jalCal(jy)
+	return leap == 0
+}
+
+func MonthLength(jy, jm int) int {
+	switch {
+	case jm <= 6:
+		return 31
+	case jm <= 11:
+		return 30
+	case IsLeap(jy):
+		return 30
+	default:
+		return 29
+	}
+}
+
+// Valid reports whether the given Jalali date exists.
+func Valid(jy, jm, jd int) bool {
+	if jy < breaks[0] || jy >= breaks[len(breaks)-1] {
+		return false
+	}
+
+	if jm < 1 || jm > 12 {
+		return false
+	}
+
+	return jd >= 1 && jd <= MonthLength(jy, jm)
+}
+
+// Format renders the Jalali date of t as yyyy/mm/dd.
+func Format(t time.Time) string {
+	jy, jm, jd := FromTime(t)
+	return fmt.Sprintf("%04d/%02d/%02d", jy, jm, jd)
+}
+
+// Parse reads a Jalali date written as yyyy/mm/dd or yyyy-mm-dd, with Latin,
+// Persian or Arabic-Indic digits.
+func Parse(s string) (int, int, int, error) {
+	s = NormalizeDigits(strings.TrimSpace(s))
+
+	var jy, jm, jd int
+	var sep1, sep2 rune
+	if _, err := fmt.Sscanf(s, "%d%c%d%c%d", &jy, &sep1, &jm, &sep2, &jd); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid jalali date: %s", s)
+	}
+
+	if sep1 != sep2 || (sep1 != '/' && sep1 != '-') {
+		return 0, 0, 0, fmt.Errorf("invalid jalali date: %s", s)
+	}
+
+	if !Valid(jy, jm, jd) {
+		return 0, 0, 0, fmt.Errorf("jalali date does not exist: %s", s)
+	}
+
+	return jy, jm, jd, nil
+}
+
+// NormalizeDigits replaces Persian and Arabic-Indic digits with Latin ones.
+func NormalizeDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		default:
+			return r
+		}
+	}, s)
+}
+
+// jalCal returns the leap state of jy (0 for a leap year), the Gregorian year
+// in which jy starts and the day of March on which Farvardin 1st falls.
+func jalCal(jy int) (int, int, int) {
+	gy := jy + 621
+	leapJ := -14
+	jp := breaks[0]
+
+	jump := 0
+	for i := 1; i < len(breaks); i++ {
+		jm := breaks[i]
+		jump = jm - jp
+		if jy < jm {
+			break
+		}
+		leapJ = leapJ + jump/33*8 + jump%33/4
+		jp = jm
+	}
+
+	n := jy - jp
+	leapJ = leapJ + n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march := 20 + leapJ - leapG
+
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+
+	leap := ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+
+	return leap, gy, march
+}
+
+// jalaliToDay and the functions below convert to and from Julian day numbers.
+func jalaliToDay(jy, jm, jd int) int {
+	_, gy, march := jalCal(jy)
+	return gregorianToDay(gy, 3, march) + (jm-1)*31 - jm/7*(jm-7) + jd - 1
+}
+
+func dayToJalali(jdn int) (int, int, int) {
+	gy, _, _ := dayToGregorian(jdn)
+	jy := gy - 621
+	leap, _, march := jalCal(jy)
+
+	k := jdn - gregorianToDay(gy, 3, march)
+	if k >= 0 {
+		if k <= 185 {
+			return jy, 1 + k/31, k%31 + 1
+		}
+		k -= 186
+	} else {
+		jy--
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	}
+
+	return jy, 7 + k/30, k%30 + 1
+}
+
+func gregorianToDay(gy, gm, gd int) int {
+	d := (gy+(gm-8)/6+100100)*1461/4 + (153*((gm+9)%12)+2)/5 + gd - 34840408
+	return d - (gy+100100+(gm-8)/6)/100*3/4 + 752
+}
+
+func dayToGregorian(jdn int) (int, int, int) {
+	j := 4*jdn + 139361631
+	j = j + (4*jdn+183187720)/146097*3/4*4 - 3908
+	i := j%1461/4*5 + 308
+	gd := i%153/5 + 1
+	gm := i/153%12 + 1
+	gy := j/1461 - 100100 + (8-gm)/6
+
+	return gy, gm, gd
+}
@@ -0,0 +1,74 @@
+package jalali
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConversion(t *testing.T) {
+	tests := []struct {
+		gy, gd     int
+		gm         time.Month
+		jy, jm, jd int
+	}{
+		{gy: 2024, gm: time.March, gd: 20, jy: 1403, jm: 1, jd: 1},
+		{gy: 2021, gm: time.March, gd: 20, jy: 1399, jm: 12, jd: 30},
+		{gy: 2026, gm: time.March, gd: 21, jy: 1405, jm: 1, jd: 1},
+		{gy: 2026, gm: time.October, gd: 19, jy: 1405, jm: 7, jd: 27},
+		{gy: 1979, gm: time.February, gd: 11, jy: 1357, jm: 11, jd: 22},
+		{gy: 2000, gm: time.February, gd: 29, jy: 1378, jm: 12, jd: 10},
+	}
+
+	for _, tc := range tests {
+		jy, jm, jd := FromGregorian(tc.gy, tc.gm, tc.gd)
+		if jy != tc.jy || jm != tc.jm || jd != tc.jd {
+			t.Errorf("FromGregorian(%d-%d-%d): got %d/%d/%d, want %d/%d/%d", tc.gy, tc.gm, tc.gd, jy, jm, jd, tc.jy, tc.jm, tc.jd)
+		}
+
+		gy, gm, gd := ToGregorian(tc.jy, tc.jm, tc.jd)
+		if gy != tc.gy || gm != tc.gm || gd != tc.gd {
+			t.Errorf("ToGregorian(%d/%d/%d): got %d-%d-%d, want %d-%d-%d", tc.jy, tc.jm, tc.jd, gy, gm, gd, tc.gy, tc.gm, tc.gd)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	day := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 365*60; i++ {
+		d := day.AddDate(0, 0, i)
+
+		jy, jm, jd := FromTime(d)
+		if !Valid(jy, jm, jd) {
+			t.Fatalf("%s converted to invalid jalali date %d/%d/%d", d.Format("2006-01-02"), jy, jm, jd)
+		}
+
+		if back := Date(jy, jm, jd, 0, 0, 0, time.UTC); !back.Equal(d) {
+			t.Fatalf("%s round tripped to %s", d.Format("2006-01-02"), back.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestIsLeap(t *testing.T) {
+	for jy, expected := range map[int]bool{1399: true, 1400: false, 1403: true, 1404: false, 1408: true} {
+		if IsLeap(jy) != expected {
+			t.Errorf("IsLeap(%d): got %t, want %t", jy, !expected, expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	jy, jm, jd, err := Parse("۱۴۰۵/۰۸/۱۰")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if jy != 1405 || jm != 8 || jd != 10 {
+		t.Errorf("got %d/%d/%d, want 1405/8/10", jy, jm, jd)
+	}
+
+	for _, input := range []string{"1404/12/30", "1405/13/01", "1405-08/10", "soon"} {
+		if _, _, _, err := Parse(input); err == nil {
+			t.Errorf("Parse should fail for %q", input)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+// Package query parses filter expressions such as
+//
+//	status:open due<2026-11-01 (tag:work OR tag:urgent) -tag:someday title~"deploy"
+//
+// into a syntax tree. Terms next to each other must all hold, OR between
+// terms needs one of them to, a leading - negates a term and parentheses
+// group. A term is either a predicate, a field name followed by an operator
+// and a value, or bare text. Values with spaces are written in double
+// quotes. What fields and values mean is up to the caller.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Operators a predicate can use.
+const (
+	Equal        = ":"
+	NotEqual     = "!="
+	Less         = "<"
+	LessEqual    = "<="
+	Greater      = ">"
+	GreaterEqual = ">="
+	Contains     = "~"
+)
+
+// operators are tried in order, so longer ones come before their prefixes.
+var operators = []string{NotEqual, LessEqual, GreaterEqual, Equal, "=", Less, Greater, Contains}
+
+// Expr is a node of the syntax tree: And, Or, Not, Predicate or Text.
+type Expr interface {
+	// Pos is the byte offset the node starts at in the input.
+	Pos() int
+}
+
+type And struct {
+	Terms []Expr
+}
+
+type Or struct {
+	Terms []Expr
+}
+
+type Not struct {
+	Term   Expr
+	Offset int
+}
+
+// Predicate compares a field with a value. "=" is read as Equal.
+type Predicate struct {
+	Field    string
+	Operator string
+	Value    string
+	Offset   int
+	// ValueOffset is where the value starts, for errors about the value.
+	ValueOffset int
+}
+
+// Text is a bare word or quoted phrase.
+type Text struct {
+	Value  string
+	Offset int
+}
+
+func (e And) Pos() int       { return e.Terms[0].Pos() }
+func (e Or) Pos() int        { return e.Terms[0].Pos() }
+func (e Not) Pos() int       { return e.Offset }
+func (e Predicate) Pos() int { return e.Offset }
+func (e Text) Pos() int      { return e.Offset }
+
+// Error is a problem with a query, found at a byte offset of the input.
+type Error struct {
+	Input  string
+	Offset int
+	Msg    string
+}
+
+// Column is the 1-based position of the error in characters.
+func (e *Error) Column() int {
+	offset := e.Offset
+	if offset > len(e.Input) {
+		offset = len(e.Input)
+	}
+
+	return utf8.RuneCountInString(e.Input[:offset]) + 1
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query error at column %d: %s", e.Column(), e.Msg)
+}
+
+// Errorf reports a problem with part of a query starting at offset.
+func Errorf(input string, offset int, format string, args ...interface{}) *Error {
+	return &Error{Input: input, Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parse builds the syntax tree of input. An input without any terms gives a
+// nil Expr.
+func Parse(input string) (Expr, error) {
+	p := &parser{input: input}
+
+	p.next()
+	if p.tok.kind == eof {
+		return nil, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != eof {
+		return nil, Errorf(input, p.tok.offset, "unexpected %s", p.tok)
+	}
+
+	return expr, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+	tok   token
+	err   *Error
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	var terms []Expr
+
+	for {
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+
+		if p.tok.kind != orKeyword {
+			break
+		}
+		p.next()
+	}
+
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+
+	return Or{Terms: terms}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	var terms []Expr
+
+	for {
+		if p.tok.kind == andKeyword {
+			if len(terms) == 0 {
+				return nil, Errorf(p.input, p.tok.offset, "AND needs a term before it")
+			}
+			p.next()
+		}
+
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+
+		if k := p.tok.kind; k == eof || k == rightParen || k == orKeyword {
+			break
+		}
+	}
+
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+
+	return And{Terms: terms}, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch p.tok.kind {
+	case minus:
+		offset := p.tok.offset
+		p.next()
+
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return Not{Term: term, Offset: offset}, nil
+	case leftParen:
+		offset := p.tok.offset
+		p.next()
+		if p.tok.kind == rightParen {
+			return nil, Errorf(p.input, offset, "empty parentheses")
+		}
+
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.tok.kind != rightParen {
+			return nil, Errorf(p.input, offset, "unclosed parenthesis")
+		}
+		p.next()
+
+		return expr, nil
+	case predicate:
+		pred := p.tok.predicate
+		p.next()
+
+		return pred, nil
+	case text:
+		t := Text{Value: p.tok.value, Offset: p.tok.offset}
+		p.next()
+
+		return t, nil
+	case invalid:
+		return nil, p.err
+	default:
+		return nil, Errorf(p.input, p.tok.offset, "expected a term, found %s", p.tok)
+	}
+}
+
+type tokenKind int
+
+const (
+	eof tokenKind = iota
+	invalid
+	leftParen
+	rightParen
+	minus
+	orKeyword
+	andKeyword
+	predicate
+	text
+)
+
+type token struct {
+	kind      tokenKind
+	offset    int
+	value     string
+	predicate Predicate
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case eof:
+		return "end of query"
+	case leftParen:
+		return `"("`
+	case rightParen:
+		return `")"`
+	case minus:
+		return `"-"`
+	case orKeyword:
+		return "OR"
+	case andKeyword:
+		return "AND"
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+// next reads the token at p.pos into p.tok.
+func (p *parser) next() {
+	for p.pos < len(p.input) && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+
+	start := p.pos
+	if start == len(p.input) {
+		p.tok = token{kind: eof, offset: start}
+		return
+	}
+
+	switch p.input[start] {
+	case '(':
+		p.pos++
+		p.tok = token{kind: leftParen, offset: start}
+		return
+	case ')':
+		p.pos++
+		p.tok = token{kind: rightParen, offset: start}
+		return
+	case '-':
+		p.pos++
+		p.tok = token{kind: minus, offset: start}
+		return
+	case '"':
+		value, ok := p.quoted()
+		if !ok {
+			return
+		}
+		p.tok = token{kind: text, offset: start, value: value}
+		return
+	}
+
+	// a field name followed by an operator starts a predicate
+	field := p.pos
+	for field < len(p.input) && isFieldByte(p.input[field]) {
+		field++
+	}
+	if field > start {
+		for _, op := range operators {
+			if !strings.HasPrefix(p.input[field:], op) {
+				continue
+			}
+
+			p.pos = field + len(op)
+			p.predicate(start, p.input[start:field], op)
+
+			return
+		}
+	}
+
+	word := p.word()
+	switch word {
+	case "OR":
+		p.tok = token{kind: orKeyword, offset: start, value: word}
+	case "AND":
+		p.tok = token{kind: andKeyword, offset: start, value: word}
+	default:
+		p.tok = token{kind: text, offset: start, value: word}
+	}
+}
+
+func (p *parser) predicate(start int, field, op string) {
+	if op == "=" {
+		op = Equal
+	}
+
+	valueStart := p.pos
+	var value string
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		var ok bool
+		if value, ok = p.quoted(); !ok {
+			return
+		}
+	} else {
+		value = p.word()
+	}
+
+	if value == "" {
+		p.fail(valueStart, "missing value for %s%s", field, op)
+		return
+	}
+
+	p.tok = token{kind: predicate, offset: start, value: p.input[start:p.pos], predicate: Predicate{
+		Field:       strings.ToLower(field),
+		Operator:    op,
+		Value:       value,
+		Offset:      start,
+		ValueOffset: valueStart,
+	}}
+}
+
+// quoted reads a double quoted string at p.pos. A backslash escapes the
+// character after it.
+func (p *parser) quoted() (string, bool) {
+	start := p.pos
+	var value strings.Builder
+
+	for i := start + 1; i < len(p.input); i++ {
+		switch c := p.input[i]; {
+		case c == '\\' && i+1 < len(p.input):
+			i++
+			value.WriteByte(p.input[i])
+		case c == '"':
+			p.pos = i + 1
+			return value.String(), true
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	p.fail(start, "unclosed quote")
+
+	return "", false
+}
+
+// word reads up to the next space or parenthesis.
+func (p *parser) word() string {
+	start := p.pos
+	for p.pos < len(p.input) && !isSpace(p.input[p.pos]) && p.input[p.pos] != '(' && p.input[p.pos] != ')' {
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
+
+func (p *parser) fail(offset int, format string, args ...interface{}) {
+	p.err = Errorf(p.input, offset, format, args...)
+	p.tok = token{kind: invalid, offset: offset}
+	p.pos = len(p.input)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isFieldByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
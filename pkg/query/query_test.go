@@ -0,0 +1,84 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `status:open due<2026-11-01 (tag:work OR tag=urgent) -tag:someday title~"deploy app" docs`
+
+	got, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := And{Terms: []Expr{
+		Predicate{Field: "status", Operator: Equal, Value: "open", Offset: 0, ValueOffset: 7},
+		Predicate{Field: "due", Operator: Less, Value: "2026-11-01", Offset: 12, ValueOffset: 16},
+		Or{Terms: []Expr{
+			Predicate{Field: "tag", Operator: Equal, Value: "work", Offset: 28, ValueOffset: 32},
+			Predicate{Field: "tag", Operator: Equal, Value: "urgent", Offset: 40, ValueOffset: 44},
+		}},
+		Not{Term: Predicate{Field: "tag", Operator: Equal, Value: "someday", Offset: 53, ValueOffset: 57}, Offset: 52},
+		Predicate{Field: "title", Operator: Contains, Value: "deploy app", Offset: 65, ValueOffset: 71},
+		Text{Value: "docs", Offset: 84},
+	}}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got  %#v\nwant %#v", got, expected)
+	}
+}
+
+func TestParseOperators(t *testing.T) {
+	for input, op := range map[string]string{
+		"due:x": Equal, "due=x": Equal, "due!=x": NotEqual, "due<x": Less, "due<=x": LessEqual,
+		"due>x": Greater, "due>=x": GreaterEqual, "due~x": Contains,
+	} {
+		got, err := Parse(input)
+		if err != nil {
+			t.Errorf("%s: Parse failed: %v", input, err)
+			continue
+		}
+
+		if pred, ok := got.(Predicate); !ok || pred.Operator != op || pred.Value != "x" {
+			t.Errorf("%s: got %#v, want operator %s", input, got, op)
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	got, err := Parse("   ")
+	if err != nil || got != nil {
+		t.Errorf("an empty query should give no expression, got %#v, %v", got, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `title~"deploy`, expected: "query error at column 7: unclosed quote"},
+		{input: `(tag:work OR tag:home`, expected: "query error at column 1: unclosed parenthesis"},
+		{input: `tag:work)`, expected: `query error at column 9: unexpected ")"`},
+		{input: `status: open`, expected: "query error at column 8: missing value for status:"},
+		{input: `tag:work OR`, expected: "query error at column 12: expected a term, found end of query"},
+		{input: `AND tag:work`, expected: "query error at column 1: AND needs a term before it"},
+		{input: `()`, expected: "query error at column 1: empty parentheses"},
+		{input: `عنوان (`, expected: "query error at column 8: expected a term, found end of query"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := Parse(tc.input)
+			if err == nil {
+				t.Fatalf("Parse should fail")
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("got %q, want %q", err.Error(), tc.expected)
+			}
+		})
+	}
+}
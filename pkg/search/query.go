@@ -0,0 +1,52 @@
+package search
+
+import "strings"
+
+// Clause is a part of a query: a single term or a phrase of consecutive
+// terms. With Prefix set, the last term matches any word it begins.
+type Clause struct {
+	Terms  []string
+	Prefix bool
+}
+
+// Parse splits a query into clauses. Text in double quotes is a phrase, a
+// word ending in * is a prefix and a word that tokenizes into several terms,
+// like "e-mail", is matched as a phrase too.
+func Parse(query string) []Clause {
+	var clauses []Clause
+
+	add := func(text string) {
+		if terms := Tokenize(text); len(terms) > 0 {
+			clauses = append(clauses, Clause{Terms: terms, Prefix: strings.HasSuffix(text, "*")})
+		}
+	}
+
+	for {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			return clauses
+		}
+
+		if query[0] == '"' {
+			end := strings.IndexByte(query[1:], '"')
+			if end < 0 {
+				// an unclosed quote runs to the end of the query
+				add(query[1:])
+				return clauses
+			}
+
+			add(query[1 : end+1])
+			query = query[end+2:]
+
+			continue
+		}
+
+		end := strings.IndexAny(query, " \t\n\"")
+		if end < 0 {
+			end = len(query)
+		}
+
+		add(query[:end])
+		query = query[end:]
+	}
+}
@@ -0,0 +1,239 @@
+// Package search is an in-memory inverted index over short documents made of
+// weighted fields. It answers queries of words, "quoted phrases" and word
+// prefixes ending in *, ranking the documents that match all of them with
+// BM25.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BM25 parameters, the usual defaults.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Field is a part of a document. Matches in fields with a higher weight
+// count for more.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+type span struct {
+	start, end int
+	weight     float64
+}
+
+type document struct {
+	terms  []string
+	length int
+	spans  []span
+}
+
+// weightAt returns the weight of the field a position falls in.
+func (d document) weightAt(position int) float64 {
+	for _, s := range d.spans {
+		if position >= s.start && position < s.end {
+			return s.weight
+		}
+	}
+
+	return 0
+}
+
+// Index is safe for concurrent use.
+type Index struct {
+	mu sync.RWMutex
+	// postings maps a term to the positions it has in every document.
+	postings    map[string]map[int][]int
+	documents   map[int]document
+	totalLength int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings:  map[string]map[int][]int{},
+		documents: map[int]document{},
+	}
+}
+
+// Add indexes a document, replacing what was indexed under its ID before.
+func (ix *Index) Add(id int, fields ...Field) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+
+	var doc document
+	position := 0
+	for _, field := range fields {
+		tokens := Tokenize(field.Text)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		doc.spans = append(doc.spans, span{start: position, end: position + len(tokens), weight: field.Weight})
+		for _, token := range tokens {
+			if ix.postings[token] == nil {
+				ix.postings[token] = map[int][]int{}
+			}
+			if len(ix.postings[token][id]) == 0 {
+				doc.terms = append(doc.terms, token)
+			}
+			ix.postings[token][id] = append(ix.postings[token][id], position)
+			position++
+		}
+
+		doc.length += len(tokens)
+		// leave a hole so phrases can't run from one field into the next
+		position++
+	}
+
+	ix.documents[id] = doc
+	ix.totalLength += doc.length
+}
+
+// Remove drops a document from the index.
+func (ix *Index) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id int) {
+	doc, ok := ix.documents[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+
+	ix.totalLength -= doc.length
+	delete(ix.documents, id)
+}
+
+// Hit is a document matching a query.
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Search returns the documents that match every part of query and are
+// accepted by keep, best first. A nil keep accepts every document.
+func (ix *Index) Search(query string, keep func(id int) bool) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	clauses := Parse(query)
+	if len(clauses) == 0 || len(ix.documents) == 0 {
+		return nil
+	}
+
+	scores := map[int]float64{}
+	averageLength := float64(ix.totalLength) / float64(len(ix.documents))
+
+	for i, c := range clauses {
+		matches := ix.match(c)
+
+		n := float64(len(matches))
+		idf := math.Log(1 + (float64(len(ix.documents))-n+0.5)/(n+0.5))
+
+		next := map[int]float64{}
+		for id, starts := range matches {
+			if _, ok := scores[id]; i > 0 && !ok {
+				continue
+			}
+			if keep != nil && !keep(id) {
+				continue
+			}
+
+			doc := ix.documents[id]
+			tf := 0.0
+			for _, start := range starts {
+				tf += doc.weightAt(start)
+			}
+
+			norm := k1 * (1 - b + b*float64(doc.length)/averageLength)
+			next[id] = scores[id] + idf*tf*(k1+1)/(tf+norm)
+		}
+
+		scores = next
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		return hits[i].ID < hits[j].ID
+	})
+
+	return hits
+}
+
+// match returns the positions where a clause starts in every document it
+// occurs in.
+func (ix *Index) match(c Clause) map[int][]int {
+	last := len(c.Terms) - 1
+	starts := ix.occurrences(c.Terms[0], c.Prefix && last == 0)
+
+	for offset := 1; offset <= last; offset++ {
+		next := ix.occurrences(c.Terms[offset], c.Prefix && offset == last)
+
+		matched := map[int][]int{}
+		for id, positions := range starts {
+			at := map[int]bool{}
+			for _, p := range next[id] {
+				at[p] = true
+			}
+
+			for _, p := range positions {
+				if at[p+offset] {
+					matched[id] = append(matched[id], p)
+				}
+			}
+		}
+
+		starts = matched
+	}
+
+	return starts
+}
+
+func (ix *Index) occurrences(term string, prefix bool) map[int][]int {
+	if !prefix {
+		return ix.postings[term]
+	}
+
+	merged := map[int][]int{}
+	for t, documents := range ix.postings {
+		if !strings.HasPrefix(t, term) {
+			continue
+		}
+
+		for id, positions := range documents {
+			merged[id] = append(merged[id], positions...)
+		}
+	}
+
+	return merged
+}
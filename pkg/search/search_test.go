@@ -0,0 +1,102 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "latin", text: "Buy milk, EGGS & bread!", expected: []string{"buy", "milk", "eggs", "bread"}},
+		{name: "tags", text: "@home #someday", expected: []string{"home", "someday"}},
+		{name: "accents", text: "Café crème", expected: []string{"café", "crème"}},
+		{name: "persian", text: "خرید شیر و نان", expected: []string{"خرید", "شیر", "و", "نان"}},
+		{name: "arabic yeh and kaf", text: "كتاب علي", expected: []string{"کتاب", "علی"}},
+		{name: "zero width non-joiner", text: "می‌روم", expected: []string{"میروم"}},
+		{name: "diacritics", text: "کِتاب", expected: []string{"کتاب"}},
+		{name: "persian digits", text: "۱۴۰۵/۰۸", expected: []string{"1405", "08"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Tokenize(tc.text); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	got := Parse(`milk "pay the rent" groc* e-mail "buy bre*`)
+	expected := []Clause{
+		{Terms: []string{"milk"}},
+		{Terms: []string{"pay", "the", "rent"}},
+		{Terms: []string{"groc"}, Prefix: true},
+		{Terms: []string{"e", "mail"}},
+		{Terms: []string{"buy", "bre"}, Prefix: true},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v, want %+v", got, expected)
+	}
+}
+
+func ids(hits []Hit) []int {
+	var result []int
+	for _, hit := range hits {
+		result = append(result, hit.ID)
+	}
+
+	return result
+}
+
+func TestSearch(t *testing.T) {
+	ix := NewIndex()
+	ix.Add(1, Field{Text: "Buy milk", Weight: 3}, Field{Text: "from the corner shop", Weight: 1})
+	ix.Add(2, Field{Text: "Pay the rent", Weight: 3}, Field{Text: "ask about the milk bill", Weight: 1})
+	ix.Add(3, Field{Text: "Buy groceries", Weight: 3}, Field{Text: "milk, eggs and bread", Weight: 1})
+	ix.Add(4, Field{Text: "خرید نان", Weight: 3}, Field{Text: "نانوایی سر کوچه", Weight: 1})
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []int
+	}{
+		{name: "title matches rank first", query: "milk", expected: []int{1, 3, 2}},
+		{name: "all terms have to match", query: "buy milk", expected: []int{1, 3}},
+		{name: "phrase", query: `"buy milk"`, expected: []int{1}},
+		{name: "phrases stay inside a field", query: `"milk from"`, expected: nil},
+		{name: "prefix", query: "groc*", expected: []int{3}},
+		{name: "phrase ending in a prefix", query: `"the ren*"`, expected: []int{2}},
+		{name: "persian", query: "نان", expected: []int{4}},
+		{name: "persian prefix", query: "نان*", expected: []int{4}},
+		{name: "no match", query: "tea", expected: nil},
+		{name: "empty", query: `"" *`, expected: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ids(ix.Search(tc.query, nil)); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %v, want %v", got, tc.expected)
+			}
+		})
+	}
+
+	if got := ids(ix.Search("milk", func(id int) bool { return id != 1 })); !reflect.DeepEqual(got, []int{3, 2}) {
+		t.Errorf("keep should filter hits, got %v", got)
+	}
+
+	ix.Add(1, Field{Text: "Buy tea", Weight: 3})
+	ix.Remove(3)
+
+	if got := ids(ix.Search("milk", nil)); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("re-added and removed documents should no longer match, got %v", got)
+	}
+	if got := ids(ix.Search("tea", nil)); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("re-added documents should match their new text, got %v", got)
+	}
+}
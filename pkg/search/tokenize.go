@@ -0,0 +1,61 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits text into the words the index is built from. Words are
+// runs of letters and digits, lower cased and normalized so that the
+// different ways Persian text is commonly typed compare equal.
+func Tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case ignored(r):
+			// doesn't break a word
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(normalize(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// ignored reports runes that are dropped from inside words: diacritics, the
+// tatweel used to stretch Arabic script and the zero width (non-)joiners
+// Persian places between the parts of a word.
+func ignored(r rune) bool {
+	return unicode.Is(unicode.Mn, r) || r == 'ـ' || r == '‌' || r == '‍'
+}
+
+func normalize(r rune) rune {
+	switch {
+	case r == 'ي' || r == 'ى':
+		return 'ی'
+	case r == 'ك':
+		return 'ک'
+	case r == 'ۀ' || r == 'ة':
+		return 'ه'
+	case r == 'أ' || r == 'إ' || r == 'ٱ':
+		return 'ا'
+	case r >= '۰' && r <= '۹':
+		return '0' + (r - '۰')
+	case r >= '٠' && r <= '٩':
+		return '0' + (r - '٠')
+	}
+
+	return unicode.ToLower(r)
+}
@@ -0,0 +1,237 @@
+package attachment
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// nothing was attached yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) AttachmentDeserializer(pData []string) []models.Attachment {
+	var attachments []models.Attachment
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			attachment, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			attachments = append(attachments, attachment)
+		case consts.JsonSerializationMode:
+			attachment, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	return attachments
+}
+
+func TextDeserializer(attachmentStr string) (models.Attachment, error) {
+	fields, ok := textrecord.Fields(attachmentStr)
+	if !ok {
+		return models.Attachment{}, fmt.Errorf("invalid attachment string: %s", attachmentStr)
+	}
+
+	for _, key := range []string{"id", "taskID", "userID", "name", "size", "hash", "createdAt"} {
+		if _, ok := fields[key]; !ok {
+			return models.Attachment{}, fmt.Errorf("invalid attachment string: %s", attachmentStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	taskID, err := strconv.Atoi(fields["taskID"])
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("invalid taskID: %s", fields["taskID"])
+	}
+
+	userID, err := strconv.Atoi(fields["userID"])
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("invalid userID: %s", fields["userID"])
+	}
+
+	size, err := strconv.ParseInt(fields["size"], 10, 64)
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("invalid size: %s", fields["size"])
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, fields["createdAt"])
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("invalid createdAt: %s", fields["createdAt"])
+	}
+
+	return models.Attachment{
+		ID:        id,
+		TaskID:    taskID,
+		UserID:    userID,
+		Name:      fields["name"],
+		Size:      size,
+		Hash:      fields["hash"],
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func JsonDeserializer(attachmentStr string) (models.Attachment, error) {
+	var attachment models.Attachment
+
+	err := json.Unmarshal([]byte(attachmentStr), &attachment)
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("invalid json: %s", attachmentStr)
+	}
+
+	return attachment, nil
+}
+
+func (f FileStore) serializeAttachment(attachment models.Attachment) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("id: %d, taskID: %d, userID: %d, name: %s, size: %d, hash: %s, createdAt: %s\n",
+			attachment.ID, attachment.TaskID, attachment.UserID, textrecord.Escape(attachment.Name), attachment.Size,
+			attachment.Hash, attachment.CreatedAt.Format(time.RFC3339))
+
+		return []byte(line), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(attachment)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal attachment struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeAttachmentToFile(attachment models.Attachment) error {
+	file, err := os.OpenFile(f.Filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("can't create or open file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := f.serializeAttachment(attachment)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+// writeAttachmentsToFile replaces the whole file with the given attachments.
+func (f FileStore) writeAttachmentsToFile(attachments []models.Attachment) error {
+	var data []byte
+	for _, attachment := range attachments {
+		line, err := f.serializeAttachment(attachment)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) CreateNewAttachment(attachment models.Attachment) (models.Attachment, error) {
+	attachments, err := f.ListAttachments()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	attachment.ID = 1
+	if len(attachments) > 0 {
+		attachment.ID = attachments[len(attachments)-1].ID + 1
+	}
+
+	if err := f.writeAttachmentToFile(attachment); err != nil {
+		return models.Attachment{}, fmt.Errorf("can't write attachment to file: %v", err)
+	}
+
+	return attachment, nil
+}
+
+// ListAttachments returns the attachments of all users, which is what
+// telling whether a blob is still referenced needs.
+func (f FileStore) ListAttachments() ([]models.Attachment, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.AttachmentDeserializer(lines), nil
+}
+
+func (f FileStore) DeleteAttachment(id int) error {
+	attachments, err := f.ListAttachments()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.Attachment
+	for _, attachment := range attachments {
+		if attachment.ID != id {
+			kept = append(kept, attachment)
+		}
+	}
+
+	if len(kept) == len(attachments) {
+		return fmt.Errorf("attachment %d not found", id)
+	}
+
+	if err := f.writeAttachmentsToFile(kept); err != nil {
+		return fmt.Errorf("can't write attachments to file: %v", err)
+	}
+
+	return nil
+}
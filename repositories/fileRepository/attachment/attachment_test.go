@@ -0,0 +1,61 @@
+package attachment
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestAttachmentStore(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "attachment.txt"), mode)
+
+			attachments, err := fs.ListAttachments()
+			if err != nil || len(attachments) != 0 {
+				t.Fatalf("a missing file should hold no attachments: got %v, %v", attachments, err)
+			}
+
+			for _, name := range []string{"screenshot, final.png", "server.log"} {
+				if _, err := fs.CreateNewAttachment(models.Attachment{
+					TaskID: 2, UserID: 3, Name: name, Size: 42, Hash: "ab12", CreatedAt: createdAt,
+				}); err != nil {
+					t.Fatalf("CreateNewAttachment failed: %v", err)
+				}
+			}
+
+			if err := fs.DeleteAttachment(1); err != nil {
+				t.Fatalf("DeleteAttachment failed: %v", err)
+			}
+
+			expected := []models.Attachment{
+				{ID: 2, TaskID: 2, UserID: 3, Name: "server.log", Size: 42, Hash: "ab12", CreatedAt: createdAt},
+			}
+
+			attachments, err = fs.ListAttachments()
+			if err != nil {
+				t.Fatalf("ListAttachments failed: %v", err)
+			}
+			if !reflect.DeepEqual(attachments, expected) {
+				t.Errorf("attachments do not match: got %v, want %v", attachments, expected)
+			}
+
+			if _, err := fs.CreateNewAttachment(models.Attachment{Name: "next", CreatedAt: createdAt}); err != nil {
+				t.Fatalf("CreateNewAttachment failed: %v", err)
+			}
+			attachments, _ = fs.ListAttachments()
+			if attachments[len(attachments)-1].ID != 3 {
+				t.Errorf("IDs should keep counting after a deletion: got %v", attachments)
+			}
+
+			if err := fs.DeleteAttachment(9); err == nil {
+				t.Errorf("DeleteAttachment should fail for a missing attachment")
+			}
+		})
+	}
+}
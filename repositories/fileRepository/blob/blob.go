@@ -0,0 +1,139 @@
+// Package blob stores file contents under a directory, keyed by the hex
+// encoded SHA-256 hash of the content. Storing the same content twice keeps a
+// single copy.
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// uploadDir holds contents while they're written, before their hash is known.
+const uploadDir = "tmp"
+
+type Store struct {
+	Dir string
+}
+
+func New(dir string) Store {
+	return Store{Dir: dir}
+}
+
+// Put stores the content read from r and returns its hash and size.
+func (s Store) Put(r io.Reader) (string, int64, error) {
+	tmpDir := filepath.Join(s.Dir, uploadDir)
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return "", 0, fmt.Errorf("can't create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(tmpDir, "upload-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("can't create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, cErr := io.Copy(io.MultiWriter(tmp, h), r)
+	if clErr := tmp.Close(); cErr == nil {
+		cErr = clErr
+	}
+	if cErr != nil {
+		return "", 0, fmt.Errorf("can't store blob: %w", cErr)
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	path := s.path(hash)
+
+	if _, err := os.Stat(path); err == nil {
+		return hash, size, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, fmt.Errorf("can't create blob directory: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("can't store blob: %w", err)
+	}
+
+	return hash, size, nil
+}
+
+func (s Store) Open(hash string) (io.ReadCloser, error) {
+	if !validHash(hash) {
+		return nil, fmt.Errorf("invalid blob hash: %q", hash)
+	}
+
+	file, err := os.Open(s.path(hash))
+	if err != nil {
+		return nil, fmt.Errorf("can't open blob: %w", err)
+	}
+
+	return file, nil
+}
+
+func (s Store) Delete(hash string) error {
+	if !validHash(hash) {
+		return fmt.Errorf("invalid blob hash: %q", hash)
+	}
+
+	if err := os.Remove(s.path(hash)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't delete blob: %w", err)
+	}
+
+	// the directory is only removed when it's empty
+	os.Remove(filepath.Dir(s.path(hash)))
+
+	return nil
+}
+
+// List returns the hashes of all stored blobs.
+func (s Store) List() ([]string, error) {
+	dirs, err := os.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read blob directory: %w", err)
+	}
+
+	var hashes []string
+	for _, dir := range dirs {
+		if !dir.IsDir() || dir.Name() == uploadDir {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(s.Dir, dir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("can't read blob directory: %w", err)
+		}
+
+		for _, file := range files {
+			if hash := dir.Name() + file.Name(); validHash(hash) {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
+// path spreads blobs over subdirectories named after the first byte of
+// their hash, keeping directories small.
+func (s Store) path(hash string) string {
+	return filepath.Join(s.Dir, hash[:2], hash[2:])
+}
+
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(hash)
+
+	return err == nil
+}
@@ -0,0 +1,65 @@
+package blob
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	s := New(t.TempDir())
+
+	hash, size, err := s.Put(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	const expectedHash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if hash != expectedHash || size != 5 {
+		t.Errorf("got hash %s and size %d, want %s and 5", hash, size, expectedHash)
+	}
+
+	if again, _, err := s.Put(strings.NewReader("hello")); err != nil || again != hash {
+		t.Errorf("storing the same content again should give the same hash: got %s, %v", again, err)
+	}
+
+	other, _, err := s.Put(strings.NewReader("world"))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	hashes, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(hashes) != 2 {
+		t.Errorf("expected 2 blobs, got %v", hashes)
+	}
+
+	r, err := s.Open(hash)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	content, _ := io.ReadAll(r)
+	r.Close()
+	if string(content) != "hello" {
+		t.Errorf("content does not match: got %q", content)
+	}
+
+	if err := s.Delete(hash); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	hashes, err = s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(hashes, []string{other}) {
+		t.Errorf("expected only %s to be left, got %v", other, hashes)
+	}
+
+	if _, err := s.Open("../../etc/passwd"); err == nil {
+		t.Errorf("Open should reject an invalid hash")
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	"todo-cli-refactor/consts"
 	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
 )
 
 type FileStore struct {
@@ -30,6 +32,10 @@ func (f FileStore) Load() ([]string, error) {
 	var pData []string
 
 	file, oErr := os.Open(f.Filepath)
+	if os.IsNotExist(oErr) {
+		// nothing was stored yet
+		return nil, nil
+	}
 	if oErr != nil {
 		err = oErr
 	}
@@ -71,15 +77,20 @@ func (f FileStore) CategoryDeserializer(pData []string) []models.Category {
 func TextDeserializer(categoryStr string) (models.Category, error) {
 
 	categoryStr = strings.TrimRight(categoryStr, "\n")
-	fields := strings.Split(categoryStr, ",")
 
-	if len(fields) != 4 {
+	fields, ok := textrecord.Fields(categoryStr)
+	if !ok {
 		return models.Category{}, fmt.Errorf("invalid category string: %s", categoryStr)
 	}
-	idStr := fields[0][4:]
-	title := fields[1][8:]
-	color := fields[2][8:]
-	userIDStr := fields[3][9:]
+
+	for _, key := range []string{"id", "title", "color", "userID"} {
+		if _, ok := fields[key]; !ok {
+			return models.Category{}, fmt.Errorf("invalid category string: %s", categoryStr)
+		}
+	}
+
+	idStr := fields["id"]
+	userIDStr := fields["userID"]
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -93,11 +104,38 @@ func TextDeserializer(categoryStr string) (models.Category, error) {
 
 	category := models.Category{
 		ID:     id,
-		Title:  title,
-		Color:  color,
+		Title:  fields["title"],
+		Color:  fields["color"],
 		UserID: userID,
 	}
 
+	if parentIDStr, ok := fields["parentID"]; ok {
+		category.ParentID, err = strconv.Atoi(parentIDStr)
+		if err != nil {
+			return models.Category{}, fmt.Errorf("invalid parentID: %s", parentIDStr)
+		}
+	}
+
+	if fieldDefs, ok := fields["fields"]; ok {
+		if err := json.Unmarshal([]byte(fieldDefs), &category.Fields); err != nil {
+			return models.Category{}, fmt.Errorf("invalid fields: %s", fieldDefs)
+		}
+	}
+
+	if shares, ok := fields["shares"]; ok {
+		if err := json.Unmarshal([]byte(shares), &category.Shares); err != nil {
+			return models.Category{}, fmt.Errorf("invalid shares: %s", shares)
+		}
+	}
+
+	if deletedAtStr, ok := fields["deletedAt"]; ok {
+		deletedAt, err := time.Parse(time.RFC3339, deletedAtStr)
+		if err != nil {
+			return models.Category{}, fmt.Errorf("invalid deletedAt: %s", deletedAtStr)
+		}
+		category.DeletedAt = &deletedAt
+	}
+
 	return category, nil
 }
 
@@ -119,27 +157,74 @@ func (f FileStore) writeCategoryToFile(category models.Category) error {
 	}
 	defer file.Close()
 
-	var data []byte
+	data, err := f.serializeCategory(category)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.WriteString(file, string(data))
+	if err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	fmt.Println("numberOfWrittenBytes", n)
+
+	return nil
+}
+
+func (f FileStore) serializeCategory(category models.Category) ([]byte, error) {
 	switch f.serializationMode {
 	case consts.TextSerializationMode:
-		data = []byte(fmt.Sprintf("id: %d, title: %s, color: %s, userID: %d\n", category.ID, category.Title,
-			category.Color, category.UserID))
+		line := fmt.Sprintf("id: %d, title: %s, color: %s, userID: %d", category.ID, textrecord.Escape(category.Title),
+			textrecord.Escape(category.Color), category.UserID)
+		if category.ParentID != 0 {
+			line += fmt.Sprintf(", parentID: %d", category.ParentID)
+		}
+		if len(category.Fields) > 0 {
+			fieldDefs, err := json.Marshal(category.Fields)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal fields to json: %w", err)
+			}
+			line += ", fields: " + textrecord.Escape(string(fieldDefs))
+		}
+		if len(category.Shares) > 0 {
+			shares, err := json.Marshal(category.Shares)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal shares to json: %w", err)
+			}
+			line += ", shares: " + textrecord.Escape(string(shares))
+		}
+		if category.DeletedAt != nil {
+			line += ", deletedAt: " + category.DeletedAt.Format(time.RFC3339)
+		}
+
+		return []byte(line + "\n"), nil
 	case consts.JsonSerializationMode:
-		data, err = json.Marshal(category)
+		data, err := json.Marshal(category)
 		if err != nil {
-			return fmt.Errorf("can't marshal category struct to json: %w", err)
+			return nil, fmt.Errorf("can't marshal category struct to json: %w", err)
 		}
-		data = append(data, '\n')
+
+		return append(data, '\n'), nil
 	default:
-		return fmt.Errorf("invalid serialization mode")
+		return nil, fmt.Errorf("invalid serialization mode")
 	}
+}
 
-	n, err := io.WriteString(file, string(data))
-	if err != nil {
-		return fmt.Errorf("can't write to the file: %w", err)
+// writeCategoriesToFile replaces the whole file with the given categories.
+func (f FileStore) writeCategoriesToFile(categories []models.Category) error {
+	var data []byte
+	for _, category := range categories {
+		line, err := f.serializeCategory(category)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
 	}
 
-	fmt.Println("numberOfWrittenBytes", n)
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
 
 	return nil
 }
@@ -160,23 +245,117 @@ func (f FileStore) CreateNewCategory(category models.Category) (models.Category,
 	return category, nil
 }
 
+// generateID returns one past the highest stored ID. Categories brought back
+// by InsertCategory are appended out of order, so the last line isn't enough.
 func (f FileStore) generateID() (int, error) {
+	categories, err := f.ListCategories()
+	if err != nil {
+		return 0, fmt.Errorf("files can't load for counting lines: %w", err)
+	}
+
+	maxID := 0
+	for _, category := range categories {
+		if category.ID > maxID {
+			maxID = category.ID
+		}
+	}
+
+	return maxID + 1, nil
+}
+
+// InsertCategory stores a category under the ID it already has, e.g. one that
+// is brought back after being removed.
+func (f FileStore) InsertCategory(category models.Category) (models.Category, error) {
+	categories, err := f.ListCategories()
+	if err != nil {
+		return models.Category{}, err
+	}
 
+	for _, stored := range categories {
+		if stored.ID == category.ID {
+			return models.Category{}, fmt.Errorf("category %d already exists", category.ID)
+		}
+	}
+
+	if err := f.writeCategoryToFile(category); err != nil {
+		return models.Category{}, fmt.Errorf("can't write category to file: %v", err)
+	}
+
+	return category, nil
+}
+
+// ListCategories returns the categories of all users.
+func (f FileStore) ListCategories() ([]models.Category, error) {
 	lines, err := f.Load()
 	if err != nil {
-		return 0, fmt.Errorf("files can't load for counting lines: %w", err)
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.CategoryDeserializer(lines), nil
+}
+
+func (f FileStore) ListUserCategories(userID int) ([]models.Category, error) {
+	categories, err := f.ListCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	var userCategories []models.Category
+	for _, category := range categories {
+		if category.UserID == userID {
+			userCategories = append(userCategories, category)
+		}
+	}
+
+	return userCategories, nil
+}
+
+func (f FileStore) UpdateCategory(category models.Category) (models.Category, error) {
+	categories, err := f.ListCategories()
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	found := false
+	for i := range categories {
+		if categories[i].ID == category.ID {
+			categories[i] = category
+			found = true
+		}
 	}
 
-	if len(lines) == 0 {
-		return 1, nil
+	if !found {
+		return models.Category{}, fmt.Errorf("category %d not found", category.ID)
 	}
 
-	lastLine := lines[len(lines)-1]
+	if err := f.writeCategoriesToFile(categories); err != nil {
+		return models.Category{}, fmt.Errorf("can't write categories to file: %v", err)
+	}
 
-	lastCategory := f.CategoryDeserializer([]string{lastLine})
+	return category, nil
+}
+
+// DeleteCategory removes a category from the file for good.
+func (f FileStore) DeleteCategory(id int) error {
+	categories, err := f.ListCategories()
 	if err != nil {
-		return 0, fmt.Errorf("can't deserialize last line to category: %w", err)
+		return err
 	}
 
-	return lastCategory[0].ID + 1, nil
+	var kept []models.Category
+	for _, category := range categories {
+		if category.ID != id {
+			kept = append(kept, category)
+		}
+	}
+
+	if len(kept) == len(categories) {
+		return fmt.Errorf("category %d not found", id)
+	}
+
+	if err := f.writeCategoriesToFile(kept); err != nil {
+		return fmt.Errorf("can't write categories to file: %v", err)
+	}
+
+	return nil
 }
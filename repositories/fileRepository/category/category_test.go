@@ -8,6 +8,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 	"todo-cli-refactor/consts"
 	"todo-cli-refactor/models"
 )
@@ -156,7 +157,7 @@ func TestCategoryDeserializer(t *testing.T) {
 	}
 
 	for i, category := range categories {
-		if category != expectedCategories[i] {
+		if !reflect.DeepEqual(category, expectedCategories[i]) {
 			t.Errorf("expected category %v, got %v", expectedCategories[i], category)
 		}
 	}
@@ -257,3 +258,60 @@ func TestGenerateID(t *testing.T) {
 		t.Errorf("expected ID %d, got %d", expectedID, id)
 	}
 }
+
+func TestUpdateCategory(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			tmpfile, err := ioutil.TempFile("", "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(tmpfile.Name())
+
+			fs := FileStore{Filepath: tmpfile.Name(), serializationMode: mode}
+
+			categories := []models.Category{
+				{ID: 1, Title: "Work, mostly", Color: "blue", UserID: 2},
+				{ID: 2, Title: "Home", Color: "green", UserID: 2},
+				{ID: 3, Title: "Hobby", Color: "red", UserID: 4},
+			}
+			for _, category := range categories {
+				if err := fs.writeCategoryToFile(category); err != nil {
+					t.Fatalf("can't write category to file: %v", err)
+				}
+			}
+
+			trashed := categories[1]
+			trashed.ParentID = 1
+			trashed.Fields = []models.FieldDef{
+				{Name: "store", Type: models.StringField},
+				{Name: "aisle", Type: models.EnumField, Options: []string{"fresh, cold", "dry"}},
+			}
+			trashed.Shares = []models.Share{{UserID: 4, Role: models.EditorRole}, {UserID: 5, Role: models.ViewerRole}}
+			trashed.DeletedAt = &deletedAt
+			if _, err := fs.UpdateCategory(trashed); err != nil {
+				t.Fatalf("UpdateCategory failed: %v", err)
+			}
+
+			if err := fs.DeleteCategory(3); err != nil {
+				t.Fatalf("DeleteCategory failed: %v", err)
+			}
+
+			result, err := fs.ListUserCategories(2)
+			if err != nil {
+				t.Fatalf("ListUserCategories failed: %v", err)
+			}
+
+			expected := []models.Category{categories[0], trashed}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("result does not match expected data: got %v, want %v", result, expected)
+			}
+
+			if _, err := fs.UpdateCategory(models.Category{ID: 9}); err == nil {
+				t.Errorf("UpdateCategory should fail for a missing category")
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package comment
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+// maxLineSize bounds a single stored comment. Comments are free text, which
+// can make lines much longer than the scanner's default limit.
+const maxLineSize = 1024 * 1024
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// no comment was saved yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) CommentDeserializer(pData []string) []models.Comment {
+	var comments []models.Comment
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			comment, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			comments = append(comments, comment)
+		case consts.JsonSerializationMode:
+			comment, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			comments = append(comments, comment)
+		}
+	}
+
+	return comments
+}
+
+func TextDeserializer(commentStr string) (models.Comment, error) {
+	fields, ok := textrecord.Fields(commentStr)
+	if !ok {
+		return models.Comment{}, fmt.Errorf("invalid comment string: %s", commentStr)
+	}
+
+	for _, key := range []string{"id", "taskID", "authorID", "createdAt", "text"} {
+		if _, ok := fields[key]; !ok {
+			return models.Comment{}, fmt.Errorf("invalid comment string: %s", commentStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	taskID, err := strconv.Atoi(fields["taskID"])
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("invalid taskID: %s", fields["taskID"])
+	}
+
+	authorID, err := strconv.Atoi(fields["authorID"])
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("invalid authorID: %s", fields["authorID"])
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, fields["createdAt"])
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("invalid createdAt: %s", fields["createdAt"])
+	}
+
+	comment := models.Comment{
+		ID:        id,
+		TaskID:    taskID,
+		AuthorID:  authorID,
+		Text:      fields["text"],
+		CreatedAt: createdAt,
+	}
+
+	if mentions, ok := fields["mentions"]; ok {
+		for _, idStr := range strings.Fields(mentions) {
+			userID, err := strconv.Atoi(idStr)
+			if err != nil {
+				return models.Comment{}, fmt.Errorf("invalid mentions: %s", mentions)
+			}
+			comment.Mentions = append(comment.Mentions, userID)
+		}
+	}
+
+	if editedAtStr, ok := fields["editedAt"]; ok {
+		editedAt, err := time.Parse(time.RFC3339, editedAtStr)
+		if err != nil {
+			return models.Comment{}, fmt.Errorf("invalid editedAt: %s", editedAtStr)
+		}
+		comment.EditedAt = &editedAt
+	}
+
+	return comment, nil
+}
+
+func JsonDeserializer(commentStr string) (models.Comment, error) {
+	var comment models.Comment
+
+	err := json.Unmarshal([]byte(commentStr), &comment)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("invalid json: %s", commentStr)
+	}
+
+	return comment, nil
+}
+
+func (f FileStore) serializeComment(comment models.Comment) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("id: %d, taskID: %d, authorID: %d, createdAt: %s", comment.ID, comment.TaskID,
+			comment.AuthorID, comment.CreatedAt.Format(time.RFC3339))
+		if len(comment.Mentions) > 0 {
+			ids := make([]string, 0, len(comment.Mentions))
+			for _, userID := range comment.Mentions {
+				ids = append(ids, strconv.Itoa(userID))
+			}
+			line += ", mentions: " + strings.Join(ids, " ")
+		}
+		if comment.EditedAt != nil {
+			line += ", editedAt: " + comment.EditedAt.Format(time.RFC3339)
+		}
+		line += ", text: " + textrecord.Escape(comment.Text)
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(comment)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal comment struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeCommentsToFile(comments []models.Comment) error {
+	var data []byte
+	for _, comment := range comments {
+		line, err := f.serializeComment(comment)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) listComments() ([]models.Comment, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.CommentDeserializer(lines), nil
+}
+
+func (f FileStore) CreateNewComment(comment models.Comment) (models.Comment, error) {
+	comments, err := f.listComments()
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	comment.ID = 1
+	for _, stored := range comments {
+		if stored.ID >= comment.ID {
+			comment.ID = stored.ID + 1
+		}
+	}
+
+	if err := f.writeCommentsToFile(append(comments, comment)); err != nil {
+		return models.Comment{}, fmt.Errorf("can't write comment to file: %v", err)
+	}
+
+	return comment, nil
+}
+
+func (f FileStore) ListComments() ([]models.Comment, error) {
+	return f.listComments()
+}
+
+func (f FileStore) UpdateComment(comment models.Comment) (models.Comment, error) {
+	comments, err := f.listComments()
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	found := false
+	for i := range comments {
+		if comments[i].ID == comment.ID {
+			comments[i] = comment
+			found = true
+		}
+	}
+
+	if !found {
+		return models.Comment{}, fmt.Errorf("comment %d not found", comment.ID)
+	}
+
+	if err := f.writeCommentsToFile(comments); err != nil {
+		return models.Comment{}, fmt.Errorf("can't write comments to file: %v", err)
+	}
+
+	return comment, nil
+}
+
+func (f FileStore) DeleteComment(id int) error {
+	comments, err := f.listComments()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.Comment
+	for _, comment := range comments {
+		if comment.ID != id {
+			kept = append(kept, comment)
+		}
+	}
+
+	if len(kept) == len(comments) {
+		return fmt.Errorf("comment %d not found", id)
+	}
+
+	if err := f.writeCommentsToFile(kept); err != nil {
+		return fmt.Errorf("can't write comments to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package comment
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestCommentStore(t *testing.T) {
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "comment.txt"), mode)
+
+			createdAt := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+			editedAt := createdAt.Add(time.Hour)
+
+			comments := []models.Comment{
+				{TaskID: 1, AuthorID: 3, Text: "Ask @bob, then book it", Mentions: []int{4}, CreatedAt: createdAt},
+				{TaskID: 1, AuthorID: 4, Text: "Booked:\n- hall \\ garden", CreatedAt: createdAt},
+				{TaskID: 2, AuthorID: 3, Text: "Never mind", CreatedAt: createdAt},
+			}
+			for i := range comments {
+				created, err := fs.CreateNewComment(comments[i])
+				if err != nil {
+					t.Fatalf("CreateNewComment failed: %v", err)
+				}
+				comments[i] = created
+			}
+
+			comments[1].Text = "Booked the hall"
+			comments[1].EditedAt = &editedAt
+			if _, err := fs.UpdateComment(comments[1]); err != nil {
+				t.Fatalf("UpdateComment failed: %v", err)
+			}
+
+			if err := fs.DeleteComment(comments[2].ID); err != nil {
+				t.Fatalf("DeleteComment failed: %v", err)
+			}
+
+			result, err := fs.ListComments()
+			if err != nil {
+				t.Fatalf("ListComments failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, comments[:2]) {
+				t.Errorf("comments do not match: got %v, want %v", result, comments[:2])
+			}
+
+			if err := fs.DeleteComment(9); err == nil {
+				t.Errorf("DeleteComment should fail for a missing comment")
+			}
+		})
+	}
+}
+
+func TestCommentTextRoundTrip(t *testing.T) {
+	fs := FileStore{serializationMode: consts.TextSerializationMode}
+
+	comment := models.Comment{ID: 2, TaskID: 1, AuthorID: 4, Text: "Booked:\n- hall, garden", Mentions: []int{3, 5},
+		CreatedAt: time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)}
+
+	data, err := fs.serializeComment(comment)
+	if err != nil {
+		t.Fatalf("serializeComment failed: %v", err)
+	}
+
+	result, err := TextDeserializer(string(data[:len(data)-1]))
+	if err != nil {
+		t.Fatalf("TextDeserializer failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, comment) {
+		t.Errorf("comment does not match: got %v, want %v", result, comment)
+	}
+}
+
+func TestLoadLongComment(t *testing.T) {
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "comment.txt"), mode)
+
+			// well past the scanner's default limit of 64 KiB a line
+			long := models.Comment{
+				TaskID:    1,
+				AuthorID:  3,
+				Text:      strings.Repeat("notes, \\ more\n", 8*1024),
+				CreatedAt: time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC),
+			}
+			created, err := fs.CreateNewComment(long)
+			if err != nil {
+				t.Fatalf("CreateNewComment failed: %v", err)
+			}
+
+			result, err := fs.ListComments()
+			if err != nil {
+				t.Fatalf("ListComments failed: %v", err)
+			}
+
+			if len(result) != 1 || !reflect.DeepEqual(result[0], created) {
+				t.Errorf("the long comment should load back unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+// maxLineSize bounds a single stored change, which can hold two versions of
+// a task description.
+const maxLineSize = 2 * 1024 * 1024
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// nothing was changed yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) ChangeDeserializer(pData []string) []models.Change {
+	var changes []models.Change
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			change, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			changes = append(changes, change)
+		case consts.JsonSerializationMode:
+			change, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+func TextDeserializer(changeStr string) (models.Change, error) {
+	fields, ok := textrecord.Fields(changeStr)
+	if !ok {
+		return models.Change{}, fmt.Errorf("invalid change string: %s", changeStr)
+	}
+
+	for _, key := range []string{"taskID", "actorID", "changedAt", "field"} {
+		if _, ok := fields[key]; !ok {
+			return models.Change{}, fmt.Errorf("invalid change string: %s", changeStr)
+		}
+	}
+
+	taskID, err := strconv.Atoi(fields["taskID"])
+	if err != nil {
+		return models.Change{}, fmt.Errorf("invalid taskID: %s", fields["taskID"])
+	}
+
+	actorID, err := strconv.Atoi(fields["actorID"])
+	if err != nil {
+		return models.Change{}, fmt.Errorf("invalid actorID: %s", fields["actorID"])
+	}
+
+	changedAt, err := time.Parse(time.RFC3339Nano, fields["changedAt"])
+	if err != nil {
+		return models.Change{}, fmt.Errorf("invalid changedAt: %s", fields["changedAt"])
+	}
+
+	return models.Change{
+		TaskID:    taskID,
+		ActorID:   actorID,
+		ChangedAt: changedAt,
+		Field:     fields["field"],
+		OldValue:  fields["old"],
+		NewValue:  fields["new"],
+	}, nil
+}
+
+func JsonDeserializer(changeStr string) (models.Change, error) {
+	var change models.Change
+
+	err := json.Unmarshal([]byte(changeStr), &change)
+	if err != nil {
+		return models.Change{}, fmt.Errorf("invalid json: %s", changeStr)
+	}
+
+	return change, nil
+}
+
+func (f FileStore) serializeChange(change models.Change) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("taskID: %d, actorID: %d, changedAt: %s, field: %s", change.TaskID, change.ActorID,
+			change.ChangedAt.Format(time.RFC3339Nano), textrecord.Escape(change.Field))
+		if change.OldValue != "" {
+			line += ", old: " + textrecord.Escape(change.OldValue)
+		}
+		if change.NewValue != "" {
+			line += ", new: " + textrecord.Escape(change.NewValue)
+		}
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(change)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal change struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+// AppendChanges adds changes to the end of the history.
+func (f FileStore) AppendChanges(changes []models.Change) error {
+	var data []byte
+	for _, change := range changes {
+		line, err := f.serializeChange(change)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	file, err := os.OpenFile(f.Filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("can't create or open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+// ListTaskChanges returns the changes of a task, oldest first.
+func (f FileStore) ListTaskChanges(taskID int) ([]models.Change, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	var changes []models.Change
+	for _, change := range f.ChangeDeserializer(lines) {
+		if change.TaskID == taskID {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,45 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestHistoryStore(t *testing.T) {
+	changedAt := time.Date(2026, 10, 19, 9, 30, 0, 123456789, time.UTC)
+
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "history.txt"), mode)
+
+			changes := []models.Change{
+				{TaskID: 1, ActorID: 3, ChangedAt: changedAt, Field: models.CreatedField},
+				{TaskID: 1, ActorID: 3, ChangedAt: changedAt, Field: "title", NewValue: "Buy milk, eggs"},
+				{TaskID: 2, ActorID: 3, ChangedAt: changedAt, Field: "title", NewValue: "Clean"},
+				{TaskID: 1, ActorID: 4, ChangedAt: changedAt.Add(time.Hour), Field: "description",
+					OldValue: "first\nsecond", NewValue: "first\nthird"},
+			}
+
+			if err := fs.AppendChanges(changes[:2]); err != nil {
+				t.Fatalf("AppendChanges failed: %v", err)
+			}
+			if err := fs.AppendChanges(changes[2:]); err != nil {
+				t.Fatalf("AppendChanges failed: %v", err)
+			}
+
+			result, err := fs.ListTaskChanges(1)
+			if err != nil {
+				t.Fatalf("ListTaskChanges failed: %v", err)
+			}
+
+			expected := []models.Change{changes[0], changes[1], changes[3]}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("changes do not match: got %v, want %v", result, expected)
+			}
+		})
+	}
+}
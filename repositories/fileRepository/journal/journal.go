@@ -0,0 +1,308 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+// maxLineSize bounds a single stored entry, which holds two versions of every
+// task the operation touched.
+const maxLineSize = 8 * 1024 * 1024
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// nothing was journaled yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) EntryDeserializer(pData []string) []models.JournalEntry {
+	var entries []models.JournalEntry
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			entry, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			entries = append(entries, entry)
+		case consts.JsonSerializationMode:
+			entry, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// TextDeserializer parses a text line. The task and category versions are
+// kept as escaped JSON since they nest whole records.
+func TextDeserializer(entryStr string) (models.JournalEntry, error) {
+	fields, ok := textrecord.Fields(entryStr)
+	if !ok {
+		return models.JournalEntry{}, fmt.Errorf("invalid journal entry string: %s", entryStr)
+	}
+
+	for _, key := range []string{"id", "userID", "command", "createdAt"} {
+		if _, ok := fields[key]; !ok {
+			return models.JournalEntry{}, fmt.Errorf("invalid journal entry string: %s", entryStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.JournalEntry{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	userID, err := strconv.Atoi(fields["userID"])
+	if err != nil {
+		return models.JournalEntry{}, fmt.Errorf("invalid userID: %s", fields["userID"])
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, fields["createdAt"])
+	if err != nil {
+		return models.JournalEntry{}, fmt.Errorf("invalid createdAt: %s", fields["createdAt"])
+	}
+
+	entry := models.JournalEntry{
+		ID:        id,
+		UserID:    userID,
+		Command:   fields["command"],
+		CreatedAt: createdAt,
+	}
+
+	if tasks, ok := fields["tasks"]; ok {
+		if err := json.Unmarshal([]byte(tasks), &entry.Tasks); err != nil {
+			return models.JournalEntry{}, fmt.Errorf("invalid tasks: %s", tasks)
+		}
+	}
+
+	if categories, ok := fields["categories"]; ok {
+		if err := json.Unmarshal([]byte(categories), &entry.Categories); err != nil {
+			return models.JournalEntry{}, fmt.Errorf("invalid categories: %s", categories)
+		}
+	}
+
+	if undone, ok := fields["undone"]; ok {
+		entry.Undone, err = strconv.ParseBool(undone)
+		if err != nil {
+			return models.JournalEntry{}, fmt.Errorf("invalid undone: %s", undone)
+		}
+	}
+
+	return entry, nil
+}
+
+func JsonDeserializer(entryStr string) (models.JournalEntry, error) {
+	var entry models.JournalEntry
+
+	err := json.Unmarshal([]byte(entryStr), &entry)
+	if err != nil {
+		return models.JournalEntry{}, fmt.Errorf("invalid json: %s", entryStr)
+	}
+
+	return entry, nil
+}
+
+func (f FileStore) serializeEntry(entry models.JournalEntry) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("id: %d, userID: %d, command: %s, createdAt: %s", entry.ID, entry.UserID,
+			textrecord.Escape(entry.Command), entry.CreatedAt.Format(time.RFC3339Nano))
+		if len(entry.Tasks) > 0 {
+			tasks, err := json.Marshal(entry.Tasks)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal task versions to json: %w", err)
+			}
+			line += ", tasks: " + textrecord.Escape(string(tasks))
+		}
+		if len(entry.Categories) > 0 {
+			categories, err := json.Marshal(entry.Categories)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal category versions to json: %w", err)
+			}
+			line += ", categories: " + textrecord.Escape(string(categories))
+		}
+		if entry.Undone {
+			line += ", undone: true"
+		}
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal journal entry struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeEntriesToFile(entries []models.JournalEntry) error {
+	var data []byte
+	for _, entry := range entries {
+		line, err := f.serializeEntry(entry)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) listEntries() ([]models.JournalEntry, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.EntryDeserializer(lines), nil
+}
+
+// AppendEntry adds an entry to the end of the journal and gives it an ID.
+func (f FileStore) AppendEntry(entry models.JournalEntry) (models.JournalEntry, error) {
+	entries, err := f.listEntries()
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	entry.ID = 1
+	for _, stored := range entries {
+		if stored.ID >= entry.ID {
+			entry.ID = stored.ID + 1
+		}
+	}
+
+	line, err := f.serializeEntry(entry)
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	file, err := os.OpenFile(f.Filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return models.JournalEntry{}, fmt.Errorf("can't create or open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return models.JournalEntry{}, fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ListUserEntries returns the journal of a user, oldest first.
+func (f FileStore) ListUserEntries(userID int) ([]models.JournalEntry, error) {
+	entries, err := f.listEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var userEntries []models.JournalEntry
+	for _, entry := range entries {
+		if entry.UserID == userID {
+			userEntries = append(userEntries, entry)
+		}
+	}
+
+	return userEntries, nil
+}
+
+func (f FileStore) UpdateEntry(entry models.JournalEntry) (models.JournalEntry, error) {
+	entries, err := f.listEntries()
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	found := false
+	for i := range entries {
+		if entries[i].ID == entry.ID {
+			entries[i] = entry
+			found = true
+		}
+	}
+
+	if !found {
+		return models.JournalEntry{}, fmt.Errorf("journal entry %d not found", entry.ID)
+	}
+
+	if err := f.writeEntriesToFile(entries); err != nil {
+		return models.JournalEntry{}, fmt.Errorf("can't write journal entries to file: %v", err)
+	}
+
+	return entry, nil
+}
+
+// DeleteEntries removes the entries with the given IDs from the journal.
+func (f FileStore) DeleteEntries(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	remove := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	entries, err := f.listEntries()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.JournalEntry
+	for _, entry := range entries {
+		if !remove[entry.ID] {
+			kept = append(kept, entry)
+		}
+	}
+
+	if err := f.writeEntriesToFile(kept); err != nil {
+		return fmt.Errorf("can't write journal entries to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package journal
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestJournalStore(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 9, 30, 0, 123456789, time.UTC)
+
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "journal.txt"), mode)
+
+			entries := []models.JournalEntry{
+				{UserID: 1, Command: "create-task", CreatedAt: createdAt, Tasks: []models.TaskVersion{
+					{After: &models.Task{ID: 1, Title: "Buy milk, eggs", UserID: 1}},
+				}},
+				{UserID: 2, Command: "create-category", CreatedAt: createdAt, Categories: []models.CategoryVersion{
+					{After: &models.Category{ID: 1, Title: "Home", UserID: 2}},
+				}},
+				{UserID: 1, Command: "edit-task", CreatedAt: createdAt.Add(time.Minute), Tasks: []models.TaskVersion{
+					{Before: &models.Task{ID: 1, Title: "Buy milk, eggs", UserID: 1}, After: &models.Task{ID: 1, Title: "Buy milk", UserID: 1}},
+				}},
+			}
+
+			for i := range entries {
+				stored, err := fs.AppendEntry(entries[i])
+				if err != nil {
+					t.Fatalf("AppendEntry failed: %v", err)
+				}
+				if stored.ID != i+1 {
+					t.Errorf("entry %d got ID %d", i, stored.ID)
+				}
+				entries[i] = stored
+			}
+
+			entries[2].Undone = true
+			if _, err := fs.UpdateEntry(entries[2]); err != nil {
+				t.Fatalf("UpdateEntry failed: %v", err)
+			}
+
+			result, err := fs.ListUserEntries(1)
+			if err != nil {
+				t.Fatalf("ListUserEntries failed: %v", err)
+			}
+
+			expected := []models.JournalEntry{entries[0], entries[2]}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("entries do not match: got %v, want %v", result, expected)
+			}
+
+			if err := fs.DeleteEntries([]int{1}); err != nil {
+				t.Fatalf("DeleteEntries failed: %v", err)
+			}
+
+			result, err = fs.ListUserEntries(1)
+			if err != nil {
+				t.Fatalf("ListUserEntries failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, expected[1:]) {
+				t.Errorf("entries do not match after delete: got %v, want %v", result, expected[1:])
+			}
+		})
+	}
+}
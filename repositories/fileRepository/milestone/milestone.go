@@ -0,0 +1,257 @@
+package milestone
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// no milestone was created yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) MilestoneDeserializer(pData []string) []models.Milestone {
+	var milestones []models.Milestone
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			milestone, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			milestones = append(milestones, milestone)
+		case consts.JsonSerializationMode:
+			milestone, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			milestones = append(milestones, milestone)
+		}
+	}
+
+	return milestones
+}
+
+func TextDeserializer(milestoneStr string) (models.Milestone, error) {
+	fields, ok := textrecord.Fields(milestoneStr)
+	if !ok {
+		return models.Milestone{}, fmt.Errorf("invalid milestone string: %s", milestoneStr)
+	}
+
+	for _, key := range []string{"id", "projectID", "userID", "title", "targetDate", "createdAt"} {
+		if _, ok := fields[key]; !ok {
+			return models.Milestone{}, fmt.Errorf("invalid milestone string: %s", milestoneStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	userID, err := strconv.Atoi(fields["userID"])
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("invalid userID: %s", fields["userID"])
+	}
+
+	projectID, err := strconv.Atoi(fields["projectID"])
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("invalid projectID: %s", fields["projectID"])
+	}
+
+	targetDate, err := models.ParseDueDate(fields["targetDate"])
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("invalid targetDate: %s", fields["targetDate"])
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, fields["createdAt"])
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("invalid createdAt: %s", fields["createdAt"])
+	}
+
+	return models.Milestone{
+		ID:         id,
+		ProjectID:  projectID,
+		UserID:     userID,
+		Title:      fields["title"],
+		TargetDate: targetDate,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+func JsonDeserializer(milestoneStr string) (models.Milestone, error) {
+	var milestone models.Milestone
+
+	err := json.Unmarshal([]byte(milestoneStr), &milestone)
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("invalid json: %s", milestoneStr)
+	}
+
+	return milestone, nil
+}
+
+func (f FileStore) serializeMilestone(milestone models.Milestone) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("id: %d, projectID: %d, userID: %d, title: %s, targetDate: %s, createdAt: %s",
+			milestone.ID, milestone.ProjectID, milestone.UserID, textrecord.Escape(milestone.Title),
+			milestone.TargetDate, milestone.CreatedAt.Format(time.RFC3339))
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(milestone)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal milestone struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeMilestonesToFile(milestones []models.Milestone) error {
+	var data []byte
+	for _, milestone := range milestones {
+		line, err := f.serializeMilestone(milestone)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) listMilestones() ([]models.Milestone, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.MilestoneDeserializer(lines), nil
+}
+
+func (f FileStore) CreateNewMilestone(milestone models.Milestone) (models.Milestone, error) {
+	milestones, err := f.listMilestones()
+	if err != nil {
+		return models.Milestone{}, err
+	}
+
+	milestone.ID = 1
+	for _, stored := range milestones {
+		if stored.ID >= milestone.ID {
+			milestone.ID = stored.ID + 1
+		}
+	}
+
+	if err := f.writeMilestonesToFile(append(milestones, milestone)); err != nil {
+		return models.Milestone{}, fmt.Errorf("can't write milestone to file: %v", err)
+	}
+
+	return milestone, nil
+}
+
+func (f FileStore) ListUserMilestones(userID int) ([]models.Milestone, error) {
+	milestones, err := f.listMilestones()
+	if err != nil {
+		return nil, err
+	}
+
+	var userMilestones []models.Milestone
+	for _, milestone := range milestones {
+		if milestone.UserID == userID {
+			userMilestones = append(userMilestones, milestone)
+		}
+	}
+
+	return userMilestones, nil
+}
+
+func (f FileStore) UpdateMilestone(milestone models.Milestone) (models.Milestone, error) {
+	milestones, err := f.listMilestones()
+	if err != nil {
+		return models.Milestone{}, err
+	}
+
+	found := false
+	for i := range milestones {
+		if milestones[i].ID == milestone.ID {
+			milestones[i] = milestone
+			found = true
+		}
+	}
+
+	if !found {
+		return models.Milestone{}, fmt.Errorf("milestone %d not found", milestone.ID)
+	}
+
+	if err := f.writeMilestonesToFile(milestones); err != nil {
+		return models.Milestone{}, fmt.Errorf("can't write milestones to file: %v", err)
+	}
+
+	return milestone, nil
+}
+
+func (f FileStore) DeleteMilestone(id int) error {
+	milestones, err := f.listMilestones()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.Milestone
+	for _, milestone := range milestones {
+		if milestone.ID != id {
+			kept = append(kept, milestone)
+		}
+	}
+
+	if len(kept) == len(milestones) {
+		return fmt.Errorf("milestone %d not found", id)
+	}
+
+	if err := f.writeMilestonesToFile(kept); err != nil {
+		return fmt.Errorf("can't write milestones to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package milestone
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestMilestoneStore(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "milestone.txt"), mode)
+
+			milestones := []models.Milestone{
+				{ProjectID: 1, UserID: 3, Title: "Beta, public", TargetDate: models.NewDueDate(2026, 11, 1), CreatedAt: createdAt},
+				{ProjectID: 2, UserID: 4, Title: "Seeds", CreatedAt: createdAt},
+				{ProjectID: 1, UserID: 3, Title: "Launch", TargetDate: models.NewDueDateTime(createdAt.AddDate(0, 2, 0)), CreatedAt: createdAt},
+			}
+			for i := range milestones {
+				created, err := fs.CreateNewMilestone(milestones[i])
+				if err != nil {
+					t.Fatalf("CreateNewMilestone failed: %v", err)
+				}
+				milestones[i] = created
+			}
+
+			milestones[0].TargetDate = models.NewDueDate(2026, 11, 8)
+			if _, err := fs.UpdateMilestone(milestones[0]); err != nil {
+				t.Fatalf("UpdateMilestone failed: %v", err)
+			}
+
+			if err := fs.DeleteMilestone(milestones[1].ID); err != nil {
+				t.Fatalf("DeleteMilestone failed: %v", err)
+			}
+
+			result, err := fs.ListUserMilestones(3)
+			if err != nil {
+				t.Fatalf("ListUserMilestones failed: %v", err)
+			}
+
+			expected := []models.Milestone{milestones[0], milestones[2]}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("milestones do not match: got %v, want %v", result, expected)
+			}
+
+			if err := fs.DeleteMilestone(9); err == nil {
+				t.Errorf("DeleteMilestone should fail for a missing milestone")
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// no project was created yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) ProjectDeserializer(pData []string) []models.Project {
+	var projects []models.Project
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			project, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			projects = append(projects, project)
+		case consts.JsonSerializationMode:
+			project, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			projects = append(projects, project)
+		}
+	}
+
+	return projects
+}
+
+func TextDeserializer(projectStr string) (models.Project, error) {
+	fields, ok := textrecord.Fields(projectStr)
+	if !ok {
+		return models.Project{}, fmt.Errorf("invalid project string: %s", projectStr)
+	}
+
+	for _, key := range []string{"id", "userID", "title", "createdAt"} {
+		if _, ok := fields[key]; !ok {
+			return models.Project{}, fmt.Errorf("invalid project string: %s", projectStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.Project{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	userID, err := strconv.Atoi(fields["userID"])
+	if err != nil {
+		return models.Project{}, fmt.Errorf("invalid userID: %s", fields["userID"])
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, fields["createdAt"])
+	if err != nil {
+		return models.Project{}, fmt.Errorf("invalid createdAt: %s", fields["createdAt"])
+	}
+
+	return models.Project{
+		ID:          id,
+		UserID:      userID,
+		Title:       fields["title"],
+		Description: fields["description"],
+		CreatedAt:   createdAt,
+	}, nil
+}
+
+func JsonDeserializer(projectStr string) (models.Project, error) {
+	var project models.Project
+
+	err := json.Unmarshal([]byte(projectStr), &project)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("invalid json: %s", projectStr)
+	}
+
+	return project, nil
+}
+
+func (f FileStore) serializeProject(project models.Project) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("id: %d, userID: %d, title: %s, createdAt: %s", project.ID, project.UserID,
+			textrecord.Escape(project.Title), project.CreatedAt.Format(time.RFC3339))
+		if project.Description != "" {
+			line += ", description: " + textrecord.Escape(project.Description)
+		}
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(project)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal project struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeProjectsToFile(projects []models.Project) error {
+	var data []byte
+	for _, project := range projects {
+		line, err := f.serializeProject(project)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) listProjects() ([]models.Project, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.ProjectDeserializer(lines), nil
+}
+
+func (f FileStore) CreateNewProject(project models.Project) (models.Project, error) {
+	projects, err := f.listProjects()
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	project.ID = 1
+	for _, stored := range projects {
+		if stored.ID >= project.ID {
+			project.ID = stored.ID + 1
+		}
+	}
+
+	if err := f.writeProjectsToFile(append(projects, project)); err != nil {
+		return models.Project{}, fmt.Errorf("can't write project to file: %v", err)
+	}
+
+	return project, nil
+}
+
+func (f FileStore) ListUserProjects(userID int) ([]models.Project, error) {
+	projects, err := f.listProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	var userProjects []models.Project
+	for _, project := range projects {
+		if project.UserID == userID {
+			userProjects = append(userProjects, project)
+		}
+	}
+
+	return userProjects, nil
+}
+
+func (f FileStore) UpdateProject(project models.Project) (models.Project, error) {
+	projects, err := f.listProjects()
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	found := false
+	for i := range projects {
+		if projects[i].ID == project.ID {
+			projects[i] = project
+			found = true
+		}
+	}
+
+	if !found {
+		return models.Project{}, fmt.Errorf("project %d not found", project.ID)
+	}
+
+	if err := f.writeProjectsToFile(projects); err != nil {
+		return models.Project{}, fmt.Errorf("can't write projects to file: %v", err)
+	}
+
+	return project, nil
+}
+
+func (f FileStore) DeleteProject(id int) error {
+	projects, err := f.listProjects()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.Project
+	for _, project := range projects {
+		if project.ID != id {
+			kept = append(kept, project)
+		}
+	}
+
+	if len(kept) == len(projects) {
+		return fmt.Errorf("project %d not found", id)
+	}
+
+	if err := f.writeProjectsToFile(kept); err != nil {
+		return fmt.Errorf("can't write projects to file: %v", err)
+	}
+
+	return nil
+}
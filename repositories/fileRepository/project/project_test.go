@@ -0,0 +1,55 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestProjectStore(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "project.txt"), mode)
+
+			projects := []models.Project{
+				{UserID: 3, Title: "Website, v2", Description: "new design\nand copy", CreatedAt: createdAt},
+				{UserID: 4, Title: "Garden", CreatedAt: createdAt},
+				{UserID: 3, Title: "Move", CreatedAt: createdAt},
+			}
+			for i := range projects {
+				created, err := fs.CreateNewProject(projects[i])
+				if err != nil {
+					t.Fatalf("CreateNewProject failed: %v", err)
+				}
+				projects[i] = created
+			}
+
+			projects[0].Title = "Website"
+			if _, err := fs.UpdateProject(projects[0]); err != nil {
+				t.Fatalf("UpdateProject failed: %v", err)
+			}
+
+			if err := fs.DeleteProject(projects[2].ID); err != nil {
+				t.Fatalf("DeleteProject failed: %v", err)
+			}
+
+			result, err := fs.ListUserProjects(3)
+			if err != nil {
+				t.Fatalf("ListUserProjects failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, projects[:1]) {
+				t.Errorf("projects do not match: got %v, want %v", result, projects[:1])
+			}
+
+			if err := fs.DeleteProject(9); err == nil {
+				t.Errorf("DeleteProject should fail for a missing project")
+			}
+		})
+	}
+}
@@ -8,10 +8,16 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	"todo-cli-refactor/consts"
 	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
 )
 
+// maxLineSize bounds a single stored task. Descriptions can make lines much
+// longer than the scanner's default limit.
+const maxLineSize = 1024 * 1024
+
 type FileStore struct {
 	Filepath          string
 	serializationMode string
@@ -30,16 +36,25 @@ func (f FileStore) Load() ([]string, error) {
 	var pData []string
 
 	file, oErr := os.Open(f.Filepath)
+	if os.IsNotExist(oErr) {
+		// nothing was stored yet
+		return nil, nil
+	}
 	if oErr != nil {
 		err = oErr
 	}
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
 	for scanner.Scan() {
 		pData = append(pData, scanner.Text())
 	}
 
+	if sErr := scanner.Err(); sErr != nil && err == nil {
+		err = sErr
+	}
+
 	return pData, err
 }
 
@@ -71,20 +86,24 @@ func (f FileStore) TaskDeserializer(pData []string) []models.Task {
 func TextDeserializer(taskStr string) (models.Task, error) {
 
 	taskStr = strings.TrimRight(taskStr, "\n")
-	fields := strings.Split(taskStr, ",")
 
-	if len(fields) != 6 {
+	fields, ok := textrecord.Fields(taskStr)
+	if !ok {
 		return models.Task{}, fmt.Errorf("invalid task string: %s", taskStr)
 	}
 
-	fmt.Println(fields)
+	for _, key := range []string{"id", "title", "dueDate", "categoryID", "isDone", "userID"} {
+		if _, ok := fields[key]; !ok {
+			return models.Task{}, fmt.Errorf("invalid task string: %s", taskStr)
+		}
+	}
 
-	idStr := fields[0][4:]
-	title := fields[1][8:]
-	dueDate := fields[2][10:]
-	categoryIDStr := fields[3][13:]
-	isDoneStr := fields[4][9:]
-	userIDStr := fields[5][9:]
+	idStr := fields["id"]
+	title := fields["title"]
+	dueDate := fields["dueDate"]
+	categoryIDStr := fields["categoryID"]
+	isDoneStr := fields["isDone"]
+	userIDStr := fields["userID"]
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -109,10 +128,83 @@ func TextDeserializer(taskStr string) (models.Task, error) {
 	task := models.Task{
 		ID:         id,
 		Title:      title,
-		DueDate:    dueDate,
+		DueDate:    models.MigrateDueDate(dueDate),
 		CategoryID: categoryID,
 		IsDone:     isDone,
 		UserID:     userID,
+		Status:     fields["status"],
+	}
+
+	if assigneeIDStr, ok := fields["assigneeID"]; ok {
+		task.AssigneeID, err = strconv.Atoi(assigneeIDStr)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("invalid assigneeID: %s", assigneeIDStr)
+		}
+	}
+
+	if priority, ok := fields["priority"]; ok {
+		task.Priority, err = models.ParsePriority(priority)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("invalid priority: %s", priority)
+		}
+	}
+
+	if tags, ok := fields["tags"]; ok {
+		task.Tags = textrecord.SplitWords(tags)
+	}
+
+	if parentIDStr, ok := fields["parentID"]; ok {
+		task.ParentID, err = strconv.Atoi(parentIDStr)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("invalid parentID: %s", parentIDStr)
+		}
+	}
+
+	if milestoneIDStr, ok := fields["milestoneID"]; ok {
+		task.MilestoneID, err = strconv.Atoi(milestoneIDStr)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("invalid milestoneID: %s", milestoneIDStr)
+		}
+	}
+
+	if checklist, ok := fields["checklist"]; ok {
+		if err := json.Unmarshal([]byte(checklist), &task.Checklist); err != nil {
+			return models.Task{}, fmt.Errorf("invalid checklist: %s", checklist)
+		}
+	}
+
+	if values, ok := fields["fields"]; ok {
+		if err := json.Unmarshal([]byte(values), &task.Fields); err != nil {
+			return models.Task{}, fmt.Errorf("invalid fields: %s", values)
+		}
+	}
+
+	if blockedBy, ok := fields["blockedBy"]; ok {
+		for _, idStr := range strings.Fields(blockedBy) {
+			blockerID, err := strconv.Atoi(idStr)
+			if err != nil {
+				return models.Task{}, fmt.Errorf("invalid blockedBy: %s", blockedBy)
+			}
+			task.BlockedBy = append(task.BlockedBy, blockerID)
+		}
+	}
+
+	if rule, ok := fields["recurrence"]; ok {
+		recurrence, err := models.ParseRecurrence(rule)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("invalid recurrence: %s", rule)
+		}
+		task.Recurrence = &recurrence
+	}
+
+	task.Description = fields["description"]
+
+	if deletedAtStr, ok := fields["deletedAt"]; ok {
+		deletedAt, err := time.Parse(time.RFC3339, deletedAtStr)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("invalid deletedAt: %s", deletedAtStr)
+		}
+		task.DeletedAt = &deletedAt
 	}
 
 	return task, nil
@@ -136,27 +228,102 @@ func (f FileStore) writeTaskToFile(task models.Task) error {
 	}
 	defer file.Close()
 
-	var data []byte
+	data, err := f.serializeTask(task)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.WriteString(file, string(data))
+	if err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	fmt.Println("numberOfWrittenBytes", n)
+
+	return nil
+}
+
+func (f FileStore) serializeTask(task models.Task) ([]byte, error) {
 	switch f.serializationMode {
 	case consts.TextSerializationMode:
-		data = []byte(fmt.Sprintf("id: %d, title: %s, dueDate: %s, categoryID: %d, isDone: %t, userID: %d\n", task.ID, task.Title,
-			task.DueDate, task.CategoryID, task.IsDone, task.UserID))
+		line := fmt.Sprintf("id: %d, title: %s, dueDate: %s, categoryID: %d, isDone: %t, userID: %d", task.ID,
+			textrecord.Escape(task.Title), task.DueDate, task.CategoryID, task.IsDone, task.UserID)
+		if task.AssigneeID != 0 {
+			line += fmt.Sprintf(", assigneeID: %d", task.AssigneeID)
+		}
+		if task.Status != "" {
+			line += ", status: " + textrecord.Escape(task.Status)
+		}
+		if task.Priority != models.NoPriority {
+			line += ", priority: " + task.Priority.String()
+		}
+		if len(task.Tags) > 0 {
+			line += ", tags: " + textrecord.Escape(textrecord.JoinWords(task.Tags))
+		}
+		if task.ParentID != 0 {
+			line += fmt.Sprintf(", parentID: %d", task.ParentID)
+		}
+		if task.MilestoneID != 0 {
+			line += fmt.Sprintf(", milestoneID: %d", task.MilestoneID)
+		}
+		if len(task.Checklist) > 0 {
+			checklist, err := json.Marshal(task.Checklist)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal checklist to json: %w", err)
+			}
+			line += ", checklist: " + textrecord.Escape(string(checklist))
+		}
+		if len(task.Fields) > 0 {
+			values, err := json.Marshal(task.Fields)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal fields to json: %w", err)
+			}
+			line += ", fields: " + textrecord.Escape(string(values))
+		}
+		if len(task.BlockedBy) > 0 {
+			ids := make([]string, 0, len(task.BlockedBy))
+			for _, blockerID := range task.BlockedBy {
+				ids = append(ids, strconv.Itoa(blockerID))
+			}
+			line += ", blockedBy: " + strings.Join(ids, " ")
+		}
+		if task.Recurrence != nil {
+			line += ", recurrence: " + textrecord.Escape(task.Recurrence.String())
+		}
+		if task.Description != "" {
+			line += ", description: " + textrecord.Escape(task.Description)
+		}
+		if task.DeletedAt != nil {
+			line += ", deletedAt: " + task.DeletedAt.Format(time.RFC3339)
+		}
+
+		return []byte(line + "\n"), nil
 	case consts.JsonSerializationMode:
-		data, err = json.Marshal(task)
+		data, err := json.Marshal(task)
 		if err != nil {
-			return fmt.Errorf("can't marshal task struct to json: %w", err)
+			return nil, fmt.Errorf("can't marshal task struct to json: %w", err)
 		}
-		data = append(data, '\n')
+
+		return append(data, '\n'), nil
 	default:
-		return fmt.Errorf("invalid serialization mode")
+		return nil, fmt.Errorf("invalid serialization mode")
 	}
+}
 
-	n, err := io.WriteString(file, string(data))
-	if err != nil {
-		return fmt.Errorf("can't write to the file: %w", err)
+// writeTasksToFile replaces the whole file with the given tasks.
+func (f FileStore) writeTasksToFile(tasks []models.Task) error {
+	var data []byte
+	for _, task := range tasks {
+		line, err := f.serializeTask(task)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
 	}
 
-	fmt.Println("numberOfWrittenBytes", n)
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
 
 	return nil
 }
@@ -169,6 +336,12 @@ func (f FileStore) CreateNewTask(task models.Task) (models.Task, error) {
 	}
 	task.ID = refID
 
+	// the ID is taken before the task is stored, a failed write wastes it
+	// rather than handing it out twice
+	if err := os.WriteFile(f.sequencePath(), []byte(strconv.Itoa(refID)+"\n"), 0644); err != nil {
+		return models.Task{}, fmt.Errorf("can't write the last task ID: %v", err)
+	}
+
 	err = f.writeTaskToFile(task)
 	if err != nil {
 		return models.Task{}, fmt.Errorf("can't write task to file: %v", err)
@@ -177,23 +350,79 @@ func (f FileStore) CreateNewTask(task models.Task) (models.Task, error) {
 	return task, nil
 }
 
+// generateID returns one past the highest ID ever handed out. Deleted tasks
+// may have had the highest IDs, and what is kept about a task outside of
+// it, like comments and history, must never end up on a new one. Tasks
+// brought back by InsertTask are appended out of order, so the last line
+// isn't enough either.
 func (f FileStore) generateID() (int, error) {
-	lines, err := f.Load()
+	tasks, err := f.ListTasks()
 	if err != nil {
 		return 0, fmt.Errorf("files can't load for counting lines%w", err)
 	}
 
-	if len(lines) == 0 {
-		return 1, err
+	maxID, sErr := f.lastID()
+	if sErr != nil {
+		return 0, sErr
+	}
+
+	for _, task := range tasks {
+		if task.ID > maxID {
+			maxID = task.ID
+		}
+	}
+
+	return maxID + 1, nil
+}
+
+// sequencePath is the file next to the tasks that keeps the last ID handed
+// out.
+func (f FileStore) sequencePath() string {
+	return f.Filepath + ".seq"
+}
+
+// lastID returns the last ID handed out, zero for stores that predate the
+// sequence file.
+func (f FileStore) lastID() (int, error) {
+	data, err := os.ReadFile(f.sequencePath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("can't read the last task ID: %w", err)
 	}
 
-	lastLine := lines[len(lines)-1]
+	id, cErr := strconv.Atoi(strings.TrimSpace(string(data)))
+	if cErr != nil {
+		return 0, fmt.Errorf("invalid last task ID %q: %v", data, cErr)
+	}
 
-	lastTask := f.TaskDeserializer([]string{lastLine})
+	return id, nil
+}
 
-	return lastTask[0].ID + 1, err
+// InsertTask stores a task under the ID it already has, e.g. one that is
+// brought back after being removed.
+func (f FileStore) InsertTask(task models.Task) (models.Task, error) {
+	tasks, err := f.ListTasks()
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	for _, stored := range tasks {
+		if stored.ID == task.ID {
+			return models.Task{}, fmt.Errorf("task %d already exists", task.ID)
+		}
+	}
+
+	if err := f.writeTaskToFile(task); err != nil {
+		return models.Task{}, fmt.Errorf("can't write task to file: %v", err)
+	}
+
+	return task, nil
 }
 
+// ListUserTasks returns the tasks a user owns along with the ones assigned
+// to them.
 func (f FileStore) ListUserTasks(userID int) ([]models.Task, error) {
 
 	lines, err := f.Load()
@@ -205,15 +434,77 @@ func (f FileStore) ListUserTasks(userID int) ([]models.Task, error) {
 
 	for _, line := range lines {
 		task := f.TaskDeserializer([]string{line})
-		if err != nil {
-			fmt.Println(err)
+		if len(task) == 0 {
 			continue
 		}
 
-		if task[0].UserID == userID {
+		if task[0].UserID == userID || task[0].AssigneeID == userID {
 			tasks = append(tasks, task[0])
 		}
 	}
 
 	return tasks, nil
 }
+
+func (f FileStore) UpdateTask(task models.Task) (models.Task, error) {
+
+	lines, err := f.Load()
+	if err != nil {
+		return models.Task{}, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	tasks := f.TaskDeserializer(lines)
+
+	found := false
+	for i := range tasks {
+		if tasks[i].ID == task.ID {
+			tasks[i] = task
+			found = true
+		}
+	}
+
+	if !found {
+		return models.Task{}, fmt.Errorf("task %d not found", task.ID)
+	}
+
+	if err := f.writeTasksToFile(tasks); err != nil {
+		return models.Task{}, fmt.Errorf("can't write tasks to file: %v", err)
+	}
+
+	return task, nil
+}
+
+// ListTasks returns the tasks of all users.
+func (f FileStore) ListTasks() ([]models.Task, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.TaskDeserializer(lines), nil
+}
+
+// DeleteTask removes a task from the file for good.
+func (f FileStore) DeleteTask(id int) error {
+	tasks, err := f.ListTasks()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.Task
+	for _, task := range tasks {
+		if task.ID != id {
+			kept = append(kept, task)
+		}
+	}
+
+	if len(kept) == len(tasks) {
+		return fmt.Errorf("task %d not found", id)
+	}
+
+	if err := f.writeTasksToFile(kept); err != nil {
+		return fmt.Errorf("can't write tasks to file: %v", err)
+	}
+
+	return nil
+}
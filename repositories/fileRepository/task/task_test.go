@@ -7,7 +7,9 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 	"todo-cli-refactor/consts"
 	"todo-cli-refactor/models"
 )
@@ -21,7 +23,7 @@ func TestWriteTaskToFile(t *testing.T) {
 	task := models.Task{
 		ID:         1,
 		Title:      "Buy groceries",
-		DueDate:    "2021-12-31",
+		DueDate:    models.NewDueDate(2021, 12, 31),
 		CategoryID: 2,
 		IsDone:     false,
 		UserID:     3,
@@ -50,7 +52,7 @@ func TestTaskJsonDeserializer(t *testing.T) {
 	task := models.Task{
 		ID:         1,
 		Title:      "Buy groceries",
-		DueDate:    "2021-12-31",
+		DueDate:    models.NewDueDate(2021, 12, 31),
 		CategoryID: 2,
 		IsDone:     false,
 		UserID:     3,
@@ -79,7 +81,7 @@ func TestTaskJsonDeserializer(t *testing.T) {
 	expected := models.Task{
 		ID:         1,
 		Title:      "Buy groceries",
-		DueDate:    "2021-12-31",
+		DueDate:    models.NewDueDate(2021, 12, 31),
 		CategoryID: 2,
 		IsDone:     false,
 		UserID:     3,
@@ -97,7 +99,7 @@ func TestTaskTextDeserializer(t *testing.T) {
 	task := models.Task{
 		ID:         1,
 		Title:      "Buy groceries",
-		DueDate:    "2021-12-31",
+		DueDate:    models.NewDueDate(2021, 12, 31),
 		CategoryID: 2,
 		IsDone:     false,
 		UserID:     3,
@@ -124,7 +126,7 @@ func TestTaskTextDeserializer(t *testing.T) {
 	expected := models.Task{
 		ID:         1,
 		Title:      "Buy groceries",
-		DueDate:    "2021-12-31",
+		DueDate:    models.NewDueDate(2021, 12, 31),
 		CategoryID: 2,
 		IsDone:     false,
 		UserID:     3,
@@ -155,14 +157,14 @@ func TestTaskDeserializer(t *testing.T) {
 	}
 
 	expectedTasks := []models.Task{
-		{ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3},
-		{ID: 2, Title: "Clean the house", DueDate: "2022-01-01", CategoryID: 1, IsDone: true, UserID: 4},
-		{ID: 3, Title: "Read a book", DueDate: "2022-01-02", CategoryID: 3, IsDone: false, UserID: 5},
-		{ID: 4, Title: "Watch a movie", DueDate: "2022-01-03", CategoryID: 4, IsDone: true, UserID: 6},
+		{ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+		{ID: 2, Title: "Clean the house", DueDate: models.NewDueDate(2022, 1, 1), CategoryID: 1, IsDone: true, UserID: 4},
+		{ID: 3, Title: "Read a book", DueDate: models.NewDueDate(2022, 1, 2), CategoryID: 3, IsDone: false, UserID: 5},
+		{ID: 4, Title: "Watch a movie", DueDate: models.NewDueDate(2022, 1, 3), CategoryID: 4, IsDone: true, UserID: 6},
 	}
 
 	for i, task := range tasks {
-		if task != expectedTasks[i] {
+		if !reflect.DeepEqual(task, expectedTasks[i]) {
 			t.Errorf("expected task %v, got %v", expectedTasks[i], task)
 		}
 	}
@@ -176,7 +178,7 @@ func TestSave(t *testing.T) {
 	defer os.Remove(tmpfile.Name())
 
 	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.TextSerializationMode}
-	task := models.Task{ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3}
+	task := models.Task{ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3}
 
 	fs.Save(task)
 
@@ -207,10 +209,11 @@ func TestCreateNewTask(t *testing.T) {
 	defer os.Remove(tmpfile.Name())
 
 	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.TextSerializationMode}
+	defer os.Remove(fs.sequencePath())
 
 	task := models.Task{
 		Title:      "Buy groceries",
-		DueDate:    "2021-12-31",
+		DueDate:    models.NewDueDate(2021, 12, 31),
 		CategoryID: 2,
 		IsDone:     false,
 		UserID:     3,
@@ -250,9 +253,10 @@ func TestGenerateID(t *testing.T) {
 	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.TextSerializationMode}
 
 	tasks := []models.Task{
-		{ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3},
-		{ID: 2, Title: "Clean the house", DueDate: "2022-01-01", CategoryID: 1, IsDone: true, UserID: 4},
-		{ID: 3, Title: "Read a book", DueDate: "2022-01-02", CategoryID: 3, IsDone: false, UserID: 5},
+		{ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+		{ID: 2, Title: "Clean the house", DueDate: models.NewDueDate(2022, 1, 1), CategoryID: 1, IsDone: true, UserID: 4},
+		{ID: 3, Title: "Read a book", DueDate: models.NewDueDate(2022, 1, 2), CategoryID: 3, IsDone: false, UserID: 5},
+		{ID: 4, Title: "Fix the bike", DueDate: models.NewDueDate(2022, 1, 3), CategoryID: 3, IsDone: false, UserID: 5, AssigneeID: 3},
 	}
 	for _, task := range tasks {
 		err := fs.writeTaskToFile(task)
@@ -281,9 +285,10 @@ func TestListUserTasks(t *testing.T) {
 	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.TextSerializationMode}
 
 	tasks := []models.Task{
-		{ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3},
-		{ID: 2, Title: "Clean the house", DueDate: "2022-01-01", CategoryID: 1, IsDone: true, UserID: 4},
-		{ID: 3, Title: "Read a book", DueDate: "2022-01-02", CategoryID: 3, IsDone: false, UserID: 5},
+		{ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+		{ID: 2, Title: "Clean the house", DueDate: models.NewDueDate(2022, 1, 1), CategoryID: 1, IsDone: true, UserID: 4},
+		{ID: 3, Title: "Read a book", DueDate: models.NewDueDate(2022, 1, 2), CategoryID: 3, IsDone: false, UserID: 5},
+		{ID: 4, Title: "Fix the bike", DueDate: models.NewDueDate(2022, 1, 3), CategoryID: 3, IsDone: false, UserID: 5, AssigneeID: 3},
 	}
 	for _, task := range tasks {
 		err := fs.writeTaskToFile(task)
@@ -299,9 +304,316 @@ func TestListUserTasks(t *testing.T) {
 	}
 
 	expected := []models.Task{
-		{ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3},
+		{ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+		{ID: 4, Title: "Fix the bike", DueDate: models.NewDueDate(2022, 1, 3), CategoryID: 3, IsDone: false, UserID: 5, AssigneeID: 3},
 	}
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("result does not match expected data: got %v, want %v", result, expected)
 	}
 }
+
+func TestDueDateMigration(t *testing.T) {
+	fs := FileStore{serializationMode: consts.JsonSerializationMode}
+	pData := []string{
+		`{"ID":1,"Title":"Buy groceries","DueDate":"2021/12/31","CategoryID":2,"IsDone":false,"UserID":3}`,
+		`{"ID":2,"Title":"Clean the house","DueDate":"test","CategoryID":1,"IsDone":true,"UserID":3}`,
+		`{"ID":3,"Title":"Read a book","DueDate":"2022-01-02T18:00:00Z","CategoryID":3,"IsDone":false,"UserID":3}`,
+	}
+
+	tasks := fs.TaskDeserializer(pData)
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(tasks))
+	}
+
+	expected := []models.DueDate{
+		models.NewDueDate(2021, 12, 31),
+		{Legacy: "test"},
+		models.NewDueDateTime(time.Date(2022, 1, 2, 18, 0, 0, 0, time.UTC)),
+	}
+	for i, task := range tasks {
+		if !reflect.DeepEqual(task.DueDate, expected[i]) {
+			t.Errorf("expected due date %v, got %v", expected[i], task.DueDate)
+		}
+	}
+
+	// due dates that aren't understood are written back as they were
+	data, err := fs.serializeTask(tasks[1])
+	if err != nil {
+		t.Fatalf("serializeTask failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"DueDate":"test"`) {
+		t.Errorf("expected the legacy due date to be kept, got %s", data)
+	}
+	if tasks[1].DueDate.Before(time.Now()) {
+		t.Errorf("a legacy due date should never be overdue")
+	}
+}
+
+func TestTaskTextRoundTrip(t *testing.T) {
+	fs := FileStore{serializationMode: consts.TextSerializationMode}
+
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	task := models.Task{
+		ID:          1,
+		Title:       `Water the plants, then \ rest`,
+		Description: "## Plants\n\n- ferns, twice\r\n- cactus `C:\\pots`",
+		DueDate:     models.NewDueDate(2026, 10, 19),
+		CategoryID:  2,
+		UserID:      3,
+		AssigneeID:  8,
+		Status:      "doing",
+		Tags:        []string{"@home", "#weekly"},
+		ParentID:    7,
+		MilestoneID: 6,
+		Checklist:   []models.ChecklistItem{{Text: "kitchen, hall", Done: true}, {Text: "balcony"}},
+		Fields:      map[string]string{"room": "hall", "liters": "1.5"},
+		BlockedBy:   []int{4, 5},
+		Recurrence: &models.Recurrence{
+			Frequency: models.WeeklyFrequency,
+			Interval:  2,
+			ByWeekday: []time.Weekday{time.Monday, time.Thursday},
+			Until:     models.NewDueDate(2026, 12, 31),
+			Count:     4,
+			SeriesID:  1,
+		},
+		DeletedAt: &deletedAt,
+	}
+
+	data, err := fs.serializeTask(task)
+	if err != nil {
+		t.Fatalf("serializeTask failed: %v", err)
+	}
+
+	expectedLine := `id: 1, title: Water the plants\, then \\ rest, dueDate: 2026-10-19, categoryID: 2, isDone: false, userID: 3, assigneeID: 8, status: doing, tags: @home #weekly, parentID: 7, milestoneID: 6, ` +
+		`checklist: [{"Text":"kitchen\, hall"\,"Done":true}\,{"Text":"balcony"\,"Done":false}], ` +
+		`fields: {"liters":"1.5"\,"room":"hall"}, blockedBy: 4 5, ` +
+		`recurrence: FREQ=WEEKLY;INTERVAL=2;BYDAY=MO\,TH;UNTIL=2026-12-31;COUNT=4;X-SERIES=1, ` +
+		`description: ## Plants\n\n- ferns\, twice\r\n- cactus ` + "`C:\\\\pots`" +
+		", deletedAt: 2026-10-19T08:00:00Z\n"
+	if string(data) != expectedLine {
+		t.Errorf("expected line %s, got %s", expectedLine, data)
+	}
+
+	result, err := TextDeserializer(string(data))
+	if err != nil {
+		t.Fatalf("TextDeserializer failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, task) {
+		t.Errorf("task does not match expected data: got %v, want %v", result, task)
+	}
+}
+
+func TestTaskTextTags(t *testing.T) {
+	fs := FileStore{serializationMode: consts.TextSerializationMode}
+
+	task := models.Task{ID: 1, Title: "Pack", UserID: 3, Tags: []string{"#trip", "two words", `a\b, c`}}
+
+	data, err := fs.serializeTask(task)
+	if err != nil {
+		t.Fatalf("serializeTask failed: %v", err)
+	}
+
+	result, err := TextDeserializer(string(data))
+	if err != nil {
+		t.Fatalf("TextDeserializer failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(result.Tags, task.Tags) {
+		t.Errorf("tags do not match: got %q, want %q", result.Tags, task.Tags)
+	}
+
+	// records written before tags were escaped still read the same
+	old, err := TextDeserializer("id: 2, title: Pack, dueDate: , categoryID: 0, isDone: false, userID: 3, tags: #trip  @home\n")
+	if err != nil {
+		t.Fatalf("TextDeserializer failed: %v", err)
+	}
+	if !reflect.DeepEqual(old.Tags, []string{"#trip", "@home"}) {
+		t.Errorf("tags do not match: got %q", old.Tags)
+	}
+}
+
+func TestUpdateTask(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.JsonSerializationMode}
+
+	tasks := []models.Task{
+		{ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+		{ID: 2, Title: "Clean the house", DueDate: models.NewDueDate(2022, 1, 1), CategoryID: 1, IsDone: false, UserID: 3},
+	}
+	for _, task := range tasks {
+		err := fs.writeTaskToFile(task)
+		if err != nil {
+			t.Errorf("can't write task to file: %v", err)
+		}
+	}
+
+	updated := tasks[1]
+	updated.IsDone = true
+	if _, err := fs.UpdateTask(updated); err != nil {
+		t.Errorf("UpdateTask failed: %v", err)
+	}
+
+	result, err := fs.ListUserTasks(3)
+	if err != nil {
+		t.Errorf("ListUserTasks failed: %v", err)
+	}
+
+	expected := []models.Task{tasks[0], updated}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("result does not match expected data: got %v, want %v", result, expected)
+	}
+
+	if _, err := fs.UpdateTask(models.Task{ID: 9}); err == nil {
+		t.Errorf("UpdateTask should fail for a missing task")
+	}
+}
+
+func TestDeleteTask(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.JsonSerializationMode}
+
+	tasks := []models.Task{
+		{ID: 1, Title: "Buy groceries", CategoryID: 2, UserID: 3},
+		{ID: 2, Title: "Clean the house", CategoryID: 1, UserID: 4},
+	}
+	for _, task := range tasks {
+		if err := fs.writeTaskToFile(task); err != nil {
+			t.Errorf("can't write task to file: %v", err)
+		}
+	}
+
+	if err := fs.DeleteTask(1); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+
+	result, err := fs.ListTasks()
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, tasks[1:]) {
+		t.Errorf("result does not match expected data: got %v, want %v", result, tasks[1:])
+	}
+
+	if err := fs.DeleteTask(1); err == nil {
+		t.Errorf("DeleteTask should fail for a missing task")
+	}
+}
+
+func TestDeletedIDsArentReused(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.JsonSerializationMode}
+	defer os.Remove(fs.sequencePath())
+
+	first, err := fs.CreateNewTask(models.Task{Title: "Buy groceries", UserID: 3})
+	if err != nil {
+		t.Fatalf("CreateNewTask failed: %v", err)
+	}
+	last, err := fs.CreateNewTask(models.Task{Title: "Clean the house", UserID: 3})
+	if err != nil {
+		t.Fatalf("CreateNewTask failed: %v", err)
+	}
+
+	if err := fs.DeleteTask(last.ID); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+
+	created, err := fs.CreateNewTask(models.Task{Title: "Read a book", UserID: 4})
+	if err != nil {
+		t.Fatalf("CreateNewTask failed: %v", err)
+	}
+
+	if created.ID == first.ID || created.ID == last.ID {
+		t.Errorf("a new task shouldn't get the ID of a deleted one, got %d", created.ID)
+	}
+
+	// a deleted task brought back keeps its ID
+	if _, err := fs.InsertTask(last); err != nil {
+		t.Errorf("InsertTask failed: %v", err)
+	}
+}
+
+func TestInsertTask(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.JsonSerializationMode}
+
+	for _, task := range []models.Task{{ID: 1, Title: "Buy groceries"}, {ID: 3, Title: "Pay rent"}} {
+		if err := fs.writeTaskToFile(task); err != nil {
+			t.Errorf("can't write task to file: %v", err)
+		}
+	}
+
+	if _, err := fs.InsertTask(models.Task{ID: 2, Title: "Clean the house"}); err != nil {
+		t.Fatalf("InsertTask failed: %v", err)
+	}
+
+	if _, err := fs.InsertTask(models.Task{ID: 3, Title: "Pay rent again"}); err == nil {
+		t.Errorf("InsertTask should fail for an existing ID")
+	}
+
+	id, err := fs.generateID()
+	if err != nil {
+		t.Fatalf("generateID failed: %v", err)
+	}
+
+	if id != 4 {
+		t.Errorf("the next ID should follow the highest one, got %d", id)
+	}
+}
+
+func TestLoadMultilineDescription(t *testing.T) {
+	description := "# Notes\n\nfirst line\nsecond line, with a comma\n\n" + strings.Repeat("long ", 20000)
+
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			tmpfile, err := ioutil.TempFile("", "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(tmpfile.Name())
+
+			fs := FileStore{Filepath: tmpfile.Name(), serializationMode: mode}
+
+			tasks := []models.Task{
+				{ID: 1, Title: "Write report", Description: description, CategoryID: 2, UserID: 3},
+				{ID: 2, Title: "Buy groceries", CategoryID: 2, UserID: 3},
+			}
+			for _, task := range tasks {
+				if err := fs.writeTaskToFile(task); err != nil {
+					t.Fatalf("can't write task to file: %v", err)
+				}
+			}
+
+			result, err := fs.ListUserTasks(3)
+			if err != nil {
+				t.Fatalf("ListUserTasks failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tasks) {
+				t.Errorf("description did not survive a round trip: got %d tasks", len(result))
+			}
+		})
+	}
+}
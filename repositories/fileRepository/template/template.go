@@ -0,0 +1,258 @@
+package template
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+// maxLineSize bounds a single stored template, which holds every task it
+// creates along with their notes.
+const maxLineSize = 1024 * 1024
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// no template was saved yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) TemplateDeserializer(pData []string) []models.Template {
+	var templates []models.Template
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			template, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			templates = append(templates, template)
+		case consts.JsonSerializationMode:
+			template, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			templates = append(templates, template)
+		}
+	}
+
+	return templates
+}
+
+// TextDeserializer parses a text line. The tasks are kept as escaped JSON
+// since they nest whole records.
+func TextDeserializer(templateStr string) (models.Template, error) {
+	fields, ok := textrecord.Fields(templateStr)
+	if !ok {
+		return models.Template{}, fmt.Errorf("invalid template string: %s", templateStr)
+	}
+
+	for _, key := range []string{"id", "userID", "name"} {
+		if _, ok := fields[key]; !ok {
+			return models.Template{}, fmt.Errorf("invalid template string: %s", templateStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.Template{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	userID, err := strconv.Atoi(fields["userID"])
+	if err != nil {
+		return models.Template{}, fmt.Errorf("invalid userID: %s", fields["userID"])
+	}
+
+	template := models.Template{
+		ID:     id,
+		UserID: userID,
+		Name:   fields["name"],
+	}
+
+	if tasks, ok := fields["tasks"]; ok {
+		if err := json.Unmarshal([]byte(tasks), &template.Tasks); err != nil {
+			return models.Template{}, fmt.Errorf("invalid tasks: %s", tasks)
+		}
+	}
+
+	return template, nil
+}
+
+func JsonDeserializer(templateStr string) (models.Template, error) {
+	var template models.Template
+
+	err := json.Unmarshal([]byte(templateStr), &template)
+	if err != nil {
+		return models.Template{}, fmt.Errorf("invalid json: %s", templateStr)
+	}
+
+	return template, nil
+}
+
+func (f FileStore) serializeTemplate(template models.Template) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("id: %d, userID: %d, name: %s", template.ID, template.UserID, textrecord.Escape(template.Name))
+		if len(template.Tasks) > 0 {
+			tasks, err := json.Marshal(template.Tasks)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal template tasks to json: %w", err)
+			}
+			line += ", tasks: " + textrecord.Escape(string(tasks))
+		}
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(template)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal template struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeTemplatesToFile(templates []models.Template) error {
+	var data []byte
+	for _, template := range templates {
+		line, err := f.serializeTemplate(template)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) listTemplates() ([]models.Template, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.TemplateDeserializer(lines), nil
+}
+
+func (f FileStore) CreateNewTemplate(template models.Template) (models.Template, error) {
+	templates, err := f.listTemplates()
+	if err != nil {
+		return models.Template{}, err
+	}
+
+	template.ID = 1
+	for _, stored := range templates {
+		if stored.ID >= template.ID {
+			template.ID = stored.ID + 1
+		}
+	}
+
+	if err := f.writeTemplatesToFile(append(templates, template)); err != nil {
+		return models.Template{}, fmt.Errorf("can't write template to file: %v", err)
+	}
+
+	return template, nil
+}
+
+func (f FileStore) ListUserTemplates(userID int) ([]models.Template, error) {
+	templates, err := f.listTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	var userTemplates []models.Template
+	for _, template := range templates {
+		if template.UserID == userID {
+			userTemplates = append(userTemplates, template)
+		}
+	}
+
+	return userTemplates, nil
+}
+
+func (f FileStore) UpdateTemplate(template models.Template) (models.Template, error) {
+	templates, err := f.listTemplates()
+	if err != nil {
+		return models.Template{}, err
+	}
+
+	found := false
+	for i := range templates {
+		if templates[i].ID == template.ID {
+			templates[i] = template
+			found = true
+		}
+	}
+
+	if !found {
+		return models.Template{}, fmt.Errorf("template %d not found", template.ID)
+	}
+
+	if err := f.writeTemplatesToFile(templates); err != nil {
+		return models.Template{}, fmt.Errorf("can't write templates to file: %v", err)
+	}
+
+	return template, nil
+}
+
+func (f FileStore) DeleteTemplate(id int) error {
+	templates, err := f.listTemplates()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.Template
+	for _, template := range templates {
+		if template.ID != id {
+			kept = append(kept, template)
+		}
+	}
+
+	if len(kept) == len(templates) {
+		return fmt.Errorf("template %d not found", id)
+	}
+
+	if err := f.writeTemplatesToFile(kept); err != nil {
+		return fmt.Errorf("can't write templates to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package template
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestTemplateStore(t *testing.T) {
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "template.txt"), mode)
+
+			templates := []models.Template{
+				{UserID: 3, Name: "release", Tasks: []models.TemplateTask{
+					{Title: "release {{version}}", Due: "+1w", CategoryID: 2, Priority: models.HighPriority, Tags: []string{"#release"}},
+					{Title: "write notes, then publish", Description: "line one\nline two", Due: "+5d 09:00", Parent: 1, Checklist: []string{"draft", "review"}},
+				}},
+				{UserID: 4, Name: "onboarding", Tasks: []models.TemplateTask{{Title: "laptop"}}},
+				{UserID: 3, Name: "empty"},
+			}
+			for i := range templates {
+				created, err := fs.CreateNewTemplate(templates[i])
+				if err != nil {
+					t.Fatalf("CreateNewTemplate failed: %v", err)
+				}
+				templates[i] = created
+			}
+
+			templates[0].Tasks[0].Due = "+2w"
+			if _, err := fs.UpdateTemplate(templates[0]); err != nil {
+				t.Fatalf("UpdateTemplate failed: %v", err)
+			}
+
+			if err := fs.DeleteTemplate(templates[2].ID); err != nil {
+				t.Fatalf("DeleteTemplate failed: %v", err)
+			}
+
+			result, err := fs.ListUserTemplates(3)
+			if err != nil {
+				t.Fatalf("ListUserTemplates failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, templates[:1]) {
+				t.Errorf("templates do not match: got %v, want %v", result, templates[:1])
+			}
+
+			if err := fs.DeleteTemplate(9); err == nil {
+				t.Errorf("DeleteTemplate should fail for a missing template")
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package textrecord reads and writes the records of the text serialization
+// mode. Records are written as comma separated "key: value" pairs. Values are
+// escaped so that commas, backslashes and newlines in them survive a round
+// trip through the line based file format.
+package textrecord
+
+import "strings"
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "\n", `\n`, "\r", `\r`)
+
+// Escape escapes a value to be written into a record.
+func Escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// Fields splits a record into its keys and unescaped values. It reports false
+// when a field isn't in "key: value" form.
+func Fields(line string) (map[string]string, bool) {
+	fields := map[string]string{}
+
+	var value strings.Builder
+	var raw []string
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			switch r {
+			case 'n':
+				value.WriteRune('\n')
+			case 'r':
+				value.WriteRune('\r')
+			default:
+				value.WriteRune(r)
+			}
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			raw = append(raw, value.String())
+			value.Reset()
+		default:
+			value.WriteRune(r)
+		}
+	}
+	raw = append(raw, value.String())
+
+	for _, field := range raw {
+		field = strings.TrimPrefix(field, " ")
+
+		key, val, found := strings.Cut(field, ": ")
+		if !found {
+			// an empty value at the end of a line may have lost its trailing space
+			if !strings.HasSuffix(field, ":") {
+				return nil, false
+			}
+			key = strings.TrimSuffix(field, ":")
+		}
+
+		fields[key] = val
+	}
+
+	return fields, true
+}
+
+var wordEscaper = strings.NewReplacer(`\`, `\\`, " ", `\ `)
+
+// JoinWords joins values into a space separated list, escaping spaces and
+// backslashes inside the values. The list still has to be escaped like any
+// other value.
+func JoinWords(values []string) string {
+	escaped := make([]string, 0, len(values))
+	for _, value := range values {
+		escaped = append(escaped, wordEscaper.Replace(value))
+	}
+
+	return strings.Join(escaped, " ")
+}
+
+// SplitWords splits a list written by JoinWords back into its values. Lists
+// of values without spaces or backslashes read the same as plain space
+// separated ones.
+func SplitWords(s string) []string {
+	var values []string
+
+	var value strings.Builder
+	escaped, started := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			value.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, started = true, true
+		case r == ' ':
+			if started {
+				values = append(values, value.String())
+				value.Reset()
+				started = false
+			}
+		default:
+			value.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		values = append(values, value.String())
+	}
+
+	return values
+}
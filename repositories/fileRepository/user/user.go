@@ -73,7 +73,8 @@ func TextDeserializer(userStr string) (models.User, error) {
 	userStr = strings.TrimRight(userStr, "\n")
 	fields := strings.Split(userStr, ",")
 
-	if len(fields) != 4 {
+	// the calendar field was added later, so older records only have four fields
+	if len(fields) != 4 && len(fields) != 5 {
 		return models.User{}, fmt.Errorf("invalid user string: %s", userStr)
 	}
 
@@ -82,6 +83,11 @@ func TextDeserializer(userStr string) (models.User, error) {
 	email := fields[2][8:]
 	password := fields[3][11:]
 
+	var calendar string
+	if len(fields) == 5 {
+		calendar = fields[4][11:]
+	}
+
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		return models.User{}, fmt.Errorf("invalid id: %s", idStr)
@@ -92,6 +98,7 @@ func TextDeserializer(userStr string) (models.User, error) {
 		Name:     name,
 		Email:    email,
 		Password: password,
+		Calendar: calendar,
 	}
 	fmt.Println(user)
 	return user, nil
@@ -115,27 +122,57 @@ func (f FileStore) writeUserToFile(user models.User) error {
 	}
 	defer file.Close()
 
-	var data []byte
+	data, err := f.serializeUser(user)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.WriteString(file, string(data))
+	if err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	fmt.Println("numberOfWrittenBytes", n)
+
+	return nil
+}
+
+func (f FileStore) serializeUser(user models.User) ([]byte, error) {
 	switch f.serializationMode {
 	case consts.TextSerializationMode:
-		data = []byte(fmt.Sprintf("ID: %d, Name: %s, Email: %s, Password: %s\n", user.ID, user.Name,
-			user.Email, user.Password))
+		line := fmt.Sprintf("ID: %d, Name: %s, Email: %s, Password: %s", user.ID, user.Name,
+			user.Email, user.Password)
+		if user.Calendar != "" {
+			line += fmt.Sprintf(", Calendar: %s", user.Calendar)
+		}
+
+		return []byte(line + "\n"), nil
 	case consts.JsonSerializationMode:
-		data, err = json.Marshal(user)
+		data, err := json.Marshal(user)
 		if err != nil {
-			return fmt.Errorf("can't marshal user struct to json: %w", err)
+			return nil, fmt.Errorf("can't marshal user struct to json: %w", err)
 		}
-		data = append(data, '\n')
+
+		return append(data, '\n'), nil
 	default:
-		return fmt.Errorf("invalid serialization mode")
+		return nil, fmt.Errorf("invalid serialization mode")
 	}
+}
 
-	n, err := io.WriteString(file, string(data))
-	if err != nil {
-		return fmt.Errorf("can't write to the file: %w", err)
+// writeUsersToFile replaces the whole file with the given users.
+func (f FileStore) writeUsersToFile(users []models.User) error {
+	var data []byte
+	for _, user := range users {
+		line, err := f.serializeUser(user)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
 	}
 
-	fmt.Println("numberOfWrittenBytes", n)
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
 
 	return nil
 }
@@ -208,3 +245,29 @@ func (f FileStore) ListUsers() ([]models.User, error) {
 
 	return users, nil
 }
+
+func (f FileStore) UpdateUser(user models.User) (models.User, error) {
+
+	users, err := f.ListUsers()
+	if err != nil {
+		return models.User{}, err
+	}
+
+	found := false
+	for i := range users {
+		if users[i].ID == user.ID {
+			users[i] = user
+			found = true
+		}
+	}
+
+	if !found {
+		return models.User{}, fmt.Errorf("user %d not found", user.ID)
+	}
+
+	if err := f.writeUsersToFile(users); err != nil {
+		return models.User{}, fmt.Errorf("can't write users to file: %v", err)
+	}
+
+	return user, nil
+}
@@ -280,3 +280,44 @@ func TestListUsers(t *testing.T) {
 		t.Errorf("result does not match expected users: got %v, want %v", result, users)
 	}
 }
+
+func TestUpdateUser(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	fs := FileStore{Filepath: tmpfile.Name(), serializationMode: consts.TextSerializationMode}
+
+	users := []models.User{
+		{ID: 1, Name: "Alice", Email: "alice@example.com", Password: "123456"},
+		{ID: 2, Name: "Bob", Email: "bob@example.com", Password: "654321"},
+	}
+	for _, user := range users {
+		err = fs.writeUserToFile(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated := users[1]
+	updated.Calendar = consts.JalaliCalendar
+	if _, err := fs.UpdateUser(updated); err != nil {
+		t.Errorf("UpdateUser failed: %v", err)
+	}
+
+	result, err := fs.ListUsers()
+	if err != nil {
+		t.Errorf("ListUsers failed: %v", err)
+	}
+
+	expected := []models.User{users[0], updated}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("result does not match expected users: got %v, want %v", result, expected)
+	}
+
+	if _, err := fs.UpdateUser(models.User{ID: 9}); err == nil {
+		t.Errorf("UpdateUser should fail for a missing user")
+	}
+}
@@ -0,0 +1,248 @@
+package view
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// no view was saved yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) ViewDeserializer(pData []string) []models.View {
+	var views []models.View
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			view, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			views = append(views, view)
+		case consts.JsonSerializationMode:
+			view, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			views = append(views, view)
+		}
+	}
+
+	return views
+}
+
+func TextDeserializer(viewStr string) (models.View, error) {
+	fields, ok := textrecord.Fields(viewStr)
+	if !ok {
+		return models.View{}, fmt.Errorf("invalid view string: %s", viewStr)
+	}
+
+	for _, key := range []string{"id", "userID", "name"} {
+		if _, ok := fields[key]; !ok {
+			return models.View{}, fmt.Errorf("invalid view string: %s", viewStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.View{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	userID, err := strconv.Atoi(fields["userID"])
+	if err != nil {
+		return models.View{}, fmt.Errorf("invalid userID: %s", fields["userID"])
+	}
+
+	return models.View{
+		ID:     id,
+		UserID: userID,
+		Name:   fields["name"],
+		Query:  fields["query"],
+		Sort:   fields["sort"],
+		Group:  fields["group"],
+	}, nil
+}
+
+func JsonDeserializer(viewStr string) (models.View, error) {
+	var view models.View
+
+	err := json.Unmarshal([]byte(viewStr), &view)
+	if err != nil {
+		return models.View{}, fmt.Errorf("invalid json: %s", viewStr)
+	}
+
+	return view, nil
+}
+
+func (f FileStore) serializeView(view models.View) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		line := fmt.Sprintf("id: %d, userID: %d, name: %s", view.ID, view.UserID, textrecord.Escape(view.Name))
+		if view.Query != "" {
+			line += ", query: " + textrecord.Escape(view.Query)
+		}
+		if view.Sort != "" {
+			line += ", sort: " + textrecord.Escape(view.Sort)
+		}
+		if view.Group != "" {
+			line += ", group: " + textrecord.Escape(view.Group)
+		}
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(view)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal view struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeViewsToFile(views []models.View) error {
+	var data []byte
+	for _, view := range views {
+		line, err := f.serializeView(view)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) listViews() ([]models.View, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.ViewDeserializer(lines), nil
+}
+
+func (f FileStore) CreateNewView(view models.View) (models.View, error) {
+	views, err := f.listViews()
+	if err != nil {
+		return models.View{}, err
+	}
+
+	view.ID = 1
+	for _, stored := range views {
+		if stored.ID >= view.ID {
+			view.ID = stored.ID + 1
+		}
+	}
+
+	if err := f.writeViewsToFile(append(views, view)); err != nil {
+		return models.View{}, fmt.Errorf("can't write view to file: %v", err)
+	}
+
+	return view, nil
+}
+
+func (f FileStore) ListUserViews(userID int) ([]models.View, error) {
+	views, err := f.listViews()
+	if err != nil {
+		return nil, err
+	}
+
+	var userViews []models.View
+	for _, view := range views {
+		if view.UserID == userID {
+			userViews = append(userViews, view)
+		}
+	}
+
+	return userViews, nil
+}
+
+func (f FileStore) UpdateView(view models.View) (models.View, error) {
+	views, err := f.listViews()
+	if err != nil {
+		return models.View{}, err
+	}
+
+	found := false
+	for i := range views {
+		if views[i].ID == view.ID {
+			views[i] = view
+			found = true
+		}
+	}
+
+	if !found {
+		return models.View{}, fmt.Errorf("view %d not found", view.ID)
+	}
+
+	if err := f.writeViewsToFile(views); err != nil {
+		return models.View{}, fmt.Errorf("can't write views to file: %v", err)
+	}
+
+	return view, nil
+}
+
+func (f FileStore) DeleteView(id int) error {
+	views, err := f.listViews()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.View
+	for _, view := range views {
+		if view.ID != id {
+			kept = append(kept, view)
+		}
+	}
+
+	if len(kept) == len(views) {
+		return fmt.Errorf("view %d not found", id)
+	}
+
+	if err := f.writeViewsToFile(kept); err != nil {
+		return fmt.Errorf("can't write views to file: %v", err)
+	}
+
+	return nil
+}
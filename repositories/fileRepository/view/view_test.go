@@ -0,0 +1,52 @@
+package view
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestViewStore(t *testing.T) {
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "view.txt"), mode)
+
+			views := []models.View{
+				{UserID: 3, Name: "work", Query: `category:work title~"deploy, ship"`, Sort: "due", Group: models.PriorityGroup},
+				{UserID: 4, Name: "home", Query: "category:home"},
+				{UserID: 3, Name: "someday", Query: "tag:someday"},
+			}
+			for i := range views {
+				created, err := fs.CreateNewView(views[i])
+				if err != nil {
+					t.Fatalf("CreateNewView failed: %v", err)
+				}
+				views[i] = created
+			}
+
+			views[0].Sort = "priority"
+			if _, err := fs.UpdateView(views[0]); err != nil {
+				t.Fatalf("UpdateView failed: %v", err)
+			}
+
+			if err := fs.DeleteView(views[2].ID); err != nil {
+				t.Fatalf("DeleteView failed: %v", err)
+			}
+
+			result, err := fs.ListUserViews(3)
+			if err != nil {
+				t.Fatalf("ListUserViews failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, views[:1]) {
+				t.Errorf("views do not match: got %v, want %v", result, views[:1])
+			}
+
+			if err := fs.DeleteView(9); err == nil {
+				t.Errorf("DeleteView should fail for a missing view")
+			}
+		})
+	}
+}
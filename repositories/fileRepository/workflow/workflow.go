@@ -0,0 +1,261 @@
+package workflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/repositories/fileRepository/textrecord"
+)
+
+type FileStore struct {
+	Filepath          string
+	serializationMode string
+}
+
+func New(path, serializationMode string) FileStore {
+	return FileStore{Filepath: path, serializationMode: serializationMode}
+}
+
+func (f FileStore) Load() ([]string, error) {
+	var pData []string
+
+	file, err := os.Open(f.Filepath)
+	if os.IsNotExist(err) {
+		// no workflow was saved yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pData = append(pData, scanner.Text())
+	}
+
+	return pData, scanner.Err()
+}
+
+func (f FileStore) WorkflowDeserializer(pData []string) []models.Workflow {
+	var workflows []models.Workflow
+
+	for _, i := range pData {
+		switch f.serializationMode {
+		case consts.TextSerializationMode:
+			workflow, err := TextDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			workflows = append(workflows, workflow)
+		case consts.JsonSerializationMode:
+			workflow, err := JsonDeserializer(i)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			workflows = append(workflows, workflow)
+		}
+	}
+
+	return workflows
+}
+
+// TextDeserializer parses a text line. The statuses and transitions are kept
+// as escaped JSON since they nest records.
+func TextDeserializer(workflowStr string) (models.Workflow, error) {
+	fields, ok := textrecord.Fields(workflowStr)
+	if !ok {
+		return models.Workflow{}, fmt.Errorf("invalid workflow string: %s", workflowStr)
+	}
+
+	for _, key := range []string{"id", "userID", "statuses"} {
+		if _, ok := fields[key]; !ok {
+			return models.Workflow{}, fmt.Errorf("invalid workflow string: %s", workflowStr)
+		}
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return models.Workflow{}, fmt.Errorf("invalid id: %s", fields["id"])
+	}
+
+	userID, err := strconv.Atoi(fields["userID"])
+	if err != nil {
+		return models.Workflow{}, fmt.Errorf("invalid userID: %s", fields["userID"])
+	}
+
+	workflow := models.Workflow{
+		ID:     id,
+		UserID: userID,
+	}
+
+	if err := json.Unmarshal([]byte(fields["statuses"]), &workflow.Statuses); err != nil {
+		return models.Workflow{}, fmt.Errorf("invalid statuses: %s", fields["statuses"])
+	}
+
+	if transitions, ok := fields["transitions"]; ok {
+		if err := json.Unmarshal([]byte(transitions), &workflow.Transitions); err != nil {
+			return models.Workflow{}, fmt.Errorf("invalid transitions: %s", transitions)
+		}
+	}
+
+	return workflow, nil
+}
+
+func JsonDeserializer(workflowStr string) (models.Workflow, error) {
+	var workflow models.Workflow
+
+	err := json.Unmarshal([]byte(workflowStr), &workflow)
+	if err != nil {
+		return models.Workflow{}, fmt.Errorf("invalid json: %s", workflowStr)
+	}
+
+	return workflow, nil
+}
+
+func (f FileStore) serializeWorkflow(workflow models.Workflow) ([]byte, error) {
+	switch f.serializationMode {
+	case consts.TextSerializationMode:
+		statuses, err := json.Marshal(workflow.Statuses)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal workflow statuses to json: %w", err)
+		}
+
+		line := fmt.Sprintf("id: %d, userID: %d, statuses: %s", workflow.ID, workflow.UserID, textrecord.Escape(string(statuses)))
+		if len(workflow.Transitions) > 0 {
+			transitions, err := json.Marshal(workflow.Transitions)
+			if err != nil {
+				return nil, fmt.Errorf("can't marshal workflow transitions to json: %w", err)
+			}
+			line += ", transitions: " + textrecord.Escape(string(transitions))
+		}
+
+		return []byte(line + "\n"), nil
+	case consts.JsonSerializationMode:
+		data, err := json.Marshal(workflow)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal workflow struct to json: %w", err)
+		}
+
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid serialization mode")
+	}
+}
+
+func (f FileStore) writeWorkflowsToFile(workflows []models.Workflow) error {
+	var data []byte
+	for _, workflow := range workflows {
+		line, err := f.serializeWorkflow(workflow)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	if err := os.WriteFile(f.Filepath, data, 0644); err != nil {
+		return fmt.Errorf("can't write to the file: %w", err)
+	}
+
+	return nil
+}
+
+func (f FileStore) listWorkflows() ([]models.Workflow, error) {
+	lines, err := f.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't read from file: %w", err)
+	}
+
+	return f.WorkflowDeserializer(lines), nil
+}
+
+func (f FileStore) CreateNewWorkflow(workflow models.Workflow) (models.Workflow, error) {
+	workflows, err := f.listWorkflows()
+	if err != nil {
+		return models.Workflow{}, err
+	}
+
+	workflow.ID = 1
+	for _, stored := range workflows {
+		if stored.ID >= workflow.ID {
+			workflow.ID = stored.ID + 1
+		}
+	}
+
+	if err := f.writeWorkflowsToFile(append(workflows, workflow)); err != nil {
+		return models.Workflow{}, fmt.Errorf("can't write workflow to file: %v", err)
+	}
+
+	return workflow, nil
+}
+
+func (f FileStore) ListUserWorkflows(userID int) ([]models.Workflow, error) {
+	workflows, err := f.listWorkflows()
+	if err != nil {
+		return nil, err
+	}
+
+	var userWorkflows []models.Workflow
+	for _, workflow := range workflows {
+		if workflow.UserID == userID {
+			userWorkflows = append(userWorkflows, workflow)
+		}
+	}
+
+	return userWorkflows, nil
+}
+
+func (f FileStore) UpdateWorkflow(workflow models.Workflow) (models.Workflow, error) {
+	workflows, err := f.listWorkflows()
+	if err != nil {
+		return models.Workflow{}, err
+	}
+
+	found := false
+	for i := range workflows {
+		if workflows[i].ID == workflow.ID {
+			workflows[i] = workflow
+			found = true
+		}
+	}
+
+	if !found {
+		return models.Workflow{}, fmt.Errorf("workflow %d not found", workflow.ID)
+	}
+
+	if err := f.writeWorkflowsToFile(workflows); err != nil {
+		return models.Workflow{}, fmt.Errorf("can't write workflows to file: %v", err)
+	}
+
+	return workflow, nil
+}
+
+func (f FileStore) DeleteWorkflow(id int) error {
+	workflows, err := f.listWorkflows()
+	if err != nil {
+		return err
+	}
+
+	var kept []models.Workflow
+	for _, workflow := range workflows {
+		if workflow.ID != id {
+			kept = append(kept, workflow)
+		}
+	}
+
+	if len(kept) == len(workflows) {
+		return fmt.Errorf("workflow %d not found", id)
+	}
+
+	if err := f.writeWorkflowsToFile(kept); err != nil {
+		return fmt.Errorf("can't write workflows to file: %v", err)
+	}
+
+	return nil
+}
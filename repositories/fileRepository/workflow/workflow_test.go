@@ -0,0 +1,52 @@
+package workflow
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"todo-cli-refactor/consts"
+	"todo-cli-refactor/models"
+)
+
+func TestWorkflowStore(t *testing.T) {
+	for _, mode := range []string{consts.TextSerializationMode, consts.JsonSerializationMode} {
+		t.Run(mode, func(t *testing.T) {
+			fs := New(filepath.Join(t.TempDir(), "workflow.txt"), mode)
+
+			workflows := []models.Workflow{
+				{UserID: 3, Statuses: []models.WorkflowStatus{{Name: "todo"}, {Name: "doing", WIPLimit: 2}, {Name: "done", Done: true}},
+					Transitions: map[string][]string{"todo": {"doing"}, "doing": {"todo", "done"}}},
+				{UserID: 4, Statuses: models.DefaultWorkflow().Statuses},
+			}
+			for i := range workflows {
+				created, err := fs.CreateNewWorkflow(workflows[i])
+				if err != nil {
+					t.Fatalf("CreateNewWorkflow failed: %v", err)
+				}
+				workflows[i] = created
+			}
+
+			workflows[0].Statuses[1].WIPLimit = 3
+			if _, err := fs.UpdateWorkflow(workflows[0]); err != nil {
+				t.Fatalf("UpdateWorkflow failed: %v", err)
+			}
+
+			if err := fs.DeleteWorkflow(workflows[1].ID); err != nil {
+				t.Fatalf("DeleteWorkflow failed: %v", err)
+			}
+
+			result, err := fs.ListUserWorkflows(3)
+			if err != nil {
+				t.Fatalf("ListUserWorkflows failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, workflows[:1]) {
+				t.Errorf("workflows do not match: got %v, want %v", result, workflows[:1])
+			}
+
+			if result, _ := fs.ListUserWorkflows(4); len(result) != 0 {
+				t.Errorf("expected the deleted workflow to be gone, got %v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package attachment
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/services/task"
+)
+
+type ServiceRepository interface {
+	CreateNewAttachment(a models.Attachment) (models.Attachment, error)
+	ListAttachments() ([]models.Attachment, error)
+	DeleteAttachment(id int) error
+}
+
+// BlobStore keeps attachment contents keyed by their SHA-256 hash.
+type BlobStore interface {
+	Put(r io.Reader) (string, int64, error)
+	Open(hash string) (io.ReadCloser, error)
+	Delete(hash string) error
+	List() ([]string, error)
+}
+
+type TaskRepository interface {
+	ListUserTasks(userID int) ([]models.Task, error)
+	// ListCategories looks up the roles users have on shared tasks.
+	ListCategories() ([]models.Category, error)
+}
+
+type Service struct {
+	repository ServiceRepository
+	blobs      BlobStore
+	tasks      TaskRepository
+	// quota is the number of bytes each user may store. Content attached
+	// more than once only counts once.
+	quota int64
+	now   func() time.Time
+}
+
+func NewService(repo ServiceRepository, blobs BlobStore, tasks TaskRepository, quota int64) Service {
+	return Service{
+		repository: repo,
+		blobs:      blobs,
+		tasks:      tasks,
+		quota:      quota,
+		now:        time.Now,
+	}
+}
+
+type UploadRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+	Name                string
+	Content             io.Reader
+}
+
+type UploadResponse struct {
+	Attachment models.Attachment
+	// Usage is the number of bytes the user stores after the upload.
+	Usage int64
+}
+
+func (s Service) Upload(req UploadRequest) (UploadResponse, error) {
+
+	name := filepath.Base(strings.TrimSpace(req.Name))
+	if name == "." || name == string(filepath.Separator) {
+		return UploadResponse{}, fmt.Errorf("an attachment needs a file name")
+	}
+
+	if fErr := s.checkTask(req.AuthenticatedUserID, req.TaskID, models.EditorRole); fErr != nil {
+		return UploadResponse{}, fErr
+	}
+
+	// nothing larger than the whole quota can ever fit, stop reading there
+	hash, size, pErr := s.blobs.Put(&quotaReader{r: req.Content, left: s.quota})
+	if pErr != nil {
+		return UploadResponse{}, fmt.Errorf("can't store attachment: %v", pErr)
+	}
+
+	attachments, lErr := s.repository.ListAttachments()
+	if lErr != nil {
+		return UploadResponse{}, fmt.Errorf("can't list attachments: %v", lErr)
+	}
+
+	usage := usageOf(attachments, req.AuthenticatedUserID)
+	if !storesHash(attachments, req.AuthenticatedUserID, hash) {
+		if usage+size > s.quota {
+			if !storesHash(attachments, 0, hash) {
+				if dErr := s.blobs.Delete(hash); dErr != nil {
+					return UploadResponse{}, dErr
+				}
+			}
+
+			return UploadResponse{}, fmt.Errorf("attachment of %d bytes exceeds the quota, %d of %d bytes are used",
+				size, usage, s.quota)
+		}
+
+		usage += size
+	}
+
+	attachment, cErr := s.repository.CreateNewAttachment(models.Attachment{
+		TaskID:    req.TaskID,
+		UserID:    req.AuthenticatedUserID,
+		Name:      name,
+		Size:      size,
+		Hash:      hash,
+		CreatedAt: s.now().UTC().Truncate(time.Second),
+	})
+	if cErr != nil {
+		return UploadResponse{}, fmt.Errorf("can't create new attachment: %v", cErr)
+	}
+
+	return UploadResponse{Attachment: attachment, Usage: usage}, nil
+}
+
+type ListRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+}
+
+type ListResponse struct {
+	Attachments []models.Attachment
+	// Usage and Quota are the bytes the user stores and may store.
+	Usage int64
+	Quota int64
+}
+
+// List lists the attachments of a task, whoever uploaded them, to anyone who
+// can see the task.
+func (s Service) List(req ListRequest) (ListResponse, error) {
+
+	if fErr := s.checkTask(req.AuthenticatedUserID, req.TaskID, models.ViewerRole); fErr != nil {
+		return ListResponse{}, fErr
+	}
+
+	attachments, lErr := s.repository.ListAttachments()
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list attachments: %v", lErr)
+	}
+
+	var taskAttachments []models.Attachment
+	for _, attachment := range attachments {
+		if attachment.TaskID == req.TaskID {
+			taskAttachments = append(taskAttachments, attachment)
+		}
+	}
+
+	return ListResponse{
+		Attachments: taskAttachments,
+		Usage:       usageOf(attachments, req.AuthenticatedUserID),
+		Quota:       s.quota,
+	}, nil
+}
+
+type DownloadRequest struct {
+	AttachmentID        int
+	AuthenticatedUserID int
+}
+
+type DownloadResponse struct {
+	Attachment models.Attachment
+	// Content has to be closed by the caller.
+	Content io.ReadCloser `json:"-"`
+}
+
+func (s Service) Download(req DownloadRequest) (DownloadResponse, error) {
+
+	attachment, fErr := s.findAttachment(req.AuthenticatedUserID, req.AttachmentID)
+	if fErr != nil {
+		return DownloadResponse{}, fErr
+	}
+
+	content, oErr := s.blobs.Open(attachment.Hash)
+	if oErr != nil {
+		return DownloadResponse{}, fmt.Errorf("can't read attachment: %v", oErr)
+	}
+
+	return DownloadResponse{Attachment: attachment, Content: content}, nil
+}
+
+type RemoveRequest struct {
+	AttachmentID        int
+	AuthenticatedUserID int
+}
+
+type RemoveResponse struct {
+	Attachment models.Attachment
+}
+
+// Remove deletes an attachment, and its content when no other attachment
+// shares it. Editors of the task remove their own uploads, admins any.
+func (s Service) Remove(req RemoveRequest) (RemoveResponse, error) {
+
+	attachment, fErr := s.findAttachment(req.AuthenticatedUserID, req.AttachmentID)
+	if fErr != nil {
+		return RemoveResponse{}, fErr
+	}
+
+	if attachment.UserID == req.AuthenticatedUserID {
+		if rErr := s.checkTask(req.AuthenticatedUserID, attachment.TaskID, models.EditorRole); rErr != nil {
+			return RemoveResponse{}, rErr
+		}
+	} else if rErr := s.checkTask(req.AuthenticatedUserID, attachment.TaskID, models.AdminRole); rErr != nil {
+		return RemoveResponse{}, fmt.Errorf("attachment %d can only be removed by its uploader or an admin", attachment.ID)
+	}
+
+	if dErr := s.repository.DeleteAttachment(attachment.ID); dErr != nil {
+		return RemoveResponse{}, fmt.Errorf("can't delete attachment: %v", dErr)
+	}
+
+	attachments, lErr := s.repository.ListAttachments()
+	if lErr != nil {
+		return RemoveResponse{}, fmt.Errorf("can't list attachments: %v", lErr)
+	}
+
+	if !storesHash(attachments, 0, attachment.Hash) {
+		if dErr := s.blobs.Delete(attachment.Hash); dErr != nil {
+			return RemoveResponse{}, dErr
+		}
+	}
+
+	return RemoveResponse{Attachment: attachment}, nil
+}
+
+type CollectGarbageResponse struct {
+	// Removed is the number of blobs that were deleted.
+	Removed int
+}
+
+// CollectGarbage deletes the blobs no attachment refers to, which a failed
+// upload or an interrupted removal can leave behind.
+func (s Service) CollectGarbage() (CollectGarbageResponse, error) {
+
+	attachments, lErr := s.repository.ListAttachments()
+	if lErr != nil {
+		return CollectGarbageResponse{}, fmt.Errorf("can't list attachments: %v", lErr)
+	}
+
+	referenced := map[string]bool{}
+	for _, attachment := range attachments {
+		referenced[attachment.Hash] = true
+	}
+
+	hashes, bErr := s.blobs.List()
+	if bErr != nil {
+		return CollectGarbageResponse{}, fmt.Errorf("can't list blobs: %v", bErr)
+	}
+
+	var response CollectGarbageResponse
+	for _, hash := range hashes {
+		if referenced[hash] {
+			continue
+		}
+
+		if dErr := s.blobs.Delete(hash); dErr != nil {
+			return response, dErr
+		}
+		response.Removed++
+	}
+
+	return response, nil
+}
+
+// checkTask makes sure a user can see a task and has at least the needed
+// role on it.
+func (s Service) checkTask(userID, taskID int, need models.Role) error {
+	tasks, err := s.tasks.ListUserTasks(userID)
+	if err != nil {
+		return fmt.Errorf("can't list user tasks: %v", err)
+	}
+
+	for _, t := range tasks {
+		if t.ID != taskID {
+			continue
+		}
+
+		categories, err := s.tasks.ListCategories()
+		if err != nil {
+			return fmt.Errorf("can't list categories: %v", err)
+		}
+
+		if role := task.Role(t, categories, userID); !role.Allows(need) {
+			return fmt.Errorf("task %d is shared with you as %s, changing its attachments takes %s", taskID, role, need)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("task %d not found", taskID)
+}
+
+// findAttachment looks up an attachment on a task the user can see.
+func (s Service) findAttachment(userID, attachmentID int) (models.Attachment, error) {
+	attachments, err := s.repository.ListAttachments()
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("can't list attachments: %v", err)
+	}
+
+	for _, attachment := range attachments {
+		if attachment.ID != attachmentID {
+			continue
+		}
+
+		if cErr := s.checkTask(userID, attachment.TaskID, models.ViewerRole); cErr != nil {
+			return models.Attachment{}, fmt.Errorf("attachment %d not found", attachmentID)
+		}
+
+		return attachment, nil
+	}
+
+	return models.Attachment{}, fmt.Errorf("attachment %d not found", attachmentID)
+}
+
+// usageOf sums the size of the distinct contents a user stores.
+func usageOf(attachments []models.Attachment, userID int) int64 {
+	var usage int64
+
+	seen := map[string]bool{}
+	for _, attachment := range attachments {
+		if attachment.UserID != userID || seen[attachment.Hash] {
+			continue
+		}
+		seen[attachment.Hash] = true
+		usage += attachment.Size
+	}
+
+	return usage
+}
+
+// storesHash reports whether an attachment of userID refers to hash, or an
+// attachment of anyone when userID is zero.
+func storesHash(attachments []models.Attachment, userID int, hash string) bool {
+	for _, attachment := range attachments {
+		if attachment.Hash == hash && (userID == 0 || attachment.UserID == userID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quotaReader fails once more than left bytes were read.
+type quotaReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+
+	q.left -= int64(n)
+	if q.left < 0 {
+		return n, fmt.Errorf("attachment is larger than the quota")
+	}
+
+	return n, err
+}
@@ -0,0 +1,285 @@
+package attachment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"todo-cli-refactor/models"
+	category2 "todo-cli-refactor/services/category"
+)
+
+type mockRepository struct {
+	data map[int]models.Attachment
+}
+
+func (m mockRepository) CreateNewAttachment(attachment models.Attachment) (models.Attachment, error) {
+	attachment.ID = len(m.data) + 1
+
+	m.data[attachment.ID] = attachment
+
+	return attachment, nil
+}
+
+func (m mockRepository) ListAttachments() ([]models.Attachment, error) {
+	var attachments []models.Attachment
+
+	for _, attachment := range m.data {
+		attachments = append(attachments, attachment)
+	}
+
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+
+	return attachments, nil
+}
+
+func (m mockRepository) DeleteAttachment(id int) error {
+	if _, ok := m.data[id]; !ok {
+		return fmt.Errorf("attachment %d not found", id)
+	}
+
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockBlobStore struct {
+	blobs map[string]string
+}
+
+func (m mockBlobStore) Put(r io.Reader) (string, int64, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", 0, err
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	m.blobs[hash] = string(content)
+
+	return hash, int64(len(content)), nil
+}
+
+func (m mockBlobStore) Open(hash string) (io.ReadCloser, error) {
+	content, ok := m.blobs[hash]
+	if !ok {
+		return nil, fmt.Errorf("blob %s not found", hash)
+	}
+
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (m mockBlobStore) Delete(hash string) error {
+	delete(m.blobs, hash)
+
+	return nil
+}
+
+func (m mockBlobStore) List() ([]string, error) {
+	var hashes []string
+	for hash := range m.blobs {
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+type mockTaskRepository struct {
+	categories []models.Category
+}
+
+// ListUserTasks lists a task of the user's own, ID ten times the user ID,
+// and task 99 in category 1 when that's shared with them.
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	tasks := []models.Task{{ID: userID * 10, Title: "Fix the build", UserID: userID}}
+
+	if category2.Role(m.categories, 1, userID) != "" {
+		tasks = append(tasks, models.Task{ID: 99, Title: "Ship the release", UserID: 1, CategoryID: 1})
+	}
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) ListCategories() ([]models.Category, error) {
+	return m.categories, nil
+}
+
+func TestAttachments(t *testing.T) {
+	mr := mockRepository{data: map[int]models.Attachment{}}
+	mb := mockBlobStore{blobs: map[string]string{}}
+
+	s := NewService(mr, mb, mockTaskRepository{}, 10)
+
+	upload := func(userID int, name, content string) (UploadResponse, error) {
+		return s.Upload(UploadRequest{
+			TaskID:              userID * 10,
+			AuthenticatedUserID: userID,
+			Name:                name,
+			Content:             strings.NewReader(content),
+		})
+	}
+
+	t.Run("deduplicate", func(t *testing.T) {
+		first, err := upload(1, "logs/build.log", "failed")
+		if err != nil {
+			t.Fatalf("Upload failed: %v", err)
+		}
+
+		if first.Attachment.Name != "build.log" || first.Attachment.Size != 6 || first.Usage != 6 {
+			t.Errorf("unexpected attachment: %+v", first)
+		}
+
+		second, err := upload(1, "copy.log", "failed")
+		if err != nil {
+			t.Fatalf("uploading the same content again should not count against the quota: %v", err)
+		}
+
+		if second.Attachment.Hash != first.Attachment.Hash || second.Usage != 6 || len(mb.blobs) != 1 {
+			t.Errorf("content should be stored once: got %+v and %d blobs", second, len(mb.blobs))
+		}
+
+		if _, err := upload(2, "other.log", "failed"); err != nil {
+			t.Fatalf("Upload failed: %v", err)
+		}
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		if _, err := upload(1, "big.log", "too big"); err == nil {
+			t.Errorf("Upload should fail when the quota would be exceeded")
+		}
+
+		if len(mb.blobs) != 1 {
+			t.Errorf("a rejected upload should not keep its content: got %d blobs", len(mb.blobs))
+		}
+
+		if _, err := upload(1, "huge.log", strings.Repeat("x", 11)); err == nil {
+			t.Errorf("Upload should fail for content larger than the quota")
+		}
+	})
+
+	t.Run("ownership", func(t *testing.T) {
+		if _, err := s.Upload(UploadRequest{TaskID: 20, AuthenticatedUserID: 1, Name: "a.txt", Content: strings.NewReader("a")}); err == nil {
+			t.Errorf("Upload should fail for a task of another user")
+		}
+
+		if _, err := s.Download(DownloadRequest{AttachmentID: 3, AuthenticatedUserID: 1}); err == nil {
+			t.Errorf("Download should fail for an attachment of another user")
+		}
+	})
+
+	t.Run("download", func(t *testing.T) {
+		res, err := s.Download(DownloadRequest{AttachmentID: 1, AuthenticatedUserID: 1})
+		if err != nil {
+			t.Fatalf("Download failed: %v", err)
+		}
+		defer res.Content.Close()
+
+		if content, _ := io.ReadAll(res.Content); string(content) != "failed" {
+			t.Errorf("content does not match: got %q", content)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		res, err := s.List(ListRequest{TaskID: 10, AuthenticatedUserID: 1})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+
+		if len(res.Attachments) != 2 || res.Usage != 6 || res.Quota != 10 {
+			t.Errorf("unexpected listing: %+v", res)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		for _, id := range []int{1, 2} {
+			if _, err := s.Remove(RemoveRequest{AttachmentID: id, AuthenticatedUserID: 1}); err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+		}
+
+		if len(mb.blobs) != 1 {
+			t.Errorf("content still attached by another user should be kept: got %d blobs", len(mb.blobs))
+		}
+
+		if _, err := s.Remove(RemoveRequest{AttachmentID: 3, AuthenticatedUserID: 2}); err != nil {
+			t.Fatalf("Remove failed: %v", err)
+		}
+
+		if len(mb.blobs) != 0 {
+			t.Errorf("unreferenced content should be deleted: got %d blobs", len(mb.blobs))
+		}
+	})
+
+	t.Run("collect garbage", func(t *testing.T) {
+		if _, err := upload(1, "kept.log", "kept"); err != nil {
+			t.Fatalf("Upload failed: %v", err)
+		}
+		mb.blobs["leftover"] = "from a failed upload"
+
+		res, err := s.CollectGarbage()
+		if err != nil {
+			t.Fatalf("CollectGarbage failed: %v", err)
+		}
+
+		if res.Removed != 1 || len(mb.blobs) != 1 {
+			t.Errorf("only the leftover blob should be removed: got %+v, %d blobs left", res, len(mb.blobs))
+		}
+	})
+}
+
+func TestSharedAttachments(t *testing.T) {
+	mr := mockRepository{data: map[int]models.Attachment{}}
+	mb := mockBlobStore{blobs: map[string]string{}}
+
+	tasks := mockTaskRepository{categories: []models.Category{{
+		ID:     1,
+		Title:  "Release",
+		UserID: 1,
+		Shares: []models.Share{{UserID: 2, Role: models.EditorRole}, {UserID: 3, Role: models.ViewerRole}},
+	}}}
+
+	s := NewService(mr, mb, tasks, 100)
+
+	upload := func(userID int, content string) error {
+		_, err := s.Upload(UploadRequest{TaskID: 99, AuthenticatedUserID: userID, Name: "notes.txt", Content: strings.NewReader(content)})
+		return err
+	}
+
+	if err := upload(1, "owner"); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if err := upload(2, "editor"); err != nil {
+		t.Fatalf("editors should upload to shared tasks: %v", err)
+	}
+	if err := upload(3, "viewer"); err == nil {
+		t.Errorf("viewers should not upload to shared tasks")
+	}
+	if err := upload(4, "stranger"); err == nil {
+		t.Errorf("Upload should fail for tasks that aren't shared")
+	}
+
+	listed, err := s.List(ListRequest{TaskID: 99, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listed.Attachments) != 2 || listed.Usage != 0 {
+		t.Errorf("viewers should see every attachment of the task: got %+v", listed)
+	}
+
+	if _, err := s.Download(DownloadRequest{AttachmentID: 2, AuthenticatedUserID: 3}); err != nil {
+		t.Errorf("viewers should download attachments of shared tasks: %v", err)
+	}
+	if _, err := s.Download(DownloadRequest{AttachmentID: 2, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("Download should fail for tasks that aren't shared")
+	}
+
+	if _, err := s.Remove(RemoveRequest{AttachmentID: 1, AuthenticatedUserID: 2}); err == nil {
+		t.Errorf("editors should not remove the uploads of others")
+	}
+	if _, err := s.Remove(RemoveRequest{AttachmentID: 2, AuthenticatedUserID: 1}); err != nil {
+		t.Errorf("the owner should remove any upload: %v", err)
+	}
+}
@@ -3,10 +3,14 @@ package category
 import (
 	"fmt"
 	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/color"
 )
 
 type ServiceRepository interface {
 	CreateNewCategory(c models.Category) (models.Category, error)
+	ListCategories() ([]models.Category, error)
+	ListUserCategories(userID int) ([]models.Category, error)
+	UpdateCategory(c models.Category) (models.Category, error)
 }
 
 type Service struct {
@@ -20,8 +24,13 @@ func NewService(repo ServiceRepository) Service {
 }
 
 type CreateRequest struct {
-	Title               string
-	Color               string
+	Title string
+	// Color is a palette name or a hex code, see the color package.
+	Color string
+	// ParentID nests the new category in another category of the user.
+	ParentID int
+	// Fields are the custom fields the tasks of the category carry.
+	Fields              []models.FieldDef
 	AuthenticatedUserID int
 }
 
@@ -31,10 +40,31 @@ type CreateResponse struct {
 
 func (c Service) Create(req CreateRequest) (CreateResponse, error) {
 
+	categoryColor, nErr := color.Normalize(req.Color)
+	if nErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new category: %v", nErr)
+	}
+
+	categories, lErr := c.repository.ListUserCategories(req.AuthenticatedUserID)
+	if lErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't list user categories: %v", lErr)
+	}
+
+	if vErr := validateParent(categories, 0, req.ParentID); vErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new category: %v", vErr)
+	}
+
+	fields, fErr := validateFields(categories, 0, req.Fields)
+	if fErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new category: %v", fErr)
+	}
+
 	createdCategory, cErr := c.repository.CreateNewCategory(models.Category{
-		Title:  req.Title,
-		Color:  req.Color, // Added the color field to the category struct
-		UserID: req.AuthenticatedUserID,
+		Title:    req.Title,
+		Color:    categoryColor,
+		UserID:   req.AuthenticatedUserID,
+		ParentID: req.ParentID,
+		Fields:   fields,
 	})
 	if cErr != nil {
 		return CreateResponse{}, fmt.Errorf("can't create new category: %v", cErr)
@@ -42,3 +72,93 @@ func (c Service) Create(req CreateRequest) (CreateResponse, error) {
 
 	return CreateResponse{Category: createdCategory}, nil
 }
+
+type ListRequest struct {
+	AuthenticatedUserID int
+}
+
+type ListResponse struct {
+	Categories []models.Category
+	// Roles holds the role the user has on the categories shared with them,
+	// keyed by category ID.
+	Roles map[int]models.Role
+}
+
+// List returns the categories of a user that aren't in the trash, followed
+// by the ones other users share with them.
+func (c Service) List(req ListRequest) (ListResponse, error) {
+
+	categories, lErr := c.repository.ListCategories()
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list categories: %v", lErr)
+	}
+
+	response := ListResponse{}
+	var shared []models.Category
+	for _, category := range categories {
+		if category.DeletedAt != nil {
+			continue
+		}
+
+		if category.UserID == req.AuthenticatedUserID {
+			response.Categories = append(response.Categories, category)
+		} else if role := Role(categories, category.ID, req.AuthenticatedUserID); role != "" {
+			shared = append(shared, category)
+			if response.Roles == nil {
+				response.Roles = map[int]models.Role{}
+			}
+			response.Roles[category.ID] = role
+		}
+	}
+	response.Categories = append(response.Categories, shared...)
+
+	return response, nil
+}
+
+type MoveRequest struct {
+	CategoryID int
+	// ParentID is the category to nest the category in, zero moves it to the
+	// top level.
+	ParentID            int
+	AuthenticatedUserID int
+}
+
+type MoveResponse struct {
+	Category models.Category
+	// Subcategories are the categories that moved along, nested in it.
+	Subcategories []models.Category
+}
+
+// Move nests a category, along with its subcategories, in another category.
+// A category can't be moved into its own subtree.
+func (c Service) Move(req MoveRequest) (MoveResponse, error) {
+
+	categories, lErr := c.repository.ListUserCategories(req.AuthenticatedUserID)
+	if lErr != nil {
+		return MoveResponse{}, fmt.Errorf("can't list user categories: %v", lErr)
+	}
+
+	category, fErr := findLive(categories, req.CategoryID)
+	if fErr != nil {
+		return MoveResponse{}, fErr
+	}
+
+	if vErr := validateParent(categories, category.ID, req.ParentID); vErr != nil {
+		return MoveResponse{}, fmt.Errorf("can't move category: %v", vErr)
+	}
+
+	category.ParentID = req.ParentID
+	moved, uErr := c.repository.UpdateCategory(category)
+	if uErr != nil {
+		return MoveResponse{}, fmt.Errorf("can't update category: %v", uErr)
+	}
+
+	var subcategories []models.Category
+	for _, sub := range Subtree(categories, category.ID)[1:] {
+		if sub.DeletedAt == nil {
+			subcategories = append(subcategories, sub)
+		}
+	}
+
+	return MoveResponse{Category: moved, Subcategories: subcategories}, nil
+}
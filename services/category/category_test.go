@@ -2,7 +2,9 @@ package category
 
 import (
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 	"todo-cli-refactor/models"
 )
 
@@ -18,6 +20,38 @@ func (m mockRepository) CreateNewCategory(c models.Category) (models.Category, e
 	return c, nil
 }
 
+func (m mockRepository) ListCategories() ([]models.Category, error) {
+	var categories []models.Category
+
+	for _, c := range m.data {
+		categories = append(categories, c)
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+
+	return categories, nil
+}
+
+func (m mockRepository) ListUserCategories(userID int) ([]models.Category, error) {
+	var categories []models.Category
+
+	for _, c := range m.data {
+		if c.UserID == userID {
+			categories = append(categories, c)
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+
+	return categories, nil
+}
+
+func (m mockRepository) UpdateCategory(c models.Category) (models.Category, error) {
+	m.data[c.ID] = c
+
+	return c, nil
+}
+
 func TestCreate(t *testing.T) {
 	mr := mockRepository{
 		data: map[int]models.Category{
@@ -50,3 +84,166 @@ func TestCreate(t *testing.T) {
 		t.Errorf("response does not match expected data : got %v , want %v ", res.Category, expected)
 	}
 }
+
+func TestCreateColor(t *testing.T) {
+	s := NewService(mockRepository{data: map[int]models.Category{}})
+
+	res, err := s.Create(CreateRequest{Title: "Work", Color: "#F80", AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if res.Category.Color != "#ff8800" {
+		t.Errorf("got color %q, want #ff8800", res.Category.Color)
+	}
+
+	if _, err := s.Create(CreateRequest{Title: "Home", Color: "sky", AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Create should fail for an unknown color")
+	}
+}
+
+func TestList(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	mr := mockRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Work", Color: "red", UserID: 3},
+			2: {ID: 2, Title: "Home", Color: "blue", UserID: 3, DeletedAt: &deletedAt},
+			3: {ID: 3, Title: "Hobby", Color: "green", UserID: 5},
+		},
+	}
+
+	s := NewService(mr)
+
+	res, err := s.List(ListRequest{AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	expected := []models.Category{mr.data[1]}
+	if !reflect.DeepEqual(res.Categories, expected) {
+		t.Errorf("response does not match expected data: got %v, want %v", res.Categories, expected)
+	}
+}
+
+func TestCreateNested(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	mr := mockRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Work", UserID: 3},
+			2: {ID: 2, Title: "Home", UserID: 3, DeletedAt: &deletedAt},
+			3: {ID: 3, Title: "Hobby", UserID: 5},
+		},
+	}
+
+	s := NewService(mr)
+
+	res, err := s.Create(CreateRequest{Title: "Clients", ParentID: 1, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if res.Category.ParentID != 1 {
+		t.Errorf("expected the category nested in 1, got %v", res.Category)
+	}
+
+	for _, parentID := range []int{2, 3, 9} {
+		if _, err := s.Create(CreateRequest{Title: "Clients", ParentID: parentID, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("Create should fail for parent %d", parentID)
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Work", UserID: 3},
+			2: {ID: 2, Title: "Clients", UserID: 3, ParentID: 1},
+			3: {ID: 3, Title: "Acme", UserID: 3, ParentID: 2},
+			4: {ID: 4, Title: "Home", UserID: 3},
+		},
+	}
+
+	s := NewService(mr)
+
+	for _, parentID := range []int{1, 2, 3} {
+		if _, err := s.Move(MoveRequest{CategoryID: 1, ParentID: parentID, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("Move should refuse to nest a category in its subtree, parent %d", parentID)
+		}
+	}
+
+	res, err := s.Move(MoveRequest{CategoryID: 2, ParentID: 4, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if mr.data[2].ParentID != 4 || len(res.Subcategories) != 1 || res.Subcategories[0].ID != 3 {
+		t.Errorf("unexpected move %v", res)
+	}
+
+	categories, _ := mr.ListUserCategories(3)
+
+	var subtree []int
+	for _, c := range Subtree(categories, 4) {
+		subtree = append(subtree, c.ID)
+	}
+	if !reflect.DeepEqual(subtree, []int{4, 2, 3}) {
+		t.Errorf("unexpected subtree %v", subtree)
+	}
+
+	var ancestors []int
+	for _, c := range Ancestors(categories, 3) {
+		ancestors = append(ancestors, c.ID)
+	}
+	if !reflect.DeepEqual(ancestors, []int{2, 4}) {
+		t.Errorf("unexpected ancestors %v", ancestors)
+	}
+
+	if _, err := s.Move(MoveRequest{CategoryID: 2, AuthenticatedUserID: 3}); err != nil || mr.data[2].ParentID != 0 {
+		t.Errorf("Move should move a category to the top level, got %v", err)
+	}
+}
+
+func TestDefineFields(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Bugs", UserID: 3},
+			2: {ID: 2, Title: "Groceries", UserID: 3, Fields: []models.FieldDef{{Name: "quantity", Type: models.NumberField}}},
+		},
+	}
+
+	s := NewService(mr)
+
+	res, err := s.DefineFields(DefineFieldsRequest{
+		CategoryID: 1,
+		Fields: []models.FieldDef{
+			{Name: " Severity ", Type: "ENUM", Options: []string{"low", " high "}},
+			{Name: "ticket", Type: models.URLField},
+		},
+		AuthenticatedUserID: 3,
+	})
+	if err != nil {
+		t.Fatalf("DefineFields failed: %v", err)
+	}
+
+	expected := []models.FieldDef{
+		{Name: "severity", Type: models.EnumField, Options: []string{"low", "high"}},
+		{Name: "ticket", Type: models.URLField},
+	}
+	if !reflect.DeepEqual(res.Category.Fields, expected) {
+		t.Errorf("got fields %v, want %v", res.Category.Fields, expected)
+	}
+
+	invalid := [][]models.FieldDef{
+		{{Name: "due", Type: models.DateField}},
+		{{Name: "ticket-url", Type: models.URLField}},
+		{{Name: "size", Type: "color"}},
+		{{Name: "severity", Type: models.EnumField}},
+		{{Name: "ticket", Type: models.URLField, Options: []string{"x"}}},
+		{{Name: "ticket", Type: models.URLField}, {Name: "ticket", Type: models.StringField}},
+		{{Name: "quantity", Type: models.StringField}},
+	}
+	for _, fields := range invalid {
+		if _, err := s.DefineFields(DefineFieldsRequest{CategoryID: 1, Fields: fields, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("DefineFields should fail for %v", fields)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package category
+
+import (
+	"fmt"
+	"strings"
+	"todo-cli-refactor/models"
+)
+
+type DefineFieldsRequest struct {
+	CategoryID int
+	// Fields replace the custom fields of the category. Values tasks hold
+	// for fields left out are kept but no longer checked.
+	Fields              []models.FieldDef
+	AuthenticatedUserID int
+}
+
+type DefineFieldsResponse struct {
+	Category models.Category
+}
+
+// DefineFields sets the custom fields the tasks of a category carry.
+func (c Service) DefineFields(req DefineFieldsRequest) (DefineFieldsResponse, error) {
+
+	categories, lErr := c.repository.ListUserCategories(req.AuthenticatedUserID)
+	if lErr != nil {
+		return DefineFieldsResponse{}, fmt.Errorf("can't list user categories: %v", lErr)
+	}
+
+	category, fErr := findLive(categories, req.CategoryID)
+	if fErr != nil {
+		return DefineFieldsResponse{}, fErr
+	}
+
+	fields, vErr := validateFields(categories, category.ID, req.Fields)
+	if vErr != nil {
+		return DefineFieldsResponse{}, fmt.Errorf("can't define fields: %v", vErr)
+	}
+
+	category.Fields = fields
+	updated, uErr := c.repository.UpdateCategory(category)
+	if uErr != nil {
+		return DefineFieldsResponse{}, fmt.Errorf("can't update category: %v", uErr)
+	}
+
+	return DefineFieldsResponse{Category: updated}, nil
+}
+
+// validateFields normalizes the custom fields of a category and checks them.
+// Names are unique in a category, and a name has the same type in every
+// category of the user so filter queries can compare its values. A
+// categoryID of zero stands for a category yet to be created.
+func validateFields(categories []models.Category, categoryID int, fields []models.FieldDef) ([]models.FieldDef, error) {
+	var normalized []models.FieldDef
+	seen := map[string]bool{}
+	for _, f := range fields {
+		f.Name = strings.ToLower(strings.TrimSpace(f.Name))
+
+		fieldType, pErr := models.ParseFieldType(string(f.Type))
+		if pErr != nil {
+			return nil, fmt.Errorf("field %s: %v", f.Name, pErr)
+		}
+		f.Type = fieldType
+
+		var options []string
+		for _, option := range f.Options {
+			options = append(options, strings.TrimSpace(option))
+		}
+		f.Options = options
+
+		if vErr := f.Validate(); vErr != nil {
+			return nil, vErr
+		}
+
+		if seen[f.Name] {
+			return nil, fmt.Errorf("field %s is defined twice", f.Name)
+		}
+		seen[f.Name] = true
+
+		for _, other := range categories {
+			if other.ID == categoryID || other.DeletedAt != nil {
+				continue
+			}
+
+			if def, ok := models.FieldDefByName(other.Fields, f.Name); ok && def.Type != f.Type {
+				return nil, fmt.Errorf("field %s is a %s in category %q", f.Name, def.Type, other.Title)
+			}
+		}
+
+		normalized = append(normalized, f)
+	}
+
+	return normalized, nil
+}
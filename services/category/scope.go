@@ -0,0 +1,88 @@
+package category
+
+import (
+	"fmt"
+	"todo-cli-refactor/models"
+)
+
+// ScopedRepository is the task storage a Scope widens the listings of.
+type ScopedRepository interface {
+	CreateNewTask(t models.Task) (models.Task, error)
+	InsertTask(t models.Task) (models.Task, error)
+	ListTasks() ([]models.Task, error)
+	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+	DeleteTask(id int) error
+}
+
+type CategoryLister interface {
+	ListCategories() ([]models.Category, error)
+}
+
+// Scope wraps a task repository so the tasks of a user include the tasks of
+// the categories they own or that are shared with them, whoever created
+// those.
+type Scope struct {
+	tasks      ScopedRepository
+	categories CategoryLister
+}
+
+func NewScope(tasks ScopedRepository, categories CategoryLister) Scope {
+	return Scope{tasks: tasks, categories: categories}
+}
+
+func (s Scope) ListTasks() ([]models.Task, error) {
+	return s.tasks.ListTasks()
+}
+
+func (s Scope) ListUserTasks(userID int) ([]models.Task, error) {
+	own, err := s.tasks.ListUserTasks(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categories.ListCategories()
+	if err != nil {
+		return nil, fmt.Errorf("can't list categories: %v", err)
+	}
+
+	tasks, err := s.tasks.ListTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	listed := map[int]bool{}
+	for _, task := range own {
+		listed[task.ID] = true
+	}
+
+	var scoped []models.Task
+	for _, task := range tasks {
+		if listed[task.ID] || (task.CategoryID != 0 && Role(categories, task.CategoryID, userID) != "") {
+			scoped = append(scoped, task)
+		}
+	}
+
+	return scoped, nil
+}
+
+// ListCategories lists the categories the roles on scoped tasks come from.
+func (s Scope) ListCategories() ([]models.Category, error) {
+	return s.categories.ListCategories()
+}
+
+func (s Scope) CreateNewTask(task models.Task) (models.Task, error) {
+	return s.tasks.CreateNewTask(task)
+}
+
+func (s Scope) InsertTask(task models.Task) (models.Task, error) {
+	return s.tasks.InsertTask(task)
+}
+
+func (s Scope) UpdateTask(task models.Task) (models.Task, error) {
+	return s.tasks.UpdateTask(task)
+}
+
+func (s Scope) DeleteTask(id int) error {
+	return s.tasks.DeleteTask(id)
+}
@@ -0,0 +1,78 @@
+package category
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+type mockTaskRepository struct {
+	tasks []models.Task
+}
+
+func (m mockTaskRepository) CreateNewTask(t models.Task) (models.Task, error) {
+	return t, nil
+}
+
+func (m mockTaskRepository) InsertTask(t models.Task) (models.Task, error) {
+	return t, nil
+}
+
+func (m mockTaskRepository) ListTasks() ([]models.Task, error) {
+	return m.tasks, nil
+}
+
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	var tasks []models.Task
+	for _, task := range m.tasks {
+		if task.UserID == userID || task.AssigneeID == userID {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) UpdateTask(t models.Task) (models.Task, error) {
+	return t, nil
+}
+
+func (m mockTaskRepository) DeleteTask(id int) error {
+	return nil
+}
+
+func TestScope(t *testing.T) {
+	mt := mockTaskRepository{tasks: []models.Task{
+		{ID: 1, Title: "Book the venue", CategoryID: 1, UserID: 3},
+		{ID: 2, Title: "Order the cake", CategoryID: 2, UserID: 5},
+		{ID: 3, Title: "Pay rent", CategoryID: 3, UserID: 3},
+		{ID: 4, Title: "Water the plants", CategoryID: 3, UserID: 3, AssigneeID: 4},
+		{ID: 5, Title: "Read a book", UserID: 4},
+	}}
+	mc := mockRepository{data: map[int]models.Category{
+		1: {ID: 1, Title: "Party", UserID: 3, Shares: []models.Share{{UserID: 4, Role: models.ViewerRole}}},
+		2: {ID: 2, Title: "Food", UserID: 3, ParentID: 1},
+		3: {ID: 3, Title: "Home", UserID: 3},
+	}}
+
+	scope := NewScope(mt, mc)
+
+	tasks, err := scope.ListUserTasks(4)
+	if err != nil {
+		t.Fatalf("ListUserTasks failed: %v", err)
+	}
+
+	var ids []int
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	if expected := []int{1, 2, 4, 5}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected tasks %v, got %v", expected, ids)
+	}
+
+	// the owner of a category sees the tasks others add to it
+	tasks, _ = scope.ListUserTasks(3)
+	if len(tasks) != 4 {
+		t.Errorf("expected the owner to list 4 tasks, got %v", tasks)
+	}
+}
@@ -0,0 +1,143 @@
+package category
+
+import (
+	"fmt"
+	"todo-cli-refactor/models"
+)
+
+// Role is the role a user has on a category: OwnerRole for its owner,
+// otherwise the highest role a share of the category or of a category it's
+// nested in grants them. It's empty when the category isn't shared with the
+// user.
+func Role(categories []models.Category, categoryID, userID int) models.Role {
+	var role models.Role
+	for _, c := range categories {
+		if c.ID != categoryID {
+			continue
+		}
+
+		for _, c := range append([]models.Category{c}, Ancestors(categories, categoryID)...) {
+			if c.UserID == userID {
+				return models.OwnerRole
+			}
+
+			for _, share := range c.Shares {
+				if share.UserID == userID && share.Role.Allows(role) {
+					role = share.Role
+				}
+			}
+		}
+	}
+
+	return role
+}
+
+type ShareRequest struct {
+	CategoryID int
+	// UserID is the user to share the category with, it's checked to be a
+	// registered user by the caller.
+	UserID              int
+	Role                string
+	AuthenticatedUserID int
+}
+
+type ShareResponse struct {
+	Category models.Category
+}
+
+// Share grants a user a role on a category and the categories nested in it,
+// replacing the role they had. The owner and admins share a category.
+func (c Service) Share(req ShareRequest) (ShareResponse, error) {
+
+	role, pErr := models.ParseRole(req.Role)
+	if pErr != nil {
+		return ShareResponse{}, fmt.Errorf("can't share category: %v", pErr)
+	}
+
+	categories, category, fErr := c.findShared(req.CategoryID, req.AuthenticatedUserID)
+	if fErr != nil {
+		return ShareResponse{}, fErr
+	}
+
+	if !Role(categories, category.ID, req.AuthenticatedUserID).Allows(models.AdminRole) {
+		return ShareResponse{}, fmt.Errorf("category %d can only be shared by its owner or an admin", category.ID)
+	}
+
+	if req.UserID == category.UserID {
+		return ShareResponse{}, fmt.Errorf("category %d already belongs to user %d", category.ID, req.UserID)
+	}
+
+	var shares []models.Share
+	for _, share := range category.Shares {
+		if share.UserID != req.UserID {
+			shares = append(shares, share)
+		}
+	}
+	category.Shares = append(shares, models.Share{UserID: req.UserID, Role: role})
+
+	updated, uErr := c.repository.UpdateCategory(category)
+	if uErr != nil {
+		return ShareResponse{}, fmt.Errorf("can't update category: %v", uErr)
+	}
+
+	return ShareResponse{Category: updated}, nil
+}
+
+type RevokeRequest struct {
+	CategoryID          int
+	UserID              int
+	AuthenticatedUserID int
+}
+
+type RevokeResponse struct {
+	Category models.Category
+}
+
+// Revoke takes a share of a category back. The owner and admins revoke any
+// share, other users can only leave a category shared with them.
+func (c Service) Revoke(req RevokeRequest) (RevokeResponse, error) {
+
+	categories, category, fErr := c.findShared(req.CategoryID, req.AuthenticatedUserID)
+	if fErr != nil {
+		return RevokeResponse{}, fErr
+	}
+
+	if req.UserID != req.AuthenticatedUserID && !Role(categories, category.ID, req.AuthenticatedUserID).Allows(models.AdminRole) {
+		return RevokeResponse{}, fmt.Errorf("access to category %d can only be revoked by its owner or an admin", category.ID)
+	}
+
+	var shares []models.Share
+	for _, share := range category.Shares {
+		if share.UserID != req.UserID {
+			shares = append(shares, share)
+		}
+	}
+
+	if len(shares) == len(category.Shares) {
+		return RevokeResponse{}, fmt.Errorf("category %d isn't shared with user %d", category.ID, req.UserID)
+	}
+	category.Shares = shares
+
+	updated, uErr := c.repository.UpdateCategory(category)
+	if uErr != nil {
+		return RevokeResponse{}, fmt.Errorf("can't update category: %v", uErr)
+	}
+
+	return RevokeResponse{Category: updated}, nil
+}
+
+// findShared looks up a live category the user owns or that is shared with
+// them, along with all categories to resolve roles in.
+func (c Service) findShared(categoryID, userID int) ([]models.Category, models.Category, error) {
+	categories, lErr := c.repository.ListCategories()
+	if lErr != nil {
+		return nil, models.Category{}, fmt.Errorf("can't list categories: %v", lErr)
+	}
+
+	category, fErr := findLive(categories, categoryID)
+	if fErr != nil || Role(categories, categoryID, userID) == "" {
+		return nil, models.Category{}, fmt.Errorf("category %d not found", categoryID)
+	}
+
+	return categories, category, nil
+}
@@ -0,0 +1,79 @@
+package category
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestShare(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Work", UserID: 3},
+			2: {ID: 2, Title: "Release", UserID: 3, ParentID: 1},
+			3: {ID: 3, Title: "Hobby", UserID: 5},
+		},
+	}
+
+	s := NewService(mr)
+
+	if _, err := s.Share(ShareRequest{CategoryID: 1, UserID: 4, Role: "boss", AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Share should fail for an unknown role")
+	}
+	if _, err := s.Share(ShareRequest{CategoryID: 3, UserID: 4, Role: "viewer", AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Share should fail for a category of another user")
+	}
+	if _, err := s.Share(ShareRequest{CategoryID: 1, UserID: 3, Role: "viewer", AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Share should fail for the owner")
+	}
+
+	if _, err := s.Share(ShareRequest{CategoryID: 1, UserID: 4, Role: "Editor", AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+	if _, err := s.Share(ShareRequest{CategoryID: 2, UserID: 4, Role: "admin", AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+
+	categories, _ := mr.ListCategories()
+	roles := map[int]models.Role{}
+	for _, c := range categories {
+		roles[c.ID] = Role(categories, c.ID, 4)
+	}
+	if expected := map[int]models.Role{1: models.EditorRole, 2: models.AdminRole, 3: ""}; !reflect.DeepEqual(roles, expected) {
+		t.Errorf("roles do not match: got %v, want %v", roles, expected)
+	}
+
+	res, err := s.List(ListRequest{AuthenticatedUserID: 4})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(res.Categories) != 2 || !reflect.DeepEqual(res.Roles, map[int]models.Role{1: models.EditorRole, 2: models.AdminRole}) {
+		t.Errorf("unexpected shared categories %v, roles %v", res.Categories, res.Roles)
+	}
+
+	// an editor of the parent can't share it, an admin of the subcategory can
+	if _, err := s.Share(ShareRequest{CategoryID: 1, UserID: 6, Role: "viewer", AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("Share should fail for an editor")
+	}
+	if _, err := s.Share(ShareRequest{CategoryID: 2, UserID: 6, Role: "viewer", AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("Share failed for an admin: %v", err)
+	}
+
+	if _, err := s.Revoke(RevokeRequest{CategoryID: 1, UserID: 4, AuthenticatedUserID: 6}); err == nil {
+		t.Errorf("Revoke should fail for a user without access")
+	}
+	if _, err := s.Revoke(RevokeRequest{CategoryID: 2, UserID: 6, AuthenticatedUserID: 6}); err != nil {
+		t.Errorf("a user should leave a category shared with them: %v", err)
+	}
+	if _, err := s.Revoke(RevokeRequest{CategoryID: 1, UserID: 4, AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := s.Revoke(RevokeRequest{CategoryID: 1, UserID: 4, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Revoke should fail once the share is gone")
+	}
+
+	categories, _ = mr.ListCategories()
+	if role := Role(categories, 1, 4); role != "" {
+		t.Errorf("expected no role on the parent after revoking, got %q", role)
+	}
+}
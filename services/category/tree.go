@@ -0,0 +1,101 @@
+package category
+
+import (
+	"fmt"
+	"todo-cli-refactor/models"
+)
+
+// Subtree returns a category followed by the categories nested in it at any
+// depth, parents before their children. It's empty when no category has the
+// ID.
+func Subtree(categories []models.Category, categoryID int) []models.Category {
+	children := map[int][]models.Category{}
+	var root *models.Category
+	for i, c := range categories {
+		children[c.ParentID] = append(children[c.ParentID], c)
+		if c.ID == categoryID {
+			root = &categories[i]
+		}
+	}
+
+	if root == nil {
+		return nil
+	}
+
+	subtree := []models.Category{*root}
+	seen := map[int]bool{root.ID: true}
+	for i := 0; i < len(subtree); i++ {
+		for _, child := range children[subtree[i].ID] {
+			// stored cycles would otherwise never end
+			if !seen[child.ID] {
+				seen[child.ID] = true
+				subtree = append(subtree, child)
+			}
+		}
+	}
+
+	return subtree
+}
+
+// Ancestors returns the categories a category is nested in, its parent
+// first.
+func Ancestors(categories []models.Category, categoryID int) []models.Category {
+	byID := map[int]models.Category{}
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	var ancestors []models.Category
+	seen := map[int]bool{categoryID: true}
+	for parentID := byID[categoryID].ParentID; parentID != 0 && !seen[parentID]; parentID = byID[parentID].ParentID {
+		parent, ok := byID[parentID]
+		if !ok {
+			break
+		}
+
+		seen[parentID] = true
+		ancestors = append(ancestors, parent)
+	}
+
+	return ancestors
+}
+
+// validateParent checks that a category can be nested in parentID: the
+// parent has to be a live category of the user outside the category's own
+// subtree. A categoryID of zero stands for a category yet to be created.
+func validateParent(categories []models.Category, categoryID, parentID int) error {
+	if parentID == 0 {
+		return nil
+	}
+
+	parent, fErr := findLive(categories, parentID)
+	if fErr != nil {
+		return fmt.Errorf("parent %v", fErr)
+	}
+
+	if categoryID == 0 {
+		return nil
+	}
+
+	for _, c := range Subtree(categories, categoryID) {
+		if c.ID == parent.ID {
+			if c.ID == categoryID {
+				return fmt.Errorf("category %d can't be nested in itself", categoryID)
+			}
+
+			return fmt.Errorf("category %d can't be nested in its own subcategory %d", categoryID, parent.ID)
+		}
+	}
+
+	return nil
+}
+
+func findLive(categories []models.Category, categoryID int) (models.Category, error) {
+	for _, c := range categories {
+		if c.ID == categoryID && c.DeletedAt == nil {
+			return c, nil
+		}
+	}
+
+	return models.Category{}, fmt.Errorf("category %d not found", categoryID)
+}
@@ -0,0 +1,301 @@
+package comment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+// maxTextSize bounds the text of a comment, in bytes, so that even escaped
+// it fits on a stored line.
+const maxTextSize = 128 * 1024
+
+type ServiceRepository interface {
+	CreateNewComment(c models.Comment) (models.Comment, error)
+	ListComments() ([]models.Comment, error)
+	UpdateComment(c models.Comment) (models.Comment, error)
+	DeleteComment(id int) error
+}
+
+type TaskRepository interface {
+	ListUserTasks(userID int) ([]models.Task, error)
+}
+
+type UserRepository interface {
+	ListUsers() ([]models.User, error)
+}
+
+// Service keeps the comments users leave on the tasks they can see. Anyone
+// who sees a task may comment on it, only the author changes a comment.
+type Service struct {
+	repository ServiceRepository
+	tasks      TaskRepository
+	users      UserRepository
+	now        func() time.Time
+}
+
+func NewService(repo ServiceRepository, tasks TaskRepository, users UserRepository) Service {
+	return Service{
+		repository: repo,
+		tasks:      tasks,
+		users:      users,
+		now:        time.Now,
+	}
+}
+
+type AddRequest struct {
+	TaskID              int
+	Text                string
+	AuthenticatedUserID int
+}
+
+type AddResponse struct {
+	Comment models.Comment
+}
+
+// Add leaves a comment on a task, the users it mentions as @name find it in
+// their mentions.
+func (s Service) Add(req AddRequest) (AddResponse, error) {
+
+	if _, fErr := s.findTask(req.AuthenticatedUserID, req.TaskID); fErr != nil {
+		return AddResponse{}, fErr
+	}
+
+	text, mentions, tErr := s.parse(req.Text, req.AuthenticatedUserID)
+	if tErr != nil {
+		return AddResponse{}, fmt.Errorf("can't add comment: %v", tErr)
+	}
+
+	created, cErr := s.repository.CreateNewComment(models.Comment{
+		TaskID:    req.TaskID,
+		AuthorID:  req.AuthenticatedUserID,
+		Text:      text,
+		Mentions:  mentions,
+		CreatedAt: s.now(),
+	})
+	if cErr != nil {
+		return AddResponse{}, fmt.Errorf("can't create new comment: %v", cErr)
+	}
+
+	return AddResponse{Comment: created}, nil
+}
+
+type ListRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+}
+
+type ListResponse struct {
+	// Comments are the comments on the task, oldest first.
+	Comments []models.Comment
+}
+
+func (s Service) List(req ListRequest) (ListResponse, error) {
+
+	if _, fErr := s.findTask(req.AuthenticatedUserID, req.TaskID); fErr != nil {
+		return ListResponse{}, fErr
+	}
+
+	comments, lErr := s.repository.ListComments()
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list comments: %v", lErr)
+	}
+
+	var onTask []models.Comment
+	for _, comment := range comments {
+		if comment.TaskID == req.TaskID {
+			onTask = append(onTask, comment)
+		}
+	}
+
+	sort.SliceStable(onTask, func(i, j int) bool { return onTask[i].CreatedAt.Before(onTask[j].CreatedAt) })
+
+	return ListResponse{Comments: onTask}, nil
+}
+
+type EditRequest struct {
+	CommentID           int
+	Text                string
+	AuthenticatedUserID int
+}
+
+type EditResponse struct {
+	Comment models.Comment
+}
+
+// Edit replaces the text of a comment, and with it who it mentions.
+func (s Service) Edit(req EditRequest) (EditResponse, error) {
+
+	comment, fErr := s.findOwnComment(req.CommentID, req.AuthenticatedUserID, "edited")
+	if fErr != nil {
+		return EditResponse{}, fErr
+	}
+
+	text, mentions, tErr := s.parse(req.Text, req.AuthenticatedUserID)
+	if tErr != nil {
+		return EditResponse{}, fmt.Errorf("can't edit comment: %v", tErr)
+	}
+
+	editedAt := s.now()
+	comment.Text = text
+	comment.Mentions = mentions
+	comment.EditedAt = &editedAt
+
+	updated, uErr := s.repository.UpdateComment(comment)
+	if uErr != nil {
+		return EditResponse{}, fmt.Errorf("can't update comment: %v", uErr)
+	}
+
+	return EditResponse{Comment: updated}, nil
+}
+
+type DeleteRequest struct {
+	CommentID           int
+	AuthenticatedUserID int
+}
+
+type DeleteResponse struct {
+	Comment models.Comment
+}
+
+func (s Service) Delete(req DeleteRequest) (DeleteResponse, error) {
+
+	comment, fErr := s.findOwnComment(req.CommentID, req.AuthenticatedUserID, "deleted")
+	if fErr != nil {
+		return DeleteResponse{}, fErr
+	}
+
+	if dErr := s.repository.DeleteComment(comment.ID); dErr != nil {
+		return DeleteResponse{}, fmt.Errorf("can't delete comment: %v", dErr)
+	}
+
+	return DeleteResponse{Comment: comment}, nil
+}
+
+type MentionsRequest struct {
+	AuthenticatedUserID int
+}
+
+// Mention is a comment that mentions the user, with the task it's on.
+type Mention struct {
+	Comment models.Comment
+	Task    models.Task
+}
+
+type MentionsResponse struct {
+	// Mentions are newest first. Comments on tasks the user can no longer
+	// see, or that are in the trash, are left out.
+	Mentions []Mention
+}
+
+// Mentions is the inbox of a user: the comments mentioning them.
+func (s Service) Mentions(req MentionsRequest) (MentionsResponse, error) {
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return MentionsResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	live := map[int]models.Task{}
+	for _, task := range tasks {
+		if task.DeletedAt == nil {
+			live[task.ID] = task
+		}
+	}
+
+	comments, cErr := s.repository.ListComments()
+	if cErr != nil {
+		return MentionsResponse{}, fmt.Errorf("can't list comments: %v", cErr)
+	}
+
+	response := MentionsResponse{}
+	for _, comment := range comments {
+		task, ok := live[comment.TaskID]
+		if !ok || !mentions(comment, req.AuthenticatedUserID) {
+			continue
+		}
+
+		response.Mentions = append(response.Mentions, Mention{Comment: comment, Task: task})
+	}
+
+	sort.SliceStable(response.Mentions, func(i, j int) bool {
+		return response.Mentions[i].Comment.CreatedAt.After(response.Mentions[j].Comment.CreatedAt)
+	})
+
+	return response, nil
+}
+
+// parse trims the text of a comment and resolves its mentions to registered
+// users. Authors mentioning themselves aren't notified.
+func (s Service) parse(text string, authorID int) (string, []int, error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return "", nil, fmt.Errorf("a comment can't be empty")
+	}
+	if len(text) > maxTextSize {
+		return "", nil, fmt.Errorf("a comment can be at most %d bytes, got %d", maxTextSize, len(text))
+	}
+
+	users, lErr := s.users.ListUsers()
+	if lErr != nil {
+		return "", nil, fmt.Errorf("can't list users: %v", lErr)
+	}
+
+	var mentioned []int
+	for _, userID := range parseMentions(text, users) {
+		if userID != authorID {
+			mentioned = append(mentioned, userID)
+		}
+	}
+
+	return text, mentioned, nil
+}
+
+// findTask makes sure the user can see a task that isn't in the trash.
+func (s Service) findTask(userID, taskID int) (models.Task, error) {
+	tasks, lErr := s.tasks.ListUserTasks(userID)
+	if lErr != nil {
+		return models.Task{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	for _, task := range tasks {
+		if task.ID == taskID && task.DeletedAt == nil {
+			return task, nil
+		}
+	}
+
+	return models.Task{}, fmt.Errorf("task %d not found", taskID)
+}
+
+func (s Service) findOwnComment(commentID, userID int, verb string) (models.Comment, error) {
+	comments, lErr := s.repository.ListComments()
+	if lErr != nil {
+		return models.Comment{}, fmt.Errorf("can't list comments: %v", lErr)
+	}
+
+	for _, comment := range comments {
+		if comment.ID != commentID {
+			continue
+		}
+
+		if comment.AuthorID != userID {
+			return models.Comment{}, fmt.Errorf("comment %d can only be %s by its author", commentID, verb)
+		}
+
+		return comment, nil
+	}
+
+	return models.Comment{}, fmt.Errorf("comment %d not found", commentID)
+}
+
+func mentions(comment models.Comment, userID int) bool {
+	for _, mentioned := range comment.Mentions {
+		if mentioned == userID {
+			return true
+		}
+	}
+
+	return false
+}
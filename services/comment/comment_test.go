@@ -0,0 +1,226 @@
+package comment
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+type mockRepository struct {
+	data map[int]models.Comment
+}
+
+func (m mockRepository) CreateNewComment(comment models.Comment) (models.Comment, error) {
+	comment.ID = len(m.data) + 1
+
+	m.data[comment.ID] = comment
+
+	return comment, nil
+}
+
+func (m mockRepository) ListComments() ([]models.Comment, error) {
+	var comments []models.Comment
+
+	for _, comment := range m.data {
+		comments = append(comments, comment)
+	}
+
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+
+	return comments, nil
+}
+
+func (m mockRepository) UpdateComment(comment models.Comment) (models.Comment, error) {
+	if _, ok := m.data[comment.ID]; !ok {
+		return models.Comment{}, fmt.Errorf("comment %d not found", comment.ID)
+	}
+
+	m.data[comment.ID] = comment
+
+	return comment, nil
+}
+
+func (m mockRepository) DeleteComment(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockTasks []models.Task
+
+func (m mockTasks) ListUserTasks(userID int) ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, task := range m {
+		if task.UserID == userID || task.AssigneeID == userID {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+type mockUsers []models.User
+
+func (m mockUsers) ListUsers() ([]models.User, error) {
+	return m, nil
+}
+
+func newTestService() Service {
+	deletedAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewService(
+		mockRepository{data: map[int]models.Comment{}},
+		mockTasks{
+			{ID: 1, Title: "write report", UserID: 1, AssigneeID: 2},
+			{ID: 2, Title: "old task", UserID: 1, AssigneeID: 2, DeletedAt: &deletedAt},
+			{ID: 3, Title: "private", UserID: 3},
+		},
+		mockUsers{
+			{ID: 1, Name: "ann"},
+			{ID: 2, Name: "Bob Smith"},
+			{ID: 3, Name: "carol"},
+		},
+	)
+
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	return s
+}
+
+func TestComments(t *testing.T) {
+	s := newTestService()
+
+	added, err := s.Add(AddRequest{TaskID: 1, Text: "  @bobsmith can you check this?\n", AuthenticatedUserID: 1})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	expected := models.Comment{
+		ID:        1,
+		TaskID:    1,
+		AuthorID:  1,
+		Text:      "@bobsmith can you check this?",
+		Mentions:  []int{2},
+		CreatedAt: time.Date(2024, 3, 10, 9, 1, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(added.Comment, expected) {
+		t.Errorf("got %v, want %v", added.Comment, expected)
+	}
+
+	// the assignee sees the task, so they can answer
+	if _, err := s.Add(AddRequest{TaskID: 1, Text: "done, @ann", AuthenticatedUserID: 2}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if _, err := s.Add(AddRequest{TaskID: 3, Text: "hi", AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Add should refuse tasks the user can't see")
+	}
+	if _, err := s.Add(AddRequest{TaskID: 2, Text: "hi", AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Add should refuse tasks in the trash")
+	}
+	if _, err := s.Add(AddRequest{TaskID: 1, Text: " \n", AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Add should refuse empty comments")
+	}
+	if _, err := s.Add(AddRequest{TaskID: 1, Text: strings.Repeat("x", maxTextSize+1), AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Add should refuse comments that are too long")
+	}
+
+	listed, err := s.List(ListRequest{TaskID: 1, AuthenticatedUserID: 2})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listed.Comments) != 2 || listed.Comments[0].ID != 1 || listed.Comments[1].ID != 2 {
+		t.Errorf("got %v, want both comments oldest first", listed.Comments)
+	}
+
+	if _, err := s.List(ListRequest{TaskID: 1, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("List should refuse tasks the user can't see")
+	}
+}
+
+func TestEditAndDeleteComments(t *testing.T) {
+	s := newTestService()
+
+	if _, err := s.Add(AddRequest{TaskID: 1, Text: "ping @bobsmith", AuthenticatedUserID: 1}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if _, err := s.Edit(EditRequest{CommentID: 1, Text: "hijacked", AuthenticatedUserID: 2}); err == nil {
+		t.Errorf("Edit should refuse comments of other users")
+	}
+	if _, err := s.Edit(EditRequest{CommentID: 1, Text: "", AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Edit should refuse empty comments")
+	}
+	if _, err := s.Edit(EditRequest{CommentID: 9, Text: "x", AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Edit should fail for unknown comments")
+	}
+
+	edited, err := s.Edit(EditRequest{CommentID: 1, Text: "never mind, @carol will do it", AuthenticatedUserID: 1})
+	if err != nil {
+		t.Fatalf("Edit failed: %v", err)
+	}
+	if edited.Comment.Text != "never mind, @carol will do it" || !reflect.DeepEqual(edited.Comment.Mentions, []int{3}) {
+		t.Errorf("got %v, want the new text and mentions", edited.Comment)
+	}
+	if edited.Comment.EditedAt == nil || !edited.Comment.EditedAt.After(edited.Comment.CreatedAt) {
+		t.Errorf("got edited at %v, want a time after it was created", edited.Comment.EditedAt)
+	}
+
+	if _, err := s.Delete(DeleteRequest{CommentID: 1, AuthenticatedUserID: 2}); err == nil {
+		t.Errorf("Delete should refuse comments of other users")
+	}
+	if _, err := s.Delete(DeleteRequest{CommentID: 1, AuthenticatedUserID: 1}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	listed, err := s.List(ListRequest{TaskID: 1, AuthenticatedUserID: 1})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listed.Comments) != 0 {
+		t.Errorf("got %v, want no comments", listed.Comments)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	s := newTestService()
+
+	for _, text := range []string{"first, @bobsmith", "no mention", "again @BobSmith and @ann", "@bobsmith noting it down"} {
+		authorID := 1
+		if text == "@bobsmith noting it down" {
+			authorID = 2
+		}
+
+		if _, err := s.Add(AddRequest{TaskID: 1, Text: text, AuthenticatedUserID: authorID}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	// mentioned on a task that later went to the trash
+	s.repository.(mockRepository).data[5] = models.Comment{ID: 5, TaskID: 2, AuthorID: 1, Text: "@bobsmith", Mentions: []int{2}}
+
+	got, err := s.Mentions(MentionsRequest{AuthenticatedUserID: 2})
+	if err != nil {
+		t.Fatalf("Mentions failed: %v", err)
+	}
+
+	var ids []int
+	for _, mention := range got.Mentions {
+		ids = append(ids, mention.Comment.ID)
+		if mention.Task.ID != 1 {
+			t.Errorf("got task %d, want task 1", mention.Task.ID)
+		}
+	}
+	if !reflect.DeepEqual(ids, []int{3, 1}) {
+		t.Errorf("got comments %v, want [3 1]", ids)
+	}
+}
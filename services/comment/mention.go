@@ -0,0 +1,48 @@
+package comment
+
+import (
+	"strings"
+	"todo-cli-refactor/models"
+	"unicode"
+)
+
+// parseMentions finds the users a text mentions as @name, in the order they
+// are first mentioned. A name matches a user's name regardless of case and
+// with its spaces left out, so "@JaneDoe" mentions "Jane Doe". Names no user
+// has, and @ signs inside words such as email addresses, are left as text.
+func parseMentions(text string, users []models.User) []int {
+	var mentions []int
+	mentioned := map[int]bool{}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isNameRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isNameRune(runes[end]) {
+			end++
+		}
+
+		// sentence punctuation right after a name isn't part of it
+		name := strings.TrimRight(string(runes[i+1:end]), ".-")
+		i = end - 1
+		if name == "" {
+			continue
+		}
+
+		for _, user := range users {
+			if strings.EqualFold(strings.ReplaceAll(user.Name, " ", ""), name) && !mentioned[user.ID] {
+				mentioned[user.ID] = true
+				mentions = append(mentions, user.ID)
+			}
+		}
+	}
+
+	return mentions
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-'
+}
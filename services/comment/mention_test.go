@@ -0,0 +1,36 @@
+package comment
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestParseMentions(t *testing.T) {
+	users := []models.User{
+		{ID: 1, Name: "ann"},
+		{ID: 2, Name: "Bob Smith"},
+		{ID: 3, Name: "o.neil"},
+	}
+
+	tests := []struct {
+		text     string
+		expected []int
+	}{
+		{"no mentions here", nil},
+		{"@ann", []int{1}},
+		{"thanks @ANN.", []int{1}},
+		{"@bobsmith and @ann, then @ann again", []int{2, 1}},
+		{"ask @o.neil about it", []int{3}},
+		{"(@ann)", []int{1}},
+		{"mail ann@ann.com", nil},
+		{"@nobody and a lone @", nil},
+		{"@annie", nil},
+	}
+
+	for _, test := range tests {
+		if got := parseMentions(test.text, users); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("parseMentions(%q) = %v, want %v", test.text, got, test.expected)
+		}
+	}
+}
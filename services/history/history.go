@@ -0,0 +1,64 @@
+package history
+
+import (
+	"fmt"
+	"todo-cli-refactor/models"
+)
+
+type ServiceRepository interface {
+	AppendChanges(changes []models.Change) error
+	ListTaskChanges(taskID int) ([]models.Change, error)
+}
+
+type TaskRepository interface {
+	ListUserTasks(userID int) ([]models.Task, error)
+}
+
+type Service struct {
+	repository ServiceRepository
+	tasks      TaskRepository
+}
+
+func NewService(repo ServiceRepository, tasks TaskRepository) Service {
+	return Service{
+		repository: repo,
+		tasks:      tasks,
+	}
+}
+
+type TaskHistoryRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+}
+
+type TaskHistoryResponse struct {
+	// Changes are the changes of the task, oldest first.
+	Changes []models.Change
+}
+
+// TaskHistory returns the changes of a task, including ones made while it
+// was in the trash.
+func (s Service) TaskHistory(req TaskHistoryRequest) (TaskHistoryResponse, error) {
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return TaskHistoryResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	found := false
+	for _, task := range tasks {
+		if task.ID == req.TaskID {
+			found = true
+		}
+	}
+	if !found {
+		return TaskHistoryResponse{}, fmt.Errorf("task %d not found", req.TaskID)
+	}
+
+	changes, cErr := s.repository.ListTaskChanges(req.TaskID)
+	if cErr != nil {
+		return TaskHistoryResponse{}, fmt.Errorf("can't list task changes: %v", cErr)
+	}
+
+	return TaskHistoryResponse{Changes: changes}, nil
+}
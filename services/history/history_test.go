@@ -0,0 +1,38 @@
+package history
+
+import (
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+func TestTaskHistory(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	mt := mockTaskRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Buy milk", UserID: 3, DeletedAt: &deletedAt},
+			2: {ID: 2, Title: "Read a book", UserID: 4},
+		},
+	}
+	mr := mockRepository{changes: &[]models.Change{
+		{TaskID: 1, ActorID: 3, Field: models.CreatedField},
+		{TaskID: 2, ActorID: 4, Field: models.CreatedField},
+		{TaskID: 1, ActorID: 3, Field: "deletedAt", NewValue: "2026-10-19T08:00:00Z"},
+	}}
+
+	s := NewService(mr, mt)
+
+	res, err := s.TaskHistory(TaskHistoryRequest{TaskID: 1, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("TaskHistory failed: %v", err)
+	}
+
+	if len(res.Changes) != 2 || res.Changes[1].Field != "deletedAt" {
+		t.Errorf("unexpected changes: %v", res.Changes)
+	}
+
+	if _, err := s.TaskHistory(TaskHistoryRequest{TaskID: 2, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("TaskHistory should fail for a task of another user")
+	}
+}
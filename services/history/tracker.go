@@ -0,0 +1,215 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+// TrackedRepository is the task storage a Tracker records the changes of.
+type TrackedRepository interface {
+	CreateNewTask(t models.Task) (models.Task, error)
+	InsertTask(t models.Task) (models.Task, error)
+	ListTasks() ([]models.Task, error)
+	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+	DeleteTask(id int) error
+}
+
+// Tracker wraps a task repository and records every change made through it
+// as done by one actor. Services are given a Tracker in place of the
+// repository for the requests of that actor.
+type Tracker struct {
+	tasks      TrackedRepository
+	repository ServiceRepository
+	actorID    int
+	now        func() time.Time
+}
+
+func NewTracker(tasks TrackedRepository, repo ServiceRepository, actorID int) Tracker {
+	return Tracker{
+		tasks:      tasks,
+		repository: repo,
+		actorID:    actorID,
+		now:        time.Now,
+	}
+}
+
+func (t Tracker) ListTasks() ([]models.Task, error) {
+	return t.tasks.ListTasks()
+}
+
+func (t Tracker) ListUserTasks(userID int) ([]models.Task, error) {
+	return t.tasks.ListUserTasks(userID)
+}
+
+func (t Tracker) CreateNewTask(task models.Task) (models.Task, error) {
+	created, err := t.tasks.CreateNewTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	// the owner of a new task goes without saying, later transfers are
+	// recorded
+	changes := append([]models.Change{{Field: models.CreatedField}}, diff(models.Task{UserID: created.UserID}, created)...)
+	if rErr := t.record(created.ID, changes); rErr != nil {
+		return models.Task{}, rErr
+	}
+
+	return created, nil
+}
+
+// InsertTask records a task brought back under its old ID the same way as a
+// created one.
+func (t Tracker) InsertTask(task models.Task) (models.Task, error) {
+	inserted, err := t.tasks.InsertTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	changes := append([]models.Change{{Field: models.CreatedField}}, diff(models.Task{UserID: inserted.UserID}, inserted)...)
+	if rErr := t.record(inserted.ID, changes); rErr != nil {
+		return models.Task{}, rErr
+	}
+
+	return inserted, nil
+}
+
+func (t Tracker) UpdateTask(task models.Task) (models.Task, error) {
+	tasks, err := t.tasks.ListTasks()
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	var before models.Task
+	for _, stored := range tasks {
+		if stored.ID == task.ID {
+			before = stored
+		}
+	}
+
+	updated, err := t.tasks.UpdateTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	if rErr := t.record(updated.ID, diff(before, updated)); rErr != nil {
+		return models.Task{}, rErr
+	}
+
+	return updated, nil
+}
+
+func (t Tracker) DeleteTask(id int) error {
+	if err := t.tasks.DeleteTask(id); err != nil {
+		return err
+	}
+
+	return t.record(id, []models.Change{{Field: models.PurgedField}})
+}
+
+func (t Tracker) record(taskID int, changes []models.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	changedAt := t.now().UTC()
+	for i := range changes {
+		changes[i].TaskID = taskID
+		changes[i].ActorID = t.actorID
+		changes[i].ChangedAt = changedAt
+	}
+
+	if err := t.repository.AppendChanges(changes); err != nil {
+		return fmt.Errorf("can't record task changes: %v", err)
+	}
+
+	return nil
+}
+
+// diff returns a change for every field that differs between two versions of
+// a task. Field names follow the keys of the text storage format.
+func diff(before, after models.Task) []models.Change {
+	var changes []models.Change
+
+	for _, field := range fieldValues {
+		oldValue, newValue := field.value(before), field.value(after)
+		if oldValue != newValue {
+			changes = append(changes, models.Change{Field: field.name, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+
+	return changes
+}
+
+var fieldValues = []struct {
+	name  string
+	value func(models.Task) string
+}{
+	{"title", func(t models.Task) string { return t.Title }},
+	{"description", func(t models.Task) string { return t.Description }},
+	{"dueDate", func(t models.Task) string { return t.DueDate.String() }},
+	{"categoryID", func(t models.Task) string { return formatID(t.CategoryID) }},
+	{"userID", func(t models.Task) string { return formatID(t.UserID) }},
+	{"assigneeID", func(t models.Task) string { return formatID(t.AssigneeID) }},
+	{"isDone", func(t models.Task) string { return strconv.FormatBool(t.IsDone) }},
+	{"status", func(t models.Task) string { return t.Status }},
+	{"priority", func(t models.Task) string {
+		if t.Priority == models.NoPriority {
+			return ""
+		}
+		return t.Priority.String()
+	}},
+	{"tags", func(t models.Task) string { return strings.Join(t.Tags, " ") }},
+	{"parentID", func(t models.Task) string { return formatID(t.ParentID) }},
+	{"milestoneID", func(t models.Task) string { return formatID(t.MilestoneID) }},
+	{"checklist", func(t models.Task) string {
+		items := make([]string, 0, len(t.Checklist))
+		for _, item := range t.Checklist {
+			check := " "
+			if item.Done {
+				check = "x"
+			}
+			items = append(items, fmt.Sprintf("[%s] %s", check, item.Text))
+		}
+		return strings.Join(items, "\n")
+	}},
+	{"fields", func(t models.Task) string {
+		values := make([]string, 0, len(t.Fields))
+		for name, value := range t.Fields {
+			values = append(values, name+": "+value)
+		}
+		sort.Strings(values)
+		return strings.Join(values, "\n")
+	}},
+	{"blockedBy", func(t models.Task) string {
+		ids := make([]string, 0, len(t.BlockedBy))
+		for _, id := range t.BlockedBy {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		return strings.Join(ids, " ")
+	}},
+	{"recurrence", func(t models.Task) string {
+		if t.Recurrence == nil {
+			return ""
+		}
+		return t.Recurrence.String()
+	}},
+	{"deletedAt", func(t models.Task) string {
+		if t.DeletedAt == nil {
+			return ""
+		}
+		return t.DeletedAt.UTC().Format(time.RFC3339)
+	}},
+}
+
+func formatID(id int) string {
+	if id == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(id)
+}
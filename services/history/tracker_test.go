@@ -0,0 +1,142 @@
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+type mockTaskRepository struct {
+	data map[int]models.Task
+}
+
+func (m mockTaskRepository) CreateNewTask(task models.Task) (models.Task, error) {
+	task.ID = len(m.data) + 1
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockTaskRepository) InsertTask(task models.Task) (models.Task, error) {
+	if _, ok := m.data[task.ID]; ok {
+		return models.Task{}, fmt.Errorf("task %d already exists", task.ID)
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockTaskRepository) ListTasks() ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, task := range m.data {
+		tasks = append(tasks, task)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	tasks, _ := m.ListTasks()
+
+	var userTasks []models.Task
+	for _, task := range tasks {
+		if task.UserID == userID {
+			userTasks = append(userTasks, task)
+		}
+	}
+
+	return userTasks, nil
+}
+
+func (m mockTaskRepository) UpdateTask(task models.Task) (models.Task, error) {
+	if _, ok := m.data[task.ID]; !ok {
+		return models.Task{}, fmt.Errorf("task %d not found", task.ID)
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockTaskRepository) DeleteTask(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockRepository struct {
+	changes *[]models.Change
+}
+
+func (m mockRepository) AppendChanges(changes []models.Change) error {
+	*m.changes = append(*m.changes, changes...)
+
+	return nil
+}
+
+func (m mockRepository) ListTaskChanges(taskID int) ([]models.Change, error) {
+	var changes []models.Change
+	for _, change := range *m.changes {
+		if change.TaskID == taskID {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+func TestTracker(t *testing.T) {
+	mt := mockTaskRepository{data: map[int]models.Task{}}
+	mr := mockRepository{changes: &[]models.Change{}}
+
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+	tracker := NewTracker(mt, mr, 3)
+	tracker.now = func() time.Time { return now }
+
+	created, err := tracker.CreateNewTask(models.Task{Title: "Buy milk", CategoryID: 2, UserID: 3, Tags: []string{"@shop"}})
+	if err != nil {
+		t.Fatalf("CreateNewTask failed: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+
+	updated := created
+	updated.Title = "Buy oat milk"
+	updated.IsDone = true
+	updated.AssigneeID = 4
+	updated.MilestoneID = 5
+	updated.Checklist = []models.ChecklistItem{{Text: "2 liters", Done: true}}
+	if _, err := tracker.UpdateTask(updated); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+
+	if _, err := tracker.UpdateTask(updated); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+
+	createdAt := now.Add(-time.Hour)
+	expected := []models.Change{
+		{TaskID: 1, ActorID: 3, ChangedAt: createdAt, Field: models.CreatedField},
+		{TaskID: 1, ActorID: 3, ChangedAt: createdAt, Field: "title", NewValue: "Buy milk"},
+		{TaskID: 1, ActorID: 3, ChangedAt: createdAt, Field: "categoryID", NewValue: "2"},
+		{TaskID: 1, ActorID: 3, ChangedAt: createdAt, Field: "tags", NewValue: "@shop"},
+		{TaskID: 1, ActorID: 3, ChangedAt: now, Field: "title", OldValue: "Buy milk", NewValue: "Buy oat milk"},
+		{TaskID: 1, ActorID: 3, ChangedAt: now, Field: "assigneeID", NewValue: "4"},
+		{TaskID: 1, ActorID: 3, ChangedAt: now, Field: "isDone", OldValue: "false", NewValue: "true"},
+		{TaskID: 1, ActorID: 3, ChangedAt: now, Field: "milestoneID", NewValue: "5"},
+		{TaskID: 1, ActorID: 3, ChangedAt: now, Field: "checklist", NewValue: "[x] 2 liters"},
+	}
+
+	if !reflect.DeepEqual(*mr.changes, expected) {
+		t.Errorf("changes do not match:\ngot  %v\nwant %v", *mr.changes, expected)
+	}
+}
@@ -0,0 +1,303 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+// maxEntries is how many operations of a user the journal keeps; older ones
+// can no longer be undone.
+const maxEntries = 50
+
+type ServiceRepository interface {
+	AppendEntry(e models.JournalEntry) (models.JournalEntry, error)
+	ListUserEntries(userID int) ([]models.JournalEntry, error)
+	UpdateEntry(e models.JournalEntry) (models.JournalEntry, error)
+	DeleteEntries(ids []int) error
+}
+
+// ReplayTaskRepository is the task storage undo and redo write to. Unlike
+// the services, it has to be able to bring back a task under its old ID.
+type ReplayTaskRepository interface {
+	ListTasks() ([]models.Task, error)
+	InsertTask(t models.Task) (models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+	DeleteTask(id int) error
+}
+
+type ReplayCategoryRepository interface {
+	ListCategories() ([]models.Category, error)
+	InsertCategory(c models.Category) (models.Category, error)
+	UpdateCategory(c models.Category) (models.Category, error)
+	DeleteCategory(id int) error
+}
+
+// Service keeps a journal of the operations of every user and undoes and
+// redoes them by writing back the recorded versions of the records they
+// changed. A replay is refused when any of those records no longer looks
+// the way the operation left it.
+type Service struct {
+	repository ServiceRepository
+	tasks      ReplayTaskRepository
+	categories ReplayCategoryRepository
+	now        func() time.Time
+}
+
+func NewService(repo ServiceRepository, tasks ReplayTaskRepository, categories ReplayCategoryRepository) Service {
+	return Service{
+		repository: repo,
+		tasks:      tasks,
+		categories: categories,
+		now:        time.Now,
+	}
+}
+
+type RecordRequest struct {
+	Command             string
+	Tasks               []models.TaskVersion
+	Categories          []models.CategoryVersion
+	AuthenticatedUserID int
+}
+
+type RecordResponse struct {
+	Entry models.JournalEntry
+}
+
+// Record adds an operation to the journal of a user. Operations that changed
+// nothing aren't recorded. Recording drops the operations that were undone,
+// as they can't be redone on top of the new one.
+func (s Service) Record(req RecordRequest) (RecordResponse, error) {
+	if len(req.Tasks) == 0 && len(req.Categories) == 0 {
+		return RecordResponse{}, nil
+	}
+
+	entries, lErr := s.repository.ListUserEntries(req.AuthenticatedUserID)
+	if lErr != nil {
+		return RecordResponse{}, fmt.Errorf("can't list journal entries: %v", lErr)
+	}
+
+	var stale []int
+	var kept []models.JournalEntry
+	for _, entry := range entries {
+		if entry.Undone {
+			stale = append(stale, entry.ID)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+
+	for len(kept) >= maxEntries {
+		stale = append(stale, kept[0].ID)
+		kept = kept[1:]
+	}
+
+	if dErr := s.repository.DeleteEntries(stale); dErr != nil {
+		return RecordResponse{}, fmt.Errorf("can't drop old journal entries: %v", dErr)
+	}
+
+	entry, aErr := s.repository.AppendEntry(models.JournalEntry{
+		UserID:     req.AuthenticatedUserID,
+		Command:    req.Command,
+		CreatedAt:  s.now().UTC(),
+		Tasks:      req.Tasks,
+		Categories: req.Categories,
+	})
+	if aErr != nil {
+		return RecordResponse{}, fmt.Errorf("can't append journal entry: %v", aErr)
+	}
+
+	return RecordResponse{Entry: entry}, nil
+}
+
+type UndoRequest struct {
+	AuthenticatedUserID int
+}
+
+type UndoResponse struct {
+	// Entry is the operation that was undone.
+	Entry models.JournalEntry
+}
+
+// Undo reverts the latest operation of a user that isn't undone yet.
+func (s Service) Undo(req UndoRequest) (UndoResponse, error) {
+
+	entries, lErr := s.repository.ListUserEntries(req.AuthenticatedUserID)
+	if lErr != nil {
+		return UndoResponse{}, fmt.Errorf("can't list journal entries: %v", lErr)
+	}
+
+	var entry *models.JournalEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Undone {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return UndoResponse{}, fmt.Errorf("nothing to undo")
+	}
+
+	if err := s.replay(*entry, true); err != nil {
+		return UndoResponse{}, fmt.Errorf("can't undo %s: %v", entry.Command, err)
+	}
+
+	entry.Undone = true
+	if _, uErr := s.repository.UpdateEntry(*entry); uErr != nil {
+		return UndoResponse{}, fmt.Errorf("can't update journal entry: %v", uErr)
+	}
+
+	return UndoResponse{Entry: *entry}, nil
+}
+
+type RedoRequest struct {
+	AuthenticatedUserID int
+}
+
+type RedoResponse struct {
+	// Entry is the operation that was redone.
+	Entry models.JournalEntry
+}
+
+// Redo applies again the operation of a user that was undone last.
+func (s Service) Redo(req RedoRequest) (RedoResponse, error) {
+
+	entries, lErr := s.repository.ListUserEntries(req.AuthenticatedUserID)
+	if lErr != nil {
+		return RedoResponse{}, fmt.Errorf("can't list journal entries: %v", lErr)
+	}
+
+	// undone entries always trail the journal, the one undone last comes first
+	var entry *models.JournalEntry
+	for i := range entries {
+		if entries[i].Undone {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return RedoResponse{}, fmt.Errorf("nothing to redo")
+	}
+
+	if err := s.replay(*entry, false); err != nil {
+		return RedoResponse{}, fmt.Errorf("can't redo %s: %v", entry.Command, err)
+	}
+
+	entry.Undone = false
+	if _, uErr := s.repository.UpdateEntry(*entry); uErr != nil {
+		return RedoResponse{}, fmt.Errorf("can't update journal entry: %v", uErr)
+	}
+
+	return RedoResponse{Entry: *entry}, nil
+}
+
+// replay writes back the versions an entry recorded, the ones from before
+// the operation when undoing and the ones after it when redoing. Every record
+// is checked first so a refused replay leaves everything untouched.
+func (s Service) replay(entry models.JournalEntry, undo bool) error {
+	tasks, err := s.tasks.ListTasks()
+	if err != nil {
+		return fmt.Errorf("can't list tasks: %v", err)
+	}
+
+	categories, err := s.categories.ListCategories()
+	if err != nil {
+		return fmt.Errorf("can't list categories: %v", err)
+	}
+
+	for _, version := range entry.Tasks {
+		expected, _ := taskVersions(version, undo)
+		id := taskVersionID(version)
+
+		var current *models.Task
+		for i := range tasks {
+			if tasks[i].ID == id {
+				current = &tasks[i]
+			}
+		}
+
+		if !sameRecord(current, expected) {
+			return fmt.Errorf("task %d has been changed since", id)
+		}
+	}
+
+	for _, version := range entry.Categories {
+		expected, _ := categoryVersions(version, undo)
+		id := categoryVersionID(version)
+
+		var current *models.Category
+		for i := range categories {
+			if categories[i].ID == id {
+				current = &categories[i]
+			}
+		}
+
+		if !sameRecord(current, expected) {
+			return fmt.Errorf("category %d has been changed since", id)
+		}
+	}
+
+	for _, version := range entry.Categories {
+		from, to := categoryVersions(version, undo)
+
+		var wErr error
+		switch {
+		case to == nil:
+			wErr = s.categories.DeleteCategory(from.ID)
+		case from == nil:
+			_, wErr = s.categories.InsertCategory(*to)
+		default:
+			_, wErr = s.categories.UpdateCategory(*to)
+		}
+		if wErr != nil {
+			return fmt.Errorf("can't write category %d: %v", categoryVersionID(version), wErr)
+		}
+	}
+
+	for _, version := range entry.Tasks {
+		from, to := taskVersions(version, undo)
+
+		var wErr error
+		switch {
+		case to == nil:
+			wErr = s.tasks.DeleteTask(from.ID)
+		case from == nil:
+			_, wErr = s.tasks.InsertTask(*to)
+		default:
+			_, wErr = s.tasks.UpdateTask(*to)
+		}
+		if wErr != nil {
+			return fmt.Errorf("can't write task %d: %v", taskVersionID(version), wErr)
+		}
+	}
+
+	return nil
+}
+
+// taskVersions returns the version a replay expects to find and the one it
+// writes in its place.
+func taskVersions(version models.TaskVersion, undo bool) (from, to *models.Task) {
+	if undo {
+		return version.After, version.Before
+	}
+
+	return version.Before, version.After
+}
+
+func categoryVersions(version models.CategoryVersion, undo bool) (from, to *models.Category) {
+	if undo {
+		return version.After, version.Before
+	}
+
+	return version.Before, version.After
+}
+
+// sameRecord compares records by their JSON form, which is how the journal
+// stores them, so time zones and empty slices don't tell them apart.
+func sameRecord(current, expected interface{}) bool {
+	a, aErr := json.Marshal(current)
+	b, bErr := json.Marshal(expected)
+
+	return aErr == nil && bErr == nil && string(a) == string(b)
+}
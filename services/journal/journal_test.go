@@ -0,0 +1,333 @@
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+type mockTaskRepository struct {
+	data map[int]models.Task
+}
+
+func (m mockTaskRepository) CreateNewTask(task models.Task) (models.Task, error) {
+	task.ID = 1
+	for id := range m.data {
+		if id >= task.ID {
+			task.ID = id + 1
+		}
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockTaskRepository) InsertTask(task models.Task) (models.Task, error) {
+	if _, ok := m.data[task.ID]; ok {
+		return models.Task{}, fmt.Errorf("task %d already exists", task.ID)
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockTaskRepository) ListTasks() ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, task := range m.data {
+		tasks = append(tasks, task)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	tasks, _ := m.ListTasks()
+
+	var userTasks []models.Task
+	for _, task := range tasks {
+		if task.UserID == userID {
+			userTasks = append(userTasks, task)
+		}
+	}
+
+	return userTasks, nil
+}
+
+func (m mockTaskRepository) UpdateTask(task models.Task) (models.Task, error) {
+	if _, ok := m.data[task.ID]; !ok {
+		return models.Task{}, fmt.Errorf("task %d not found", task.ID)
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockTaskRepository) DeleteTask(id int) error {
+	if _, ok := m.data[id]; !ok {
+		return fmt.Errorf("task %d not found", id)
+	}
+
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockCategoryRepository struct {
+	data map[int]models.Category
+}
+
+func (m mockCategoryRepository) CreateNewCategory(category models.Category) (models.Category, error) {
+	category.ID = len(m.data) + 1
+
+	m.data[category.ID] = category
+
+	return category, nil
+}
+
+func (m mockCategoryRepository) InsertCategory(category models.Category) (models.Category, error) {
+	m.data[category.ID] = category
+
+	return category, nil
+}
+
+func (m mockCategoryRepository) ListCategories() ([]models.Category, error) {
+	var categories []models.Category
+
+	for _, category := range m.data {
+		categories = append(categories, category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+
+	return categories, nil
+}
+
+func (m mockCategoryRepository) ListUserCategories(userID int) ([]models.Category, error) {
+	categories, _ := m.ListCategories()
+
+	var userCategories []models.Category
+	for _, category := range categories {
+		if category.UserID == userID {
+			userCategories = append(userCategories, category)
+		}
+	}
+
+	return userCategories, nil
+}
+
+func (m mockCategoryRepository) UpdateCategory(category models.Category) (models.Category, error) {
+	m.data[category.ID] = category
+
+	return category, nil
+}
+
+func (m mockCategoryRepository) DeleteCategory(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockRepository struct {
+	entries *[]models.JournalEntry
+}
+
+func (m mockRepository) AppendEntry(entry models.JournalEntry) (models.JournalEntry, error) {
+	entry.ID = len(*m.entries) + 1
+	for _, stored := range *m.entries {
+		if stored.ID >= entry.ID {
+			entry.ID = stored.ID + 1
+		}
+	}
+
+	*m.entries = append(*m.entries, entry)
+
+	return entry, nil
+}
+
+func (m mockRepository) ListUserEntries(userID int) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	for _, entry := range *m.entries {
+		if entry.UserID == userID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (m mockRepository) UpdateEntry(entry models.JournalEntry) (models.JournalEntry, error) {
+	for i := range *m.entries {
+		if (*m.entries)[i].ID == entry.ID {
+			(*m.entries)[i] = entry
+		}
+	}
+
+	return entry, nil
+}
+
+func (m mockRepository) DeleteEntries(ids []int) error {
+	var kept []models.JournalEntry
+	for _, entry := range *m.entries {
+		remove := false
+		for _, id := range ids {
+			remove = remove || entry.ID == id
+		}
+		if !remove {
+			kept = append(kept, entry)
+		}
+	}
+
+	*m.entries = kept
+
+	return nil
+}
+
+type fixture struct {
+	tasks      mockTaskRepository
+	categories mockCategoryRepository
+	service    Service
+}
+
+func newFixture() fixture {
+	tasks := mockTaskRepository{data: map[int]models.Task{}}
+	categories := mockCategoryRepository{data: map[int]models.Category{}}
+
+	return fixture{
+		tasks:      tasks,
+		categories: categories,
+		service:    NewService(mockRepository{entries: &[]models.JournalEntry{}}, tasks, categories),
+	}
+}
+
+// run records the changes made by op as one operation of user 1.
+func (f fixture) run(t *testing.T, command string, op func(r *Recorder)) {
+	t.Helper()
+
+	recorder := NewRecorder(f.tasks, f.categories)
+	op(recorder)
+
+	_, err := f.service.Record(RecordRequest{
+		Command:             command,
+		Tasks:               recorder.Tasks(),
+		Categories:          recorder.Categories(),
+		AuthenticatedUserID: 1,
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	f := newFixture()
+
+	f.run(t, "create-task", func(r *Recorder) {
+		r.CreateNewTask(models.Task{Title: "Buy milk", UserID: 1})
+	})
+	f.run(t, "edit-task", func(r *Recorder) {
+		r.UpdateTask(models.Task{ID: 1, Title: "Buy milk and eggs", UserID: 1})
+		r.UpdateTask(models.Task{ID: 1, Title: "Buy eggs", UserID: 1})
+	})
+
+	undone, err := f.service.Undo(UndoRequest{AuthenticatedUserID: 1})
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undone.Entry.Command != "edit-task" {
+		t.Errorf("undid %q, want edit-task", undone.Entry.Command)
+	}
+	if title := f.tasks.data[1].Title; title != "Buy milk" {
+		t.Errorf("undo should bring back the title from before the edit, got %q", title)
+	}
+
+	if _, err := f.service.Undo(UndoRequest{AuthenticatedUserID: 1}); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if len(f.tasks.data) != 0 {
+		t.Errorf("undoing create-task should remove the task, got %v", f.tasks.data)
+	}
+
+	if _, err := f.service.Undo(UndoRequest{AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Undo should fail with nothing left to undo")
+	}
+
+	redone, err := f.service.Redo(RedoRequest{AuthenticatedUserID: 1})
+	if err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if redone.Entry.Command != "create-task" {
+		t.Errorf("redid %q, want create-task", redone.Entry.Command)
+	}
+	if title := f.tasks.data[1].Title; title != "Buy milk" {
+		t.Errorf("redo should bring the task back under its ID, got %v", f.tasks.data)
+	}
+
+	// a new operation drops what's left to redo
+	f.run(t, "create-category", func(r *Recorder) {
+		r.CreateNewCategory(models.Category{Title: "Home", UserID: 1})
+	})
+	if _, err := f.service.Redo(RedoRequest{AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Redo should fail after a new operation")
+	}
+
+	if _, err := f.service.Undo(UndoRequest{AuthenticatedUserID: 1}); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if len(f.categories.data) != 0 {
+		t.Errorf("undoing create-category should remove the category, got %v", f.categories.data)
+	}
+
+	if _, err := f.service.Undo(UndoRequest{AuthenticatedUserID: 2}); err == nil {
+		t.Errorf("Undo should not reach the journal of another user")
+	}
+}
+
+func TestUndoRefusesChangedRecords(t *testing.T) {
+	f := newFixture()
+
+	f.run(t, "create-task", func(r *Recorder) {
+		r.CreateNewTask(models.Task{Title: "Buy milk", UserID: 1})
+		r.CreateNewTask(models.Task{Title: "Pay rent", UserID: 1})
+	})
+
+	// another client completes one of the tasks
+	f.tasks.UpdateTask(models.Task{ID: 2, Title: "Pay rent", UserID: 1, IsDone: true})
+
+	if _, err := f.service.Undo(UndoRequest{AuthenticatedUserID: 1}); err == nil {
+		t.Fatalf("Undo should refuse a task changed since the operation")
+	}
+	if len(f.tasks.data) != 2 {
+		t.Errorf("a refused undo should leave every task in place, got %v", f.tasks.data)
+	}
+
+	f.tasks.UpdateTask(models.Task{ID: 2, Title: "Pay rent", UserID: 1})
+
+	if _, err := f.service.Undo(UndoRequest{AuthenticatedUserID: 1}); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	f.tasks.CreateNewTask(models.Task{Title: "Clean the house", UserID: 1})
+
+	if _, err := f.service.Redo(RedoRequest{AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Redo should refuse to bring back a task whose ID is taken")
+	}
+}
+
+func TestRecorderSkipsShortLivedTasks(t *testing.T) {
+	f := newFixture()
+	recorder := NewRecorder(f.tasks, f.categories)
+
+	created, _ := recorder.CreateNewTask(models.Task{Title: "Buy milk", UserID: 1})
+	recorder.UpdateTask(models.Task{ID: created.ID, Title: "Buy eggs", UserID: 1})
+	recorder.DeleteTask(created.ID)
+
+	if versions := recorder.Tasks(); len(versions) != 0 {
+		t.Errorf("a task created and removed in one operation should leave nothing, got %v", versions)
+	}
+}
@@ -0,0 +1,232 @@
+package journal
+
+import "todo-cli-refactor/models"
+
+// TaskRepository is the task storage a Recorder writes through.
+type TaskRepository interface {
+	CreateNewTask(t models.Task) (models.Task, error)
+	ListTasks() ([]models.Task, error)
+	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+	DeleteTask(id int) error
+}
+
+// CategoryRepository is the category storage a Recorder writes through.
+type CategoryRepository interface {
+	CreateNewCategory(c models.Category) (models.Category, error)
+	ListCategories() ([]models.Category, error)
+	ListUserCategories(userID int) ([]models.Category, error)
+	UpdateCategory(c models.Category) (models.Category, error)
+	DeleteCategory(id int) error
+}
+
+// Recorder wraps the task and category repositories for the length of one
+// operation and remembers every record it changed, as it was before the
+// operation and as it is after. Services are given a Recorder in place of
+// the repositories; the versions it collected become a journal entry.
+type Recorder struct {
+	tasks      TaskRepository
+	categories CategoryRepository
+
+	taskVersions     []models.TaskVersion
+	categoryVersions []models.CategoryVersion
+}
+
+func NewRecorder(tasks TaskRepository, categories CategoryRepository) *Recorder {
+	return &Recorder{tasks: tasks, categories: categories}
+}
+
+// Tasks returns the task versions recorded so far.
+func (r *Recorder) Tasks() []models.TaskVersion {
+	return r.taskVersions
+}
+
+// Categories returns the category versions recorded so far.
+func (r *Recorder) Categories() []models.CategoryVersion {
+	return r.categoryVersions
+}
+
+func (r *Recorder) ListTasks() ([]models.Task, error) {
+	return r.tasks.ListTasks()
+}
+
+func (r *Recorder) ListUserTasks(userID int) ([]models.Task, error) {
+	return r.tasks.ListUserTasks(userID)
+}
+
+func (r *Recorder) CreateNewTask(task models.Task) (models.Task, error) {
+	created, err := r.tasks.CreateNewTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	r.recordTask(created.ID, nil, &created)
+
+	return created, nil
+}
+
+func (r *Recorder) UpdateTask(task models.Task) (models.Task, error) {
+	before, err := r.storedTask(task.ID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	updated, err := r.tasks.UpdateTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	r.recordTask(updated.ID, before, &updated)
+
+	return updated, nil
+}
+
+func (r *Recorder) DeleteTask(id int) error {
+	before, err := r.storedTask(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.tasks.DeleteTask(id); err != nil {
+		return err
+	}
+
+	r.recordTask(id, before, nil)
+
+	return nil
+}
+
+func (r *Recorder) storedTask(id int) (*models.Task, error) {
+	tasks, err := r.tasks.ListTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		if task.ID == id {
+			return &task, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// recordTask keeps the first before and the last after version of a task
+// changed more than once in the same operation. A task both created and
+// removed by the operation leaves nothing to record.
+func (r *Recorder) recordTask(id int, before, after *models.Task) {
+	for i, version := range r.taskVersions {
+		if taskVersionID(version) != id {
+			continue
+		}
+
+		if version.Before == nil && after == nil {
+			r.taskVersions = append(r.taskVersions[:i], r.taskVersions[i+1:]...)
+		} else {
+			r.taskVersions[i].After = after
+		}
+
+		return
+	}
+
+	r.taskVersions = append(r.taskVersions, models.TaskVersion{Before: before, After: after})
+}
+
+func taskVersionID(version models.TaskVersion) int {
+	if version.Before != nil {
+		return version.Before.ID
+	}
+
+	return version.After.ID
+}
+
+func (r *Recorder) ListCategories() ([]models.Category, error) {
+	return r.categories.ListCategories()
+}
+
+func (r *Recorder) ListUserCategories(userID int) ([]models.Category, error) {
+	return r.categories.ListUserCategories(userID)
+}
+
+func (r *Recorder) CreateNewCategory(category models.Category) (models.Category, error) {
+	created, err := r.categories.CreateNewCategory(category)
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	r.recordCategory(created.ID, nil, &created)
+
+	return created, nil
+}
+
+func (r *Recorder) UpdateCategory(category models.Category) (models.Category, error) {
+	before, err := r.storedCategory(category.ID)
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	updated, err := r.categories.UpdateCategory(category)
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	r.recordCategory(updated.ID, before, &updated)
+
+	return updated, nil
+}
+
+func (r *Recorder) DeleteCategory(id int) error {
+	before, err := r.storedCategory(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.categories.DeleteCategory(id); err != nil {
+		return err
+	}
+
+	r.recordCategory(id, before, nil)
+
+	return nil
+}
+
+func (r *Recorder) storedCategory(id int) (*models.Category, error) {
+	categories, err := r.categories.ListCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		if category.ID == id {
+			return &category, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *Recorder) recordCategory(id int, before, after *models.Category) {
+	for i, version := range r.categoryVersions {
+		if categoryVersionID(version) != id {
+			continue
+		}
+
+		if version.Before == nil && after == nil {
+			r.categoryVersions = append(r.categoryVersions[:i], r.categoryVersions[i+1:]...)
+		} else {
+			r.categoryVersions[i].After = after
+		}
+
+		return
+	}
+
+	r.categoryVersions = append(r.categoryVersions, models.CategoryVersion{Before: before, After: after})
+}
+
+func categoryVersionID(version models.CategoryVersion) int {
+	if version.Before != nil {
+		return version.Before.ID
+	}
+
+	return version.After.ID
+}
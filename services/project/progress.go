@@ -0,0 +1,121 @@
+package project
+
+import (
+	"fmt"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+// Progress counts the tasks of a project or a milestone.
+type Progress struct {
+	Open    int
+	Done    int
+	Overdue int
+	// Projected is when the open tasks will be done at the pace tasks were
+	// done since the project or milestone was created. It's zero when
+	// nothing was done yet, or when nothing is left to do.
+	Projected time.Time
+}
+
+type MilestoneProgress struct {
+	Milestone models.Milestone
+	Progress  Progress
+	// Late is set when the milestone's target date has passed with tasks
+	// still open, or is projected to.
+	Late bool
+}
+
+type ProgressRequest struct {
+	ProjectID           int
+	AuthenticatedUserID int
+}
+
+type ProgressResponse struct {
+	Project models.Project
+	// Milestones are in the order of their target dates.
+	Milestones []MilestoneProgress
+	Total      Progress
+}
+
+// Progress computes how far along a project and each of its milestones are
+// from the tasks planned for them. Tasks in the trash are left out.
+func (s Service) Progress(req ProgressRequest) (ProgressResponse, error) {
+	project, fErr := s.findProject(req.AuthenticatedUserID, req.ProjectID)
+	if fErr != nil {
+		return ProgressResponse{}, fErr
+	}
+
+	milestones, mErr := s.projectMilestones(req.AuthenticatedUserID, project.ID)
+	if mErr != nil {
+		return ProgressResponse{}, mErr
+	}
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ProgressResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	now := s.now()
+	response := ProgressResponse{Project: project}
+
+	for _, milestone := range milestones {
+		progress := Progress{}
+		for _, task := range tasks {
+			if task.MilestoneID == milestone.ID && task.DeletedAt == nil {
+				count(&progress, task, now)
+			}
+		}
+		progress.Projected = projectCompletion(progress, milestone.CreatedAt, now)
+
+		response.Total.Open += progress.Open
+		response.Total.Done += progress.Done
+		response.Total.Overdue += progress.Overdue
+
+		response.Milestones = append(response.Milestones, MilestoneProgress{
+			Milestone: milestone,
+			Progress:  progress,
+			Late:      isLate(milestone, progress, now),
+		})
+	}
+
+	response.Total.Projected = projectCompletion(response.Total, project.CreatedAt, now)
+
+	return response, nil
+}
+
+func count(progress *Progress, task models.Task, now time.Time) {
+	if task.IsDone {
+		progress.Done++
+
+		return
+	}
+
+	progress.Open++
+	if task.DueDate.Before(now) {
+		progress.Overdue++
+	}
+}
+
+// projectCompletion extrapolates the pace tasks were done at since start to
+// the tasks still open.
+func projectCompletion(progress Progress, start, now time.Time) time.Time {
+	if progress.Done == 0 || progress.Open == 0 || !start.Before(now) {
+		return time.Time{}
+	}
+
+	perTask := now.Sub(start) / time.Duration(progress.Done)
+
+	return now.Add(perTask * time.Duration(progress.Open)).Truncate(time.Second)
+}
+
+func isLate(milestone models.Milestone, progress Progress, now time.Time) bool {
+	if milestone.TargetDate.IsZero() || progress.Open == 0 {
+		return false
+	}
+
+	if milestone.TargetDate.Before(now) {
+		return true
+	}
+
+	return !progress.Projected.IsZero() && milestone.TargetDate.Before(progress.Projected)
+}
@@ -0,0 +1,344 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+type ProjectRepository interface {
+	CreateNewProject(p models.Project) (models.Project, error)
+	ListUserProjects(userID int) ([]models.Project, error)
+	UpdateProject(p models.Project) (models.Project, error)
+	DeleteProject(id int) error
+}
+
+type MilestoneRepository interface {
+	CreateNewMilestone(m models.Milestone) (models.Milestone, error)
+	ListUserMilestones(userID int) ([]models.Milestone, error)
+	UpdateMilestone(m models.Milestone) (models.Milestone, error)
+	DeleteMilestone(id int) error
+}
+
+// TaskRepository gives the tasks progress is computed from, and takes tasks
+// out of milestones that are deleted.
+type TaskRepository interface {
+	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+}
+
+type Service struct {
+	projects   ProjectRepository
+	milestones MilestoneRepository
+	tasks      TaskRepository
+	now        func() time.Time
+}
+
+func NewService(projects ProjectRepository, milestones MilestoneRepository, tasks TaskRepository) Service {
+	return Service{
+		projects:   projects,
+		milestones: milestones,
+		tasks:      tasks,
+		now:        time.Now,
+	}
+}
+
+type CreateRequest struct {
+	Title               string
+	Description         string
+	AuthenticatedUserID int
+}
+
+type CreateResponse struct {
+	Project models.Project
+}
+
+func (s Service) Create(req CreateRequest) (CreateResponse, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return CreateResponse{}, fmt.Errorf("can't create new project: title can't be empty")
+	}
+
+	created, cErr := s.projects.CreateNewProject(models.Project{
+		UserID:      req.AuthenticatedUserID,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+		CreatedAt:   s.now().UTC().Truncate(time.Second),
+	})
+	if cErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new project: %v", cErr)
+	}
+
+	return CreateResponse{Project: created}, nil
+}
+
+type ListRequest struct {
+	AuthenticatedUserID int
+}
+
+type ListResponse struct {
+	Projects []models.Project
+	// Milestones are the milestones of all listed projects.
+	Milestones []models.Milestone
+}
+
+func (s Service) List(req ListRequest) (ListResponse, error) {
+	projects, lErr := s.projects.ListUserProjects(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list user projects: %v", lErr)
+	}
+
+	milestones, mErr := s.milestones.ListUserMilestones(req.AuthenticatedUserID)
+	if mErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list user milestones: %v", mErr)
+	}
+
+	return ListResponse{Projects: projects, Milestones: milestones}, nil
+}
+
+// UpdateRequest changes the fields of a project that are set.
+type UpdateRequest struct {
+	ProjectID           int
+	Title               *string
+	Description         *string
+	AuthenticatedUserID int
+}
+
+type UpdateResponse struct {
+	Project models.Project
+}
+
+func (s Service) Update(req UpdateRequest) (UpdateResponse, error) {
+	project, fErr := s.findProject(req.AuthenticatedUserID, req.ProjectID)
+	if fErr != nil {
+		return UpdateResponse{}, fErr
+	}
+
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return UpdateResponse{}, fmt.Errorf("can't update project: title can't be empty")
+		}
+		project.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		project.Description = strings.TrimSpace(*req.Description)
+	}
+
+	updated, uErr := s.projects.UpdateProject(project)
+	if uErr != nil {
+		return UpdateResponse{}, fmt.Errorf("can't update project: %v", uErr)
+	}
+
+	return UpdateResponse{Project: updated}, nil
+}
+
+type DeleteRequest struct {
+	ProjectID           int
+	AuthenticatedUserID int
+}
+
+type DeleteResponse struct {
+	Project    models.Project
+	Milestones []models.Milestone
+}
+
+// Delete removes a project along with its milestones. Their tasks are kept
+// and only leave the project.
+func (s Service) Delete(req DeleteRequest) (DeleteResponse, error) {
+	project, fErr := s.findProject(req.AuthenticatedUserID, req.ProjectID)
+	if fErr != nil {
+		return DeleteResponse{}, fErr
+	}
+
+	milestones, mErr := s.projectMilestones(req.AuthenticatedUserID, project.ID)
+	if mErr != nil {
+		return DeleteResponse{}, mErr
+	}
+
+	for _, milestone := range milestones {
+		if dErr := s.deleteMilestone(milestone); dErr != nil {
+			return DeleteResponse{}, fmt.Errorf("can't delete project: %v", dErr)
+		}
+	}
+
+	if dErr := s.projects.DeleteProject(project.ID); dErr != nil {
+		return DeleteResponse{}, fmt.Errorf("can't delete project: %v", dErr)
+	}
+
+	return DeleteResponse{Project: project, Milestones: milestones}, nil
+}
+
+type CreateMilestoneRequest struct {
+	ProjectID           int
+	Title               string
+	TargetDate          models.DueDate
+	AuthenticatedUserID int
+}
+
+type CreateMilestoneResponse struct {
+	Milestone models.Milestone
+}
+
+func (s Service) CreateMilestone(req CreateMilestoneRequest) (CreateMilestoneResponse, error) {
+	if _, fErr := s.findProject(req.AuthenticatedUserID, req.ProjectID); fErr != nil {
+		return CreateMilestoneResponse{}, fErr
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return CreateMilestoneResponse{}, fmt.Errorf("can't create new milestone: title can't be empty")
+	}
+
+	created, cErr := s.milestones.CreateNewMilestone(models.Milestone{
+		ProjectID:  req.ProjectID,
+		UserID:     req.AuthenticatedUserID,
+		Title:      title,
+		TargetDate: req.TargetDate,
+		CreatedAt:  s.now().UTC().Truncate(time.Second),
+	})
+	if cErr != nil {
+		return CreateMilestoneResponse{}, fmt.Errorf("can't create new milestone: %v", cErr)
+	}
+
+	return CreateMilestoneResponse{Milestone: created}, nil
+}
+
+// UpdateMilestoneRequest changes the fields of a milestone that are set.
+type UpdateMilestoneRequest struct {
+	MilestoneID         int
+	Title               *string
+	TargetDate          *models.DueDate
+	AuthenticatedUserID int
+}
+
+type UpdateMilestoneResponse struct {
+	Milestone models.Milestone
+}
+
+func (s Service) UpdateMilestone(req UpdateMilestoneRequest) (UpdateMilestoneResponse, error) {
+	milestone, fErr := s.findMilestone(req.AuthenticatedUserID, req.MilestoneID)
+	if fErr != nil {
+		return UpdateMilestoneResponse{}, fErr
+	}
+
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return UpdateMilestoneResponse{}, fmt.Errorf("can't update milestone: title can't be empty")
+		}
+		milestone.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.TargetDate != nil {
+		milestone.TargetDate = *req.TargetDate
+	}
+
+	updated, uErr := s.milestones.UpdateMilestone(milestone)
+	if uErr != nil {
+		return UpdateMilestoneResponse{}, fmt.Errorf("can't update milestone: %v", uErr)
+	}
+
+	return UpdateMilestoneResponse{Milestone: updated}, nil
+}
+
+type DeleteMilestoneRequest struct {
+	MilestoneID         int
+	AuthenticatedUserID int
+}
+
+type DeleteMilestoneResponse struct {
+	Milestone models.Milestone
+}
+
+// DeleteMilestone removes a milestone, its tasks are kept and only leave
+// the project.
+func (s Service) DeleteMilestone(req DeleteMilestoneRequest) (DeleteMilestoneResponse, error) {
+	milestone, fErr := s.findMilestone(req.AuthenticatedUserID, req.MilestoneID)
+	if fErr != nil {
+		return DeleteMilestoneResponse{}, fErr
+	}
+
+	if dErr := s.deleteMilestone(milestone); dErr != nil {
+		return DeleteMilestoneResponse{}, fmt.Errorf("can't delete milestone: %v", dErr)
+	}
+
+	return DeleteMilestoneResponse{Milestone: milestone}, nil
+}
+
+func (s Service) deleteMilestone(milestone models.Milestone) error {
+	tasks, lErr := s.tasks.ListUserTasks(milestone.UserID)
+	if lErr != nil {
+		return fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	for _, task := range tasks {
+		if task.MilestoneID != milestone.ID {
+			continue
+		}
+
+		task.MilestoneID = 0
+		if _, uErr := s.tasks.UpdateTask(task); uErr != nil {
+			return fmt.Errorf("can't update task: %v", uErr)
+		}
+	}
+
+	return s.milestones.DeleteMilestone(milestone.ID)
+}
+
+func (s Service) findProject(userID, projectID int) (models.Project, error) {
+	projects, lErr := s.projects.ListUserProjects(userID)
+	if lErr != nil {
+		return models.Project{}, fmt.Errorf("can't list user projects: %v", lErr)
+	}
+
+	for _, project := range projects {
+		if project.ID == projectID {
+			return project, nil
+		}
+	}
+
+	return models.Project{}, fmt.Errorf("project %d not found", projectID)
+}
+
+func (s Service) findMilestone(userID, milestoneID int) (models.Milestone, error) {
+	milestones, lErr := s.milestones.ListUserMilestones(userID)
+	if lErr != nil {
+		return models.Milestone{}, fmt.Errorf("can't list user milestones: %v", lErr)
+	}
+
+	for _, milestone := range milestones {
+		if milestone.ID == milestoneID {
+			return milestone, nil
+		}
+	}
+
+	return models.Milestone{}, fmt.Errorf("milestone %d not found", milestoneID)
+}
+
+// projectMilestones returns the milestones of a project, earliest target
+// first.
+func (s Service) projectMilestones(userID, projectID int) ([]models.Milestone, error) {
+	milestones, lErr := s.milestones.ListUserMilestones(userID)
+	if lErr != nil {
+		return nil, fmt.Errorf("can't list user milestones: %v", lErr)
+	}
+
+	var owned []models.Milestone
+	for _, milestone := range milestones {
+		if milestone.ProjectID == projectID {
+			owned = append(owned, milestone)
+		}
+	}
+
+	// milestones without a target come last
+	sort.SliceStable(owned, func(i, j int) bool {
+		a, b := owned[i].TargetDate, owned[j].TargetDate
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+
+		return a.Time.Before(b.Time)
+	})
+
+	return owned, nil
+}
@@ -0,0 +1,242 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+type mockProjectRepository struct {
+	data map[int]models.Project
+}
+
+func (m mockProjectRepository) CreateNewProject(p models.Project) (models.Project, error) {
+	p.ID = len(m.data) + 1
+
+	m.data[p.ID] = p
+
+	return p, nil
+}
+
+func (m mockProjectRepository) ListUserProjects(userID int) ([]models.Project, error) {
+	var projects []models.Project
+
+	for _, p := range m.data {
+		if p.UserID == userID {
+			projects = append(projects, p)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+
+	return projects, nil
+}
+
+func (m mockProjectRepository) UpdateProject(p models.Project) (models.Project, error) {
+	if _, ok := m.data[p.ID]; !ok {
+		return models.Project{}, fmt.Errorf("project %d not found", p.ID)
+	}
+
+	m.data[p.ID] = p
+
+	return p, nil
+}
+
+func (m mockProjectRepository) DeleteProject(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockMilestoneRepository struct {
+	data map[int]models.Milestone
+}
+
+func (m mockMilestoneRepository) CreateNewMilestone(milestone models.Milestone) (models.Milestone, error) {
+	milestone.ID = len(m.data) + 1
+
+	m.data[milestone.ID] = milestone
+
+	return milestone, nil
+}
+
+func (m mockMilestoneRepository) ListUserMilestones(userID int) ([]models.Milestone, error) {
+	var milestones []models.Milestone
+
+	for _, milestone := range m.data {
+		if milestone.UserID == userID {
+			milestones = append(milestones, milestone)
+		}
+	}
+
+	sort.Slice(milestones, func(i, j int) bool { return milestones[i].ID < milestones[j].ID })
+
+	return milestones, nil
+}
+
+func (m mockMilestoneRepository) UpdateMilestone(milestone models.Milestone) (models.Milestone, error) {
+	if _, ok := m.data[milestone.ID]; !ok {
+		return models.Milestone{}, fmt.Errorf("milestone %d not found", milestone.ID)
+	}
+
+	m.data[milestone.ID] = milestone
+
+	return milestone, nil
+}
+
+func (m mockMilestoneRepository) DeleteMilestone(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockTaskRepository struct {
+	data map[int]models.Task
+}
+
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, task := range m.data {
+		if task.UserID == userID {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) UpdateTask(task models.Task) (models.Task, error) {
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func newService(tasks map[int]models.Task, now time.Time) Service {
+	s := NewService(
+		mockProjectRepository{data: map[int]models.Project{}},
+		mockMilestoneRepository{data: map[int]models.Milestone{}},
+		mockTaskRepository{data: tasks},
+	)
+	s.now = func() time.Time { return now }
+
+	return s
+}
+
+func TestProjects(t *testing.T) {
+	tasks := map[int]models.Task{
+		1: {ID: 1, UserID: 3, Title: "design", MilestoneID: 1},
+		2: {ID: 2, UserID: 3, Title: "launch", MilestoneID: 2},
+	}
+	s := newService(tasks, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC))
+
+	created, err := s.Create(CreateRequest{Title: " Website ", AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.Project.Title != "Website" {
+		t.Errorf("unexpected project %+v", created.Project)
+	}
+
+	if _, err := s.Create(CreateRequest{Title: " ", AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Create should fail for an empty title")
+	}
+
+	for _, title := range []string{"Beta", "Launch"} {
+		if _, err := s.CreateMilestone(CreateMilestoneRequest{ProjectID: created.Project.ID, Title: title, AuthenticatedUserID: 3}); err != nil {
+			t.Fatalf("CreateMilestone failed: %v", err)
+		}
+	}
+
+	if _, err := s.CreateMilestone(CreateMilestoneRequest{ProjectID: created.Project.ID, Title: "Beta", AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("CreateMilestone should not find the projects of another user")
+	}
+
+	if _, err := s.DeleteMilestone(DeleteMilestoneRequest{MilestoneID: 1, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("DeleteMilestone should not find the milestones of another user")
+	}
+
+	if _, err := s.DeleteMilestone(DeleteMilestoneRequest{MilestoneID: 1, AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("DeleteMilestone failed: %v", err)
+	}
+	if tasks[1].MilestoneID != 0 || tasks[2].MilestoneID != 2 {
+		t.Errorf("only the tasks of the deleted milestone should leave it, got %v", tasks)
+	}
+
+	deleted, err := s.Delete(DeleteRequest{ProjectID: created.Project.ID, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(deleted.Milestones) != 1 || tasks[2].MilestoneID != 0 {
+		t.Errorf("deleting a project should delete its milestones, got %v and tasks %v", deleted.Milestones, tasks)
+	}
+
+	listed, err := s.List(ListRequest{AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listed.Projects) != 0 || len(listed.Milestones) != 0 {
+		t.Errorf("expected nothing left, got %v", listed)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	deletedAt := now
+
+	tasks := map[int]models.Task{
+		1: {ID: 1, UserID: 3, MilestoneID: 1, IsDone: true},
+		2: {ID: 2, UserID: 3, MilestoneID: 1, IsDone: true},
+		3: {ID: 3, UserID: 3, MilestoneID: 1, DueDate: models.NewDueDate(2026, 10, 18)},
+		4: {ID: 4, UserID: 3, MilestoneID: 2, DueDate: models.NewDueDate(2026, 10, 19)},
+		5: {ID: 5, UserID: 3, MilestoneID: 2, DeletedAt: &deletedAt},
+		6: {ID: 6, UserID: 3},
+	}
+
+	s := newService(tasks, now.AddDate(0, 0, -10))
+	project, _ := s.Create(CreateRequest{Title: "Website", AuthenticatedUserID: 3})
+	s.CreateMilestone(CreateMilestoneRequest{ProjectID: project.Project.ID, Title: "Launch", AuthenticatedUserID: 3})
+	s.CreateMilestone(CreateMilestoneRequest{ProjectID: project.Project.ID, Title: "Beta", TargetDate: models.NewDueDate(2026, 10, 22), AuthenticatedUserID: 3})
+	s.now = func() time.Time { return now }
+
+	res, err := s.Progress(ProgressRequest{ProjectID: project.Project.ID, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Progress failed: %v", err)
+	}
+
+	// two tasks done in ten days leave five days for each open one
+	expected := Progress{Open: 2, Done: 2, Overdue: 1, Projected: now.AddDate(0, 0, 10)}
+	if res.Total != expected {
+		t.Errorf("unexpected total: got %+v, want %+v", res.Total, expected)
+	}
+
+	if len(res.Milestones) != 2 {
+		t.Fatalf("expected 2 milestones, got %v", res.Milestones)
+	}
+
+	beta, launch := res.Milestones[0], res.Milestones[1]
+	if beta.Milestone.Title != "Beta" || launch.Milestone.Title != "Launch" {
+		t.Fatalf("milestones should be ordered by target date, got %v", res.Milestones)
+	}
+
+	// nothing of beta was done yet, so there's no pace to project from
+	if beta.Progress != (Progress{Open: 1}) || beta.Late {
+		t.Errorf("unexpected beta progress %+v, late %t", beta.Progress, beta.Late)
+	}
+
+	if launch.Progress != (Progress{Open: 1, Done: 2, Overdue: 1, Projected: now.AddDate(0, 0, 5)}) || launch.Late {
+		t.Errorf("unexpected launch progress %+v, late %t", launch.Progress, launch.Late)
+	}
+
+	s.UpdateMilestone(UpdateMilestoneRequest{MilestoneID: 1, TargetDate: &models.DueDate{Time: now.AddDate(0, 0, 2)}, AuthenticatedUserID: 3})
+
+	// launch now comes first, due before beta
+	res, _ = s.Progress(ProgressRequest{ProjectID: project.Project.ID, AuthenticatedUserID: 3})
+	if !res.Milestones[0].Late {
+		t.Errorf("a milestone projected to finish after its target should be late")
+	}
+}
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"strings"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/search"
+)
+
+// Field weights: a word in the title says more about a task than one in its
+// description.
+const (
+	titleWeight       = 3
+	tagWeight         = 2
+	descriptionWeight = 1
+)
+
+// IndexedRepository is the task storage an Indexer keeps the index of.
+type IndexedRepository interface {
+	CreateNewTask(t models.Task) (models.Task, error)
+	InsertTask(t models.Task) (models.Task, error)
+	ListTasks() ([]models.Task, error)
+	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+	DeleteTask(id int) error
+}
+
+// Indexer wraps a task repository and updates a search index with every
+// task written through it.
+type Indexer struct {
+	tasks IndexedRepository
+	index *search.Index
+}
+
+func NewIndexer(tasks IndexedRepository, index *search.Index) Indexer {
+	return Indexer{tasks: tasks, index: index}
+}
+
+// Rebuild indexes every stored task.
+func (i Indexer) Rebuild() error {
+	tasks, err := i.tasks.ListTasks()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		i.add(task)
+	}
+
+	return nil
+}
+
+func (i Indexer) ListTasks() ([]models.Task, error) {
+	return i.tasks.ListTasks()
+}
+
+func (i Indexer) ListUserTasks(userID int) ([]models.Task, error) {
+	return i.tasks.ListUserTasks(userID)
+}
+
+func (i Indexer) CreateNewTask(task models.Task) (models.Task, error) {
+	created, err := i.tasks.CreateNewTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	i.add(created)
+
+	return created, nil
+}
+
+func (i Indexer) InsertTask(task models.Task) (models.Task, error) {
+	inserted, err := i.tasks.InsertTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	i.add(inserted)
+
+	return inserted, nil
+}
+
+func (i Indexer) UpdateTask(task models.Task) (models.Task, error) {
+	updated, err := i.tasks.UpdateTask(task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	i.add(updated)
+
+	return updated, nil
+}
+
+func (i Indexer) DeleteTask(id int) error {
+	if err := i.tasks.DeleteTask(id); err != nil {
+		return err
+	}
+
+	i.index.Remove(id)
+
+	return nil
+}
+
+func (i Indexer) add(task models.Task) {
+	i.index.Add(task.ID,
+		search.Field{Text: task.Title, Weight: titleWeight},
+		search.Field{Text: strings.Join(task.Tags, " "), Weight: tagWeight},
+		search.Field{Text: task.Description, Weight: descriptionWeight},
+	)
+}
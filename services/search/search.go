@@ -0,0 +1,87 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/search"
+)
+
+// defaultLimit is how many results a search returns when no limit is given.
+const defaultLimit = 10
+
+type ServiceRepository interface {
+	ListUserTasks(userID int) ([]models.Task, error)
+}
+
+type Service struct {
+	repository ServiceRepository
+	index      *search.Index
+}
+
+func NewService(repo ServiceRepository, index *search.Index) Service {
+	return Service{
+		repository: repo,
+		index:      index,
+	}
+}
+
+type SearchRequest struct {
+	Query               string
+	Limit               int
+	AuthenticatedUserID int
+}
+
+type Result struct {
+	Task  models.Task
+	Score float64
+}
+
+type SearchResponse struct {
+	// Results are the matching tasks, best match first.
+	Results []Result
+	// Total is the number of matching tasks, including ones past the limit.
+	Total int
+}
+
+// Search finds the tasks of a user matching a query. Tasks in the trash
+// aren't searched.
+func (s Service) Search(req SearchRequest) (SearchResponse, error) {
+	if len(search.Parse(req.Query)) == 0 {
+		return SearchResponse{}, fmt.Errorf("search query %q has no words to search for", strings.TrimSpace(req.Query))
+	}
+
+	tasks, lErr := s.repository.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return SearchResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	live := map[int]models.Task{}
+	for _, task := range tasks {
+		if task.DeletedAt == nil {
+			live[task.ID] = task
+		}
+	}
+
+	hits := s.index.Search(req.Query, func(id int) bool {
+		_, ok := live[id]
+
+		return ok
+	})
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	response := SearchResponse{Total: len(hits)}
+	for i, hit := range hits {
+		if i == limit {
+			break
+		}
+
+		response.Results = append(response.Results, Result{Task: live[hit.ID], Score: hit.Score})
+	}
+
+	return response, nil
+}
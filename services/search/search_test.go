@@ -0,0 +1,129 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/search"
+)
+
+type mockRepository struct {
+	data map[int]models.Task
+}
+
+func (m mockRepository) CreateNewTask(task models.Task) (models.Task, error) {
+	task.ID = len(m.data) + 1
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockRepository) InsertTask(task models.Task) (models.Task, error) {
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockRepository) ListTasks() ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, task := range m.data {
+		tasks = append(tasks, task)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+func (m mockRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	tasks, _ := m.ListTasks()
+
+	var userTasks []models.Task
+	for _, task := range tasks {
+		if task.UserID == userID {
+			userTasks = append(userTasks, task)
+		}
+	}
+
+	return userTasks, nil
+}
+
+func (m mockRepository) UpdateTask(task models.Task) (models.Task, error) {
+	if _, ok := m.data[task.ID]; !ok {
+		return models.Task{}, fmt.Errorf("task %d not found", task.ID)
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockRepository) DeleteTask(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+func resultIDs(response SearchResponse) []int {
+	var ids []int
+	for _, result := range response.Results {
+		ids = append(ids, result.Task.ID)
+	}
+
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	repo := mockRepository{data: map[int]models.Task{
+		1: {ID: 1, Title: "Buy milk", UserID: 1},
+		2: {ID: 2, Title: "Pay rent", Description: "remember the **milk** bill", UserID: 1},
+	}}
+
+	index := search.NewIndex()
+	indexer := NewIndexer(repo, index)
+	if err := indexer.Rebuild(); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	service := NewService(indexer, index)
+
+	indexer.CreateNewTask(models.Task{Title: "Call mom", Tags: []string{"@milk"}, UserID: 1})
+	indexer.CreateNewTask(models.Task{Title: "Buy milk", UserID: 2})
+
+	response, err := service.Search(SearchRequest{Query: "milk", AuthenticatedUserID: 1})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := resultIDs(response); fmt.Sprint(ids) != "[1 3 2]" {
+		t.Errorf("title, tag and description matches should rank in that order, got %v", ids)
+	}
+
+	deletedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	indexer.UpdateTask(models.Task{ID: 1, Title: "Buy milk", UserID: 1, DeletedAt: &deletedAt})
+	indexer.UpdateTask(models.Task{ID: 3, Title: "Call mom", UserID: 1})
+	indexer.DeleteTask(2)
+
+	response, err = service.Search(SearchRequest{Query: "milk", AuthenticatedUserID: 1})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(response.Results) != 0 {
+		t.Errorf("trashed, edited and removed tasks should no longer match, got %v", resultIDs(response))
+	}
+
+	response, err = service.Search(SearchRequest{Query: "mil*", Limit: 1, AuthenticatedUserID: 2})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := resultIDs(response); fmt.Sprint(ids) != "[4]" || response.Total != 1 {
+		t.Errorf("got %v of %d, want [4] of 1", ids, response.Total)
+	}
+
+	if _, err := service.Search(SearchRequest{Query: " ** ", AuthenticatedUserID: 1}); err == nil {
+		t.Errorf("Search should fail for a query without words")
+	}
+}
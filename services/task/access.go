@@ -0,0 +1,84 @@
+package task
+
+import (
+	"fmt"
+	"todo-cli-refactor/models"
+	category2 "todo-cli-refactor/services/category"
+)
+
+// Role is the role a user has on a task: owners own it, assignees edit
+// it, and others have the role the task's category is shared with them as.
+func Role(task models.Task, categories []models.Category, userID int) models.Role {
+	if task.UserID == userID {
+		return models.OwnerRole
+	}
+
+	role := category2.Role(categories, task.CategoryID, userID)
+	if task.AssigneeID == userID && !role.Allows(models.EditorRole) {
+		return models.EditorRole
+	}
+
+	return role
+}
+
+// checkRole makes sure a user has at least the needed role on a task.
+func (t Service) checkRole(task models.Task, userID int, need models.Role) error {
+	categories, err := t.repository.ListCategories()
+	if err != nil {
+		return fmt.Errorf("can't list categories: %v", err)
+	}
+
+	if role := Role(task, categories, userID); !role.Allows(need) {
+		return fmt.Errorf("task %d is shared with you as %s, changing it takes %s", task.ID, role, need)
+	}
+
+	return nil
+}
+
+// checkCategory makes sure a user may put tasks in a category, which takes
+// an editor. Categories that aren't stored are left to the caller.
+func (t Service) checkCategory(categoryID, userID int) error {
+	if categoryID == 0 {
+		return nil
+	}
+
+	categories, err := t.repository.ListCategories()
+	if err != nil {
+		return fmt.Errorf("can't list categories: %v", err)
+	}
+
+	if len(category2.Subtree(categories, categoryID)) == 0 {
+		return nil
+	}
+
+	role := category2.Role(categories, categoryID, userID)
+	if role == "" {
+		return fmt.Errorf("category %d isn't shared with you, adding tasks takes %s", categoryID, models.EditorRole)
+	}
+	if !role.Allows(models.EditorRole) {
+		return fmt.Errorf("category %d is shared with you as %s, adding tasks takes %s", categoryID, role, models.EditorRole)
+	}
+
+	return nil
+}
+
+// checkMilestone makes sure a task is planned for a milestone of the user.
+// Zero takes a task out of its project and is always valid.
+func (t Service) checkMilestone(milestoneID, userID int) error {
+	if milestoneID == 0 {
+		return nil
+	}
+
+	milestones, err := t.milestones.ListUserMilestones(userID)
+	if err != nil {
+		return fmt.Errorf("can't list user milestones: %v", err)
+	}
+
+	for _, milestone := range milestones {
+		if milestone.ID == milestoneID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("milestone %d not found", milestoneID)
+}
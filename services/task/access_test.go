@@ -0,0 +1,160 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestSharedCategoryRoles(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Book the venue", CategoryID: 1, UserID: 3, Tags: []string{"#party"}},
+			2: {ID: 2, Title: "Order the cake", CategoryID: 2, UserID: 3, Tags: []string{"#party"}},
+			3: {ID: 3, Title: "Pay rent", CategoryID: 3, UserID: 3},
+		},
+		categories: []models.Category{
+			{ID: 1, Title: "Party", UserID: 3, Shares: []models.Share{
+				{UserID: 4, Role: models.ViewerRole},
+				{UserID: 5, Role: models.AdminRole},
+			}},
+			{ID: 2, Title: "Food", UserID: 3, ParentID: 1, Shares: []models.Share{{UserID: 4, Role: models.EditorRole}}},
+			{ID: 3, Title: "Home", UserID: 3},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	list, err := s.List(ListRequest{UserID: 4})
+	if err != nil || len(list.Tasks) != 2 {
+		t.Fatalf("the viewer should list the tasks of the shared categories: got %v, %v", list.Tasks, err)
+	}
+
+	title := "Book the hall"
+	if _, err := s.Update(UpdateRequest{TaskID: 1, Title: &title, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("a viewer shouldn't update a task")
+	}
+	if _, err := s.Complete(CompleteRequest{TaskID: 1, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("a viewer shouldn't complete a task")
+	}
+	if _, err := s.AddTags(TagRequest{TaskID: 1, Tags: []string{"#venue"}, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("a viewer shouldn't tag a task")
+	}
+
+	// the subcategory is shared for editing on its own
+	if _, err := s.Complete(CompleteRequest{TaskID: 2, AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("an editor should complete a task: %v", err)
+	}
+
+	renamed, rErr := s.RenameTag(RenameTagRequest{From: "#party", To: "#birthday", AuthenticatedUserID: 4})
+	if rErr != nil || len(renamed.Tasks) != 1 || renamed.Tasks[0].ID != 2 {
+		t.Errorf("the tag should only be renamed on the editable task: got %v, %v", renamed.Tasks, rErr)
+	}
+
+	if _, err := s.Create(CreateRequest{Title: "Send invites", CategoryID: 1, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("a viewer shouldn't add tasks to the category")
+	}
+	if _, err := s.Create(CreateRequest{Title: "Pay rent twice", CategoryID: 3, AuthenticatedUserID: 4}); err == nil ||
+		!strings.Contains(err.Error(), "isn't shared with you") {
+		t.Errorf("tasks shouldn't be added to a category that isn't shared: got %v", err)
+	}
+
+	created, cErr := s.Create(CreateRequest{Title: "Buy candles", CategoryID: 2, AuthenticatedUserID: 4})
+	if cErr != nil {
+		t.Fatalf("an editor should add tasks to the category: %v", cErr)
+	}
+
+	// the owner of the category sees the task its editor added
+	owner, _ := s.List(ListRequest{UserID: 3})
+	if len(owner.Tasks) != 4 {
+		t.Errorf("the owner should list %d tasks, got %v", 4, owner.Tasks)
+	}
+
+	if _, err := s.Assign(AssignRequest{TaskID: 1, AssigneeID: 4, AuthenticatedUserID: 5}); err != nil {
+		t.Errorf("an admin should assign a task: %v", err)
+	}
+	if _, err := s.Assign(AssignRequest{TaskID: created.Task.ID, AssigneeID: 5, AuthenticatedUserID: 3}); err != nil {
+		t.Errorf("the owner of the category should assign a task: %v", err)
+	}
+	if _, err := s.Assign(AssignRequest{TaskID: 2, AssigneeID: 5, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("an editor shouldn't assign a task")
+	}
+
+	// the viewer is assigned the task now, which makes them an editor of it
+	if _, err := s.Update(UpdateRequest{TaskID: 1, Title: &title, AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("the assignee should update the task: %v", err)
+	}
+}
+
+func TestMilestoneOwnership(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Draft the spec", UserID: 3},
+		},
+		milestones: []models.Milestone{
+			{ID: 1, ProjectID: 1, UserID: 3, Title: "Alpha"},
+			{ID: 2, ProjectID: 2, UserID: 4, Title: "Beta"},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	if _, err := s.Create(CreateRequest{Title: "Review the spec", MilestoneID: 2, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("a task shouldn't be planned for a milestone of another user")
+	}
+
+	created, err := s.Create(CreateRequest{Title: "Review the spec", MilestoneID: 1, AuthenticatedUserID: 3})
+	if err != nil || created.Task.MilestoneID != 1 {
+		t.Fatalf("a task should be planned for a milestone of the user: got %v, %v", created.Task, err)
+	}
+
+	other := 2
+	if _, err := s.Update(UpdateRequest{TaskID: 1, MilestoneID: &other, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("a task shouldn't be moved to a milestone of another user")
+	}
+
+	none := 0
+	if _, err := s.Update(UpdateRequest{TaskID: created.Task.ID, MilestoneID: &none, AuthenticatedUserID: 3}); err != nil {
+		t.Errorf("a task should be taken out of its milestone: %v", err)
+	}
+}
+
+func TestSubtaskRoles(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Plan the party", CategoryID: 2, UserID: 3},
+			2: {ID: 2, Title: "Book the venue", CategoryID: 1, ParentID: 1, UserID: 3},
+		},
+		categories: []models.Category{
+			{ID: 1, Title: "Party", UserID: 3, Shares: []models.Share{{UserID: 4, Role: models.ViewerRole}}},
+			{ID: 2, Title: "Food", UserID: 3, Shares: []models.Share{{UserID: 4, Role: models.EditorRole}}},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	if _, err := s.Complete(CompleteRequest{TaskID: 1, Force: true, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("forcing a task done shouldn't complete subtasks the user can only view")
+	}
+	if mr.data[1].IsDone || mr.data[2].IsDone {
+		t.Errorf("a refused completion shouldn't change any task: got %v", mr.data)
+	}
+
+	if _, err := s.Create(CreateRequest{Title: "Bring snacks", ParentID: 2, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("a subtask shouldn't be added below a task the user can only view")
+	}
+
+	own, err := s.Create(CreateRequest{Title: "Bring snacks", AuthenticatedUserID: 4})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	parentID := 2
+	if _, err := s.Update(UpdateRequest{TaskID: own.Task.ID, ParentID: &parentID, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("a task shouldn't be moved below a task the user can only view")
+	}
+
+	parentID = 1
+	if _, err := s.Update(UpdateRequest{TaskID: own.Task.ID, ParentID: &parentID, AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("a task should be moved below a task the user can edit: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package task
+
+import (
+	"fmt"
+	"todo-cli-refactor/models"
+)
+
+type AssignRequest struct {
+	TaskID int
+	// AssigneeID is the user to assign the task to, it's checked to be a
+	// registered user by the caller. Zero, or the owner, unassigns the task.
+	AssigneeID          int
+	AuthenticatedUserID int
+}
+
+type AssignResponse struct {
+	Task models.Task
+}
+
+// Assign hands a task to another user, who then sees it among their own
+// tasks. The owner and admins of its category assign a task, the assignee
+// can only give it back.
+func (t Service) Assign(req AssignRequest) (AssignResponse, error) {
+
+	task, fErr := t.findEditableTask(req.AuthenticatedUserID, req.TaskID)
+	if fErr != nil {
+		return AssignResponse{}, fErr
+	}
+
+	assigneeID := req.AssigneeID
+	if assigneeID == task.UserID {
+		assigneeID = 0
+	}
+
+	givenBack := assigneeID == 0 && task.AssigneeID == req.AuthenticatedUserID
+	if !givenBack {
+		if rErr := t.checkRole(task, req.AuthenticatedUserID, models.AdminRole); rErr != nil {
+			return AssignResponse{}, fmt.Errorf("task %d can only be assigned by its owner or an admin", task.ID)
+		}
+	}
+
+	if assigneeID == task.AssigneeID {
+		return AssignResponse{Task: task}, nil
+	}
+
+	task.AssigneeID = assigneeID
+
+	updatedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return AssignResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return AssignResponse{Task: updatedTask}, nil
+}
+
+type TransferRequest struct {
+	TaskID int
+	// OwnerID is the user to give the task to, it's checked to be a
+	// registered user by the caller.
+	OwnerID int
+	// Workflow is the new owner's workflow, the tasks take its statuses.
+	Workflow            models.Workflow
+	AuthenticatedUserID int
+}
+
+type TransferResponse struct {
+	// Tasks are the transferred task followed by its subtasks.
+	Tasks []models.Task
+	// Unlinked are tasks of the old owner that no longer wait on a
+	// transferred task.
+	Unlinked []models.Task
+}
+
+// Transfer makes another user the owner of a task and its subtasks. The
+// category, milestone and custom fields of the tasks are the old owner's, so
+// they're dropped, and a task handed to its assignee is no longer assigned.
+// Dependencies only link tasks of the same user, so the ones between the
+// transferred tasks and the tasks staying behind are removed, and the tasks
+// start over in the new owner's workflow. Nothing is changed unless all the
+// tasks fit in the WIP limits of that workflow.
+func (t Service) Transfer(req TransferRequest) (TransferResponse, error) {
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return TransferResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID)
+	if fErr != nil {
+		return TransferResponse{}, fErr
+	}
+
+	if task.UserID != req.AuthenticatedUserID {
+		return TransferResponse{}, fmt.Errorf("task %d can only be transferred by its owner", task.ID)
+	}
+
+	if req.OwnerID == 0 || req.OwnerID == task.UserID {
+		return TransferResponse{}, fmt.Errorf("task %d needs another user to be transferred to", task.ID)
+	}
+
+	// the parent stays with the old owner
+	task.ParentID = 0
+
+	var moving []models.Task
+	transferred := map[int]bool{}
+	for _, moved := range append([]models.Task{task}, descendants(task.ID, childrenOf(tasks))...) {
+		if moved.UserID == req.AuthenticatedUserID {
+			moving = append(moving, moved)
+			transferred[moved.ID] = true
+		}
+	}
+
+	workflow := workflowOrDefault(req.Workflow)
+
+	// the recipient's own tasks hold the WIP limits the moved tasks need room in
+	recipientTasks, rErr := t.listUserTasks(req.OwnerID)
+	if rErr != nil {
+		return TransferResponse{}, fmt.Errorf("can't list user tasks: %v", rErr)
+	}
+
+	var changed, originals []models.Task
+	for _, moved := range moving {
+		originals = append(originals, moved)
+
+		moved.UserID = req.OwnerID
+		if moved.AssigneeID == req.OwnerID {
+			moved.AssigneeID = 0
+		}
+		moved.CategoryID = 0
+		moved.MilestoneID = 0
+		moved.Fields = nil
+		moved.BlockedBy = linkedWithin(moved.BlockedBy, transferred, true)
+
+		moved.Status = workflow.InitialStatus()
+		if moved.IsDone {
+			moved.Status = workflow.DoneStatus()
+		}
+
+		if wErr := checkWIPLimit(recipientTasks, workflow, moved.Status, req.OwnerID, 0); wErr != nil {
+			return TransferResponse{}, fmt.Errorf("can't transfer task %d: %v", task.ID, wErr)
+		}
+		recipientTasks = append(recipientTasks, moved)
+		changed = append(changed, moved)
+	}
+
+	for _, staying := range tasks {
+		if transferred[staying.ID] {
+			continue
+		}
+
+		blockedBy := linkedWithin(staying.BlockedBy, transferred, false)
+		if len(blockedBy) == len(staying.BlockedBy) {
+			continue
+		}
+		originals = append(originals, staying)
+
+		staying.BlockedBy = blockedBy
+		changed = append(changed, staying)
+	}
+
+	response := TransferResponse{}
+	for i, c := range changed {
+		updatedTask, uErr := t.repository.UpdateTask(c)
+		if uErr != nil {
+			return TransferResponse{}, t.rollback(originals[:i], fmt.Errorf("can't update task: %v", uErr))
+		}
+
+		if transferred[c.ID] {
+			response.Tasks = append(response.Tasks, updatedTask)
+		} else {
+			response.Unlinked = append(response.Unlinked, updatedTask)
+		}
+	}
+
+	return response, nil
+}
+
+// linkedWithin keeps the task IDs that are among the transferred tasks, or
+// the ones that aren't when within is false.
+func linkedWithin(ids []int, transferred map[int]bool, within bool) []int {
+	var kept []int
+	for _, id := range ids {
+		if transferred[id] == within {
+			kept = append(kept, id)
+		}
+	}
+
+	return kept
+}
+
+// checkOwner refuses changes to a task that only its owner may make.
+func checkOwner(task models.Task, userID int, change string) error {
+	if task.UserID != userID {
+		return fmt.Errorf("only the owner of task %d can change its %s", task.ID, change)
+	}
+
+	return nil
+}
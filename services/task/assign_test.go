@@ -0,0 +1,183 @@
+package task
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestAssign(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Review the budget", CategoryID: 2, UserID: 3},
+			2: {ID: 2, Title: "Read a book", UserID: 5},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	if _, err := s.Assign(AssignRequest{TaskID: 2, AssigneeID: 4, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Assign should fail for a task of another user")
+	}
+
+	res, err := s.Assign(AssignRequest{TaskID: 1, AssigneeID: 4, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if res.Task.AssigneeID != 4 {
+		t.Errorf("expected the task to be assigned to 4, got %d", res.Task.AssigneeID)
+	}
+
+	list, lErr := s.List(ListRequest{UserID: 4})
+	if lErr != nil || len(list.Tasks) != 1 || list.Tasks[0].ID != 1 {
+		t.Errorf("the assignee should list the task: got %v, %v", list.Tasks, lErr)
+	}
+
+	if _, err := s.Assign(AssignRequest{TaskID: 1, AssigneeID: 5, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("the assignee shouldn't pass the task on")
+	}
+
+	title := "Review the budget for 2027"
+	if _, err := s.Update(UpdateRequest{TaskID: 1, Title: &title, AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("the assignee should update the title: %v", err)
+	}
+
+	categoryID := 7
+	if _, err := s.Update(UpdateRequest{TaskID: 1, CategoryID: &categoryID, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("the assignee shouldn't move the task to a category")
+	}
+
+	if _, err := s.Complete(CompleteRequest{TaskID: 1, AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("the assignee should complete the task: %v", err)
+	}
+	if !mr.data[1].IsDone || mr.data[1].Status != "done" {
+		t.Errorf("expected the task to be done, got %+v", mr.data[1])
+	}
+
+	if _, err := s.Assign(AssignRequest{TaskID: 1, AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("the assignee should give the task back: %v", err)
+	}
+	if mr.data[1].AssigneeID != 0 {
+		t.Errorf("expected the task to be unassigned, got %d", mr.data[1].AssigneeID)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Release 3", CategoryID: 2, MilestoneID: 1, UserID: 3, AssigneeID: 4},
+			2: {ID: 2, Title: "Write changelog", CategoryID: 2, ParentID: 1, UserID: 3},
+			3: {ID: 3, Title: "Plan release 4", UserID: 3, AssigneeID: 5},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	if _, err := s.Transfer(TransferRequest{TaskID: 3, OwnerID: 5, AuthenticatedUserID: 5}); err == nil {
+		t.Errorf("Transfer should fail for the assignee")
+	}
+	if _, err := s.Transfer(TransferRequest{TaskID: 1, OwnerID: 3, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Transfer should fail for the owner")
+	}
+
+	res, err := s.Transfer(TransferRequest{TaskID: 1, OwnerID: 4, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if len(res.Tasks) != 2 {
+		t.Fatalf("the subtask should move with its parent: got %v", res.Tasks)
+	}
+
+	for _, id := range []int{1, 2} {
+		task := mr.data[id]
+		if task.UserID != 4 || task.AssigneeID != 0 || task.CategoryID != 0 || task.MilestoneID != 0 {
+			t.Errorf("unexpected transferred task %+v", task)
+		}
+	}
+	if mr.data[2].ParentID != 1 {
+		t.Errorf("the subtask should keep its parent, got %d", mr.data[2].ParentID)
+	}
+}
+
+func TestTransferDependenciesAndStatus(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Release 3", UserID: 3, Status: "doing"},
+			2: {ID: 2, Title: "Write changelog", ParentID: 1, UserID: 3, Status: "done", IsDone: true, BlockedBy: []int{1, 4}},
+			3: {ID: 3, Title: "Announce release 3", UserID: 3, BlockedBy: []int{1, 4}},
+			4: {ID: 4, Title: "Freeze the branch", UserID: 3},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	workflow := models.Workflow{Statuses: []models.WorkflowStatus{{Name: "inbox"}, {Name: "shipped", Done: true}}}
+	res, err := s.Transfer(TransferRequest{TaskID: 1, OwnerID: 4, Workflow: workflow, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	if mr.data[1].Status != "inbox" || mr.data[2].Status != "shipped" {
+		t.Errorf("transferred tasks should take the statuses of the new owner's workflow, got %q and %q",
+			mr.data[1].Status, mr.data[2].Status)
+	}
+
+	if !reflect.DeepEqual(mr.data[2].BlockedBy, []int{1}) {
+		t.Errorf("only dependencies among the transferred tasks should be kept, got %v", mr.data[2].BlockedBy)
+	}
+	if !reflect.DeepEqual(mr.data[3].BlockedBy, []int{4}) {
+		t.Errorf("tasks staying behind shouldn't wait on transferred ones, got %v", mr.data[3].BlockedBy)
+	}
+	if len(res.Unlinked) != 1 || res.Unlinked[0].ID != 3 {
+		t.Errorf("expected task 3 to be unlinked, got %v", res.Unlinked)
+	}
+}
+
+// failingUpdateRepository stores tasks like mockRepository but can't update
+// the task with failID.
+type failingUpdateRepository struct {
+	mockRepository
+	failID int
+}
+
+func (m failingUpdateRepository) UpdateTask(task models.Task) (models.Task, error) {
+	if task.ID == m.failID {
+		return models.Task{}, fmt.Errorf("disk full")
+	}
+
+	return m.mockRepository.UpdateTask(task)
+}
+
+func TestTransferIsAllOrNothing(t *testing.T) {
+	stored := func() map[int]models.Task {
+		return map[int]models.Task{
+			1: {ID: 1, Title: "Release 3", UserID: 3},
+			2: {ID: 2, Title: "Write changelog", ParentID: 1, UserID: 3},
+			3: {ID: 3, Title: "Announce release 3", UserID: 3, BlockedBy: []int{1}},
+			4: {ID: 4, Title: "Plan the trip", UserID: 4, Status: "inbox"},
+		}
+	}
+
+	workflow := models.Workflow{Statuses: []models.WorkflowStatus{{Name: "inbox", WIPLimit: 2}, {Name: "done", Done: true}}}
+
+	mr := mockRepository{data: stored()}
+	s := NewService(mr, mr)
+
+	if _, err := s.Transfer(TransferRequest{TaskID: 1, OwnerID: 4, Workflow: workflow, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Transfer should fail past the WIP limit of the new owner")
+	}
+	if !reflect.DeepEqual(mr.data, stored()) {
+		t.Errorf("a refused transfer shouldn't change any task: got %v", mr.data)
+	}
+
+	fr := failingUpdateRepository{mockRepository: mockRepository{data: stored()}, failID: 3}
+	s = NewService(fr, fr)
+
+	if _, err := s.Transfer(TransferRequest{TaskID: 1, OwnerID: 5, Workflow: workflow, AuthenticatedUserID: 3}); err == nil {
+		t.Fatalf("Transfer should fail when a task can't be updated")
+	}
+	if !reflect.DeepEqual(fr.data, stored()) {
+		t.Errorf("a failed transfer should put the tasks back: got %v", fr.data)
+	}
+}
@@ -0,0 +1,240 @@
+package task
+
+import (
+	"fmt"
+	"todo-cli-refactor/models"
+)
+
+type DependencyRequest struct {
+	TaskID              int
+	BlockerID           int
+	AuthenticatedUserID int
+}
+
+type DependencyResponse struct {
+	Task models.Task
+}
+
+// AddDependency records that a task is blocked by another task of the same
+// user. Dependencies that would make a task wait on itself are rejected.
+func (t Service) AddDependency(req DependencyRequest) (DependencyResponse, error) {
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return DependencyResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID)
+	if fErr != nil {
+		return DependencyResponse{}, fErr
+	}
+
+	// the tasks a user sees include shared ones, which mustn't hold up
+	// tasks of another owner
+	if oErr := checkOwner(task, req.AuthenticatedUserID, "dependencies"); oErr != nil {
+		return DependencyResponse{}, oErr
+	}
+
+	blocker, bErr := findTask(tasks, req.BlockerID)
+	if bErr != nil {
+		return DependencyResponse{}, fmt.Errorf("blocking %v", bErr)
+	}
+	if blocker.UserID != task.UserID {
+		return DependencyResponse{}, fmt.Errorf("task %d can only be blocked by tasks of its owner", task.ID)
+	}
+
+	for _, blockerID := range task.BlockedBy {
+		if blockerID == req.BlockerID {
+			return DependencyResponse{}, fmt.Errorf("task %d is already blocked by task %d", task.ID, req.BlockerID)
+		}
+	}
+
+	if cycle := dependencyPath(tasks, req.BlockerID, task.ID); cycle != nil {
+		return DependencyResponse{}, fmt.Errorf("task %d can't be blocked by task %d, that would make a cycle: %s",
+			task.ID, req.BlockerID, formatPath(append([]int{task.ID}, cycle...)))
+	}
+
+	task.BlockedBy = append(task.BlockedBy, req.BlockerID)
+
+	updatedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return DependencyResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return DependencyResponse{Task: updatedTask}, nil
+}
+
+func (t Service) RemoveDependency(req DependencyRequest) (DependencyResponse, error) {
+
+	task, fErr := t.findEditableTask(req.AuthenticatedUserID, req.TaskID)
+	if fErr != nil {
+		return DependencyResponse{}, fErr
+	}
+
+	var blockedBy []int
+	for _, blockerID := range task.BlockedBy {
+		if blockerID != req.BlockerID {
+			blockedBy = append(blockedBy, blockerID)
+		}
+	}
+
+	if len(blockedBy) == len(task.BlockedBy) {
+		return DependencyResponse{}, fmt.Errorf("task %d is not blocked by task %d", task.ID, req.BlockerID)
+	}
+
+	task.BlockedBy = blockedBy
+
+	updatedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return DependencyResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return DependencyResponse{Task: updatedTask}, nil
+}
+
+type CriticalPathRequest struct {
+	AuthenticatedUserID int
+	// TaskID limits the path to the blockers leading up to that task. When
+	// zero the longest chain among all open tasks is returned.
+	TaskID int
+}
+
+type CriticalPathResponse struct {
+	// Tasks are ordered from the first one to work on to the last.
+	Tasks []models.Task
+}
+
+// CriticalPath returns the longest chain of open tasks where each one blocks
+// the next, which is the least number of steps left before the last can be
+// done.
+func (t Service) CriticalPath(req CriticalPathRequest) (CriticalPathResponse, error) {
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return CriticalPathResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	byID := map[int]models.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	// longest[id] is the longest chain of open tasks ending at id
+	longest := map[int][]int{}
+	var chain func(id int, visiting map[int]bool) []int
+	chain = func(id int, visiting map[int]bool) []int {
+		if path, ok := longest[id]; ok {
+			return path
+		}
+
+		task, ok := byID[id]
+		if !ok || task.IsDone || visiting[id] {
+			return nil
+		}
+		visiting[id] = true
+
+		var best []int
+		for _, blockerID := range task.BlockedBy {
+			if path := chain(blockerID, visiting); len(path) > len(best) {
+				best = path
+			}
+		}
+		delete(visiting, id)
+
+		path := append(append([]int{}, best...), id)
+		longest[id] = path
+
+		return path
+	}
+
+	var path []int
+	if req.TaskID != 0 {
+		task, fErr := findTask(tasks, req.TaskID)
+		if fErr != nil {
+			return CriticalPathResponse{}, fErr
+		}
+		if task.IsDone {
+			return CriticalPathResponse{}, fmt.Errorf("task %d is already done", task.ID)
+		}
+
+		path = chain(task.ID, map[int]bool{})
+	} else {
+		for _, task := range tasks {
+			if p := chain(task.ID, map[int]bool{}); len(p) > len(path) {
+				path = p
+			}
+		}
+	}
+
+	response := CriticalPathResponse{}
+	for _, id := range path {
+		response.Tasks = append(response.Tasks, byID[id])
+	}
+
+	return response, nil
+}
+
+// isReady reports whether a task is open and every task blocking it is done.
+// Blockers that no longer exist don't hold a task back.
+func isReady(task models.Task, byID map[int]models.Task) bool {
+	if task.IsDone {
+		return false
+	}
+
+	return len(openBlockers(task, byID)) == 0
+}
+
+func openBlockers(task models.Task, byID map[int]models.Task) []int {
+	var open []int
+
+	for _, blockerID := range task.BlockedBy {
+		if blocker, ok := byID[blockerID]; ok && !blocker.IsDone {
+			open = append(open, blockerID)
+		}
+	}
+
+	return open
+}
+
+// dependencyPath returns the chain of blockers leading from task from to
+// task to, or nil when from doesn't wait on to at all.
+func dependencyPath(tasks []models.Task, from, to int) []int {
+	byID := map[int]models.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	visited := map[int]bool{}
+	var walk func(id int) []int
+	walk = func(id int) []int {
+		if id == to {
+			return []int{id}
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+
+		for _, blockerID := range byID[id].BlockedBy {
+			if path := walk(blockerID); path != nil {
+				return append([]int{id}, path...)
+			}
+		}
+
+		return nil
+	}
+
+	return walk(from)
+}
+
+func formatPath(ids []int) string {
+	var path string
+	for i, id := range ids {
+		if i > 0 {
+			path += " -> "
+		}
+		path += fmt.Sprintf("#%d", id)
+	}
+
+	return path
+}
@@ -0,0 +1,123 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestDependencies(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Design", UserID: 3, IsDone: true},
+			2: {ID: 2, Title: "Build", UserID: 3, BlockedBy: []int{1}},
+			3: {ID: 3, Title: "Test", UserID: 3},
+			4: {ID: 4, Title: "Ship", UserID: 3},
+			5: {ID: 5, Title: "Read a book", UserID: 4},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	t.Run("add", func(t *testing.T) {
+		for _, req := range []DependencyRequest{
+			{TaskID: 3, BlockerID: 2, AuthenticatedUserID: 3},
+			{TaskID: 4, BlockerID: 3, AuthenticatedUserID: 3},
+		} {
+			if _, err := s.AddDependency(req); err != nil {
+				t.Fatalf("AddDependency failed: %v", err)
+			}
+		}
+
+		if !reflect.DeepEqual(mr.data[4].BlockedBy, []int{3}) {
+			t.Errorf("dependency was not stored: got %v", mr.data[4].BlockedBy)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		for name, req := range map[string]DependencyRequest{
+			"cycle":        {TaskID: 2, BlockerID: 4, AuthenticatedUserID: 3},
+			"self":         {TaskID: 2, BlockerID: 2, AuthenticatedUserID: 3},
+			"duplicate":    {TaskID: 4, BlockerID: 3, AuthenticatedUserID: 3},
+			"other user":   {TaskID: 4, BlockerID: 5, AuthenticatedUserID: 3},
+			"missing task": {TaskID: 9, BlockerID: 1, AuthenticatedUserID: 3},
+		} {
+			if _, err := s.AddDependency(req); err == nil {
+				t.Errorf("AddDependency should fail for %s", name)
+			}
+		}
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		res, err := s.List(ListRequest{UserID: 3, Ready: true, Sort: CreatedSort})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+
+		if len(res.Tasks) != 1 || res.Tasks[0].ID != 2 {
+			t.Errorf("only task 2 should be ready: got %v", res.Tasks)
+		}
+
+		expected := map[int][]int{3: {2}, 4: {3}}
+		if !reflect.DeepEqual(res.OpenBlockers, expected) {
+			t.Errorf("open blockers do not match: got %v, want %v", res.OpenBlockers, expected)
+		}
+	})
+
+	t.Run("critical path", func(t *testing.T) {
+		res, err := s.CriticalPath(CriticalPathRequest{AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("CriticalPath failed: %v", err)
+		}
+
+		var ids []int
+		for _, task := range res.Tasks {
+			ids = append(ids, task.ID)
+		}
+		if !reflect.DeepEqual(ids, []int{2, 3, 4}) {
+			t.Errorf("critical path does not match: got %v", ids)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if _, err := s.RemoveDependency(DependencyRequest{TaskID: 4, BlockerID: 3, AuthenticatedUserID: 3}); err != nil {
+			t.Fatalf("RemoveDependency failed: %v", err)
+		}
+
+		if len(mr.data[4].BlockedBy) != 0 {
+			t.Errorf("dependency was not removed: got %v", mr.data[4].BlockedBy)
+		}
+
+		if _, err := s.RemoveDependency(DependencyRequest{TaskID: 4, BlockerID: 3, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("RemoveDependency should fail for a missing dependency")
+		}
+	})
+}
+
+func TestSharedDependencies(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Write the release notes", UserID: 3},
+			2: {ID: 2, Title: "Review the design", CategoryID: 1, UserID: 4},
+			3: {ID: 3, Title: "Plan the release", CategoryID: 1, UserID: 4},
+		},
+		categories: []models.Category{
+			{ID: 1, Title: "Team", UserID: 4, Shares: []models.Share{{UserID: 3, Role: models.EditorRole}}},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	for name, req := range map[string]DependencyRequest{
+		"shared blocker": {TaskID: 1, BlockerID: 2, AuthenticatedUserID: 3},
+		"shared task":    {TaskID: 3, BlockerID: 2, AuthenticatedUserID: 3},
+	} {
+		if _, err := s.AddDependency(req); err == nil {
+			t.Errorf("AddDependency should fail for a %s", name)
+		}
+	}
+
+	if _, err := s.AddDependency(DependencyRequest{TaskID: 3, BlockerID: 2, AuthenticatedUserID: 4}); err != nil {
+		t.Errorf("the owner should add dependencies between their tasks: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/duedate"
+	"todo-cli-refactor/pkg/query"
+)
+
+// setFields checks custom field values against the fields the task's
+// category defines and merges them into the values the task holds. An empty
+// value removes the field. It returns nil when no values are left.
+func setFields(values, updates map[string]string, categories []models.Category, categoryID int) (map[string]string, error) {
+	if len(updates) == 0 {
+		return values, nil
+	}
+
+	category := fieldCategory(categories, categoryID)
+	if category == nil || len(category.Fields) == 0 {
+		return nil, fmt.Errorf("category %d has no custom fields", categoryID)
+	}
+
+	merged := map[string]string{}
+	for name, value := range values {
+		merged[name] = value
+	}
+
+	for name, value := range updates {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		def, ok := models.FieldDefByName(category.Fields, name)
+		if !ok {
+			names := make([]string, 0, len(category.Fields))
+			for _, f := range category.Fields {
+				names = append(names, f.Name)
+			}
+
+			return nil, fmt.Errorf("category %q has no field %q, use one of %s", category.Title, name, strings.Join(names, ", "))
+		}
+
+		normalized, err := def.Normalize(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if normalized == "" {
+			delete(merged, name)
+		} else {
+			merged[name] = normalized
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil, nil
+	}
+
+	return merged, nil
+}
+
+// keepFields keeps the custom field values a task brings along that are
+// valid in the category it moves to. It returns nil when no values are left.
+func keepFields(values map[string]string, categories []models.Category, categoryID int) map[string]string {
+	category := fieldCategory(categories, categoryID)
+	if category == nil {
+		return nil
+	}
+
+	kept := map[string]string{}
+	for name, value := range values {
+		def, ok := models.FieldDefByName(category.Fields, name)
+		if !ok {
+			continue
+		}
+
+		if normalized, err := def.Normalize(value); err == nil && normalized != "" {
+			kept[name] = normalized
+		}
+	}
+
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return kept
+}
+
+// fieldCategory looks up the category whose fields a task's values follow,
+// nil when it isn't among the live categories.
+func fieldCategory(categories []models.Category, categoryID int) *models.Category {
+	for i, c := range categories {
+		if c.ID == categoryID && c.DeletedAt == nil {
+			return &categories[i]
+		}
+	}
+
+	return nil
+}
+
+// customField looks a custom field up by name in the user's categories. A
+// name has the same type in every category defining it.
+func (env filterEnv) customField(name string) (models.FieldDef, bool) {
+	for _, c := range env.categories {
+		if def, ok := models.FieldDefByName(c.Fields, name); ok {
+			return def, true
+		}
+	}
+
+	return models.FieldDef{}, false
+}
+
+// fieldMatcher compares the values of a custom field. Numbers and dates are
+// ordered, enums are matched by option and strings and URLs like titles.
+// Tasks without a value only match name:none, and != for the unordered
+// types.
+func (env filterEnv) fieldMatcher(p query.Predicate, def models.FieldDef) (matcher, error) {
+	operators := textOperators
+	switch def.Type {
+	case models.NumberField, models.DateField:
+		operators = orderOperators
+	case models.EnumField:
+		operators = equalityOperators
+	}
+
+	if err := env.checkFieldOperator(p, operators); err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(p.Value) == "none" {
+		if p.Operator != query.Equal && p.Operator != query.NotEqual {
+			return nil, query.Errorf(env.input, p.Offset, "%s can't be compared with %s none, use : or !=", p.Field, p.Operator)
+		}
+
+		return func(t models.Task) bool { return (t.Fields[def.Name] == "") == (p.Operator == query.Equal) }, nil
+	}
+
+	switch def.Type {
+	case models.NumberField:
+		n, err := strconv.ParseFloat(p.Value, 64)
+		if err != nil {
+			return nil, query.Errorf(env.input, p.ValueOffset, "%s: %q isn't a number", p.Field, p.Value)
+		}
+
+		return func(t models.Task) bool {
+			value, err := strconv.ParseFloat(t.Fields[def.Name], 64)
+			if err != nil {
+				return false
+			}
+
+			return compare(p.Operator, compareFloats(value, n))
+		}, nil
+	case models.DateField:
+		d, err := duedate.Parse(p.Value, env.now)
+		if err != nil {
+			return nil, query.Errorf(env.input, p.ValueOffset, "%v", err)
+		}
+		day := dueDay(d, env.now.Location()).Format("2006-01-02")
+
+		return func(t models.Task) bool {
+			value := t.Fields[def.Name]
+			if value == "" {
+				return false
+			}
+
+			return compare(p.Operator, strings.Compare(value, day))
+		}, nil
+	case models.EnumField:
+		// options may differ between the categories defining the field
+		var options []string
+		known := false
+		for _, c := range env.categories {
+			if other, ok := models.FieldDefByName(c.Fields, def.Name); ok {
+				for _, option := range other.Options {
+					options = append(options, option)
+					known = known || strings.EqualFold(option, p.Value)
+				}
+			}
+		}
+
+		if !known {
+			return nil, query.Errorf(env.input, p.ValueOffset, "unknown %s %q, use one of %s", p.Field, p.Value, strings.Join(options, ", "))
+		}
+
+		return func(t models.Task) bool {
+			return strings.EqualFold(t.Fields[def.Name], p.Value) == (p.Operator == query.Equal)
+		}, nil
+	default:
+		return textMatcher(p, func(t models.Task) string { return t.Fields[def.Name] }), nil
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,206 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+var fieldCategories = []models.Category{
+	{ID: 1, Title: "Bugs", UserID: 3, Fields: []models.FieldDef{
+		{Name: "severity", Type: models.EnumField, Options: []string{"low", "high"}},
+		{Name: "ticket", Type: models.URLField},
+		{Name: "found", Type: models.DateField},
+	}},
+	{ID: 2, Title: "Groceries", UserID: 3, Fields: []models.FieldDef{{Name: "quantity", Type: models.NumberField}}},
+	{ID: 3, Title: "Home", UserID: 3},
+}
+
+func TestCreateWithFields(t *testing.T) {
+	mr := mockRepository{data: map[int]models.Task{}}
+	s := NewService(mr, mr)
+
+	res, err := s.Create(CreateRequest{
+		Title:               "Crash on start",
+		CategoryID:          1,
+		Fields:              map[string]string{"Severity": "HIGH", "ticket": "https://example.com/1", "found": ""},
+		Categories:          fieldCategories,
+		AuthenticatedUserID: 3,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	expected := map[string]string{"severity": "high", "ticket": "https://example.com/1"}
+	if !reflect.DeepEqual(res.Task.Fields, expected) {
+		t.Errorf("got fields %v, want %v", res.Task.Fields, expected)
+	}
+
+	invalid := []struct {
+		categoryID int
+		fields     map[string]string
+	}{
+		{categoryID: 1, fields: map[string]string{"severity": "medium"}},
+		{categoryID: 1, fields: map[string]string{"ticket": "example.com"}},
+		{categoryID: 1, fields: map[string]string{"found": "yesterday"}},
+		{categoryID: 1, fields: map[string]string{"quantity": "2"}},
+		{categoryID: 2, fields: map[string]string{"quantity": "two"}},
+		{categoryID: 3, fields: map[string]string{"quantity": "2"}},
+	}
+	for _, tc := range invalid {
+		_, err := s.Create(CreateRequest{Title: "Milk", CategoryID: tc.categoryID, Fields: tc.fields,
+			Categories: fieldCategories, AuthenticatedUserID: 3})
+		if err == nil {
+			t.Errorf("Create should fail for %v in category %d", tc.fields, tc.categoryID)
+		}
+	}
+}
+
+func TestUpdateFields(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Milk", CategoryID: 2, UserID: 3, Fields: map[string]string{"quantity": "2"}},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	res, err := s.Update(UpdateRequest{TaskID: 1, AuthenticatedUserID: 3,
+		Fields: map[string]string{"quantity": "2.50"}, Categories: fieldCategories})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if res.Task.Fields["quantity"] != "2.5" {
+		t.Errorf("got fields %v, want quantity 2.5", res.Task.Fields)
+	}
+
+	res, err = s.Update(UpdateRequest{TaskID: 1, AuthenticatedUserID: 3,
+		Fields: map[string]string{"quantity": ""}, Categories: fieldCategories})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if res.Task.Fields != nil {
+		t.Errorf("got fields %v, want none", res.Task.Fields)
+	}
+}
+
+func TestUpdateCategoryFields(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Crash on start", CategoryID: 1, UserID: 3,
+				Fields: map[string]string{"severity": "high", "ticket": "https://example.com/1", "found": "2026-10-01"}},
+		},
+	}
+
+	categories := append([]models.Category{{ID: 4, Title: "Incidents", UserID: 3, Fields: []models.FieldDef{
+		{Name: "severity", Type: models.EnumField, Options: []string{"minor", "major"}},
+		{Name: "ticket", Type: models.URLField},
+	}}}, fieldCategories...)
+
+	s := NewService(mr, mr)
+
+	move := func(categoryID int, fields map[string]string) (UpdateResponse, error) {
+		return s.Update(UpdateRequest{TaskID: 1, CategoryID: &categoryID, Fields: fields, Categories: categories,
+			AuthenticatedUserID: 3})
+	}
+
+	res, err := move(4, nil)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	expected := map[string]string{"ticket": "https://example.com/1"}
+	if !reflect.DeepEqual(res.Task.Fields, expected) {
+		t.Errorf("only values the new category takes should be kept, got %v, want %v", res.Task.Fields, expected)
+	}
+
+	if _, err := move(2, map[string]string{"ticket": "https://example.com/2"}); err == nil {
+		t.Errorf("fields set along with the category should follow the new category")
+	}
+
+	res, err = move(2, map[string]string{"quantity": "3"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	expected = map[string]string{"quantity": "3"}
+	if !reflect.DeepEqual(res.Task.Fields, expected) {
+		t.Errorf("got fields %v, want %v", res.Task.Fields, expected)
+	}
+
+	if res, err := move(3, nil); err != nil || res.Task.Fields != nil {
+		t.Errorf("a category without fields should leave none: got %v, %v", res.Task.Fields, err)
+	}
+}
+
+func TestFilterFields(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Crash on start", CategoryID: 1, UserID: 3,
+				Fields: map[string]string{"severity": "high", "ticket": "https://example.com/1", "found": "2026-10-12"}},
+			2: {ID: 2, Title: "Typo", CategoryID: 1, UserID: 3, Fields: map[string]string{"severity": "low", "found": "2026-10-18"}},
+			3: {ID: 3, Title: "Milk", CategoryID: 2, UserID: 3, Fields: map[string]string{"quantity": "2"}},
+			4: {ID: 4, Title: "Eggs", CategoryID: 2, UserID: 3, Fields: map[string]string{"quantity": "12"}},
+		},
+	}
+
+	s := NewService(mr, mr)
+	s.now = func() time.Time { return time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{query: `severity:HIGH`, expected: []int{1}},
+		{query: `severity!=high category:bugs`, expected: []int{2}},
+		{query: `ticket~example`, expected: []int{1}},
+		{query: `ticket:none category:1`, expected: []int{2}},
+		{query: `quantity>2`, expected: []int{4}},
+		{query: `quantity<=2.0`, expected: []int{3}},
+		{query: `found>-1w`, expected: []int{2}},
+		{query: `found<=2026-10-12`, expected: []int{1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			res, err := s.List(ListRequest{UserID: 3, Query: tc.query, Categories: fieldCategories})
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+
+			var ids []int
+			for _, task := range res.Tasks {
+				ids = append(ids, task.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("got tasks %v, want %v", ids, tc.expected)
+			}
+		})
+	}
+
+	errors := map[string]string{
+		`severity>low`:  `query error at column 1: severity can't be compared with >, use one of : !=`,
+		`severity:mid`:  `query error at column 10: unknown severity "mid", use one of low, high`,
+		`quantity:lots`: `query error at column 10: quantity: "lots" isn't a number`,
+		`size:big`:      `query error at column 1: unknown field "size", use one of status, due, category, under, tag, title, description, priority, id, parent, severity, ticket, found, quantity`,
+	}
+	for query, expected := range errors {
+		_, err := s.List(ListRequest{UserID: 3, Query: query, Categories: fieldCategories})
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s: got error %v, want %s", query, err, expected)
+		}
+	}
+}
+
+func TestFilterFieldOperators(t *testing.T) {
+	for _, name := range models.FilterFieldNames {
+		if _, ok := filterOperators[name]; !ok {
+			t.Errorf("filter field %s has no operators", name)
+		}
+	}
+
+	if len(filterOperators) != len(models.FilterFieldNames) {
+		t.Errorf("operators are set for fields a filter query doesn't test: %v", filterOperators)
+	}
+}
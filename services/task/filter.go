@@ -0,0 +1,432 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/duedate"
+	"todo-cli-refactor/pkg/query"
+	category2 "todo-cli-refactor/services/category"
+	"unicode"
+)
+
+// Task states a status predicate can ask for.
+const (
+	OpenStatus    = "open"
+	DoneStatus    = "done"
+	BlockedStatus = "blocked"
+	ReadyStatus   = "ready"
+	OverdueStatus = "overdue"
+)
+
+var (
+	equalityOperators = []string{query.Equal, query.NotEqual}
+	orderOperators    = []string{query.Equal, query.NotEqual, query.Less, query.LessEqual, query.Greater, query.GreaterEqual}
+	textOperators     = []string{query.Equal, query.NotEqual, query.Contains}
+)
+
+// filterOperators holds the operators each of models.FilterFieldNames
+// supports.
+var filterOperators = map[string][]string{
+	"status":   equalityOperators,
+	"due":      orderOperators,
+	"category": equalityOperators,
+	// under is a category along with the categories nested in it
+	"under":       equalityOperators,
+	"tag":         equalityOperators,
+	"title":       textOperators,
+	"description": textOperators,
+	"priority":    orderOperators,
+	"id":          orderOperators,
+	"parent":      orderOperators,
+}
+
+type matcher func(t models.Task) bool
+
+// filterEnv is what predicates are evaluated against besides the task.
+type filterEnv struct {
+	input      string
+	now        time.Time
+	byID       map[int]models.Task
+	categories []models.Category
+	workflow   models.Workflow
+}
+
+// compileFilter parses a filter query into a matcher. Bare text matches
+// tasks with it in their title or description. An empty query matches every
+// task.
+func compileFilter(input string, env filterEnv) (matcher, error) {
+	expr, err := query.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr == nil {
+		return func(models.Task) bool { return true }, nil
+	}
+
+	env.input = input
+
+	return env.compile(expr)
+}
+
+// ValidateQuery reports the errors listing tasks with a filter query would
+// run into, for queries that are stored to be run later. Categories and
+// workflow are the user's, as in ListRequest.
+func ValidateQuery(input string, categories []models.Category, workflow models.Workflow) error {
+	_, err := compileFilter(input, filterEnv{now: time.Now(), categories: categories, workflow: workflowOrDefault(workflow)})
+
+	return err
+}
+
+// filterTasks keeps the tasks matching a filter query.
+func filterTasks(tasks []models.Task, input string, env filterEnv) ([]models.Task, error) {
+	if strings.TrimSpace(input) == "" {
+		return tasks, nil
+	}
+
+	matches, err := compileFilter(input, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []models.Task
+	for _, task := range tasks {
+		if matches(task) {
+			filtered = append(filtered, task)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (env filterEnv) compile(expr query.Expr) (matcher, error) {
+	switch e := expr.(type) {
+	case query.And, query.Or:
+		terms, or := exprTerms(e)
+
+		matchers := make([]matcher, 0, len(terms))
+		for _, term := range terms {
+			m, err := env.compile(term)
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, m)
+		}
+
+		// an AND fails on its first false term, an OR holds on its first true one
+		return func(t models.Task) bool {
+			for _, m := range matchers {
+				if m(t) == or {
+					return or
+				}
+			}
+
+			return !or
+		}, nil
+	case query.Not:
+		m, err := env.compile(e.Term)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(t models.Task) bool { return !m(t) }, nil
+	case query.Text:
+		text := strings.ToLower(e.Value)
+
+		return func(t models.Task) bool {
+			return strings.Contains(strings.ToLower(t.Title), text) || strings.Contains(strings.ToLower(t.Description), text)
+		}, nil
+	case query.Predicate:
+		return env.predicate(e)
+	}
+
+	return nil, fmt.Errorf("unknown query expression %T", expr)
+}
+
+func exprTerms(expr query.Expr) ([]query.Expr, bool) {
+	if or, ok := expr.(query.Or); ok {
+		return or.Terms, true
+	}
+
+	return expr.(query.And).Terms, false
+}
+
+func (env filterEnv) predicate(p query.Predicate) (matcher, error) {
+	if def, ok := env.customField(p.Field); ok {
+		return env.fieldMatcher(p, def)
+	}
+
+	if err := env.checkOperator(p); err != nil {
+		return nil, err
+	}
+
+	switch p.Field {
+	case "status":
+		return env.statusMatcher(p)
+	case "due":
+		return env.dueMatcher(p)
+	case "category", "under":
+		return env.categoryMatcher(p)
+	case "tag":
+		tag, err := models.NormalizeTag(p.Value)
+		if err != nil {
+			return nil, query.Errorf(env.input, p.ValueOffset, "%v", err)
+		}
+
+		return func(t models.Task) bool { return matchesTag(t, tag) == (p.Operator == query.Equal) }, nil
+	case "title":
+		return textMatcher(p, func(t models.Task) string { return t.Title }), nil
+	case "description":
+		return textMatcher(p, func(t models.Task) string { return t.Description }), nil
+	case "priority":
+		priority, err := models.ParsePriority(p.Value)
+		if err != nil {
+			return nil, query.Errorf(env.input, p.ValueOffset, "%v", err)
+		}
+
+		return func(t models.Task) bool { return compare(p.Operator, int(t.Priority)-int(priority)) }, nil
+	case "id":
+		id, err := strconv.Atoi(p.Value)
+		if err != nil {
+			return nil, query.Errorf(env.input, p.ValueOffset, "invalid task id %q", p.Value)
+		}
+
+		return func(t models.Task) bool { return compare(p.Operator, t.ID-id) }, nil
+	default:
+		// parent, where none stands for top level tasks
+		id := 0
+		if p.Value != "none" {
+			var err error
+			if id, err = strconv.Atoi(p.Value); err != nil {
+				return nil, query.Errorf(env.input, p.ValueOffset, "invalid task id %q, use a number or none", p.Value)
+			}
+		}
+
+		return func(t models.Task) bool { return compare(p.Operator, t.ParentID-id) }, nil
+	}
+}
+
+func (env filterEnv) checkOperator(p query.Predicate) error {
+	if operators, ok := filterOperators[p.Field]; ok {
+		return env.checkFieldOperator(p, operators)
+	}
+
+	names := append([]string{}, models.FilterFieldNames...)
+
+	// custom fields come after the task attributes
+	seen := map[string]bool{}
+	for _, c := range env.categories {
+		for _, f := range c.Fields {
+			if !seen[f.Name] {
+				seen[f.Name] = true
+				names = append(names, f.Name)
+			}
+		}
+	}
+
+	return query.Errorf(env.input, p.Offset, "unknown field %q, use one of %s", p.Field, strings.Join(names, ", "))
+}
+
+func (env filterEnv) checkFieldOperator(p query.Predicate, operators []string) error {
+	for _, op := range operators {
+		if op == p.Operator {
+			return nil
+		}
+	}
+
+	return query.Errorf(env.input, p.Offset, "%s can't be compared with %s, use one of %s",
+		p.Field, p.Operator, strings.Join(operators, " "))
+}
+
+func (env filterEnv) statusMatcher(p query.Predicate) (matcher, error) {
+	var m matcher
+
+	switch strings.ToLower(p.Value) {
+	case OpenStatus:
+		m = func(t models.Task) bool { return !t.IsDone }
+	case DoneStatus:
+		m = func(t models.Task) bool { return t.IsDone }
+	case BlockedStatus:
+		m = func(t models.Task) bool { return !t.IsDone && len(openBlockers(t, env.byID)) > 0 }
+	case ReadyStatus:
+		m = func(t models.Task) bool { return isReady(t, env.byID) }
+	case OverdueStatus:
+		m = func(t models.Task) bool { return !t.IsDone && t.DueDate.Before(env.now) }
+	default:
+		// the statuses of the workflow come after the task states
+		names := []string{OpenStatus, DoneStatus, BlockedStatus, ReadyStatus, OverdueStatus}
+		for _, status := range env.workflow.Statuses {
+			if status.Name == strings.ToLower(p.Value) {
+				name := status.Name
+				m = func(t models.Task) bool { return env.workflow.StatusOf(t) == name }
+			} else if status.Name != DoneStatus {
+				names = append(names, status.Name)
+			}
+		}
+
+		if m == nil {
+			return nil, query.Errorf(env.input, p.ValueOffset, "unknown status %q, use one of %s", p.Value, strings.Join(names, ", "))
+		}
+	}
+
+	if p.Operator == query.NotEqual {
+		return func(t models.Task) bool { return !m(t) }, nil
+	}
+
+	return m, nil
+}
+
+// dueMatcher compares due dates by calendar day when the value is a date and
+// by the moment when it has a time of day. Tasks without a due date only
+// match due:none.
+func (env filterEnv) dueMatcher(p query.Predicate) (matcher, error) {
+	if strings.ToLower(p.Value) == "none" {
+		if p.Operator != query.Equal && p.Operator != query.NotEqual {
+			return nil, query.Errorf(env.input, p.Offset, "due can't be compared with %s none, use : or !=", p.Operator)
+		}
+
+		return func(t models.Task) bool { return t.DueDate.IsZero() == (p.Operator == query.Equal) }, nil
+	}
+
+	value, err := duedate.Parse(p.Value, env.now)
+	if err != nil {
+		return nil, query.Errorf(env.input, p.ValueOffset, "%v", err)
+	}
+
+	loc := env.now.Location()
+
+	return func(t models.Task) bool {
+		if t.DueDate.IsZero() {
+			return false
+		}
+
+		if value.AllDay {
+			return compare(p.Operator, compareTimes(dueDay(t.DueDate, loc), dueDay(value, loc)))
+		}
+
+		due := t.DueDate.Time
+		if t.DueDate.AllDay {
+			due = dueDay(t.DueDate, loc)
+		}
+
+		return compare(p.Operator, compareTimes(due, value.Time))
+	}, nil
+}
+
+// dueDay returns midnight of the day a due date falls on, in loc for due
+// dates with a time of day.
+func dueDay(d models.DueDate, loc *time.Location) time.Time {
+	y, m, day := d.Date()
+	if !d.AllDay {
+		y, m, day = d.Time.In(loc).Date()
+	}
+
+	return time.Date(y, m, day, 0, 0, 0, 0, loc)
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// categoryMatcher matches tasks by the title or ID of their category. A
+// title names every category carrying it, wherever they're nested.
+func (env filterEnv) categoryMatcher(p query.Predicate) (matcher, error) {
+	ids := map[int]bool{}
+
+	value := strings.ToLower(p.Value)
+	if value == "none" {
+		ids[0] = true
+	} else if n, err := strconv.Atoi(value); err == nil {
+		ids[n] = true
+	} else {
+		for _, c := range env.categories {
+			if strings.ToLower(c.Title) == value {
+				ids[c.ID] = true
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, query.Errorf(env.input, p.ValueOffset, "unknown category %q", p.Value)
+	}
+
+	if p.Field == "under" {
+		for id := range ids {
+			for _, c := range category2.Subtree(env.categories, id) {
+				ids[c.ID] = true
+			}
+		}
+	}
+
+	return func(t models.Task) bool { return ids[t.CategoryID] == (p.Operator == query.Equal) }, nil
+}
+
+// matchesTag reports whether a task carries a tag. A tag given without a
+// sigil, like "urgent", matches it with any sigil, like "#urgent".
+func matchesTag(t models.Task, tag string) bool {
+	if hasTag(t, tag) {
+		return true
+	}
+
+	if strings.TrimLeftFunc(tag, isSigil) != tag {
+		return false
+	}
+
+	for _, other := range t.Tags {
+		if strings.TrimLeftFunc(other, isSigil) == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSigil(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func textMatcher(p query.Predicate, field func(t models.Task) string) matcher {
+	value := strings.ToLower(p.Value)
+
+	return func(t models.Task) bool {
+		text := strings.ToLower(field(t))
+
+		switch p.Operator {
+		case query.Contains:
+			return strings.Contains(text, value)
+		case query.NotEqual:
+			return text != value
+		default:
+			return text == value
+		}
+	}
+}
+
+// compare applies an ordering operator to the result of comparing two
+// values, negative when the first is smaller.
+func compare(op string, c int) bool {
+	switch op {
+	case query.NotEqual:
+		return c != 0
+	case query.Less:
+		return c < 0
+	case query.LessEqual:
+		return c <= 0
+	case query.Greater:
+		return c > 0
+	case query.GreaterEqual:
+		return c >= 0
+	default:
+		return c == 0
+	}
+}
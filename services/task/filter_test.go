@@ -0,0 +1,102 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+func TestFilterQuery(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Deploy the app", UserID: 3, CategoryID: 1, DueDate: models.NewDueDate(2026, 10, 30),
+				Priority: models.UrgentPriority, Tags: []string{"#urgent"}},
+			2: {ID: 2, Title: "Write release notes", UserID: 3, CategoryID: 1, DueDate: models.NewDueDate(2026, 11, 5),
+				Tags: []string{"#someday"}, BlockedBy: []int{1}},
+			3: {ID: 3, Title: "Buy groceries", Description: "milk and eggs", UserID: 3, CategoryID: 3, IsDone: true},
+			4: {ID: 4, Title: "Pay rent", UserID: 3, ParentID: 3,
+				DueDate: models.NewDueDateTime(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC))},
+		},
+	}
+
+	s := NewService(mr, mr)
+	s.now = func() time.Time { return time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC) }
+
+	categories := []models.Category{{ID: 1, Title: "Work", UserID: 3}, {ID: 2, Title: "Home", UserID: 3},
+		{ID: 3, Title: "Groceries", UserID: 3, ParentID: 2}}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{query: `status:open due<2026-11-01 category:work tag:urgent -tag:someday title~"deploy"`, expected: []int{1}},
+		{query: `status:done`, expected: []int{3}},
+		{query: `status!=done`, expected: []int{1, 2, 4}},
+		{query: `status:blocked`, expected: []int{2}},
+		{query: `status:ready`, expected: []int{1, 4}},
+		{query: `status:overdue`, expected: []int{4}},
+		{query: `due:none`, expected: []int{3}},
+		{query: `due>=2026-10-30`, expected: []int{1, 2}},
+		{query: `due:2026-10-18`, expected: []int{4}},
+		{query: `due<"tomorrow 09:00"`, expected: []int{4}},
+		{query: `category:none OR category:3`, expected: []int{3, 4}},
+		{query: `under:home OR category:work`, expected: []int{1, 2, 3}},
+		{query: `-under:2`, expected: []int{1, 2, 4}},
+		{query: `-(category:work OR tag:urgent)`, expected: []int{3, 4}},
+		{query: `priority>=high`, expected: []int{1}},
+		{query: `title="pay rent"`, expected: []int{4}},
+		{query: `description~milk`, expected: []int{3}},
+		{query: `milk`, expected: []int{3}},
+		{query: `parent:3`, expected: []int{4}},
+		{query: `parent:none id>1`, expected: []int{2, 3}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			res, err := s.List(ListRequest{UserID: 3, Query: tc.query, Categories: categories, Sort: CreatedSort})
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+
+			var ids []int
+			for _, task := range res.Tasks {
+				ids = append(ids, task.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("got %v, want %v", ids, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFilterQueryErrors(t *testing.T) {
+	mr := mockRepository{data: map[int]models.Task{}}
+	s := NewService(mr, mr)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: `stat:open`, expected: `query error at column 1: unknown field "stat", use one of status, due, category, under, tag, title, description, priority, id, parent`},
+		{query: `status:opn`, expected: `query error at column 8: unknown status "opn", use one of open, done, blocked, ready, overdue, backlog, todo, doing, review`},
+		{query: `tag~work`, expected: `query error at column 1: tag can't be compared with ~, use one of : !=`},
+		{query: `status:open due<someday`, expected: `query error at column 17: `},
+		{query: `category:travel`, expected: `query error at column 10: unknown category "travel"`},
+		{query: `priority>=hgh`, expected: `query error at column 11: unknown priority "hgh", use one of none, low, medium, high, urgent`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := s.List(ListRequest{UserID: 3, Query: tc.query})
+			if err == nil {
+				t.Fatalf("List should fail")
+			}
+
+			if got := err.Error(); len(got) < len(tc.expected) || got[:len(tc.expected)] != tc.expected {
+				t.Errorf("got %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/cursor"
+)
+
+// pagePosition is what a task listing cursor holds: the listing's sort
+// order, the moment it was first listed at and the sort key of the last task
+// returned. Pages start right after that key rather than at an offset, so
+// tasks added or removed meanwhile don't shift tasks between pages.
+type pagePosition struct {
+	Sort  string
+	Now   time.Time
+	After models.Task
+}
+
+func decodePagePosition(token, sortOption string) (*pagePosition, error) {
+	var position pagePosition
+	if err := cursor.Decode(token, &position); err != nil {
+		return nil, err
+	}
+
+	if normalizeSort(position.Sort) != normalizeSort(sortOption) {
+		return nil, fmt.Errorf("the cursor belongs to tasks sorted by %s, not %s",
+			normalizeSort(position.Sort), normalizeSort(sortOption))
+	}
+
+	return &position, nil
+}
+
+func normalizeSort(option string) string {
+	if option == "" {
+		return SmartSort
+	}
+
+	return option
+}
+
+// paginate cuts the page a request asks for out of sorted tasks. Progress and
+// blockers are only kept for the tasks on the page.
+func paginate(tasks []models.Task, req ListRequest, position *pagePosition, now time.Time,
+	percentages map[int]int, blockers map[int][]int) (ListResponse, error) {
+
+	less, err := taskOrder(req.Sort, now)
+	if err != nil {
+		return ListResponse{}, err
+	}
+
+	if position != nil {
+		start := sort.Search(len(tasks), func(i int) bool { return less(position.After, tasks[i]) })
+		tasks = tasks[start:]
+	}
+
+	response := ListResponse{Progress: map[int]int{}, OpenBlockers: map[int][]int{}}
+
+	if req.Limit > 0 && len(tasks) > req.Limit {
+		tasks = tasks[:req.Limit]
+
+		last := tasks[len(tasks)-1]
+		response.NextCursor, err = cursor.Encode(pagePosition{
+			Sort: req.Sort,
+			Now:  now,
+			// only the fields the orders compare by
+			After: models.Task{ID: last.ID, Title: last.Title, DueDate: last.DueDate, IsDone: last.IsDone, Priority: last.Priority},
+		})
+		if err != nil {
+			return ListResponse{}, err
+		}
+	}
+
+	response.Tasks = tasks
+	for _, task := range tasks {
+		if percentage, ok := percentages[task.ID]; ok {
+			response.Progress[task.ID] = percentage
+		}
+		if open, ok := blockers[task.ID]; ok {
+			response.OpenBlockers[task.ID] = open
+		}
+	}
+
+	return response, nil
+}
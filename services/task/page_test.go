@@ -0,0 +1,62 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestListPages(t *testing.T) {
+	mr := mockRepository{data: map[int]models.Task{}}
+	for i, title := range []string{"b", "d", "f", "h", "j", "l", "n"} {
+		mr.data[i+1] = models.Task{ID: i + 1, Title: title, UserID: 3}
+	}
+
+	s := NewService(mr, mr)
+
+	page := func(cursor string) ([]string, string) {
+		t.Helper()
+
+		res, err := s.List(ListRequest{UserID: 3, Sort: TitleSort, Limit: 3, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+
+		var titles []string
+		for _, task := range res.Tasks {
+			titles = append(titles, task.Title)
+		}
+
+		return titles, res.NextCursor
+	}
+
+	first, next := page("")
+	if !reflect.DeepEqual(first, []string{"b", "d", "f"}) || next == "" {
+		t.Fatalf("first page: got %v, %q", first, next)
+	}
+
+	// tasks added before and after the cursor and one removed from the first
+	// page don't shift the pages that follow
+	mr.data[8] = models.Task{ID: 8, Title: "a", UserID: 3}
+	mr.data[9] = models.Task{ID: 9, Title: "g", UserID: 3}
+	delete(mr.data, 2)
+
+	second, next := page(next)
+	if !reflect.DeepEqual(second, []string{"g", "h", "j"}) || next == "" {
+		t.Fatalf("second page: got %v, %q", second, next)
+	}
+
+	third, next := page(next)
+	if !reflect.DeepEqual(third, []string{"l", "n"}) || next != "" {
+		t.Fatalf("last page: got %v, %q", third, next)
+	}
+
+	if _, err := s.List(ListRequest{UserID: 3, Sort: DueDateSort, Limit: 3, Cursor: "garbage"}); err == nil {
+		t.Errorf("List should fail for an invalid cursor")
+	}
+
+	_, cursor := page("")
+	if _, err := s.List(ListRequest{UserID: 3, Sort: DueDateSort, Limit: 3, Cursor: cursor}); err == nil {
+		t.Errorf("List should fail for a cursor of another sort order")
+	}
+}
@@ -0,0 +1,116 @@
+package task
+
+import (
+	"time"
+	"todo-cli-refactor/models"
+)
+
+// maxRecurrenceSteps bounds the search for the next matching day so a rule
+// that can never match doesn't loop forever.
+const maxRecurrenceSteps = 366 * 8
+
+// nextOccurrence returns the due date following due under rule r, or false
+// when the series has ended.
+func nextOccurrence(r models.Recurrence, due models.DueDate) (models.DueDate, bool) {
+	if r.Count == 1 {
+		return models.DueDate{}, false
+	}
+
+	var next time.Time
+	switch r.Frequency {
+	case models.DailyFrequency:
+		next = nextDay(r, due.Time)
+	case models.WeeklyFrequency:
+		next = nextWeekDay(r, due.Time)
+	case models.MonthlyFrequency:
+		next = addMonths(due.Time, r.Interval, r.ByMonthDay)
+	case models.YearlyFrequency:
+		next = addMonths(due.Time, 12*r.Interval, r.ByMonthDay)
+	}
+
+	if next.IsZero() {
+		return models.DueDate{}, false
+	}
+
+	nextDue := models.DueDate{Time: next, AllDay: due.AllDay}
+	if !r.Until.IsZero() && untilPassed(r.Until, nextDue) {
+		return models.DueDate{}, false
+	}
+
+	return nextDue, true
+}
+
+func nextDay(r models.Recurrence, t time.Time) time.Time {
+	for i := 1; i <= maxRecurrenceSteps; i++ {
+		candidate := t.AddDate(0, 0, i*r.Interval)
+		if matchesWeekday(r, candidate) {
+			return candidate
+		}
+	}
+
+	return time.Time{}
+}
+
+// nextWeekDay walks forward day by day, only accepting days in weeks that are
+// a multiple of the interval away from t's week. Weeks start on Monday.
+func nextWeekDay(r models.Recurrence, t time.Time) time.Time {
+	if len(r.ByWeekday) == 0 {
+		return t.AddDate(0, 0, 7*r.Interval)
+	}
+
+	start := weekStart(t)
+	for i := 1; i <= maxRecurrenceSteps; i++ {
+		candidate := t.AddDate(0, 0, i)
+
+		weeks := int(weekStart(candidate).Sub(start).Hours()+12) / (24 * 7)
+		if weeks%r.Interval == 0 && matchesWeekday(r, candidate) {
+			return candidate
+		}
+	}
+
+	return time.Time{}
+}
+
+func weekStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	offset := (int(t.Weekday()) + 6) % 7
+
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, time.UTC)
+}
+
+func matchesWeekday(r models.Recurrence, t time.Time) bool {
+	if len(r.ByWeekday) == 0 {
+		return true
+	}
+
+	for _, wd := range r.ByWeekday {
+		if t.Weekday() == wd {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addMonths moves t forward by n months, landing on day of month anchor
+// clamped to the length of the target month.
+func addMonths(t time.Time, n, anchor int) time.Time {
+	y, m, d := t.Date()
+	if anchor != 0 {
+		d = anchor
+	}
+
+	first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+
+	return first.AddDate(0, 0, d-1)
+}
+
+func untilPassed(until, next models.DueDate) bool {
+	uy, um, ud := until.Date()
+	ny, nm, nd := next.Date()
+
+	return time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC).After(time.Date(uy, um, ud, 0, 0, 0, 0, time.UTC))
+}
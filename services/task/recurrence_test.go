@@ -0,0 +1,87 @@
+package task
+
+import (
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	tehran := time.FixedZone("IRST", 3*60*60+30*60)
+
+	tests := []struct {
+		name     string
+		rule     models.Recurrence
+		due      models.DueDate
+		expected models.DueDate
+		ends     bool
+	}{
+		{
+			name:     "every other day",
+			rule:     models.Recurrence{Frequency: models.DailyFrequency, Interval: 2},
+			due:      models.NewDueDate(2026, 10, 30),
+			expected: models.NewDueDate(2026, 11, 1),
+		},
+		{
+			name:     "weekdays only",
+			rule:     models.Recurrence{Frequency: models.DailyFrequency, Interval: 1, ByWeekday: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}},
+			due:      models.NewDueDate(2026, 10, 23),
+			expected: models.NewDueDate(2026, 10, 26),
+		},
+		{
+			name:     "weekly keeps time of day",
+			rule:     models.Recurrence{Frequency: models.WeeklyFrequency, Interval: 1},
+			due:      models.NewDueDateTime(time.Date(2026, 10, 19, 9, 30, 0, 0, tehran)),
+			expected: models.NewDueDateTime(time.Date(2026, 10, 26, 9, 30, 0, 0, tehran)),
+		},
+		{
+			name:     "every two weeks on monday and friday",
+			rule:     models.Recurrence{Frequency: models.WeeklyFrequency, Interval: 2, ByWeekday: []time.Weekday{time.Monday, time.Friday}},
+			due:      models.NewDueDate(2026, 10, 23),
+			expected: models.NewDueDate(2026, 11, 2),
+		},
+		{
+			name:     "monthly clamps to short months",
+			rule:     models.Recurrence{Frequency: models.MonthlyFrequency, Interval: 1, ByMonthDay: 31},
+			due:      models.NewDueDate(2027, 1, 31),
+			expected: models.NewDueDate(2027, 2, 28),
+		},
+		{
+			name:     "monthly returns to its anchor day",
+			rule:     models.Recurrence{Frequency: models.MonthlyFrequency, Interval: 1, ByMonthDay: 31},
+			due:      models.NewDueDate(2027, 2, 28),
+			expected: models.NewDueDate(2027, 3, 31),
+		},
+		{
+			name:     "yearly on leap day",
+			rule:     models.Recurrence{Frequency: models.YearlyFrequency, Interval: 1, ByMonthDay: 29},
+			due:      models.NewDueDate(2028, 2, 29),
+			expected: models.NewDueDate(2029, 2, 28),
+		},
+		{
+			name: "until reached",
+			rule: models.Recurrence{Frequency: models.DailyFrequency, Interval: 1, Until: models.NewDueDate(2026, 10, 30)},
+			due:  models.NewDueDate(2026, 10, 30),
+			ends: true,
+		},
+		{
+			name: "last of count",
+			rule: models.Recurrence{Frequency: models.DailyFrequency, Interval: 1, Count: 1},
+			due:  models.NewDueDate(2026, 10, 30),
+			ends: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := nextOccurrence(tc.rule, tc.due)
+			if ok == tc.ends {
+				t.Fatalf("expected series to end: %t, got next %v", tc.ends, next)
+			}
+
+			if !tc.ends && (next.AllDay != tc.expected.AllDay || !next.Time.Equal(tc.expected.Time)) {
+				t.Errorf("next occurrence does not match: got %v, want %v", next, tc.expected)
+			}
+		})
+	}
+}
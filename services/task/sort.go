@@ -0,0 +1,110 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+const (
+	SmartSort    = "smart"
+	PrioritySort = "priority"
+	DueDateSort  = "due"
+	CreatedSort  = "created"
+	TitleSort    = "title"
+)
+
+// sortTasks orders tasks in place by the given sort option.
+func sortTasks(tasks []models.Task, option string, now time.Time) error {
+	less, err := taskOrder(option, now)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return less(tasks[i], tasks[j])
+	})
+
+	return nil
+}
+
+// taskOrder returns the less function of a sort option. The smart order,
+// used when no option is given, lists open tasks before done ones, overdue
+// tasks first among them and then the most important and most urgent. All
+// orders fall back to the task ID, so no two tasks are ever equal.
+func taskOrder(option string, now time.Time) (func(a, b models.Task) bool, error) {
+	var less func(a, b models.Task) bool
+
+	switch option {
+	case "", SmartSort:
+		less = func(a, b models.Task) bool {
+			if a.IsDone != b.IsDone {
+				return !a.IsDone
+			}
+
+			if aOverdue, bOverdue := a.DueDate.Before(now), b.DueDate.Before(now); !a.IsDone && aOverdue != bOverdue {
+				return aOverdue
+			}
+
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+
+			return dueDateLess(a, b)
+		}
+	case PrioritySort:
+		less = func(a, b models.Task) bool {
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+
+			return dueDateLess(a, b)
+		}
+	case DueDateSort:
+		less = func(a, b models.Task) bool {
+			if !a.DueDate.Time.Equal(b.DueDate.Time) {
+				return dueDateLess(a, b)
+			}
+
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+
+			return a.ID < b.ID
+		}
+	case CreatedSort:
+		// IDs are handed out in creation order
+		less = func(a, b models.Task) bool {
+			return a.ID < b.ID
+		}
+	case TitleSort:
+		less = func(a, b models.Task) bool {
+			aTitle, bTitle := strings.ToLower(a.Title), strings.ToLower(b.Title)
+			if aTitle != bTitle {
+				return aTitle < bTitle
+			}
+
+			return a.ID < b.ID
+		}
+	default:
+		return nil, fmt.Errorf("unknown sort option %q, use one of %s, %s, %s, %s, %s",
+			option, SmartSort, PrioritySort, DueDateSort, CreatedSort, TitleSort)
+	}
+
+	return less, nil
+}
+
+// dueDateLess orders tasks by due date with tasks without one last, falling
+// back to creation order.
+func dueDateLess(a, b models.Task) bool {
+	switch {
+	case a.DueDate.IsZero() != b.DueDate.IsZero():
+		return !a.DueDate.IsZero()
+	case !a.DueDate.Time.Equal(b.DueDate.Time):
+		return a.DueDate.Time.Before(b.DueDate.Time)
+	default:
+		return a.ID < b.ID
+	}
+}
@@ -0,0 +1,51 @@
+package task
+
+import (
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+func TestSortTasks(t *testing.T) {
+	now := time.Date(2026, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	tasks := []models.Task{
+		{ID: 1, Title: "read a book"},
+		{ID: 2, Title: "Pay rent", DueDate: models.NewDueDate(2026, 11, 1), Priority: models.LowPriority},
+		{ID: 3, Title: "Fix the roof", DueDate: models.NewDueDate(2026, 11, 20), Priority: models.UrgentPriority},
+		{ID: 4, Title: "Call mom", DueDate: models.NewDueDate(2026, 11, 3), Priority: models.HighPriority},
+		{ID: 5, Title: "Buy groceries", DueDate: models.NewDueDate(2026, 10, 1), Priority: models.UrgentPriority, IsDone: true},
+		{ID: 6, Title: "Book flights", DueDate: models.NewDueDate(2026, 11, 3), Priority: models.HighPriority},
+	}
+
+	tests := []struct {
+		option   string
+		expected []int
+	}{
+		{option: "", expected: []int{2, 3, 4, 6, 1, 5}},
+		{option: SmartSort, expected: []int{2, 3, 4, 6, 1, 5}},
+		{option: PrioritySort, expected: []int{5, 3, 4, 6, 2, 1}},
+		{option: DueDateSort, expected: []int{5, 2, 4, 6, 3, 1}},
+		{option: CreatedSort, expected: []int{1, 2, 3, 4, 5, 6}},
+		{option: TitleSort, expected: []int{6, 5, 4, 3, 2, 1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.option, func(t *testing.T) {
+			sorted := append([]models.Task(nil), tasks...)
+			if err := sortTasks(sorted, tc.option, now); err != nil {
+				t.Fatalf("sortTasks failed: %v", err)
+			}
+
+			for i, task := range sorted {
+				if task.ID != tc.expected[i] {
+					t.Fatalf("order does not match: got task %d at %d, want %v", task.ID, i, tc.expected)
+				}
+			}
+		})
+	}
+
+	if err := sortTasks(tasks, "random", now); err == nil {
+		t.Errorf("sortTasks should fail for an unknown option")
+	}
+}
@@ -0,0 +1,229 @@
+package task
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"todo-cli-refactor/models"
+)
+
+// progress returns the percentage complete of every task that has subtasks
+// or checklist items. A part counts fully when it's done, and subtasks with
+// parts of their own count with their own progress.
+func progress(tasks []models.Task) map[int]int {
+	children := childrenOf(tasks)
+
+	percentages := map[int]int{}
+	for _, task := range tasks {
+		if len(children[task.ID]) == 0 && len(task.Checklist) == 0 {
+			continue
+		}
+
+		percentages[task.ID] = int(math.Round(100 * completion(task, children, map[int]bool{})))
+	}
+
+	return percentages
+}
+
+func completion(task models.Task, children map[int][]models.Task, visited map[int]bool) float64 {
+	if task.IsDone {
+		return 1
+	}
+
+	// a broken file could link tasks in a loop, don't follow it forever
+	if visited[task.ID] {
+		return 0
+	}
+	visited[task.ID] = true
+
+	parts := len(children[task.ID]) + len(task.Checklist)
+	if parts == 0 {
+		return 0
+	}
+
+	var done float64
+	for _, child := range children[task.ID] {
+		done += completion(child, children, visited)
+	}
+	for _, item := range task.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+
+	return done / float64(parts)
+}
+
+func childrenOf(tasks []models.Task) map[int][]models.Task {
+	children := map[int][]models.Task{}
+	for _, task := range tasks {
+		if task.ParentID != 0 {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+
+	return children
+}
+
+// descendants returns every task below taskID in the tree.
+func descendants(taskID int, children map[int][]models.Task) []models.Task {
+	var found []models.Task
+
+	visited := map[int]bool{taskID: true}
+	queue := []int{taskID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, child := range children[id] {
+			if visited[child.ID] {
+				continue
+			}
+			visited[child.ID] = true
+
+			found = append(found, child)
+			queue = append(queue, child.ID)
+		}
+	}
+
+	return found
+}
+
+// validateParent checks that parentID is a task the user can edit and that
+// making it the parent of taskID doesn't put taskID below itself. taskID is
+// zero for tasks that don't exist yet.
+func (t Service) validateParent(tasks []models.Task, taskID, parentID, userID int) error {
+	if parentID == 0 {
+		return nil
+	}
+
+	byID := map[int]models.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	parent, ok := byID[parentID]
+	if !ok {
+		return fmt.Errorf("parent task %d not found", parentID)
+	}
+
+	if rErr := t.checkRole(parent, userID, models.EditorRole); rErr != nil {
+		return rErr
+	}
+
+	for id, depth := parentID, 0; id != 0; id, depth = byID[id].ParentID, depth+1 {
+		if id == taskID || depth > len(tasks) {
+			return fmt.Errorf("task %d can't be a subtask of its own subtask %d", taskID, parentID)
+		}
+	}
+
+	return nil
+}
+
+// openParts describes the subtasks and checklist items of a task that are
+// still open, or is empty when everything is done.
+func openParts(task models.Task, children map[int][]models.Task) string {
+	var open []string
+
+	for _, child := range descendants(task.ID, children) {
+		if !child.IsDone {
+			open = append(open, fmt.Sprintf("#%d", child.ID))
+		}
+	}
+
+	for _, item := range task.Checklist {
+		if !item.Done {
+			open = append(open, fmt.Sprintf("%q", item.Text))
+		}
+	}
+
+	return strings.Join(open, ", ")
+}
+
+// reopenChecklist copies a checklist with every item open, for the next
+// occurrence of a recurring task.
+func reopenChecklist(checklist []models.ChecklistItem) []models.ChecklistItem {
+	var reopened []models.ChecklistItem
+	for _, item := range checklist {
+		reopened = append(reopened, models.ChecklistItem{Text: item.Text})
+	}
+
+	return reopened
+}
+
+type ChecklistRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+	Text                string
+}
+
+type ChecklistItemRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+	// Index is the zero based position of the item in the checklist.
+	Index int
+	Done  bool
+}
+
+type ChecklistResponse struct {
+	Task models.Task
+}
+
+func (t Service) AddChecklistItem(req ChecklistRequest) (ChecklistResponse, error) {
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return ChecklistResponse{}, fmt.Errorf("checklist item can't be empty")
+	}
+
+	task, fErr := t.findEditableTask(req.AuthenticatedUserID, req.TaskID)
+	if fErr != nil {
+		return ChecklistResponse{}, fErr
+	}
+
+	task.Checklist = append(task.Checklist, models.ChecklistItem{Text: text})
+
+	return t.updateChecklist(task)
+}
+
+// CheckChecklistItem marks a checklist item as done or open again.
+func (t Service) CheckChecklistItem(req ChecklistItemRequest) (ChecklistResponse, error) {
+
+	task, fErr := t.findEditableTask(req.AuthenticatedUserID, req.TaskID)
+	if fErr != nil {
+		return ChecklistResponse{}, fErr
+	}
+
+	if req.Index < 0 || req.Index >= len(task.Checklist) {
+		return ChecklistResponse{}, fmt.Errorf("task %d has no checklist item %d", task.ID, req.Index)
+	}
+
+	task.Checklist[req.Index].Done = req.Done
+
+	return t.updateChecklist(task)
+}
+
+func (t Service) RemoveChecklistItem(req ChecklistItemRequest) (ChecklistResponse, error) {
+
+	task, fErr := t.findEditableTask(req.AuthenticatedUserID, req.TaskID)
+	if fErr != nil {
+		return ChecklistResponse{}, fErr
+	}
+
+	if req.Index < 0 || req.Index >= len(task.Checklist) {
+		return ChecklistResponse{}, fmt.Errorf("task %d has no checklist item %d", task.ID, req.Index)
+	}
+
+	task.Checklist = append(task.Checklist[:req.Index:req.Index], task.Checklist[req.Index+1:]...)
+
+	return t.updateChecklist(task)
+}
+
+func (t Service) updateChecklist(task models.Task) (ChecklistResponse, error) {
+	updatedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return ChecklistResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return ChecklistResponse{Task: updatedTask}, nil
+}
@@ -0,0 +1,118 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestProgress(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Title: "Release 3", UserID: 3},
+		{ID: 2, Title: "Write changelog", UserID: 3, ParentID: 1, IsDone: true},
+		{ID: 3, Title: "Ship binaries", UserID: 3, ParentID: 1, Checklist: []models.ChecklistItem{{Text: "linux", Done: true}, {Text: "mac"}}},
+		{ID: 4, Title: "Announce", UserID: 3, ParentID: 1},
+		{ID: 5, Title: "Read a book", UserID: 3},
+	}
+
+	expected := map[int]int{1: 50, 3: 50}
+	if got := progress(tasks); !reflect.DeepEqual(got, expected) {
+		t.Errorf("progress does not match: got %v, want %v", got, expected)
+	}
+}
+
+func TestCompleteWithSubtasks(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Release 3", UserID: 3, Checklist: []models.ChecklistItem{{Text: "tag"}}},
+			2: {ID: 2, Title: "Write changelog", UserID: 3, ParentID: 1},
+			3: {ID: 3, Title: "Proofread", UserID: 3, ParentID: 2},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	if _, err := s.Complete(CompleteRequest{TaskID: 1, AuthenticatedUserID: 3}); err == nil {
+		t.Fatalf("Complete should fail for a task with open subtasks")
+	}
+
+	res, err := s.Complete(CompleteRequest{TaskID: 1, AuthenticatedUserID: 3, Force: true})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if !res.Task.IsDone || !res.Task.Checklist[0].Done {
+		t.Errorf("forced task and its checklist should be done: got %v", res.Task)
+	}
+	if !mr.data[2].IsDone || !mr.data[3].IsDone {
+		t.Errorf("subtasks at every depth should be done: got %v, %v", mr.data[2], mr.data[3])
+	}
+}
+
+func TestSubtaskParents(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Release 3", UserID: 3},
+			2: {ID: 2, Title: "Write changelog", UserID: 3, ParentID: 1},
+			3: {ID: 3, Title: "Read a book", UserID: 4},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	res, err := s.Create(CreateRequest{Title: "Proofread", ParentID: 2, Checklist: []string{"typos", " "}, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if res.Task.ParentID != 2 || len(res.Task.Checklist) != 1 {
+		t.Errorf("created subtask does not match: got %v", res.Task)
+	}
+
+	if _, err := s.Create(CreateRequest{Title: "Sneaky", ParentID: 3, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Create should fail below a task of another user")
+	}
+
+	parent := res.Task.ID
+	if _, err := s.Update(UpdateRequest{TaskID: 1, AuthenticatedUserID: 3, ParentID: &parent}); err == nil {
+		t.Errorf("Update should fail when a task is moved below its own subtask")
+	}
+
+	top := 0
+	if _, err := s.Update(UpdateRequest{TaskID: 2, AuthenticatedUserID: 3, ParentID: &top}); err != nil {
+		t.Errorf("Update failed: %v", err)
+	}
+}
+
+func TestChecklist(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Pack", UserID: 3},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	for _, text := range []string{"passport", "charger", "tickets"} {
+		if _, err := s.AddChecklistItem(ChecklistRequest{TaskID: 1, AuthenticatedUserID: 3, Text: text}); err != nil {
+			t.Fatalf("AddChecklistItem failed: %v", err)
+		}
+	}
+
+	if _, err := s.CheckChecklistItem(ChecklistItemRequest{TaskID: 1, AuthenticatedUserID: 3, Index: 0, Done: true}); err != nil {
+		t.Fatalf("CheckChecklistItem failed: %v", err)
+	}
+
+	res, err := s.RemoveChecklistItem(ChecklistItemRequest{TaskID: 1, AuthenticatedUserID: 3, Index: 1})
+	if err != nil {
+		t.Fatalf("RemoveChecklistItem failed: %v", err)
+	}
+
+	expected := []models.ChecklistItem{{Text: "passport", Done: true}, {Text: "tickets"}}
+	if !reflect.DeepEqual(res.Task.Checklist, expected) {
+		t.Errorf("checklist does not match: got %v, want %v", res.Task.Checklist, expected)
+	}
+
+	if _, err := s.CheckChecklistItem(ChecklistItemRequest{TaskID: 1, AuthenticatedUserID: 3, Index: 5}); err == nil {
+		t.Errorf("CheckChecklistItem should fail for a missing item")
+	}
+}
@@ -0,0 +1,246 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"todo-cli-refactor/models"
+)
+
+type TagRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+	Tags                []string
+}
+
+type TagResponse struct {
+	Task models.Task
+}
+
+func (t Service) AddTags(req TagRequest) (TagResponse, error) {
+
+	task, fErr := t.findEditableTask(req.AuthenticatedUserID, req.TaskID)
+	if fErr != nil {
+		return TagResponse{}, fErr
+	}
+
+	tags, nErr := normalizeTags(req.Tags)
+	if nErr != nil {
+		return TagResponse{}, nErr
+	}
+
+	for _, tag := range tags {
+		if !hasTag(task, tag) {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+
+	updatedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return TagResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return TagResponse{Task: updatedTask}, nil
+}
+
+func (t Service) RemoveTags(req TagRequest) (TagResponse, error) {
+
+	task, fErr := t.findEditableTask(req.AuthenticatedUserID, req.TaskID)
+	if fErr != nil {
+		return TagResponse{}, fErr
+	}
+
+	tags, nErr := normalizeTags(req.Tags)
+	if nErr != nil {
+		return TagResponse{}, nErr
+	}
+
+	task.Tags = removeTags(task.Tags, tags)
+
+	updatedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return TagResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return TagResponse{Task: updatedTask}, nil
+}
+
+type RenameTagRequest struct {
+	AuthenticatedUserID int
+	From                string
+	To                  string
+}
+
+type RenameTagResponse struct {
+	// Tasks are the tasks the tag was renamed on.
+	Tasks []models.Task
+}
+
+// RenameTag renames a tag on every task the user can change. Tasks that already carry
+// the new tag simply lose the old one.
+func (t Service) RenameTag(req RenameTagRequest) (RenameTagResponse, error) {
+
+	from, fErr := models.NormalizeTag(req.From)
+	if fErr != nil {
+		return RenameTagResponse{}, fErr
+	}
+
+	to, tErr := models.NormalizeTag(req.To)
+	if tErr != nil {
+		return RenameTagResponse{}, tErr
+	}
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return RenameTagResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	categories, cErr := t.repository.ListCategories()
+	if cErr != nil {
+		return RenameTagResponse{}, fmt.Errorf("can't list categories: %v", cErr)
+	}
+
+	var renamed []models.Task
+	for _, task := range tasks {
+		// tasks shared with the user for viewing keep their tags
+		if !hasTag(task, from) || !Role(task, categories, req.AuthenticatedUserID).Allows(models.EditorRole) {
+			continue
+		}
+
+		task.Tags = removeTags(task.Tags, []string{from})
+		if !hasTag(task, to) {
+			task.Tags = append(task.Tags, to)
+		}
+
+		updatedTask, uErr := t.repository.UpdateTask(task)
+		if uErr != nil {
+			return RenameTagResponse{}, fmt.Errorf("can't update task: %v", uErr)
+		}
+		renamed = append(renamed, updatedTask)
+	}
+
+	if len(renamed) == 0 {
+		return RenameTagResponse{}, fmt.Errorf("tag %q not found", from)
+	}
+
+	return RenameTagResponse{Tasks: renamed}, nil
+}
+
+type ListTagsRequest struct {
+	AuthenticatedUserID int
+}
+
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+type ListTagsResponse struct {
+	Tags []TagCount
+}
+
+// ListTags returns the tags of the user with the number of tasks carrying
+// each, most used first.
+func (t Service) ListTags(req ListTagsRequest) (ListTagsResponse, error) {
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ListTagsResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	counts := map[string]int{}
+	for _, task := range tasks {
+		for _, tag := range task.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return ListTagsResponse{Tags: tags}, nil
+}
+
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags given")
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		n, err := models.NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if !hasTag(models.Task{Tags: normalized}, n) {
+			normalized = append(normalized, n)
+		}
+	}
+
+	return normalized, nil
+}
+
+func normalizeFilterTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	return normalizeTags(tags)
+}
+
+func hasTag(task models.Task, tag string) bool {
+	for _, t := range task.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeTags(tags, removed []string) []string {
+	var kept []string
+
+	for _, tag := range tags {
+		found := false
+		for _, r := range removed {
+			if tag == r {
+				found = true
+			}
+		}
+
+		if !found {
+			kept = append(kept, tag)
+		}
+	}
+
+	return kept
+}
+
+// matchesTags reports whether a task carries every included tag and none of
+// the excluded ones.
+func matchesTags(task models.Task, include, exclude []string) bool {
+	for _, tag := range include {
+		if !hasTag(task, tag) {
+			return false
+		}
+	}
+
+	for _, tag := range exclude {
+		if hasTag(task, tag) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+func TestTags(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Buy groceries", UserID: 3, Tags: []string{"@errands"}},
+			2: {ID: 2, Title: "Ship release", UserID: 3, Tags: []string{"#release-3", "@waiting"}},
+			3: {ID: 3, Title: "Write changelog", UserID: 3, Tags: []string{"#release-3"}},
+			4: {ID: 4, Title: "Read a book", UserID: 4, Tags: []string{"#release-3"}},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	t.Run("add", func(t *testing.T) {
+		res, err := s.AddTags(TagRequest{TaskID: 1, AuthenticatedUserID: 3, Tags: []string{"@Waiting", "@errands"}})
+		if err != nil {
+			t.Fatalf("AddTags failed: %v", err)
+		}
+
+		expected := []string{"@errands", "@waiting"}
+		if !reflect.DeepEqual(res.Task.Tags, expected) {
+			t.Errorf("tags do not match: got %v, want %v", res.Task.Tags, expected)
+		}
+
+		if _, err := s.AddTags(TagRequest{TaskID: 1, AuthenticatedUserID: 3, Tags: []string{"two words"}}); err == nil {
+			t.Errorf("AddTags should fail for a tag with spaces")
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		res, err := s.RemoveTags(TagRequest{TaskID: 1, AuthenticatedUserID: 3, Tags: []string{"@errands"}})
+		if err != nil {
+			t.Fatalf("RemoveTags failed: %v", err)
+		}
+
+		expected := []string{"@waiting"}
+		if !reflect.DeepEqual(res.Task.Tags, expected) {
+			t.Errorf("tags do not match: got %v, want %v", res.Task.Tags, expected)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		res, err := s.List(ListRequest{UserID: 3, Tags: []string{"#release-3"}, ExcludeTags: []string{"@waiting"}, Sort: CreatedSort})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+
+		if len(res.Tasks) != 1 || res.Tasks[0].ID != 3 {
+			t.Errorf("filtered tasks do not match: got %v", res.Tasks)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		res, err := s.ListTags(ListTagsRequest{AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("ListTags failed: %v", err)
+		}
+
+		expected := []TagCount{{Tag: "#release-3", Count: 2}, {Tag: "@waiting", Count: 2}}
+		if !reflect.DeepEqual(res.Tags, expected) {
+			t.Errorf("tag counts do not match: got %v, want %v", res.Tags, expected)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		res, err := s.RenameTag(RenameTagRequest{AuthenticatedUserID: 3, From: "#release-3", To: "#release-4"})
+		if err != nil {
+			t.Fatalf("RenameTag failed: %v", err)
+		}
+
+		if len(res.Tasks) != 2 {
+			t.Errorf("expected 2 renamed tasks, got %d", len(res.Tasks))
+		}
+		if !reflect.DeepEqual(mr.data[2].Tags, []string{"@waiting", "#release-4"}) {
+			t.Errorf("tags do not match: got %v", mr.data[2].Tags)
+		}
+		if !reflect.DeepEqual(mr.data[4].Tags, []string{"#release-3"}) {
+			t.Errorf("tags of other users should be untouched: got %v", mr.data[4].Tags)
+		}
+
+		if _, err := s.RenameTag(RenameTagRequest{AuthenticatedUserID: 3, From: "#missing", To: "#found"}); err == nil {
+			t.Errorf("RenameTag should fail for an unknown tag")
+		}
+	})
+}
@@ -2,28 +2,61 @@ package task
 
 import (
 	"fmt"
+	"strings"
+	"time"
 	"todo-cli-refactor/models"
 )
 
 type ServiceRepository interface {
 	CreateNewTask(t models.Task) (models.Task, error)
 	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+	// ListCategories looks up the roles users have on shared tasks.
+	ListCategories() ([]models.Category, error)
+}
+
+// MilestoneRepository looks up the milestones tasks are planned for.
+type MilestoneRepository interface {
+	ListUserMilestones(userID int) ([]models.Milestone, error)
 }
 
 type Service struct {
 	repository ServiceRepository
+	milestones MilestoneRepository
+	now        func() time.Time
 }
 
-func NewService(repo ServiceRepository) Service {
+func NewService(repo ServiceRepository, milestones MilestoneRepository) Service {
 	return Service{
 		repository: repo,
+		milestones: milestones,
+		now:        time.Now,
 	}
 }
 
 type CreateRequest struct {
-	Title               string
-	DueDate             string
-	CategoryID          int
+	Title string
+	// Description holds free-form markdown notes, it may span several lines.
+	Description string
+	DueDate     models.DueDate
+	CategoryID  int
+	Priority    models.Priority
+	Tags        []string
+	Recurrence  *models.Recurrence
+	// ParentID makes the new task a subtask of another task of the user.
+	ParentID int
+	// MilestoneID plans the task for a project milestone of the user.
+	MilestoneID int
+	Checklist   []string
+	// Fields holds values of the custom fields the category defines, keyed
+	// by field name.
+	Fields map[string]string
+	// Categories are the categories of the user, used to look up the fields
+	// of the task's category.
+	Categories []models.Category
+	// Workflow is the user's workflow, the task starts in its initial status.
+	// The default workflow is used when it has no statuses.
+	Workflow            models.Workflow
 	AuthenticatedUserID int
 }
 
@@ -33,12 +66,83 @@ type CreateResponse struct {
 
 func (t Service) Create(req CreateRequest) (CreateResponse, error) {
 
+	if vErr := validatePriority(req.Priority); vErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new task: %v", vErr)
+	}
+
+	var tags []string
+	if len(req.Tags) > 0 {
+		var nErr error
+		tags, nErr = normalizeTags(req.Tags)
+		if nErr != nil {
+			return CreateResponse{}, fmt.Errorf("can't create new task: %v", nErr)
+		}
+	}
+
+	var recurrence *models.Recurrence
+	if req.Recurrence != nil {
+		r, vErr := validateRecurrence(*req.Recurrence, req.DueDate)
+		if vErr != nil {
+			return CreateResponse{}, fmt.Errorf("can't create new task: %v", vErr)
+		}
+		recurrence = &r
+	}
+
+	if cErr := t.checkCategory(req.CategoryID, req.AuthenticatedUserID); cErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new task: %v", cErr)
+	}
+
+	if mErr := t.checkMilestone(req.MilestoneID, req.AuthenticatedUserID); mErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new task: %v", mErr)
+	}
+
+	workflow := workflowOrDefault(req.Workflow)
+	status := workflow.InitialStatus()
+
+	if initial, _ := workflow.Status(status); req.ParentID != 0 || initial.WIPLimit > 0 {
+		tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+		if lErr != nil {
+			return CreateResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+		}
+
+		if req.ParentID != 0 {
+			if vErr := t.validateParent(tasks, 0, req.ParentID, req.AuthenticatedUserID); vErr != nil {
+				return CreateResponse{}, fmt.Errorf("can't create new task: %v", vErr)
+			}
+		}
+
+		if wErr := checkWIPLimit(tasks, workflow, status, req.AuthenticatedUserID, 0); wErr != nil {
+			return CreateResponse{}, fmt.Errorf("can't create new task: %v", wErr)
+		}
+	}
+
+	fields, fErr := setFields(nil, req.Fields, req.Categories, req.CategoryID)
+	if fErr != nil {
+		return CreateResponse{}, fmt.Errorf("can't create new task: %v", fErr)
+	}
+
+	var checklist []models.ChecklistItem
+	for _, text := range req.Checklist {
+		if text = strings.TrimSpace(text); text != "" {
+			checklist = append(checklist, models.ChecklistItem{Text: text})
+		}
+	}
+
 	createdTask, cErr := t.repository.CreateNewTask(models.Task{
-		Title:      req.Title,
-		DueDate:    req.DueDate,
-		CategoryID: req.CategoryID,
-		IsDone:     false,
-		UserID:     req.AuthenticatedUserID,
+		Title:       req.Title,
+		Description: normalizeDescription(req.Description),
+		DueDate:     req.DueDate,
+		CategoryID:  req.CategoryID,
+		IsDone:      false,
+		UserID:      req.AuthenticatedUserID,
+		Status:      status,
+		Priority:    req.Priority,
+		Tags:        tags,
+		Recurrence:  recurrence,
+		ParentID:    req.ParentID,
+		MilestoneID: req.MilestoneID,
+		Checklist:   checklist,
+		Fields:      fields,
 	})
 	if cErr != nil {
 		return CreateResponse{}, fmt.Errorf("can't create new task: %v", cErr)
@@ -47,19 +151,517 @@ func (t Service) Create(req CreateRequest) (CreateResponse, error) {
 	return CreateResponse{Task: createdTask}, nil
 }
 
+// normalizeDescription unifies line endings and drops surrounding blank
+// space so descriptions typed on any platform render the same.
+func normalizeDescription(description string) string {
+	description = strings.ReplaceAll(description, "\r\n", "\n")
+
+	return strings.TrimSpace(description)
+}
+
+func validatePriority(p models.Priority) error {
+	if p < models.NoPriority || p > models.UrgentPriority {
+		return fmt.Errorf("invalid priority: %d", p)
+	}
+
+	return nil
+}
+
+func validateRecurrence(r models.Recurrence, dueDate models.DueDate) (models.Recurrence, error) {
+	if dueDate.IsZero() {
+		return models.Recurrence{}, fmt.Errorf("a recurring task needs a due date")
+	}
+
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+
+	if err := r.Validate(); err != nil {
+		return models.Recurrence{}, err
+	}
+
+	// a series is anchored to the day of month it started on
+	if (r.Frequency == models.MonthlyFrequency || r.Frequency == models.YearlyFrequency) && r.ByMonthDay == 0 {
+		_, _, r.ByMonthDay = dueDate.Date()
+	}
+
+	r.SeriesID = 0
+
+	return r, nil
+}
+
 type ListRequest struct {
 	UserID int
+	// Sort is one of the sort options, the smart order when empty.
+	Sort string
+	// Tags keeps only tasks carrying all of them, ExcludeTags drops tasks
+	// carrying any of them.
+	Tags        []string
+	ExcludeTags []string
+	// Ready keeps only open tasks whose blockers are all done.
+	Ready bool
+	// Query is a filter expression such as "status:open tag:work due<+1w",
+	// see compileFilter.
+	Query string
+	// ViewQuery is the filter of the saved view being listed, tasks have to
+	// match it as well as Query.
+	ViewQuery string
+	// Categories are the categories of the user, used to look up the ones a
+	// query names.
+	Categories []models.Category
+	// Workflow is the user's workflow, a query can ask for its statuses.
+	Workflow models.Workflow
+	// Limit is the most tasks to return, all of them when zero. Cursor is the
+	// NextCursor of the page before, empty for the first page.
+	Limit  int
+	Cursor string
 }
 
 type ListResponse struct {
 	Tasks []models.Task
+	// Progress holds the percentage complete of tasks with subtasks or
+	// checklist items, keyed by task ID.
+	Progress map[int]int
+	// OpenBlockers holds the IDs of the open tasks blocking each listed
+	// task, keyed by task ID.
+	OpenBlockers map[int][]int
+	// NextCursor fetches the page after this one, it's empty on the last.
+	NextCursor string
 }
 
 func (t Service) List(req ListRequest) (ListResponse, error) {
-	tasks, err := t.repository.ListUserTasks(req.UserID)
+	tasks, err := t.listUserTasks(req.UserID)
 	if err != nil {
 		return ListResponse{}, fmt.Errorf("can't list user tasks: %v", err)
 	}
 
-	return ListResponse{Tasks: tasks}, nil
+	now := t.now()
+
+	var position *pagePosition
+	if req.Cursor != "" {
+		position, err = decodePagePosition(req.Cursor, req.Sort)
+		if err != nil {
+			return ListResponse{}, err
+		}
+
+		// later pages are filtered and sorted as of the first one
+		now = position.Now
+	}
+
+	// progress and blockers are measured over all tasks, including the
+	// filtered out ones
+	percentages := progress(tasks)
+
+	byID := map[int]models.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	blockers := map[int][]int{}
+	for _, task := range tasks {
+		if open := openBlockers(task, byID); len(open) > 0 {
+			blockers[task.ID] = open
+		}
+	}
+
+	env := filterEnv{now: now, byID: byID, categories: req.Categories, workflow: workflowOrDefault(req.Workflow)}
+
+	tasks, err = filterTasks(tasks, req.ViewQuery, env)
+	if err != nil {
+		return ListResponse{}, fmt.Errorf("view %v", err)
+	}
+
+	tasks, err = filterTasks(tasks, req.Query, env)
+	if err != nil {
+		return ListResponse{}, err
+	}
+
+	if req.Ready {
+		var ready []models.Task
+		for _, task := range tasks {
+			if isReady(task, byID) {
+				ready = append(ready, task)
+			}
+		}
+		tasks = ready
+	}
+
+	if len(req.Tags) > 0 || len(req.ExcludeTags) > 0 {
+		include, iErr := normalizeFilterTags(req.Tags)
+		if iErr != nil {
+			return ListResponse{}, iErr
+		}
+
+		exclude, eErr := normalizeFilterTags(req.ExcludeTags)
+		if eErr != nil {
+			return ListResponse{}, eErr
+		}
+
+		var filtered []models.Task
+		for _, task := range tasks {
+			if matchesTags(task, include, exclude) {
+				filtered = append(filtered, task)
+			}
+		}
+		tasks = filtered
+	}
+
+	if sErr := sortTasks(tasks, req.Sort, now); sErr != nil {
+		return ListResponse{}, sErr
+	}
+
+	if req.Limit <= 0 && position == nil {
+		return ListResponse{Tasks: tasks, Progress: percentages, OpenBlockers: blockers}, nil
+	}
+
+	return paginate(tasks, req, position, now, percentages, blockers)
+}
+
+// UpdateRequest changes the fields of a task that are set, leaving nil ones
+// untouched. The category, parent, milestone and custom fields point into
+// the owner's records, so only the owner can change them.
+type UpdateRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+	Title               *string
+	Description         *string
+	DueDate             *models.DueDate
+	CategoryID          *int
+	Priority            *models.Priority
+	// ParentID moves the task below another task, or to the top level when
+	// it points to zero.
+	ParentID *int
+	// MilestoneID moves the task to a project milestone, or out of its
+	// project when it points to zero.
+	MilestoneID *int
+	// Fields sets values of the custom fields of the task's category, an
+	// empty value clears one. A task moved to another category keeps only
+	// the values that category's fields take. Categories are the categories
+	// of the user to look the fields up in.
+	Fields     map[string]string
+	Categories []models.Category
+}
+
+type UpdateResponse struct {
+	Task models.Task
+}
+
+func (t Service) Update(req UpdateRequest) (UpdateResponse, error) {
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return UpdateResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID)
+	if fErr != nil {
+		return UpdateResponse{}, fErr
+	}
+
+	if rErr := t.checkRole(task, req.AuthenticatedUserID, models.EditorRole); rErr != nil {
+		return UpdateResponse{}, rErr
+	}
+
+	if req.Title != nil {
+		task.Title = *req.Title
+	}
+	if req.Description != nil {
+		task.Description = normalizeDescription(*req.Description)
+	}
+	if req.DueDate != nil {
+		if task.Recurrence != nil && req.DueDate.IsZero() {
+			return UpdateResponse{}, fmt.Errorf("a recurring task needs a due date")
+		}
+		task.DueDate = *req.DueDate
+	}
+	if req.CategoryID != nil {
+		if oErr := checkOwner(task, req.AuthenticatedUserID, "category"); oErr != nil {
+			return UpdateResponse{}, oErr
+		}
+		if cErr := t.checkCategory(*req.CategoryID, req.AuthenticatedUserID); cErr != nil {
+			return UpdateResponse{}, cErr
+		}
+		if *req.CategoryID != task.CategoryID {
+			task.Fields = keepFields(task.Fields, req.Categories, *req.CategoryID)
+		}
+		task.CategoryID = *req.CategoryID
+	}
+	if req.Priority != nil {
+		if vErr := validatePriority(*req.Priority); vErr != nil {
+			return UpdateResponse{}, vErr
+		}
+		task.Priority = *req.Priority
+	}
+	if req.ParentID != nil {
+		if oErr := checkOwner(task, req.AuthenticatedUserID, "parent"); oErr != nil {
+			return UpdateResponse{}, oErr
+		}
+		if vErr := t.validateParent(tasks, task.ID, *req.ParentID, req.AuthenticatedUserID); vErr != nil {
+			return UpdateResponse{}, vErr
+		}
+		task.ParentID = *req.ParentID
+	}
+	if req.MilestoneID != nil {
+		if oErr := checkOwner(task, req.AuthenticatedUserID, "milestone"); oErr != nil {
+			return UpdateResponse{}, oErr
+		}
+		if mErr := t.checkMilestone(*req.MilestoneID, req.AuthenticatedUserID); mErr != nil {
+			return UpdateResponse{}, mErr
+		}
+		task.MilestoneID = *req.MilestoneID
+	}
+	if len(req.Fields) > 0 {
+		if oErr := checkOwner(task, req.AuthenticatedUserID, "fields"); oErr != nil {
+			return UpdateResponse{}, oErr
+		}
+	}
+
+	fields, fErr := setFields(task.Fields, req.Fields, req.Categories, task.CategoryID)
+	if fErr != nil {
+		return UpdateResponse{}, fErr
+	}
+	task.Fields = fields
+
+	updatedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return UpdateResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return UpdateResponse{Task: updatedTask}, nil
+}
+
+type CompleteRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+	// Force completes a task with open subtasks or checklist items, which
+	// are completed along with it.
+	Force bool
+	// Workflow is the user's workflow, the task moves to its first done
+	// status. The default workflow is used when it has no statuses.
+	Workflow models.Workflow
+}
+
+type CompleteResponse struct {
+	Task models.Task
+	// Next is the following occurrence created when a recurring task is
+	// completed, nil when the task doesn't repeat or its series has ended.
+	Next *models.Task
+}
+
+// Complete marks a task as done. The completed task is kept as the history of
+// its series and the next occurrence of a recurring task is created, in the
+// initial status whatever its WIP limit.
+func (t Service) Complete(req CompleteRequest) (CompleteResponse, error) {
+	workflow := workflowOrDefault(req.Workflow)
+
+	return t.complete(req, workflow, workflow.DoneStatus())
+}
+
+// complete moves a task to a done status of the workflow.
+func (t Service) complete(req CompleteRequest, workflow models.Workflow, status string) (CompleteResponse, error) {
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return CompleteResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID)
+	if fErr != nil {
+		return CompleteResponse{}, fErr
+	}
+
+	if rErr := t.checkRole(task, req.AuthenticatedUserID, models.EditorRole); rErr != nil {
+		return CompleteResponse{}, rErr
+	}
+
+	if task.IsDone {
+		return CompleteResponse{}, fmt.Errorf("task %d is already done", task.ID)
+	}
+
+	if mErr := checkMove(tasks, workflow, task, status, req.AuthenticatedUserID); mErr != nil {
+		return CompleteResponse{}, mErr
+	}
+
+	// originals are put back when the completion fails part way, so a failed
+	// request doesn't leave the task done with its series ended
+	var originals []models.Task
+	completing := task
+
+	children := childrenOf(tasks)
+	if open := openParts(task, children); open != "" {
+		if !req.Force {
+			return CompleteResponse{}, fmt.Errorf("task %d still has open parts: %s, complete them first or force it", task.ID, open)
+		}
+
+		// every open subtask is checked before the first one changes
+		var open []models.Task
+		for _, child := range descendants(task.ID, children) {
+			if child.IsDone {
+				continue
+			}
+
+			if rErr := t.checkRole(child, req.AuthenticatedUserID, models.EditorRole); rErr != nil {
+				return CompleteResponse{}, fmt.Errorf("can't complete subtask %d: %v", child.ID, rErr)
+			}
+			open = append(open, child)
+		}
+
+		for _, child := range open {
+			original := child
+			child.IsDone = true
+			child.Status = status
+			if _, uErr := t.repository.UpdateTask(child); uErr != nil {
+				return CompleteResponse{}, t.rollback(originals, fmt.Errorf("can't update subtask: %v", uErr))
+			}
+			originals = append(originals, original)
+		}
+
+		task.Checklist = append([]models.ChecklistItem(nil), task.Checklist...)
+		for i := range task.Checklist {
+			task.Checklist[i].Done = true
+		}
+	}
+
+	task.IsDone = true
+	task.Status = status
+
+	completedTask, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return CompleteResponse{}, t.rollback(originals, fmt.Errorf("can't update task: %v", uErr))
+	}
+	originals = append(originals, completing)
+
+	if task.Recurrence == nil {
+		return CompleteResponse{Task: completedTask}, nil
+	}
+
+	nextDueDate, ok := nextOccurrence(*task.Recurrence, task.DueDate)
+	if !ok {
+		return CompleteResponse{Task: completedTask}, nil
+	}
+
+	recurrence := *task.Recurrence
+	if recurrence.Count > 0 {
+		recurrence.Count--
+	}
+	if recurrence.SeriesID == 0 {
+		recurrence.SeriesID = task.ID
+	}
+
+	nextTask, cErr := t.repository.CreateNewTask(models.Task{
+		Title:       task.Title,
+		Description: task.Description,
+		DueDate:     nextDueDate,
+		CategoryID:  task.CategoryID,
+		IsDone:      false,
+		UserID:      task.UserID,
+		Status:      workflow.InitialStatus(),
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		Recurrence:  &recurrence,
+		ParentID:    task.ParentID,
+		Checklist:   reopenChecklist(task.Checklist),
+		Fields:      task.Fields,
+	})
+	if cErr != nil {
+		return CompleteResponse{}, t.rollback(originals, fmt.Errorf("can't create next occurrence: %v", cErr))
+	}
+
+	return CompleteResponse{Task: completedTask, Next: &nextTask}, nil
+}
+
+// rollback puts back tasks a failed change already updated and returns the
+// error that made it fail.
+func (t Service) rollback(originals []models.Task, err error) error {
+	for _, original := range originals {
+		if _, uErr := t.repository.UpdateTask(original); uErr != nil {
+			return fmt.Errorf("%v, and can't put task %d back: %v", err, original.ID, uErr)
+		}
+	}
+
+	return err
+}
+
+type ShowRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+}
+
+type ShowResponse struct {
+	Task models.Task
+	// Subtasks are the direct subtasks of the task.
+	Subtasks []models.Task
+	// OpenBlockers lists the IDs of the open tasks the task waits on.
+	OpenBlockers []int
+}
+
+// Show returns a single task with the details a listing leaves out.
+func (t Service) Show(req ShowRequest) (ShowResponse, error) {
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ShowResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID)
+	if fErr != nil {
+		return ShowResponse{}, fErr
+	}
+
+	byID := map[int]models.Task{}
+	for _, other := range tasks {
+		byID[other.ID] = other
+	}
+
+	return ShowResponse{
+		Task:         task,
+		Subtasks:     childrenOf(tasks)[task.ID],
+		OpenBlockers: openBlockers(task, byID),
+	}, nil
+}
+
+// listUserTasks returns the tasks of a user that aren't in the trash.
+func (t Service) listUserTasks(userID int) ([]models.Task, error) {
+	tasks, err := t.repository.ListUserTasks(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var live []models.Task
+	for _, task := range tasks {
+		if task.DeletedAt == nil {
+			live = append(live, task)
+		}
+	}
+
+	return live, nil
+}
+
+// findEditableTask finds a task the user may change, see checkRole.
+func (t Service) findEditableTask(userID, taskID int) (models.Task, error) {
+	tasks, err := t.listUserTasks(userID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("can't list user tasks: %v", err)
+	}
+
+	task, fErr := findTask(tasks, taskID)
+	if fErr != nil {
+		return models.Task{}, fErr
+	}
+
+	if rErr := t.checkRole(task, userID, models.EditorRole); rErr != nil {
+		return models.Task{}, rErr
+	}
+
+	return task, nil
+}
+
+func findTask(tasks []models.Task, taskID int) (models.Task, error) {
+	for _, task := range tasks {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+
+	return models.Task{}, fmt.Errorf("task %d not found", taskID)
 }
@@ -1,13 +1,19 @@
 package task
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 	"todo-cli-refactor/models"
+	category2 "todo-cli-refactor/services/category"
 )
 
 type mockRepository struct {
-	data map[int]models.Task
+	data       map[int]models.Task
+	categories []models.Category
+	milestones []models.Milestone
 }
 
 func (m mockRepository) CreateNewTask(task models.Task) (models.Task, error) {
@@ -22,28 +28,66 @@ func (m mockRepository) ListUserTasks(userID int) ([]models.Task, error) {
 	var tasks []models.Task
 
 	for _, task := range m.data {
-		if task.UserID == userID {
+		if task.UserID == userID || task.AssigneeID == userID || category2.Role(m.categories, task.CategoryID, userID) != "" {
 			tasks = append(tasks, task)
 		}
 	}
 
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
 	return tasks, nil
 }
 
+func (m mockRepository) UpdateTask(task models.Task) (models.Task, error) {
+	if _, ok := m.data[task.ID]; !ok {
+		return models.Task{}, fmt.Errorf("task %d not found", task.ID)
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockRepository) ListCategories() ([]models.Category, error) {
+	return m.categories, nil
+}
+
+func (m mockRepository) ListUserMilestones(userID int) ([]models.Milestone, error) {
+	var milestones []models.Milestone
+
+	for _, milestone := range m.milestones {
+		if milestone.UserID == userID {
+			milestones = append(milestones, milestone)
+		}
+	}
+
+	return milestones, nil
+}
+
+// failingCreateRepository stores tasks like mockRepository but can't create
+// new ones.
+type failingCreateRepository struct {
+	mockRepository
+}
+
+func (m failingCreateRepository) CreateNewTask(task models.Task) (models.Task, error) {
+	return models.Task{}, fmt.Errorf("disk full")
+}
+
 func TestCreate(t *testing.T) {
 	mr := mockRepository{
 		data: map[int]models.Task{
-			1: {ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3},
-			2: {ID: 2, Title: "Clean the house", DueDate: "2022-01-01", CategoryID: 1, IsDone: true, UserID: 4},
-			3: {ID: 3, Title: "Read a book", DueDate: "2022-01-02", CategoryID: 3, IsDone: false, UserID: 5},
+			1: {ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+			2: {ID: 2, Title: "Clean the house", DueDate: models.NewDueDate(2022, 1, 1), CategoryID: 1, IsDone: true, UserID: 4},
+			3: {ID: 3, Title: "Read a book", DueDate: models.NewDueDate(2022, 1, 2), CategoryID: 3, IsDone: false, UserID: 5},
 		},
 	}
 
-	s := NewService(mr)
+	s := NewService(mr, mr)
 
 	req := CreateRequest{
 		Title:               "Watch a movie",
-		DueDate:             "2022-01-03",
+		DueDate:             models.NewDueDate(2022, 1, 3),
 		CategoryID:          4,
 		AuthenticatedUserID: 6,
 	}
@@ -56,10 +100,11 @@ func TestCreate(t *testing.T) {
 	expected := models.Task{
 		ID:         4,
 		Title:      "Watch a movie",
-		DueDate:    "2022-01-03",
+		DueDate:    models.NewDueDate(2022, 1, 3),
 		CategoryID: 4,
 		IsDone:     false,
 		UserID:     6,
+		Status:     "backlog",
 	}
 	if !reflect.DeepEqual(res.Task, expected) {
 		t.Errorf("response does not match expected data: got %v, want %v", res.Task, expected)
@@ -69,14 +114,14 @@ func TestCreate(t *testing.T) {
 func TestListUserTasks(t *testing.T) {
 	mr := mockRepository{
 		data: map[int]models.Task{
-			1: {ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3},
-			2: {ID: 2, Title: "Clean the house", DueDate: "2022-01-01", CategoryID: 1, IsDone: true, UserID: 4},
-			3: {ID: 3, Title: "Read a book", DueDate: "2022-01-02", CategoryID: 3, IsDone: false, UserID: 5},
-			4: {ID: 4, Title: "Watch a movie", DueDate: "2022-01-03", CategoryID: 4, IsDone: false, UserID: 6},
+			1: {ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+			2: {ID: 2, Title: "Clean the house", DueDate: models.NewDueDate(2022, 1, 1), CategoryID: 1, IsDone: true, UserID: 4},
+			3: {ID: 3, Title: "Read a book", DueDate: models.NewDueDate(2022, 1, 2), CategoryID: 3, IsDone: false, UserID: 5},
+			4: {ID: 4, Title: "Watch a movie", DueDate: models.NewDueDate(2022, 1, 3), CategoryID: 4, IsDone: false, UserID: 6},
 		},
 	}
 
-	s := NewService(mr)
+	s := NewService(mr, mr)
 
 	req := ListRequest{
 		UserID: 3,
@@ -88,9 +133,242 @@ func TestListUserTasks(t *testing.T) {
 	}
 
 	expected := []models.Task{
-		{ID: 1, Title: "Buy groceries", DueDate: "2021-12-31", CategoryID: 2, IsDone: false, UserID: 3},
+		{ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
 	}
 	if !reflect.DeepEqual(res.Tasks, expected) {
 		t.Errorf("response does not match expected data: got %v, want %v", res.Tasks, expected)
 	}
 }
+
+func TestComplete(t *testing.T) {
+	t.Run("single task", func(t *testing.T) {
+		mr := mockRepository{
+			data: map[int]models.Task{
+				1: {ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+			},
+		}
+
+		s := NewService(mr, mr)
+
+		res, err := s.Complete(CompleteRequest{TaskID: 1, AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+
+		if !res.Task.IsDone || !mr.data[1].IsDone {
+			t.Errorf("task should be done: got %v", mr.data[1])
+		}
+		if res.Next != nil || len(mr.data) != 1 {
+			t.Errorf("a single task should not create another one: got %v", res.Next)
+		}
+
+		if _, err := s.Complete(CompleteRequest{TaskID: 1, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("Complete should fail for a done task")
+		}
+	})
+
+	t.Run("other user's task", func(t *testing.T) {
+		mr := mockRepository{
+			data: map[int]models.Task{
+				1: {ID: 1, Title: "Buy groceries", UserID: 3},
+			},
+		}
+
+		s := NewService(mr, mr)
+
+		if _, err := s.Complete(CompleteRequest{TaskID: 1, AuthenticatedUserID: 4}); err == nil {
+			t.Errorf("Complete should fail for a task of another user")
+		}
+	})
+
+	t.Run("recurring task", func(t *testing.T) {
+		mr := mockRepository{data: map[int]models.Task{}}
+
+		s := NewService(mr, mr)
+
+		rule := models.Recurrence{Frequency: models.WeeklyFrequency, ByWeekday: []time.Weekday{time.Monday, time.Thursday}, Count: 2}
+		created, err := s.Create(CreateRequest{
+			Title:               "Water the plants",
+			DueDate:             models.NewDueDate(2026, 10, 19),
+			Recurrence:          &rule,
+			AuthenticatedUserID: 3,
+		})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		res, err := s.Complete(CompleteRequest{TaskID: created.Task.ID, AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+
+		if res.Next == nil {
+			t.Fatalf("completing a recurring task should create the next occurrence")
+		}
+
+		expected := models.Task{
+			ID:      2,
+			Title:   "Water the plants",
+			DueDate: models.NewDueDate(2026, 10, 22),
+			UserID:  3,
+			Status:  "backlog",
+			Recurrence: &models.Recurrence{
+				Frequency: models.WeeklyFrequency,
+				Interval:  1,
+				ByWeekday: []time.Weekday{time.Monday, time.Thursday},
+				Count:     1,
+				SeriesID:  1,
+			},
+		}
+		if !reflect.DeepEqual(*res.Next, expected) {
+			t.Errorf("next occurrence does not match expected data: got %v, want %v", *res.Next, expected)
+		}
+		if !mr.data[1].IsDone {
+			t.Errorf("completed occurrence should be kept as done")
+		}
+
+		last, err := s.Complete(CompleteRequest{TaskID: res.Next.ID, AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		if last.Next != nil {
+			t.Errorf("a series with its count used up should end: got %v", last.Next)
+		}
+	})
+
+	t.Run("recurring task when the next occurrence can't be created", func(t *testing.T) {
+		mr := mockRepository{
+			data: map[int]models.Task{
+				1: {ID: 1, Title: "Water the plants", DueDate: models.NewDueDate(2026, 10, 19), UserID: 3, Status: "backlog",
+					Recurrence: &models.Recurrence{Frequency: models.DailyFrequency, Interval: 1}},
+				2: {ID: 2, Title: "Fill the can", UserID: 3, ParentID: 1, Status: "backlog"},
+			},
+		}
+
+		s := NewService(failingCreateRepository{mr}, mr)
+
+		if _, err := s.Complete(CompleteRequest{TaskID: 1, Force: true, AuthenticatedUserID: 3}); err == nil {
+			t.Fatalf("Complete should fail when the next occurrence can't be created")
+		}
+
+		if mr.data[1].IsDone || mr.data[1].Status != "backlog" || mr.data[2].IsDone {
+			t.Errorf("a failed completion should leave the tasks open: got %v", mr.data)
+		}
+	})
+
+	t.Run("recurring task without due date", func(t *testing.T) {
+		mr := mockRepository{data: map[int]models.Task{}}
+		s := NewService(mr, mr)
+
+		_, err := s.Create(CreateRequest{
+			Title:               "Water the plants",
+			Recurrence:          &models.Recurrence{Frequency: models.DailyFrequency},
+			AuthenticatedUserID: 3,
+		})
+		if err == nil {
+			t.Errorf("Create should fail for a recurring task without due date")
+		}
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Buy groceries", DueDate: models.NewDueDate(2021, 12, 31), CategoryID: 2, IsDone: false, UserID: 3},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	title := "Buy groceries and milk"
+	description := "\r\n- eggs\r\n- bread\r\n"
+	priority := models.HighPriority
+	res, err := s.Update(UpdateRequest{TaskID: 1, AuthenticatedUserID: 3, Title: &title, Description: &description, Priority: &priority})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	expected := models.Task{
+		ID:          1,
+		Title:       "Buy groceries and milk",
+		Description: "- eggs\n- bread",
+		DueDate:     models.NewDueDate(2021, 12, 31),
+		CategoryID:  2,
+		UserID:      3,
+		Priority:    models.HighPriority,
+	}
+	if !reflect.DeepEqual(res.Task, expected) || !reflect.DeepEqual(mr.data[1], expected) {
+		t.Errorf("response does not match expected data: got %v, want %v", res.Task, expected)
+	}
+
+	invalid := models.Priority(9)
+	if _, err := s.Update(UpdateRequest{TaskID: 1, AuthenticatedUserID: 3, Priority: &invalid}); err == nil {
+		t.Errorf("Update should fail with an invalid priority")
+	}
+
+	if _, err := s.Update(UpdateRequest{TaskID: 1, AuthenticatedUserID: 4, Title: &title}); err == nil {
+		t.Errorf("Update should fail for a task of another user")
+	}
+}
+
+func TestShow(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Release 3", Description: "# Plan\n\n- ship it", UserID: 3, BlockedBy: []int{4, 5}},
+			2: {ID: 2, Title: "Write changelog", ParentID: 1, UserID: 3},
+			3: {ID: 3, Title: "Proofread", ParentID: 2, UserID: 3},
+			4: {ID: 4, Title: "Fix bugs", UserID: 3},
+			5: {ID: 5, Title: "Update docs", UserID: 3, IsDone: true},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	res, err := s.Show(ShowRequest{TaskID: 1, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(res.Task, mr.data[1]) {
+		t.Errorf("task does not match: got %v, want %v", res.Task, mr.data[1])
+	}
+
+	if len(res.Subtasks) != 1 || res.Subtasks[0].ID != 2 {
+		t.Errorf("only task 2 should be a direct subtask: got %v", res.Subtasks)
+	}
+
+	if !reflect.DeepEqual(res.OpenBlockers, []int{4}) {
+		t.Errorf("open blockers do not match: got %v", res.OpenBlockers)
+	}
+
+	if _, err := s.Show(ShowRequest{TaskID: 1, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("Show should fail for a task of another user")
+	}
+}
+
+func TestTrashedTasksAreHidden(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Buy groceries", UserID: 3},
+			2: {ID: 2, Title: "Clean the house", UserID: 3, DeletedAt: &deletedAt},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	res, err := s.List(ListRequest{UserID: 3})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(res.Tasks) != 1 || res.Tasks[0].ID != 1 {
+		t.Errorf("trashed tasks should not be listed: got %v", res.Tasks)
+	}
+
+	title := "Clean the kitchen"
+	if _, err := s.Update(UpdateRequest{TaskID: 2, AuthenticatedUserID: 3, Title: &title}); err == nil {
+		t.Errorf("Update should fail for a trashed task")
+	}
+}
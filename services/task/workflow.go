@@ -0,0 +1,182 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"todo-cli-refactor/models"
+)
+
+// workflowOrDefault returns the default workflow in place of one without
+// statuses, which callers that don't know the user's workflow pass.
+func workflowOrDefault(w models.Workflow) models.Workflow {
+	if len(w.Statuses) == 0 {
+		return models.DefaultWorkflow()
+	}
+
+	return w
+}
+
+// checkWIPLimit fails when a status already holds as many tasks of the
+// workflow's owner as its WIP limit allows. Tasks shared with the owner
+// follow their own owner's workflow and aren't counted, nor is the task with
+// exceptID, the one being moved.
+func checkWIPLimit(tasks []models.Task, workflow models.Workflow, status string, ownerID, exceptID int) error {
+	s, _ := workflow.Status(status)
+	if s.WIPLimit == 0 {
+		return nil
+	}
+
+	count := 0
+	for _, task := range tasks {
+		if task.UserID == ownerID && task.ID != exceptID && task.DeletedAt == nil && workflow.StatusOf(task) == status {
+			count++
+		}
+	}
+
+	if count >= s.WIPLimit {
+		return fmt.Errorf("status %s is at its WIP limit of %d", status, s.WIPLimit)
+	}
+
+	return nil
+}
+
+// checkMove checks that a task can move to a status: the workflow allows
+// the transition and the status has room for it among the tasks of userID.
+func checkMove(tasks []models.Task, workflow models.Workflow, task models.Task, status string, userID int) error {
+	current := workflow.StatusOf(task)
+	if current == status {
+		return fmt.Errorf("task %d is already in %s", task.ID, status)
+	}
+
+	if !workflow.CanMove(current, status) {
+		return fmt.Errorf("task %d can't move from %s to %s, use one of %s", task.ID, current, status,
+			strings.Join(workflow.Transitions[current], ", "))
+	}
+
+	return checkWIPLimit(tasks, workflow, status, userID, task.ID)
+}
+
+type MoveRequest struct {
+	TaskID int
+	Status string
+	// Force completes a task with open parts when it moves to a done status,
+	// see CompleteRequest.
+	Force bool
+	// Workflow is the user's workflow, the default one when it has no
+	// statuses.
+	Workflow            models.Workflow
+	AuthenticatedUserID int
+}
+
+type MoveResponse struct {
+	Task models.Task
+	// Next is the following occurrence of a recurring task moved to a done
+	// status.
+	Next *models.Task
+}
+
+// Move puts a task in another status of the workflow. Moving to a done
+// status completes the task, moving out of one reopens it.
+func (t Service) Move(req MoveRequest) (MoveResponse, error) {
+	workflow := workflowOrDefault(req.Workflow)
+
+	name := strings.ToLower(strings.TrimSpace(req.Status))
+	status, ok := workflow.Status(name)
+	if !ok {
+		names := make([]string, 0, len(workflow.Statuses))
+		for _, s := range workflow.Statuses {
+			names = append(names, s.Name)
+		}
+
+		return MoveResponse{}, fmt.Errorf("unknown status %q, use one of %s", req.Status, strings.Join(names, ", "))
+	}
+
+	if status.Done {
+		completed, cErr := t.complete(CompleteRequest{
+			TaskID:              req.TaskID,
+			AuthenticatedUserID: req.AuthenticatedUserID,
+			Force:               req.Force,
+		}, workflow, status.Name)
+		if cErr != nil {
+			return MoveResponse{}, cErr
+		}
+
+		return MoveResponse{Task: completed.Task, Next: completed.Next}, nil
+	}
+
+	tasks, lErr := t.listUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return MoveResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID)
+	if fErr != nil {
+		return MoveResponse{}, fErr
+	}
+
+	if rErr := t.checkRole(task, req.AuthenticatedUserID, models.EditorRole); rErr != nil {
+		return MoveResponse{}, rErr
+	}
+
+	if mErr := checkMove(tasks, workflow, task, status.Name, req.AuthenticatedUserID); mErr != nil {
+		return MoveResponse{}, mErr
+	}
+
+	task.Status = status.Name
+	task.IsDone = false
+
+	moved, uErr := t.repository.UpdateTask(task)
+	if uErr != nil {
+		return MoveResponse{}, fmt.Errorf("can't update task: %v", uErr)
+	}
+
+	return MoveResponse{Task: moved}, nil
+}
+
+type BoardRequest struct {
+	UserID int
+	// Query narrows the board down to the tasks matching it, see
+	// ListRequest.
+	Query      string
+	Categories []models.Category
+	Workflow   models.Workflow
+}
+
+// Column holds the tasks in a status, in the smart order.
+type Column struct {
+	Status models.WorkflowStatus
+	Tasks  []models.Task
+}
+
+type BoardResponse struct {
+	Columns []Column
+}
+
+// Board lays the tasks of a user out in a column per workflow status.
+func (t Service) Board(req BoardRequest) (BoardResponse, error) {
+	workflow := workflowOrDefault(req.Workflow)
+
+	list, lErr := t.List(ListRequest{
+		UserID:     req.UserID,
+		Query:      req.Query,
+		Categories: req.Categories,
+		Workflow:   workflow,
+	})
+	if lErr != nil {
+		return BoardResponse{}, lErr
+	}
+
+	columns := make([]Column, len(workflow.Statuses))
+	index := map[string]int{}
+	for i, s := range workflow.Statuses {
+		columns[i].Status = s
+		index[s.Name] = i
+	}
+
+	for _, task := range list.Tasks {
+		i := index[workflow.StatusOf(task)]
+		columns[i].Tasks = append(columns[i].Tasks, task)
+	}
+
+	return BoardResponse{Columns: columns}, nil
+}
@@ -0,0 +1,156 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+var kanban = models.Workflow{
+	Statuses: []models.WorkflowStatus{
+		{Name: "todo"},
+		{Name: "doing", WIPLimit: 1},
+		{Name: "done", Done: true},
+	},
+	Transitions: map[string][]string{"todo": {"doing"}},
+}
+
+func TestMove(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			// stored before workflows, so only IsDone is known
+			1: {ID: 1, Title: "Write docs", UserID: 3},
+			2: {ID: 2, Title: "Ship", UserID: 3, Status: "doing"},
+			3: {ID: 3, Title: "Plan", UserID: 3, IsDone: true},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	move := func(taskID int, status string) (MoveResponse, error) {
+		return s.Move(MoveRequest{TaskID: taskID, Status: status, Workflow: kanban, AuthenticatedUserID: 3})
+	}
+
+	if _, err := move(1, "done"); err == nil {
+		t.Errorf("todo shouldn't move straight to done")
+	}
+	if _, err := move(1, "doing"); err == nil {
+		t.Errorf("doing is at its WIP limit")
+	}
+	if _, err := move(1, "review"); err == nil {
+		t.Errorf("review isn't a status of the workflow")
+	}
+
+	res, err := move(2, "done")
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if !res.Task.IsDone || res.Task.Status != "done" {
+		t.Errorf("expected the task done, got %v", res.Task)
+	}
+
+	res, err = move(1, "doing")
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if res.Task.IsDone || res.Task.Status != "doing" {
+		t.Errorf("expected the task in doing, got %v", res.Task)
+	}
+
+	res, err = move(3, "Todo")
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if res.Task.IsDone || res.Task.Status != "todo" {
+		t.Errorf("expected the task reopened, got %v", res.Task)
+	}
+
+	if _, err := move(3, "todo"); err == nil {
+		t.Errorf("Move should fail for a task already in the status")
+	}
+}
+
+func TestCreateWIPLimit(t *testing.T) {
+	workflow := models.Workflow{Statuses: []models.WorkflowStatus{{Name: "inbox", WIPLimit: 1}, {Name: "done", Done: true}}}
+
+	mr := mockRepository{data: map[int]models.Task{}}
+	s := NewService(mr, mr)
+
+	res, err := s.Create(CreateRequest{Title: "First", Workflow: workflow, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if res.Task.Status != "inbox" {
+		t.Errorf("got status %q, want inbox", res.Task.Status)
+	}
+
+	if _, err := s.Create(CreateRequest{Title: "Second", Workflow: workflow, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Create should fail past the WIP limit")
+	}
+}
+
+func TestSharedWIPLimit(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Write docs", UserID: 3},
+			2: {ID: 2, Title: "Review the design", CategoryID: 1, UserID: 4, Status: "doing"},
+			3: {ID: 3, Title: "Fix the build", UserID: 3},
+		},
+		categories: []models.Category{
+			{ID: 1, Title: "Team", UserID: 4, Shares: []models.Share{{UserID: 3, Role: models.EditorRole}}},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	// the shared task counts against its owner's limit, not against the user's
+	res, err := s.Move(MoveRequest{TaskID: 1, Status: "doing", Workflow: kanban, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if res.Task.Status != "doing" {
+		t.Errorf("expected the task in doing, got %v", res.Task)
+	}
+
+	if _, err := s.Move(MoveRequest{TaskID: 3, Status: "doing", Workflow: kanban, AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("doing is at its WIP limit with the user's own task")
+	}
+}
+
+func TestBoard(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Write docs", UserID: 3},
+			2: {ID: 2, Title: "Ship", UserID: 3, Status: "doing"},
+			3: {ID: 3, Title: "Plan", UserID: 3, IsDone: true},
+			4: {ID: 4, Title: "Archived", UserID: 3, Status: "gone"},
+		},
+	}
+
+	s := NewService(mr, mr)
+
+	res, err := s.Board(BoardRequest{UserID: 3, Workflow: kanban})
+	if err != nil {
+		t.Fatalf("Board failed: %v", err)
+	}
+
+	columns := map[string][]int{}
+	for _, column := range res.Columns {
+		for _, task := range column.Tasks {
+			columns[column.Status.Name] = append(columns[column.Status.Name], task.ID)
+		}
+	}
+
+	expected := map[string][]int{"todo": {1, 4}, "doing": {2}, "done": {3}}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("got columns %v, want %v", columns, expected)
+	}
+
+	list, err := s.List(ListRequest{UserID: 3, Query: "status:todo", Workflow: kanban})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list.Tasks) != 2 {
+		t.Errorf("got %v, want the tasks in todo", list.Tasks)
+	}
+}
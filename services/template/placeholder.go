@@ -0,0 +1,55 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// placeholderPattern matches a {{name}} placeholder, spaces inside the braces
+// are allowed.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([\pL\pN_-]+)\s*\}\}`)
+
+// placeholders returns the names of the placeholders in text, failing for
+// braces that don't form one.
+func placeholders(text string) ([]string, error) {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		names = append(names, strings.ToLower(match[1]))
+	}
+
+	if rest := placeholderPattern.ReplaceAllString(text, ""); strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return nil, fmt.Errorf("invalid placeholder in %q, use {{name}}", text)
+	}
+
+	return names, nil
+}
+
+// render fills in the placeholders of text. Names without a value are added
+// to missing and left in place.
+func render(text string, values map[string]string, missing map[string]bool) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := strings.ToLower(placeholderPattern.FindStringSubmatch(placeholder)[1])
+
+		value, ok := values[name]
+		if !ok {
+			missing[name] = true
+
+			return placeholder
+		}
+
+		return value
+	})
+}
+
+// placeholderList renders names as "{{a}}, {{b}}" in a stable order.
+func placeholderList(names map[string]bool) string {
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, "{{"+name+"}}")
+	}
+	sort.Strings(list)
+
+	return strings.Join(list, ", ")
+}
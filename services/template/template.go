@@ -0,0 +1,363 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/duedate"
+	"todo-cli-refactor/services/task"
+	"unicode"
+)
+
+type ServiceRepository interface {
+	CreateNewTemplate(t models.Template) (models.Template, error)
+	ListUserTemplates(userID int) ([]models.Template, error)
+	UpdateTemplate(t models.Template) (models.Template, error)
+	DeleteTemplate(id int) error
+}
+
+// TaskService creates the tasks of an applied template.
+type TaskService interface {
+	Create(req task.CreateRequest) (task.CreateResponse, error)
+}
+
+// TaskRepository removes the tasks of a template that failed to apply
+// halfway.
+type TaskRepository interface {
+	DeleteTask(id int) error
+}
+
+type Service struct {
+	repository     ServiceRepository
+	tasks          TaskService
+	taskRepository TaskRepository
+}
+
+func NewService(repo ServiceRepository, tasks TaskService, taskRepo TaskRepository) Service {
+	return Service{
+		repository:     repo,
+		tasks:          tasks,
+		taskRepository: taskRepo,
+	}
+}
+
+type SaveRequest struct {
+	Name                string
+	Tasks               []models.TemplateTask
+	AuthenticatedUserID int
+}
+
+type SaveResponse struct {
+	Template models.Template
+}
+
+// Save stores a template of a user, replacing the one with the same name.
+func (s Service) Save(req SaveRequest) (SaveResponse, error) {
+	name, nErr := normalizeName(req.Name)
+	if nErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save template: %v", nErr)
+	}
+
+	if vErr := validateTasks(req.Tasks); vErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save template: %v", vErr)
+	}
+
+	template := models.Template{
+		UserID: req.AuthenticatedUserID,
+		Name:   name,
+		Tasks:  req.Tasks,
+	}
+
+	existing, found, fErr := s.find(req.AuthenticatedUserID, name)
+	if fErr != nil {
+		return SaveResponse{}, fErr
+	}
+
+	var saved models.Template
+	var sErr error
+	if found {
+		template.ID = existing.ID
+		saved, sErr = s.repository.UpdateTemplate(template)
+	} else {
+		saved, sErr = s.repository.CreateNewTemplate(template)
+	}
+	if sErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save template: %v", sErr)
+	}
+
+	return SaveResponse{Template: saved}, nil
+}
+
+type ListRequest struct {
+	AuthenticatedUserID int
+}
+
+type ListResponse struct {
+	Templates []models.Template
+}
+
+func (s Service) List(req ListRequest) (ListResponse, error) {
+	templates, lErr := s.repository.ListUserTemplates(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list user templates: %v", lErr)
+	}
+
+	return ListResponse{Templates: templates}, nil
+}
+
+type DeleteRequest struct {
+	Name                string
+	AuthenticatedUserID int
+}
+
+type DeleteResponse struct {
+	Template models.Template
+}
+
+func (s Service) Delete(req DeleteRequest) (DeleteResponse, error) {
+	template, fErr := s.get(req.AuthenticatedUserID, req.Name)
+	if fErr != nil {
+		return DeleteResponse{}, fErr
+	}
+
+	if dErr := s.repository.DeleteTemplate(template.ID); dErr != nil {
+		return DeleteResponse{}, fmt.Errorf("can't delete template: %v", dErr)
+	}
+
+	return DeleteResponse{Template: template}, nil
+}
+
+type ApplyRequest struct {
+	Name string
+	// Values fill in the placeholders of the template, keyed by their name.
+	Values map[string]string
+	// Start is the day due offsets count from, in the user's time zone.
+	Start time.Time
+	// Workflow is the user's workflow, the tasks start in its initial status.
+	Workflow            models.Workflow
+	AuthenticatedUserID int
+}
+
+type ApplyResponse struct {
+	// Tasks are the created tasks in the order of the template.
+	Tasks []models.Task
+}
+
+// Apply creates the tasks of a template. Every task is checked before the
+// first one is created, and should creating one still fail the tasks created
+// before it are removed again, so a template is applied whole or not at all.
+func (s Service) Apply(req ApplyRequest) (ApplyResponse, error) {
+	template, fErr := s.get(req.AuthenticatedUserID, req.Name)
+	if fErr != nil {
+		return ApplyResponse{}, fErr
+	}
+
+	requests, pErr := prepare(template, req)
+	if pErr != nil {
+		return ApplyResponse{}, fmt.Errorf("can't apply template %s: %v", template.Name, pErr)
+	}
+
+	var created []models.Task
+	for i, createRequest := range requests {
+		if parent := template.Tasks[i].Parent; parent != 0 {
+			createRequest.ParentID = created[parent-1].ID
+		}
+
+		response, cErr := s.tasks.Create(createRequest)
+		if cErr != nil {
+			if rErr := s.rollback(created); rErr != nil {
+				return ApplyResponse{}, fmt.Errorf("can't apply template %s: task %d: %v, and %v", template.Name, i+1, cErr, rErr)
+			}
+
+			return ApplyResponse{}, fmt.Errorf("can't apply template %s: task %d: %v", template.Name, i+1, cErr)
+		}
+
+		created = append(created, response.Task)
+	}
+
+	return ApplyResponse{Tasks: created}, nil
+}
+
+// prepare turns the tasks of a template into create requests, filling in
+// placeholders and resolving due offsets against the start day.
+func prepare(template models.Template, req ApplyRequest) ([]task.CreateRequest, error) {
+	values := map[string]string{}
+	for name, value := range req.Values {
+		values[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+
+	used := map[string]bool{}
+	for _, name := range Placeholders(template) {
+		used[name] = true
+	}
+
+	unknown := map[string]bool{}
+	for name := range values {
+		if !used[name] {
+			unknown[name] = true
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("the template has no placeholder %s", placeholderList(unknown))
+	}
+
+	start := req.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	missing := map[string]bool{}
+	requests := make([]task.CreateRequest, 0, len(template.Tasks))
+	for i, t := range template.Tasks {
+		createRequest := task.CreateRequest{
+			Title:               render(t.Title, values, missing),
+			Description:         render(t.Description, values, missing),
+			CategoryID:          t.CategoryID,
+			Priority:            t.Priority,
+			Workflow:            req.Workflow,
+			AuthenticatedUserID: req.AuthenticatedUserID,
+		}
+
+		for _, tag := range t.Tags {
+			normalized, nErr := models.NormalizeTag(render(tag, values, missing))
+			if nErr != nil && len(missing) == 0 {
+				return nil, fmt.Errorf("task %d: %v", i+1, nErr)
+			}
+			createRequest.Tags = append(createRequest.Tags, normalized)
+		}
+
+		for _, item := range t.Checklist {
+			createRequest.Checklist = append(createRequest.Checklist, render(item, values, missing))
+		}
+
+		due, dErr := duedate.Parse(render(t.Due, values, missing), start)
+		if dErr != nil && len(missing) == 0 {
+			return nil, fmt.Errorf("task %d: %v", i+1, dErr)
+		}
+		createRequest.DueDate = due
+
+		requests = append(requests, createRequest)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no value given for %s", placeholderList(missing))
+	}
+
+	return requests, nil
+}
+
+// rollback removes the tasks created so far, subtasks first.
+func (s Service) rollback(created []models.Task) error {
+	for i := len(created) - 1; i >= 0; i-- {
+		if dErr := s.taskRepository.DeleteTask(created[i].ID); dErr != nil {
+			return fmt.Errorf("can't remove the tasks created so far: %v", dErr)
+		}
+	}
+
+	return nil
+}
+
+// Placeholders returns the names of the placeholders of a template, each
+// once, in the order they first appear in.
+func Placeholders(template models.Template) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	for _, t := range template.Tasks {
+		texts := append([]string{t.Title, t.Description, t.Due}, t.Tags...)
+		for _, text := range append(texts, t.Checklist...) {
+			found, _ := placeholders(text)
+			for _, name := range found {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// validateTasks checks what can be checked of template tasks before their
+// placeholders are filled in.
+func validateTasks(tasks []models.TemplateTask) error {
+	if len(tasks) == 0 {
+		return fmt.Errorf("a template needs at least one task")
+	}
+
+	for i, t := range tasks {
+		if strings.TrimSpace(t.Title) == "" {
+			return fmt.Errorf("task %d has no title", i+1)
+		}
+
+		if t.Priority < models.NoPriority || t.Priority > models.UrgentPriority {
+			return fmt.Errorf("task %d: invalid priority: %d", i+1, t.Priority)
+		}
+
+		if t.Parent < 0 || t.Parent > i {
+			return fmt.Errorf("task %d: parent %d isn't an earlier task of the template", i+1, t.Parent)
+		}
+
+		texts := append([]string{t.Title, t.Description, t.Due}, t.Tags...)
+		for _, text := range append(texts, t.Checklist...) {
+			if _, pErr := placeholders(text); pErr != nil {
+				return fmt.Errorf("task %d: %v", i+1, pErr)
+			}
+		}
+
+		// offsets holding placeholders can only be checked once applied
+		if names, _ := placeholders(t.Due); len(names) == 0 {
+			if _, dErr := duedate.Parse(t.Due, time.Now()); dErr != nil {
+				return fmt.Errorf("task %d: %v", i+1, dErr)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s Service) get(userID int, name string) (models.Template, error) {
+	template, found, fErr := s.find(userID, strings.ToLower(strings.TrimSpace(name)))
+	if fErr != nil {
+		return models.Template{}, fErr
+	}
+	if !found {
+		return models.Template{}, fmt.Errorf("template %q not found", name)
+	}
+
+	return template, nil
+}
+
+func (s Service) find(userID int, name string) (models.Template, bool, error) {
+	templates, lErr := s.repository.ListUserTemplates(userID)
+	if lErr != nil {
+		return models.Template{}, false, fmt.Errorf("can't list user templates: %v", lErr)
+	}
+
+	for _, template := range templates {
+		if template.Name == name {
+			return template, true, nil
+		}
+	}
+
+	return models.Template{}, false, nil
+}
+
+// normalizeName lower cases a template name, which has to be a single word
+// of letters, digits, dashes and underscores.
+func normalizeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("template name can't be empty")
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", fmt.Errorf("invalid template name %q, use letters, digits, - and _", name)
+		}
+	}
+
+	return name, nil
+}
@@ -0,0 +1,259 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/services/task"
+)
+
+type mockRepository struct {
+	data map[int]models.Template
+}
+
+func (m mockRepository) CreateNewTemplate(template models.Template) (models.Template, error) {
+	template.ID = len(m.data) + 1
+
+	m.data[template.ID] = template
+
+	return template, nil
+}
+
+func (m mockRepository) ListUserTemplates(userID int) ([]models.Template, error) {
+	var templates []models.Template
+
+	for _, template := range m.data {
+		if template.UserID == userID {
+			templates = append(templates, template)
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+
+	return templates, nil
+}
+
+func (m mockRepository) UpdateTemplate(template models.Template) (models.Template, error) {
+	if _, ok := m.data[template.ID]; !ok {
+		return models.Template{}, fmt.Errorf("template %d not found", template.ID)
+	}
+
+	m.data[template.ID] = template
+
+	return template, nil
+}
+
+func (m mockRepository) DeleteTemplate(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+// mockTaskRepository stores tasks for a task.Service and fails to create
+// tasks titled failTitle.
+type mockTaskRepository struct {
+	data      map[int]models.Task
+	failTitle string
+}
+
+func (m mockTaskRepository) CreateNewTask(t models.Task) (models.Task, error) {
+	if t.Title == m.failTitle {
+		return models.Task{}, fmt.Errorf("disk full")
+	}
+
+	t.ID = len(m.data) + 1
+	for m.data[t.ID].ID != 0 {
+		t.ID++
+	}
+
+	m.data[t.ID] = t
+
+	return t, nil
+}
+
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, t := range m.data {
+		if t.UserID == userID {
+			tasks = append(tasks, t)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) UpdateTask(t models.Task) (models.Task, error) {
+	m.data[t.ID] = t
+
+	return t, nil
+}
+
+func (m mockTaskRepository) DeleteTask(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+func (m mockTaskRepository) ListCategories() ([]models.Category, error) {
+	return nil, nil
+}
+
+func (m mockTaskRepository) ListUserMilestones(userID int) ([]models.Milestone, error) {
+	return nil, nil
+}
+
+var release = []models.TemplateTask{
+	{Title: "Release {{version}}", Due: "+1w", CategoryID: 2, Priority: models.HighPriority, Tags: []string{"#release-{{ version }}"}},
+	{Title: "Write notes for {{version}}", Due: "+5d 09:00", Parent: 1, Checklist: []string{"mention {{codename}}"}},
+	{Title: "Announce"},
+}
+
+func TestSaveTemplate(t *testing.T) {
+	s := NewService(mockRepository{data: map[int]models.Template{}}, nil, nil)
+
+	saved, err := s.Save(SaveRequest{Name: " Release ", Tasks: release, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if saved.Template.Name != "release" {
+		t.Errorf("template names should be lower cased, got %q", saved.Template.Name)
+	}
+
+	if got := Placeholders(saved.Template); !reflect.DeepEqual(got, []string{"version", "codename"}) {
+		t.Errorf("unexpected placeholders %v", got)
+	}
+
+	invalid := map[string][]models.TemplateTask{
+		"no tasks":        nil,
+		"no title":        {{Title: " "}},
+		"later parent":    {{Title: "a", Parent: 2}, {Title: "b"}},
+		"own parent":      {{Title: "a", Parent: 1}},
+		"bad offset":      {{Title: "a", Due: "+3 fortnights"}},
+		"open braces":     {{Title: "release {{version"}},
+		"bad priority":    {{Title: "a", Priority: 7}},
+		"bad placeholder": {{Title: "a", Tags: []string{"{{two words}}"}}},
+	}
+	for name, tasks := range invalid {
+		if _, err := s.Save(SaveRequest{Name: "other", Tasks: tasks, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("Save should fail for %s", name)
+		}
+	}
+
+	listed, err := s.List(ListRequest{AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listed.Templates) != 1 {
+		t.Errorf("invalid templates shouldn't be saved, got %v", listed.Templates)
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	repo := mockRepository{data: map[int]models.Template{}}
+	tasks := mockTaskRepository{data: map[int]models.Task{}}
+	s := NewService(repo, task.NewService(tasks, tasks), tasks)
+
+	if _, err := s.Save(SaveRequest{Name: "release", Tasks: release, AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	start := time.Date(2026, 11, 2, 15, 0, 0, 0, time.FixedZone("IRST", 3*3600+1800))
+
+	t.Run("missing value", func(t *testing.T) {
+		_, err := s.Apply(ApplyRequest{Name: "release", Values: map[string]string{"version": "3.2"}, Start: start, AuthenticatedUserID: 3})
+		if err == nil || !strings.Contains(err.Error(), "{{codename}}") {
+			t.Errorf("Apply should name the missing placeholder, got %v", err)
+		}
+	})
+
+	t.Run("unknown value", func(t *testing.T) {
+		values := map[string]string{"version": "3.2", "codename": "owl", "vresion": "3.2"}
+		if _, err := s.Apply(ApplyRequest{Name: "release", Values: values, Start: start, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("Apply should fail for a value without a placeholder")
+		}
+	})
+
+	t.Run("another user", func(t *testing.T) {
+		if _, err := s.Apply(ApplyRequest{Name: "release", AuthenticatedUserID: 4}); err == nil {
+			t.Errorf("Apply should not find the templates of another user")
+		}
+	})
+
+	if len(tasks.data) != 0 {
+		t.Fatalf("failed applies shouldn't create tasks, got %v", tasks.data)
+	}
+
+	values := map[string]string{"Version": "3.2", "codename": "owl"}
+	applied, err := s.Apply(ApplyRequest{Name: "release", Values: values, Start: start, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if len(applied.Tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %v", applied.Tasks)
+	}
+
+	first, second := applied.Tasks[0], applied.Tasks[1]
+	if first.Title != "Release 3.2" || first.DueDate != models.NewDueDate(2026, 11, 9) ||
+		first.CategoryID != 2 || first.Priority != models.HighPriority || !reflect.DeepEqual(first.Tags, []string{"#release-3.2"}) {
+		t.Errorf("unexpected first task %+v", first)
+	}
+
+	due := models.NewDueDateTime(time.Date(2026, 11, 7, 9, 0, 0, 0, start.Location()))
+	if second.Title != "Write notes for 3.2" || second.ParentID != first.ID || !second.DueDate.Time.Equal(due.Time) ||
+		len(second.Checklist) != 1 || second.Checklist[0].Text != "mention owl" {
+		t.Errorf("unexpected second task %+v", second)
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		tasks.failTitle = "Announce"
+		s := NewService(repo, task.NewService(tasks, tasks), tasks)
+
+		if _, err := s.Apply(ApplyRequest{Name: "release", Values: values, Start: start, AuthenticatedUserID: 3}); err == nil {
+			t.Fatalf("Apply should fail when a task can't be created")
+		}
+
+		if len(tasks.data) != 3 {
+			t.Errorf("a failed apply should leave no tasks behind, got %v", tasks.data)
+		}
+	})
+}
+
+func TestApplyTemplateWorkflow(t *testing.T) {
+	repo := mockRepository{data: map[int]models.Template{}}
+	tasks := mockTaskRepository{data: map[int]models.Task{}}
+	s := NewService(repo, task.NewService(tasks, tasks), tasks)
+
+	if _, err := s.Save(SaveRequest{Name: "release", Tasks: release, AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	values := map[string]string{"version": "3.2", "codename": "owl"}
+	workflow := models.Workflow{Statuses: []models.WorkflowStatus{{Name: "inbox", WIPLimit: 2}, {Name: "done", Done: true}}}
+
+	if _, err := s.Apply(ApplyRequest{Name: "release", Values: values, Workflow: workflow, AuthenticatedUserID: 3}); err == nil {
+		t.Fatalf("Apply should fail when the tasks go past the WIP limit of the initial status")
+	}
+	if len(tasks.data) != 0 {
+		t.Errorf("a failed apply should leave no tasks behind, got %v", tasks.data)
+	}
+
+	workflow.Statuses[0].WIPLimit = 3
+	applied, err := s.Apply(ApplyRequest{Name: "release", Values: values, Workflow: workflow, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	for _, created := range applied.Tasks {
+		if created.Status != "inbox" {
+			t.Errorf("tasks should start in the initial status of the workflow, got %q", created.Status)
+		}
+	}
+}
@@ -0,0 +1,545 @@
+package trash
+
+import (
+	"fmt"
+	"time"
+	"todo-cli-refactor/models"
+	category2 "todo-cli-refactor/services/category"
+)
+
+type TaskRepository interface {
+	ListTasks() ([]models.Task, error)
+	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+	DeleteTask(id int) error
+}
+
+type CategoryRepository interface {
+	ListCategories() ([]models.Category, error)
+	ListUserCategories(userID int) ([]models.Category, error)
+	UpdateCategory(c models.Category) (models.Category, error)
+	DeleteCategory(id int) error
+}
+
+// AttachmentRepository and CommentRepository hold what is kept next to a
+// task, which goes away with the task when it's purged.
+type AttachmentRepository interface {
+	ListAttachments() ([]models.Attachment, error)
+	DeleteAttachment(id int) error
+}
+
+type CommentRepository interface {
+	ListComments() ([]models.Comment, error)
+	DeleteComment(id int) error
+}
+
+// Service moves tasks and categories to the trash and back. Items deleted
+// together share their deletion timestamp, which is how restoring one of
+// them brings back the rest.
+type Service struct {
+	tasks       TaskRepository
+	categories  CategoryRepository
+	attachments AttachmentRepository
+	comments    CommentRepository
+	// retention is how long items stay in the trash before they're purged.
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewService(tasks TaskRepository, categories CategoryRepository, attachments AttachmentRepository,
+	comments CommentRepository, retention time.Duration) Service {
+	return Service{
+		tasks:       tasks,
+		categories:  categories,
+		attachments: attachments,
+		comments:    comments,
+		retention:   retention,
+		now:         time.Now,
+	}
+}
+
+type DeleteTaskRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+}
+
+type DeleteTaskResponse struct {
+	// Tasks are the trashed task followed by its subtasks.
+	Tasks []models.Task
+}
+
+// DeleteTask moves a task and its subtasks to the trash.
+func (s Service) DeleteTask(req DeleteTaskRequest) (DeleteTaskResponse, error) {
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return DeleteTaskResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID, false)
+	if fErr != nil {
+		return DeleteTaskResponse{}, fErr
+	}
+
+	if oErr := s.checkAdmin(task, req.AuthenticatedUserID, "deleted"); oErr != nil {
+		return DeleteTaskResponse{}, oErr
+	}
+
+	trashed, tErr := s.trashTasks(append([]models.Task{task}, subtasks(tasks, task.ID)...), s.stamp())
+	if tErr != nil {
+		return DeleteTaskResponse{}, tErr
+	}
+
+	return DeleteTaskResponse{Tasks: trashed}, nil
+}
+
+type DeleteCategoryRequest struct {
+	CategoryID          int
+	AuthenticatedUserID int
+}
+
+type DeleteCategoryResponse struct {
+	Category models.Category
+	// Subcategories are the categories nested in it, which go to the trash
+	// with it.
+	Subcategories []models.Category
+	// Tasks are the tasks of those categories, which go to the trash too.
+	Tasks []models.Task
+}
+
+// DeleteCategory moves a category to the trash together with the categories
+// nested in it, their tasks and the subtasks of those.
+func (s Service) DeleteCategory(req DeleteCategoryRequest) (DeleteCategoryResponse, error) {
+
+	categories, lErr := s.categories.ListUserCategories(req.AuthenticatedUserID)
+	if lErr != nil {
+		return DeleteCategoryResponse{}, fmt.Errorf("can't list user categories: %v", lErr)
+	}
+
+	category, fErr := findCategory(categories, req.CategoryID, false)
+	if fErr != nil {
+		return DeleteCategoryResponse{}, fErr
+	}
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return DeleteCategoryResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	// subcategories trashed before keep their own deletion time
+	var subtree []models.Category
+	inSubtree := map[int]bool{}
+	for _, c := range category2.Subtree(categories, category.ID) {
+		if c.DeletedAt == nil {
+			subtree = append(subtree, c)
+			inSubtree[c.ID] = true
+		}
+	}
+
+	var inCategory []models.Task
+	seen := map[int]bool{}
+	for _, task := range tasks {
+		if task.DeletedAt != nil || !inSubtree[task.CategoryID] {
+			continue
+		}
+
+		for _, t := range append([]models.Task{task}, subtasks(tasks, task.ID)...) {
+			if !seen[t.ID] {
+				seen[t.ID] = true
+				inCategory = append(inCategory, t)
+			}
+		}
+	}
+
+	stamp := s.stamp()
+
+	for i := range subtree {
+		subtree[i].DeletedAt = &stamp
+		if _, uErr := s.categories.UpdateCategory(subtree[i]); uErr != nil {
+			return DeleteCategoryResponse{}, fmt.Errorf("can't update category: %v", uErr)
+		}
+	}
+
+	trashed, tErr := s.trashTasks(inCategory, stamp)
+	if tErr != nil {
+		return DeleteCategoryResponse{}, tErr
+	}
+
+	return DeleteCategoryResponse{Category: subtree[0], Subcategories: subtree[1:], Tasks: trashed}, nil
+}
+
+type RestoreTaskRequest struct {
+	TaskID              int
+	AuthenticatedUserID int
+}
+
+type RestoreTaskResponse struct {
+	// Tasks are the restored task and the subtasks trashed along with it.
+	Tasks []models.Task
+	// Category is the category of the task when it had to be restored too,
+	// so the task doesn't point into the trash.
+	Category *models.Category
+}
+
+func (s Service) RestoreTask(req RestoreTaskRequest) (RestoreTaskResponse, error) {
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return RestoreTaskResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	task, fErr := findTask(tasks, req.TaskID, true)
+	if fErr != nil {
+		return RestoreTaskResponse{}, fErr
+	}
+
+	if oErr := s.checkAdmin(task, req.AuthenticatedUserID, "restored"); oErr != nil {
+		return RestoreTaskResponse{}, oErr
+	}
+
+	var together []models.Task
+	for _, subtask := range subtasks(tasks, task.ID) {
+		if subtask.DeletedAt != nil && subtask.DeletedAt.Equal(*task.DeletedAt) {
+			together = append(together, subtask)
+		}
+	}
+
+	restored, rErr := s.restoreTasks(append([]models.Task{task}, together...))
+	if rErr != nil {
+		return RestoreTaskResponse{}, rErr
+	}
+
+	response := RestoreTaskResponse{Tasks: restored}
+
+	categories, lErr := s.categories.ListUserCategories(req.AuthenticatedUserID)
+	if lErr != nil {
+		return RestoreTaskResponse{}, fmt.Errorf("can't list user categories: %v", lErr)
+	}
+
+	if category, fErr := findCategory(categories, task.CategoryID, true); fErr == nil {
+		category.DeletedAt = nil
+		if _, uErr := s.categories.UpdateCategory(category); uErr != nil {
+			return RestoreTaskResponse{}, fmt.Errorf("can't update category: %v", uErr)
+		}
+		response.Category = &category
+	}
+
+	if rErr := s.restoreAncestors(categories, task.CategoryID); rErr != nil {
+		return RestoreTaskResponse{}, rErr
+	}
+
+	return response, nil
+}
+
+type RestoreCategoryRequest struct {
+	CategoryID          int
+	AuthenticatedUserID int
+}
+
+type RestoreCategoryResponse struct {
+	Category models.Category
+	// Subcategories are the categories trashed along with the category.
+	Subcategories []models.Category
+	// Tasks are the tasks trashed along with the category.
+	Tasks []models.Task
+}
+
+// RestoreCategory brings back a category with the subcategories and tasks
+// trashed along with it. The categories it's nested in are restored as well
+// should they be in the trash.
+func (s Service) RestoreCategory(req RestoreCategoryRequest) (RestoreCategoryResponse, error) {
+
+	categories, lErr := s.categories.ListUserCategories(req.AuthenticatedUserID)
+	if lErr != nil {
+		return RestoreCategoryResponse{}, fmt.Errorf("can't list user categories: %v", lErr)
+	}
+
+	category, fErr := findCategory(categories, req.CategoryID, true)
+	if fErr != nil {
+		return RestoreCategoryResponse{}, fErr
+	}
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return RestoreCategoryResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	var together []models.Task
+	for _, task := range tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Equal(*category.DeletedAt) {
+			together = append(together, task)
+		}
+	}
+
+	var subtree []models.Category
+	for _, c := range category2.Subtree(categories, category.ID) {
+		if c.DeletedAt != nil && c.DeletedAt.Equal(*category.DeletedAt) {
+			c.DeletedAt = nil
+			if _, uErr := s.categories.UpdateCategory(c); uErr != nil {
+				return RestoreCategoryResponse{}, fmt.Errorf("can't update category: %v", uErr)
+			}
+			subtree = append(subtree, c)
+		}
+	}
+
+	if rErr := s.restoreAncestors(categories, category.ID); rErr != nil {
+		return RestoreCategoryResponse{}, rErr
+	}
+
+	restored, rErr := s.restoreTasks(together)
+	if rErr != nil {
+		return RestoreCategoryResponse{}, rErr
+	}
+
+	return RestoreCategoryResponse{Category: subtree[0], Subcategories: subtree[1:], Tasks: restored}, nil
+}
+
+type ListRequest struct {
+	AuthenticatedUserID int
+}
+
+type ListResponse struct {
+	Tasks      []models.Task
+	Categories []models.Category
+	// Retention is how long items stay in the trash before they're purged.
+	Retention time.Duration
+}
+
+// List returns the contents of the trash of a user.
+func (s Service) List(req ListRequest) (ListResponse, error) {
+
+	tasks, lErr := s.tasks.ListUserTasks(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	categories, lErr := s.categories.ListUserCategories(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list user categories: %v", lErr)
+	}
+
+	response := ListResponse{Retention: s.retention}
+	for _, task := range tasks {
+		// tasks assigned to the user are in their owner's trash
+		if task.DeletedAt != nil && task.UserID == req.AuthenticatedUserID {
+			response.Tasks = append(response.Tasks, task)
+		}
+	}
+	for _, category := range categories {
+		if category.DeletedAt != nil {
+			response.Categories = append(response.Categories, category)
+		}
+	}
+
+	return response, nil
+}
+
+type PurgeResponse struct {
+	Tasks      int
+	Categories int
+}
+
+// Purge deletes the items of all users that have been in the trash for
+// longer than the retention period. The attachments and comments of purged
+// tasks are deleted with them, the blobs of the attachments are left to be
+// collected.
+func (s Service) Purge() (PurgeResponse, error) {
+
+	deadline := s.now().Add(-s.retention)
+
+	tasks, lErr := s.tasks.ListTasks()
+	if lErr != nil {
+		return PurgeResponse{}, fmt.Errorf("can't list tasks: %v", lErr)
+	}
+
+	var response PurgeResponse
+	for _, task := range tasks {
+		if task.DeletedAt == nil || !task.DeletedAt.Before(deadline) {
+			continue
+		}
+
+		if pErr := s.purgeTask(task.ID); pErr != nil {
+			return response, pErr
+		}
+		response.Tasks++
+	}
+
+	categories, lErr := s.categories.ListCategories()
+	if lErr != nil {
+		return response, fmt.Errorf("can't list categories: %v", lErr)
+	}
+
+	for _, category := range categories {
+		if category.DeletedAt == nil || !category.DeletedAt.Before(deadline) {
+			continue
+		}
+
+		if dErr := s.categories.DeleteCategory(category.ID); dErr != nil {
+			return response, fmt.Errorf("can't delete category: %v", dErr)
+		}
+		response.Categories++
+	}
+
+	return response, nil
+}
+
+// purgeTask deletes a task after its attachments and comments, so a failure
+// leaves the task in the trash to be purged again.
+func (s Service) purgeTask(taskID int) error {
+	attachments, lErr := s.attachments.ListAttachments()
+	if lErr != nil {
+		return fmt.Errorf("can't list attachments: %v", lErr)
+	}
+
+	for _, attachment := range attachments {
+		if attachment.TaskID != taskID {
+			continue
+		}
+
+		if dErr := s.attachments.DeleteAttachment(attachment.ID); dErr != nil {
+			return fmt.Errorf("can't delete attachment: %v", dErr)
+		}
+	}
+
+	comments, lErr := s.comments.ListComments()
+	if lErr != nil {
+		return fmt.Errorf("can't list comments: %v", lErr)
+	}
+
+	for _, comment := range comments {
+		if comment.TaskID != taskID {
+			continue
+		}
+
+		if dErr := s.comments.DeleteComment(comment.ID); dErr != nil {
+			return fmt.Errorf("can't delete comment: %v", dErr)
+		}
+	}
+
+	if dErr := s.tasks.DeleteTask(taskID); dErr != nil {
+		return fmt.Errorf("can't delete task: %v", dErr)
+	}
+
+	return nil
+}
+
+// checkAdmin makes sure a user owns a task or is an admin of its category
+// before the task is deleted or restored.
+func (s Service) checkAdmin(task models.Task, userID int, verb string) error {
+	if task.UserID == userID {
+		return nil
+	}
+
+	categories, err := s.categories.ListCategories()
+	if err != nil {
+		return fmt.Errorf("can't list categories: %v", err)
+	}
+
+	if !category2.Role(categories, task.CategoryID, userID).Allows(models.AdminRole) {
+		return fmt.Errorf("task %d can only be %s by its owner or an admin", task.ID, verb)
+	}
+
+	return nil
+}
+
+func (s Service) stamp() time.Time {
+	return s.now().UTC().Truncate(time.Second)
+}
+
+func (s Service) trashTasks(tasks []models.Task, stamp time.Time) ([]models.Task, error) {
+	var trashed []models.Task
+	for _, task := range tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+
+		task.DeletedAt = &stamp
+		if _, uErr := s.tasks.UpdateTask(task); uErr != nil {
+			return nil, fmt.Errorf("can't update task: %v", uErr)
+		}
+		trashed = append(trashed, task)
+	}
+
+	return trashed, nil
+}
+
+// restoreAncestors brings back the trashed categories a category is nested
+// in so it can be reached again. Their tasks stay in the trash.
+func (s Service) restoreAncestors(categories []models.Category, categoryID int) error {
+	for _, ancestor := range category2.Ancestors(categories, categoryID) {
+		if ancestor.DeletedAt == nil {
+			continue
+		}
+
+		ancestor.DeletedAt = nil
+		if _, uErr := s.categories.UpdateCategory(ancestor); uErr != nil {
+			return fmt.Errorf("can't update category: %v", uErr)
+		}
+	}
+
+	return nil
+}
+
+func (s Service) restoreTasks(tasks []models.Task) ([]models.Task, error) {
+	for i := range tasks {
+		tasks[i].DeletedAt = nil
+		if _, uErr := s.tasks.UpdateTask(tasks[i]); uErr != nil {
+			return nil, fmt.Errorf("can't update task: %v", uErr)
+		}
+	}
+
+	return tasks, nil
+}
+
+// subtasks returns every task below taskID in the tree.
+func subtasks(tasks []models.Task, taskID int) []models.Task {
+	var found []models.Task
+
+	visited := map[int]bool{taskID: true}
+	queue := []int{taskID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, task := range tasks {
+			if task.ParentID != id || visited[task.ID] {
+				continue
+			}
+			visited[task.ID] = true
+			found = append(found, task)
+			queue = append(queue, task.ID)
+		}
+	}
+
+	return found
+}
+
+// findTask looks a task up among the live tasks, or among the trashed ones
+// when trashed is set.
+func findTask(tasks []models.Task, taskID int, trashed bool) (models.Task, error) {
+	for _, task := range tasks {
+		if task.ID == taskID && (task.DeletedAt != nil) == trashed {
+			return task, nil
+		}
+	}
+
+	if trashed {
+		return models.Task{}, fmt.Errorf("task %d not found in the trash", taskID)
+	}
+
+	return models.Task{}, fmt.Errorf("task %d not found", taskID)
+}
+
+func findCategory(categories []models.Category, categoryID int, trashed bool) (models.Category, error) {
+	for _, category := range categories {
+		if category.ID == categoryID && (category.DeletedAt != nil) == trashed {
+			return category, nil
+		}
+	}
+
+	if trashed {
+		return models.Category{}, fmt.Errorf("category %d not found in the trash", categoryID)
+	}
+
+	return models.Category{}, fmt.Errorf("category %d not found", categoryID)
+}
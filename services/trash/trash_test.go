@@ -0,0 +1,429 @@
+package trash
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+type mockTaskRepository struct {
+	data map[int]models.Task
+}
+
+func (m mockTaskRepository) ListTasks() ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, task := range m.data {
+		tasks = append(tasks, task)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	tasks, _ := m.ListTasks()
+
+	var userTasks []models.Task
+	for _, task := range tasks {
+		if task.UserID == userID || task.AssigneeID == userID {
+			userTasks = append(userTasks, task)
+		}
+	}
+
+	return userTasks, nil
+}
+
+func (m mockTaskRepository) UpdateTask(task models.Task) (models.Task, error) {
+	if _, ok := m.data[task.ID]; !ok {
+		return models.Task{}, fmt.Errorf("task %d not found", task.ID)
+	}
+
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func (m mockTaskRepository) DeleteTask(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockCategoryRepository struct {
+	data map[int]models.Category
+}
+
+func (m mockCategoryRepository) ListCategories() ([]models.Category, error) {
+	var categories []models.Category
+
+	for _, category := range m.data {
+		categories = append(categories, category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+
+	return categories, nil
+}
+
+func (m mockCategoryRepository) ListUserCategories(userID int) ([]models.Category, error) {
+	categories, _ := m.ListCategories()
+
+	var userCategories []models.Category
+	for _, category := range categories {
+		if category.UserID == userID {
+			userCategories = append(userCategories, category)
+		}
+	}
+
+	return userCategories, nil
+}
+
+func (m mockCategoryRepository) UpdateCategory(category models.Category) (models.Category, error) {
+	if _, ok := m.data[category.ID]; !ok {
+		return models.Category{}, fmt.Errorf("category %d not found", category.ID)
+	}
+
+	m.data[category.ID] = category
+
+	return category, nil
+}
+
+func (m mockCategoryRepository) DeleteCategory(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockAttachmentRepository struct {
+	data []models.Attachment
+}
+
+func (m *mockAttachmentRepository) ListAttachments() ([]models.Attachment, error) {
+	return m.data, nil
+}
+
+func (m *mockAttachmentRepository) DeleteAttachment(id int) error {
+	var kept []models.Attachment
+	for _, attachment := range m.data {
+		if attachment.ID != id {
+			kept = append(kept, attachment)
+		}
+	}
+	m.data = kept
+
+	return nil
+}
+
+type mockCommentRepository struct {
+	data []models.Comment
+}
+
+func (m *mockCommentRepository) ListComments() ([]models.Comment, error) {
+	return m.data, nil
+}
+
+func (m *mockCommentRepository) DeleteComment(id int) error {
+	var kept []models.Comment
+	for _, comment := range m.data {
+		if comment.ID != id {
+			kept = append(kept, comment)
+		}
+	}
+	m.data = kept
+
+	return nil
+}
+
+func TestTrash(t *testing.T) {
+	mt := mockTaskRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Release 3", CategoryID: 1, UserID: 3},
+			2: {ID: 2, Title: "Write changelog", CategoryID: 1, ParentID: 1, UserID: 3},
+			3: {ID: 3, Title: "Buy groceries", CategoryID: 2, UserID: 3},
+			4: {ID: 4, Title: "Clean the house", CategoryID: 2, UserID: 3},
+			5: {ID: 5, Title: "Read a book", CategoryID: 3, UserID: 4},
+		},
+	}
+	mc := mockCategoryRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Work", UserID: 3},
+			2: {ID: 2, Title: "Home", UserID: 3},
+			3: {ID: 3, Title: "Hobby", UserID: 4},
+		},
+	}
+
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	s := NewService(mt, mc, &mockAttachmentRepository{}, &mockCommentRepository{}, 30*24*time.Hour)
+	s.now = func() time.Time { return now }
+
+	t.Run("delete task", func(t *testing.T) {
+		res, err := s.DeleteTask(DeleteTaskRequest{TaskID: 1, AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+
+		if len(res.Tasks) != 2 || mt.data[2].DeletedAt == nil {
+			t.Errorf("the subtask should go to the trash with its parent: got %v", res.Tasks)
+		}
+
+		if _, err := s.DeleteTask(DeleteTaskRequest{TaskID: 1, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("DeleteTask should fail for a trashed task")
+		}
+
+		if _, err := s.DeleteTask(DeleteTaskRequest{TaskID: 5, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("DeleteTask should fail for a task of another user")
+		}
+	})
+
+	t.Run("delete category", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		if _, err := s.DeleteTask(DeleteTaskRequest{TaskID: 4, AuthenticatedUserID: 3}); err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+
+		now = now.Add(time.Hour)
+
+		res, err := s.DeleteCategory(DeleteCategoryRequest{CategoryID: 2, AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("DeleteCategory failed: %v", err)
+		}
+
+		if len(res.Tasks) != 1 || res.Tasks[0].ID != 3 {
+			t.Errorf("only the live task of the category should be trashed with it: got %v", res.Tasks)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		res, err := s.List(ListRequest{AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+
+		if len(res.Tasks) != 4 || len(res.Categories) != 1 {
+			t.Errorf("unexpected trash: %v", res)
+		}
+	})
+
+	t.Run("restore category", func(t *testing.T) {
+		res, err := s.RestoreCategory(RestoreCategoryRequest{CategoryID: 2, AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("RestoreCategory failed: %v", err)
+		}
+
+		if len(res.Tasks) != 1 || mt.data[3].DeletedAt != nil || mt.data[4].DeletedAt == nil {
+			t.Errorf("only the task trashed along with the category should be restored: got %v", res.Tasks)
+		}
+	})
+
+	t.Run("restore task", func(t *testing.T) {
+		if _, err := s.DeleteCategory(DeleteCategoryRequest{CategoryID: 2, AuthenticatedUserID: 3}); err != nil {
+			t.Fatalf("DeleteCategory failed: %v", err)
+		}
+
+		res, err := s.RestoreTask(RestoreTaskRequest{TaskID: 4, AuthenticatedUserID: 3})
+		if err != nil {
+			t.Fatalf("RestoreTask failed: %v", err)
+		}
+
+		if res.Category == nil || mc.data[2].DeletedAt != nil || mt.data[4].CategoryID != 2 {
+			t.Errorf("the category of a restored task should be restored too: got %v", res)
+		}
+
+		if res, err := s.RestoreTask(RestoreTaskRequest{TaskID: 1, AuthenticatedUserID: 3}); err != nil || len(res.Tasks) != 2 {
+			t.Errorf("the subtask should be restored with its parent: got %v, %v", res, err)
+		}
+
+		if _, err := s.RestoreTask(RestoreTaskRequest{TaskID: 1, AuthenticatedUserID: 3}); err == nil {
+			t.Errorf("RestoreTask should fail for a task that isn't in the trash")
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		if _, err := s.DeleteTask(DeleteTaskRequest{TaskID: 2, AuthenticatedUserID: 3}); err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+		deleted := now.Add(-31 * 24 * time.Hour)
+		category := mc.data[3]
+		category.DeletedAt = &deleted
+		mc.data[3] = category
+
+		res, err := s.Purge()
+		if err != nil {
+			t.Fatalf("Purge failed: %v", err)
+		}
+
+		if res.Tasks != 0 || res.Categories != 1 {
+			t.Errorf("only items past the retention should be purged: got %+v", res)
+		}
+
+		now = now.Add(31 * 24 * time.Hour)
+
+		// task 3 is still in the trash from when its category was deleted again
+		if res, err := s.Purge(); err != nil || res.Tasks != 2 {
+			t.Errorf("the tasks should be purged after the retention: got %+v, %v", res, err)
+		}
+
+		if _, ok := mt.data[2]; ok {
+			t.Errorf("the purged task should be gone")
+		}
+	})
+}
+
+func TestTrashSubtree(t *testing.T) {
+	mt := mockTaskRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Invoice", CategoryID: 2, UserID: 3},
+			2: {ID: 2, Title: "Call Acme", CategoryID: 3, UserID: 3},
+			3: {ID: 3, Title: "Plan the quarter", CategoryID: 1, UserID: 3},
+		},
+	}
+	mc := mockCategoryRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Work", UserID: 3},
+			2: {ID: 2, Title: "Clients", ParentID: 1, UserID: 3},
+			3: {ID: 3, Title: "Acme", ParentID: 2, UserID: 3},
+		},
+	}
+
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	s := NewService(mt, mc, &mockAttachmentRepository{}, &mockCommentRepository{}, 30*24*time.Hour)
+	s.now = func() time.Time { return now }
+
+	res, err := s.DeleteCategory(DeleteCategoryRequest{CategoryID: 2, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("DeleteCategory failed: %v", err)
+	}
+
+	if len(res.Subcategories) != 1 || mc.data[3].DeletedAt == nil || len(res.Tasks) != 2 || mt.data[3].DeletedAt != nil {
+		t.Errorf("the subtree and only its tasks should be trashed: got %v", res)
+	}
+
+	now = now.Add(time.Hour)
+
+	if _, err := s.DeleteCategory(DeleteCategoryRequest{CategoryID: 1, AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("DeleteCategory failed: %v", err)
+	}
+
+	// restoring the nested category brings back the ones it's nested in, but
+	// not their tasks
+	restored, err := s.RestoreCategory(RestoreCategoryRequest{CategoryID: 2, AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("RestoreCategory failed: %v", err)
+	}
+
+	if len(restored.Subcategories) != 1 || len(restored.Tasks) != 2 {
+		t.Errorf("the subtree should be restored with its tasks: got %v", restored)
+	}
+
+	if mc.data[1].DeletedAt != nil || mt.data[3].DeletedAt == nil {
+		t.Errorf("the parent category should be restored without its tasks: got %v, %v", mc.data[1], mt.data[3])
+	}
+}
+
+func TestTrashAssignedTask(t *testing.T) {
+	mt := mockTaskRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Review the budget", UserID: 3, AssigneeID: 4},
+		},
+	}
+	mc := mockCategoryRepository{data: map[int]models.Category{}}
+
+	s := NewService(mt, mc, &mockAttachmentRepository{}, &mockCommentRepository{}, 30*24*time.Hour)
+
+	if _, err := s.DeleteTask(DeleteTaskRequest{TaskID: 1, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("DeleteTask should fail for the assignee")
+	}
+
+	if _, err := s.DeleteTask(DeleteTaskRequest{TaskID: 1, AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+
+	if res, err := s.List(ListRequest{AuthenticatedUserID: 4}); err != nil || len(res.Tasks) != 0 {
+		t.Errorf("the assignee's trash should be empty: got %v, %v", res.Tasks, err)
+	}
+
+	if _, err := s.RestoreTask(RestoreTaskRequest{TaskID: 1, AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("RestoreTask should fail for the assignee")
+	}
+}
+
+func TestTrashSharedTask(t *testing.T) {
+	mt := mockTaskRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Book the venue", CategoryID: 1, UserID: 3},
+		},
+	}
+	mc := mockCategoryRepository{
+		data: map[int]models.Category{
+			1: {ID: 1, Title: "Party", UserID: 3, Shares: []models.Share{
+				{UserID: 4, Role: models.EditorRole},
+				{UserID: 5, Role: models.AdminRole},
+			}},
+		},
+	}
+
+	s := NewService(mt, mc, &mockAttachmentRepository{}, &mockCommentRepository{}, 30*24*time.Hour)
+
+	// the mock lists owned tasks only, so the role check is run on its own
+	if err := s.checkAdmin(mt.data[1], 4, "deleted"); err == nil {
+		t.Errorf("an editor shouldn't delete the task")
+	}
+	if err := s.checkAdmin(mt.data[1], 5, "deleted"); err != nil {
+		t.Errorf("an admin should delete the task: %v", err)
+	}
+}
+
+func TestPurgeTaskData(t *testing.T) {
+	deleted := time.Date(2026, 8, 1, 8, 0, 0, 0, time.UTC)
+
+	mt := mockTaskRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Buy groceries", UserID: 3},
+			2: {ID: 2, Title: "Scan the passport", UserID: 3, DeletedAt: &deleted},
+		},
+	}
+	ma := &mockAttachmentRepository{
+		data: []models.Attachment{
+			{ID: 1, TaskID: 1, UserID: 3, Name: "list.txt"},
+			{ID: 2, TaskID: 2, UserID: 3, Name: "passport.pdf"},
+		},
+	}
+	mcm := &mockCommentRepository{
+		data: []models.Comment{
+			{ID: 1, TaskID: 2, AuthorID: 3, Text: "the number is on page 2"},
+			{ID: 2, TaskID: 1, AuthorID: 3, Text: "don't forget the milk"},
+		},
+	}
+
+	s := NewService(mt, mockCategoryRepository{data: map[int]models.Category{}}, ma, mcm, 30*24*time.Hour)
+	s.now = func() time.Time { return time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC) }
+
+	if res, err := s.Purge(); err != nil || res.Tasks != 1 {
+		t.Fatalf("the trashed task should be purged: got %+v, %v", res, err)
+	}
+
+	// another user's new task may get the ID of the purged one
+	mt.data[2] = models.Task{ID: 2, Title: "Plan the trip", UserID: 4}
+
+	for _, attachment := range ma.data {
+		if attachment.TaskID == 2 {
+			t.Errorf("the attachments of a purged task should be deleted: got %v", ma.data)
+		}
+	}
+	for _, comment := range mcm.data {
+		if comment.TaskID == 2 {
+			t.Errorf("the comments of a purged task should be deleted: got %v", mcm.data)
+		}
+	}
+
+	if len(ma.data) != 1 || len(mcm.data) != 1 {
+		t.Errorf("the attachments and comments of other tasks should be kept: got %v, %v", ma.data, mcm.data)
+	}
+}
@@ -2,12 +2,16 @@ package user
 
 import (
 	"fmt"
+	"sort"
+	"todo-cli-refactor/consts"
 	"todo-cli-refactor/models"
+	"todo-cli-refactor/pkg/cursor"
 )
 
 type ServiceRepository interface {
 	CreateNewUser(user models.User) (models.User, error)
 	ListUsers() ([]models.User, error)
+	UpdateUser(user models.User) (models.User, error)
 }
 
 type Service struct {
@@ -79,18 +83,126 @@ func (u Service) Login(req LoginRequest) (LoginResponse, error) {
 	return LoginResponse{User: *authenticatedUser}, nil
 }
 
-type ListUsersRequest struct{}
+// ListUsersRequest pages through users by ID when Limit is set. Cursor is
+// the NextCursor of the previous page.
+type ListUsersRequest struct {
+	Limit  int
+	Cursor string
+}
 
 type ListUsersResponse struct {
-	Users []models.User
+	Users      []models.User
+	NextCursor string
+}
+
+// userPosition is what a user listing cursor holds: the ID of the last user
+// returned.
+type userPosition struct {
+	AfterID int
 }
 
 func (u Service) ListUsers(req ListUsersRequest) (ListUsersResponse, error) {
 
+	var position userPosition
+	if req.Cursor != "" {
+		if err := cursor.Decode(req.Cursor, &position); err != nil {
+			return ListUsersResponse{}, err
+		}
+	}
+
 	users, err := u.repository.ListUsers()
 	if err != nil {
 		return ListUsersResponse{}, fmt.Errorf("can't list users: %v", err)
 	}
 
-	return ListUsersResponse{Users: users}, nil
+	if req.Limit <= 0 && req.Cursor == "" {
+		return ListUsersResponse{Users: users}, nil
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	start := sort.Search(len(users), func(i int) bool { return users[i].ID > position.AfterID })
+	users = users[start:]
+
+	response := ListUsersResponse{}
+	if req.Limit > 0 && len(users) > req.Limit {
+		users = users[:req.Limit]
+
+		response.NextCursor, err = cursor.Encode(userPosition{AfterID: users[len(users)-1].ID})
+		if err != nil {
+			return ListUsersResponse{}, err
+		}
+	}
+	response.Users = users
+
+	return response, nil
+}
+
+type FindRequest struct {
+	Email string
+}
+
+type FindResponse struct {
+	User models.User
+}
+
+// Find looks a registered user up by email, for requests naming another
+// user.
+func (u Service) Find(req FindRequest) (FindResponse, error) {
+
+	users, err := u.repository.ListUsers()
+	if err != nil {
+		return FindResponse{}, fmt.Errorf("can't list users: %v", err)
+	}
+
+	for _, user := range users {
+		if user.Email == req.Email {
+			return FindResponse{User: user}, nil
+		}
+	}
+
+	return FindResponse{}, fmt.Errorf("no user with email %q", req.Email)
+}
+
+type UpdateProfileRequest struct {
+	AuthenticatedUserID int
+	Calendar            string
+}
+
+type UpdateProfileResponse struct {
+	User models.User
+}
+
+func (u Service) UpdateProfile(req UpdateProfileRequest) (UpdateProfileResponse, error) {
+
+	if req.Calendar != consts.GregorianCalendar && req.Calendar != consts.JalaliCalendar {
+		return UpdateProfileResponse{}, fmt.Errorf("unknown calendar %q, use %s or %s",
+			req.Calendar, consts.GregorianCalendar, consts.JalaliCalendar)
+	}
+
+	users, err := u.repository.ListUsers()
+	if err != nil {
+		return UpdateProfileResponse{}, fmt.Errorf("can't list users: %v", err)
+	}
+
+	var user *models.User
+	for i := range users {
+		if users[i].ID == req.AuthenticatedUserID {
+			user = &users[i]
+			break
+		}
+	}
+
+	if user == nil {
+		return UpdateProfileResponse{}, fmt.Errorf("user %d not found", req.AuthenticatedUserID)
+	}
+
+	user.Calendar = req.Calendar
+
+	updatedUser, uErr := u.repository.UpdateUser(*user)
+	if uErr != nil {
+		return UpdateProfileResponse{}, fmt.Errorf("can't update user: %v", uErr)
+	}
+
+	return UpdateProfileResponse{User: updatedUser}, nil
 }
@@ -1,8 +1,11 @@
 package user
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 	"testing"
+	"todo-cli-refactor/consts"
 	"todo-cli-refactor/models"
 )
 
@@ -25,9 +28,21 @@ func (m mockRepository) ListUsers() ([]models.User, error) {
 		users = append(users, user)
 	}
 
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
 	return users, nil
 }
 
+func (m mockRepository) UpdateUser(user models.User) (models.User, error) {
+	if _, ok := m.data[user.ID]; !ok {
+		return models.User{}, fmt.Errorf("user %d not found", user.ID)
+	}
+
+	m.data[user.ID] = user
+
+	return user, nil
+}
+
 func TestCreate(t *testing.T) {
 	mr := mockRepository{
 		data: map[int]models.User{
@@ -139,3 +154,97 @@ func TestListUsers(t *testing.T) {
 		t.Errorf("response does not match expected data : got %v , want %v ", res.Users, expected)
 	}
 }
+
+func TestListUsersPages(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.User{
+			1: {ID: 1, Name: "Alice"},
+			2: {ID: 2, Name: "Bob"},
+			3: {ID: 3, Name: "Charlie"},
+		},
+	}
+
+	s := NewService(mr)
+
+	first, err := s.ListUsers(ListUsersRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListUsers failed : %v", err)
+	}
+	if len(first.Users) != 2 || first.Users[1].ID != 2 || first.NextCursor == "" {
+		t.Fatalf("unexpected first page : %v, %q", first.Users, first.NextCursor)
+	}
+
+	// a user signing up between pages shows up on a later one
+	mr.data[4] = models.User{ID: 4, Name: "David"}
+
+	second, err := s.ListUsers(ListUsersRequest{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("ListUsers failed : %v", err)
+	}
+	expected := []models.User{{ID: 3, Name: "Charlie"}, {ID: 4, Name: "David"}}
+	if !reflect.DeepEqual(second.Users, expected) || second.NextCursor != "" {
+		t.Errorf("unexpected last page : got %v, %q, want %v", second.Users, second.NextCursor, expected)
+	}
+
+	if _, err := s.ListUsers(ListUsersRequest{Limit: 2, Cursor: "%%"}); err == nil {
+		t.Errorf("ListUsers should fail for an invalid cursor")
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.User{
+			1: {ID: 1, Name: "Alice", Email: "alice@example.com", Password: "123456"},
+			2: {ID: 2, Name: "Bob", Email: "bob@example.com", Password: "654321"},
+		},
+	}
+
+	s := NewService(mr)
+
+	t.Run("valid calendar", func(t *testing.T) {
+
+		res, err := s.UpdateProfile(UpdateProfileRequest{AuthenticatedUserID: 2, Calendar: consts.JalaliCalendar})
+		if err != nil {
+			t.Errorf("UpdateProfile failed : %v", err)
+		}
+
+		expected := models.User{ID: 2, Name: "Bob", Email: "bob@example.com", Password: "654321", Calendar: consts.JalaliCalendar}
+		if !reflect.DeepEqual(res.User, expected) {
+			t.Errorf("response does not match expected data : got %v , want %v ", res.User, expected)
+		}
+		if !reflect.DeepEqual(mr.data[2], expected) {
+			t.Errorf("stored user does not match expected data : got %v , want %v ", mr.data[2], expected)
+		}
+	})
+
+	t.Run("unknown calendar", func(t *testing.T) {
+
+		_, err := s.UpdateProfile(UpdateProfileRequest{AuthenticatedUserID: 2, Calendar: "lunar"})
+		if err == nil {
+			t.Errorf("UpdateProfile should fail with an unknown calendar")
+		}
+	})
+}
+
+func TestFind(t *testing.T) {
+	mr := mockRepository{
+		data: map[int]models.User{
+			1: {ID: 1, Name: "Alice", Email: "alice@example.com", Password: "123456"},
+			2: {ID: 2, Name: "Bob", Email: "bob@example.com", Password: "654321"},
+		},
+	}
+
+	s := NewService(mr)
+
+	res, err := s.Find(FindRequest{Email: "bob@example.com"})
+	if err != nil {
+		t.Fatalf("Find failed : %v", err)
+	}
+	if res.User.ID != 2 {
+		t.Errorf("expected user 2, got %v", res.User)
+	}
+
+	if _, err := s.Find(FindRequest{Email: "carol@example.com"}); err == nil {
+		t.Errorf("Find should fail for an unknown email")
+	}
+}
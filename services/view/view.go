@@ -0,0 +1,246 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"todo-cli-refactor/models"
+	"todo-cli-refactor/services/task"
+	"unicode"
+)
+
+// Builtins are the views every user has. They can't be changed or deleted,
+// and saved views can't take their names.
+var Builtins = []models.View{
+	{Name: "today", Query: "status:open due<=today", Sort: task.DueDateSort},
+	{Name: "overdue", Query: "status:overdue", Sort: task.DueDateSort},
+	{Name: "next-7-days", Query: "status:open due>=today due<=+7d", Sort: task.DueDateSort, Group: models.DueGroup},
+	{Name: "no-category", Query: "status:open category:none"},
+}
+
+var sorts = []string{"", task.SmartSort, task.PrioritySort, task.DueDateSort, task.CreatedSort, task.TitleSort}
+
+var groups = []string{models.NoGroup, models.CategoryGroup, models.PriorityGroup, models.DueGroup}
+
+type ServiceRepository interface {
+	CreateNewView(v models.View) (models.View, error)
+	ListUserViews(userID int) ([]models.View, error)
+	UpdateView(v models.View) (models.View, error)
+	DeleteView(id int) error
+}
+
+type Service struct {
+	repository ServiceRepository
+}
+
+func NewService(repo ServiceRepository) Service {
+	return Service{
+		repository: repo,
+	}
+}
+
+type SaveRequest struct {
+	Name  string
+	Query string
+	Sort  string
+	Group string
+	// Categories and Workflow are the user's, the query is checked against
+	// them the way listing the view runs it.
+	Categories          []models.Category
+	Workflow            models.Workflow
+	AuthenticatedUserID int
+}
+
+type SaveResponse struct {
+	View models.View
+}
+
+// Save stores a view of a user, replacing the one with the same name.
+func (s Service) Save(req SaveRequest) (SaveResponse, error) {
+	name, nErr := normalizeName(req.Name)
+	if nErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save view: %v", nErr)
+	}
+
+	if _, ok := builtin(name); ok {
+		return SaveResponse{}, fmt.Errorf("can't save view: %q is a built-in view", name)
+	}
+
+	if qErr := task.ValidateQuery(req.Query, req.Categories, req.Workflow); qErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save view: %v", qErr)
+	}
+
+	if !contains(sorts, req.Sort) {
+		return SaveResponse{}, fmt.Errorf("can't save view: unknown sort %q, use one of %s", req.Sort, strings.Join(sorts[1:], ", "))
+	}
+
+	if gErr := ValidateGroup(req.Group); gErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save view: %v", gErr)
+	}
+
+	view := models.View{
+		UserID: req.AuthenticatedUserID,
+		Name:   name,
+		Query:  strings.TrimSpace(req.Query),
+		Sort:   req.Sort,
+		Group:  req.Group,
+	}
+
+	existing, found, fErr := s.find(req.AuthenticatedUserID, name)
+	if fErr != nil {
+		return SaveResponse{}, fErr
+	}
+
+	var saved models.View
+	var sErr error
+	if found {
+		view.ID = existing.ID
+		saved, sErr = s.repository.UpdateView(view)
+	} else {
+		saved, sErr = s.repository.CreateNewView(view)
+	}
+	if sErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save view: %v", sErr)
+	}
+
+	return SaveResponse{View: saved}, nil
+}
+
+type ListRequest struct {
+	AuthenticatedUserID int
+}
+
+type ListResponse struct {
+	// Views are the built-in views followed by the ones the user saved.
+	// Built-in views have no ID.
+	Views []models.View
+}
+
+func (s Service) List(req ListRequest) (ListResponse, error) {
+	views, lErr := s.repository.ListUserViews(req.AuthenticatedUserID)
+	if lErr != nil {
+		return ListResponse{}, fmt.Errorf("can't list user views: %v", lErr)
+	}
+
+	return ListResponse{Views: append(append([]models.View{}, Builtins...), views...)}, nil
+}
+
+type GetRequest struct {
+	Name                string
+	AuthenticatedUserID int
+}
+
+type GetResponse struct {
+	View models.View
+}
+
+// Get returns a view of a user by its name.
+func (s Service) Get(req GetRequest) (GetResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+
+	if view, ok := builtin(name); ok {
+		return GetResponse{View: view}, nil
+	}
+
+	view, found, fErr := s.find(req.AuthenticatedUserID, name)
+	if fErr != nil {
+		return GetResponse{}, fErr
+	}
+	if !found {
+		return GetResponse{}, fmt.Errorf("view %q not found", req.Name)
+	}
+
+	return GetResponse{View: view}, nil
+}
+
+type DeleteRequest struct {
+	Name                string
+	AuthenticatedUserID int
+}
+
+type DeleteResponse struct {
+	View models.View
+}
+
+func (s Service) Delete(req DeleteRequest) (DeleteResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+
+	if _, ok := builtin(name); ok {
+		return DeleteResponse{}, fmt.Errorf("can't delete view: %q is a built-in view", name)
+	}
+
+	view, found, fErr := s.find(req.AuthenticatedUserID, name)
+	if fErr != nil {
+		return DeleteResponse{}, fErr
+	}
+	if !found {
+		return DeleteResponse{}, fmt.Errorf("view %q not found", req.Name)
+	}
+
+	if dErr := s.repository.DeleteView(view.ID); dErr != nil {
+		return DeleteResponse{}, fmt.Errorf("can't delete view: %v", dErr)
+	}
+
+	return DeleteResponse{View: view}, nil
+}
+
+func (s Service) find(userID int, name string) (models.View, bool, error) {
+	views, lErr := s.repository.ListUserViews(userID)
+	if lErr != nil {
+		return models.View{}, false, fmt.Errorf("can't list user views: %v", lErr)
+	}
+
+	for _, view := range views {
+		if view.Name == name {
+			return view, true, nil
+		}
+	}
+
+	return models.View{}, false, nil
+}
+
+// ValidateGroup checks that a listing can be grouped the given way. No
+// grouping is valid too.
+func ValidateGroup(group string) error {
+	if !contains(groups, group) {
+		return fmt.Errorf("unknown grouping %q, use one of %s", group, strings.Join(groups[1:], ", "))
+	}
+
+	return nil
+}
+
+func builtin(name string) (models.View, bool) {
+	for _, view := range Builtins {
+		if view.Name == name {
+			return view, true
+		}
+	}
+
+	return models.View{}, false
+}
+
+// normalizeName lower cases a view name, which has to be a single word of
+// letters, digits, dashes and underscores so it can be typed after -view.
+func normalizeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("view name can't be empty")
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", fmt.Errorf("invalid view name %q, use letters, digits, - and _", name)
+		}
+	}
+
+	return name, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
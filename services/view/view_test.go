@@ -0,0 +1,130 @@
+package view
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"todo-cli-refactor/models"
+)
+
+type mockRepository struct {
+	data map[int]models.View
+}
+
+func (m mockRepository) CreateNewView(view models.View) (models.View, error) {
+	view.ID = len(m.data) + 1
+
+	m.data[view.ID] = view
+
+	return view, nil
+}
+
+func (m mockRepository) ListUserViews(userID int) ([]models.View, error) {
+	var views []models.View
+
+	for _, view := range m.data {
+		if view.UserID == userID {
+			views = append(views, view)
+		}
+	}
+
+	sort.Slice(views, func(i, j int) bool { return views[i].ID < views[j].ID })
+
+	return views, nil
+}
+
+func (m mockRepository) UpdateView(view models.View) (models.View, error) {
+	if _, ok := m.data[view.ID]; !ok {
+		return models.View{}, fmt.Errorf("view %d not found", view.ID)
+	}
+
+	m.data[view.ID] = view
+
+	return view, nil
+}
+
+func (m mockRepository) DeleteView(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+func TestViews(t *testing.T) {
+	s := NewService(mockRepository{data: map[int]models.View{}})
+
+	categories := []models.Category{{ID: 1, Title: "Work", UserID: 3}}
+
+	saved, err := s.Save(SaveRequest{Name: " Work ", Query: "category:work status:open", Sort: "due", Categories: categories,
+		AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if saved.View.Name != "work" {
+		t.Errorf("view names should be lower cased, got %q", saved.View.Name)
+	}
+
+	// saving under the same name replaces the view
+	if _, err := s.Save(SaveRequest{Name: "work", Query: "category:work", Group: models.PriorityGroup, Categories: categories,
+		AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := s.Get(GetRequest{Name: "WORK", AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	expected := models.View{ID: 1, UserID: 3, Name: "work", Query: "category:work", Group: models.PriorityGroup}
+	if !reflect.DeepEqual(got.View, expected) {
+		t.Errorf("got %v, want %v", got.View, expected)
+	}
+
+	if _, err := s.Get(GetRequest{Name: "work", AuthenticatedUserID: 4}); err == nil {
+		t.Errorf("Get should not find the views of another user")
+	}
+
+	if got, err := s.Get(GetRequest{Name: "today", AuthenticatedUserID: 4}); err != nil || got.View.Query == "" {
+		t.Errorf("every user should have the built-in views, got %v, %v", got.View, err)
+	}
+
+	list, err := s.List(ListRequest{AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list.Views) != len(Builtins)+1 || list.Views[len(Builtins)].Name != "work" {
+		t.Errorf("List should give the built-in views and then the saved ones, got %v", list.Views)
+	}
+
+	if _, err := s.Delete(DeleteRequest{Name: "work", AuthenticatedUserID: 3}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Get(GetRequest{Name: "work", AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("a deleted view should be gone")
+	}
+}
+
+func TestSaveValidation(t *testing.T) {
+	s := NewService(mockRepository{data: map[int]models.View{}})
+
+	for name, req := range map[string]SaveRequest{
+		"empty name":       {Name: " "},
+		"spaces":           {Name: "my view"},
+		"built-in name":    {Name: "Today"},
+		"bad query":        {Name: "work", Query: `title~"deploy`},
+		"unknown field":    {Name: "work", Query: "size:large"},
+		"bad operator":     {Name: "work", Query: "tag>#work"},
+		"bad priority":     {Name: "work", Query: "priority>=huge"},
+		"unknown category": {Name: "work", Query: "category:work"},
+		"unknown sort":     {Name: "work", Sort: "size"},
+		"unknown group":    {Name: "work", Group: "tag"},
+	} {
+		if _, err := s.Save(req); err == nil {
+			t.Errorf("%s: Save should fail", name)
+		}
+	}
+
+	if _, err := s.Delete(DeleteRequest{Name: "overdue"}); err == nil {
+		t.Errorf("Delete should refuse built-in views")
+	}
+}
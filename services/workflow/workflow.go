@@ -0,0 +1,194 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"todo-cli-refactor/models"
+)
+
+type ServiceRepository interface {
+	CreateNewWorkflow(w models.Workflow) (models.Workflow, error)
+	ListUserWorkflows(userID int) ([]models.Workflow, error)
+	UpdateWorkflow(w models.Workflow) (models.Workflow, error)
+	DeleteWorkflow(id int) error
+}
+
+// TaskRepository is where the tasks moved into a new workflow are written.
+type TaskRepository interface {
+	ListUserTasks(userID int) ([]models.Task, error)
+	UpdateTask(t models.Task) (models.Task, error)
+}
+
+type Service struct {
+	repository ServiceRepository
+	tasks      TaskRepository
+}
+
+func NewService(repo ServiceRepository, tasks TaskRepository) Service {
+	return Service{
+		repository: repo,
+		tasks:      tasks,
+	}
+}
+
+type GetRequest struct {
+	AuthenticatedUserID int
+}
+
+type GetResponse struct {
+	Workflow models.Workflow
+}
+
+// Get returns the workflow of a user, the default one unless they saved
+// their own.
+func (s Service) Get(req GetRequest) (GetResponse, error) {
+	workflow, _, fErr := s.find(req.AuthenticatedUserID)
+	if fErr != nil {
+		return GetResponse{}, fErr
+	}
+
+	return GetResponse{Workflow: workflow}, nil
+}
+
+type SaveRequest struct {
+	Statuses            []models.WorkflowStatus
+	Transitions         map[string][]string
+	AuthenticatedUserID int
+}
+
+type SaveResponse struct {
+	Workflow models.Workflow
+	// Migrated are the tasks whose status changed: ones stored before
+	// workflows existed and ones in statuses the workflow no longer has.
+	Migrated []models.Task
+}
+
+// Save replaces the workflow of a user and moves their tasks into it.
+func (s Service) Save(req SaveRequest) (SaveResponse, error) {
+	workflow, found, fErr := s.find(req.AuthenticatedUserID)
+	if fErr != nil {
+		return SaveResponse{}, fErr
+	}
+
+	workflow.Statuses = nil
+	for _, status := range req.Statuses {
+		status.Name = normalizeName(status.Name)
+		workflow.Statuses = append(workflow.Statuses, status)
+	}
+
+	workflow.Transitions = nil
+	for from, to := range req.Transitions {
+		if workflow.Transitions == nil {
+			workflow.Transitions = map[string][]string{}
+		}
+
+		names := make([]string, 0, len(to))
+		for _, name := range to {
+			names = append(names, normalizeName(name))
+		}
+		workflow.Transitions[normalizeName(from)] = names
+	}
+
+	if vErr := workflow.Validate(); vErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save workflow: %v", vErr)
+	}
+
+	var saved models.Workflow
+	var sErr error
+	if found {
+		saved, sErr = s.repository.UpdateWorkflow(workflow)
+	} else {
+		saved, sErr = s.repository.CreateNewWorkflow(workflow)
+	}
+	if sErr != nil {
+		return SaveResponse{}, fmt.Errorf("can't save workflow: %v", sErr)
+	}
+
+	migrated, mErr := s.migrate(saved)
+	if mErr != nil {
+		return SaveResponse{}, mErr
+	}
+
+	return SaveResponse{Workflow: saved, Migrated: migrated}, nil
+}
+
+type ResetRequest struct {
+	AuthenticatedUserID int
+}
+
+type ResetResponse struct {
+	Workflow models.Workflow
+	Migrated []models.Task
+}
+
+// Reset drops the workflow a user saved, going back to the default one.
+func (s Service) Reset(req ResetRequest) (ResetResponse, error) {
+	workflow, found, fErr := s.find(req.AuthenticatedUserID)
+	if fErr != nil {
+		return ResetResponse{}, fErr
+	}
+	if !found {
+		return ResetResponse{}, fmt.Errorf("the default workflow is already in use")
+	}
+
+	if dErr := s.repository.DeleteWorkflow(workflow.ID); dErr != nil {
+		return ResetResponse{}, fmt.Errorf("can't delete workflow: %v", dErr)
+	}
+
+	workflow = models.DefaultWorkflow()
+	workflow.UserID = req.AuthenticatedUserID
+
+	migrated, mErr := s.migrate(workflow)
+	if mErr != nil {
+		return ResetResponse{}, mErr
+	}
+
+	return ResetResponse{Workflow: workflow, Migrated: migrated}, nil
+}
+
+// migrate stores the status each task the user owns is in under a workflow,
+// see models.Workflow.StatusOf. Tasks in the trash are left as they are.
+func (s Service) migrate(workflow models.Workflow) ([]models.Task, error) {
+	tasks, lErr := s.tasks.ListUserTasks(workflow.UserID)
+	if lErr != nil {
+		return nil, fmt.Errorf("can't list user tasks: %v", lErr)
+	}
+
+	var migrated []models.Task
+	for _, task := range tasks {
+		// tasks of others shared with the user follow their owner's workflow
+		status := workflow.StatusOf(task)
+		if task.DeletedAt != nil || task.UserID != workflow.UserID || task.Status == status {
+			continue
+		}
+
+		task.Status = status
+		updated, uErr := s.tasks.UpdateTask(task)
+		if uErr != nil {
+			return nil, fmt.Errorf("can't update task: %v", uErr)
+		}
+		migrated = append(migrated, updated)
+	}
+
+	return migrated, nil
+}
+
+func (s Service) find(userID int) (models.Workflow, bool, error) {
+	workflows, lErr := s.repository.ListUserWorkflows(userID)
+	if lErr != nil {
+		return models.Workflow{}, false, fmt.Errorf("can't list user workflows: %v", lErr)
+	}
+
+	if len(workflows) > 0 {
+		return workflows[0], true, nil
+	}
+
+	workflow := models.DefaultWorkflow()
+	workflow.UserID = userID
+
+	return workflow, false, nil
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
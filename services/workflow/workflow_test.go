@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+	"todo-cli-refactor/models"
+)
+
+type mockRepository struct {
+	data map[int]models.Workflow
+}
+
+func (m mockRepository) CreateNewWorkflow(workflow models.Workflow) (models.Workflow, error) {
+	workflow.ID = len(m.data) + 1
+
+	m.data[workflow.ID] = workflow
+
+	return workflow, nil
+}
+
+func (m mockRepository) ListUserWorkflows(userID int) ([]models.Workflow, error) {
+	var workflows []models.Workflow
+
+	for _, workflow := range m.data {
+		if workflow.UserID == userID {
+			workflows = append(workflows, workflow)
+		}
+	}
+
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].ID < workflows[j].ID })
+
+	return workflows, nil
+}
+
+func (m mockRepository) UpdateWorkflow(workflow models.Workflow) (models.Workflow, error) {
+	if _, ok := m.data[workflow.ID]; !ok {
+		return models.Workflow{}, fmt.Errorf("workflow %d not found", workflow.ID)
+	}
+
+	m.data[workflow.ID] = workflow
+
+	return workflow, nil
+}
+
+func (m mockRepository) DeleteWorkflow(id int) error {
+	delete(m.data, id)
+
+	return nil
+}
+
+type mockTaskRepository struct {
+	data map[int]models.Task
+}
+
+func (m mockTaskRepository) ListUserTasks(userID int) ([]models.Task, error) {
+	var tasks []models.Task
+
+	for _, task := range m.data {
+		if task.UserID == userID {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+func (m mockTaskRepository) UpdateTask(task models.Task) (models.Task, error) {
+	m.data[task.ID] = task
+
+	return task, nil
+}
+
+func TestWorkflow(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	tasks := mockTaskRepository{
+		data: map[int]models.Task{
+			1: {ID: 1, Title: "Legacy open", UserID: 3},
+			2: {ID: 2, Title: "Legacy done", UserID: 3, IsDone: true},
+			3: {ID: 3, Title: "In review", UserID: 3, Status: "review"},
+			4: {ID: 4, Title: "Trashed", UserID: 3, Status: "review", DeletedAt: &deletedAt},
+			5: {ID: 5, Title: "Doing", UserID: 3, Status: "doing"},
+		},
+	}
+
+	s := NewService(mockRepository{data: map[int]models.Workflow{}}, tasks)
+
+	got, err := s.Get(GetRequest{AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Workflow.Statuses, models.DefaultWorkflow().Statuses) {
+		t.Errorf("expected the default workflow, got %v", got.Workflow)
+	}
+
+	saved, err := s.Save(SaveRequest{
+		Statuses: []models.WorkflowStatus{
+			{Name: " Todo "},
+			{Name: "doing", WIPLimit: 2},
+			{Name: "shipped", Done: true},
+		},
+		Transitions:         map[string][]string{"Todo": {"DOING"}},
+		AuthenticatedUserID: 3,
+	})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if saved.Workflow.Statuses[0].Name != "todo" || !reflect.DeepEqual(saved.Workflow.Transitions, map[string][]string{"todo": {"doing"}}) {
+		t.Errorf("expected names to be normalized, got %v", saved.Workflow)
+	}
+
+	var migrated []int
+	for _, task := range saved.Migrated {
+		migrated = append(migrated, task.ID)
+	}
+	if !reflect.DeepEqual(migrated, []int{1, 2, 3}) {
+		t.Errorf("got migrated tasks %v, want 1, 2 and 3", migrated)
+	}
+
+	statuses := map[int]string{}
+	for id, task := range tasks.data {
+		statuses[id] = task.Status
+	}
+	expected := map[int]string{1: "todo", 2: "shipped", 3: "todo", 4: "review", 5: "doing"}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("got statuses %v, want %v", statuses, expected)
+	}
+
+	invalid := []SaveRequest{
+		{Statuses: []models.WorkflowStatus{{Name: "todo"}}},
+		{Statuses: []models.WorkflowStatus{{Name: "open"}, {Name: "done", Done: true}}},
+		{Statuses: []models.WorkflowStatus{{Name: "done"}, {Name: "shipped", Done: true}}},
+		{Statuses: []models.WorkflowStatus{{Name: "todo"}, {Name: "todo"}, {Name: "done", Done: true}}},
+		{Statuses: []models.WorkflowStatus{{Name: "todo"}, {Name: "done", Done: true}}, Transitions: map[string][]string{"todo": {"review"}}},
+	}
+	for _, req := range invalid {
+		req.AuthenticatedUserID = 3
+		if _, err := s.Save(req); err == nil {
+			t.Errorf("Save should fail for %v", req)
+		}
+	}
+
+	reset, err := s.Reset(ResetRequest{AuthenticatedUserID: 3})
+	if err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if len(reset.Migrated) != 1 || tasks.data[2].Status != "done" {
+		t.Errorf("expected tasks back in the default statuses, got %v", tasks.data)
+	}
+
+	if _, err := s.Reset(ResetRequest{AuthenticatedUserID: 3}); err == nil {
+		t.Errorf("Reset should fail without a saved workflow")
+	}
+}